@@ -1,16 +1,46 @@
 package main
 
 import (
+	"bufio"
+	"errors"
+	"flag"
 	"fmt"
+	"io/fs"
 	"os"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
+	"github.com/debtq/debtq/internal/cli"
 	"github.com/debtq/debtq/internal/config"
 	"github.com/debtq/debtq/internal/storage"
 	"github.com/debtq/debtq/internal/tui"
 )
 
+// version is the build version, injected with -ldflags "-X main.version=..."
+var version = "dev"
+
 func main() {
+	showVersion := flag.Bool("version", false, "print the version and exit")
+	noSave := flag.Bool("no-save", false, "read the data file but keep changes in memory, discarding them on exit")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: debtq [flags] [command]\n\nRuns the debtq TUI when no command is given.\n\nCommands:\n  add <expense|debt|investment|savings> [flags]\n        record an entry without opening the TUI (see debtq add <kind> -h)\n  export --format json|csv [--out path]\n        dump all data for backups (see debtq export -h)\n  import [--mode merge|replace] file.json\n        load a JSON export, merging by default (see debtq import -h)\n\nFlags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Printf("debtq %s\n", version)
+		return
+	}
+
+	command := flag.Arg(0)
+	if command != "" && command != "add" && command != "export" && command != "import" {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n", command)
+		flag.Usage()
+		os.Exit(2)
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
@@ -25,12 +55,37 @@ func main() {
 	}
 
 	// Initialize storage
-	store, err := storage.New(cfg)
+	store, err := openStorage(cfg, *noSave)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing storage: %v\n", err)
 		os.Exit(1)
 	}
 
+	if notice := store.Notice(); notice != "" && command != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", notice)
+	}
+
+	if command != "" {
+		var err error
+		switch command {
+		case "add":
+			err = cli.Add(flag.Args()[1:], cfg, store, os.Stdout)
+		case "export":
+			err = cli.Export(flag.Args()[1:], store, os.Stdout)
+		case "import":
+			err = cli.Import(flag.Args()[1:], store, os.Stdout)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *noSave {
+		fmt.Fprintln(os.Stderr, "debtq: --no-save is set, changes are discarded on exit")
+	}
+
 	// Create and run TUI
 	model := tui.New(cfg, store)
 	p := tea.NewProgram(model, tea.WithAltScreen())
@@ -40,3 +95,71 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// openStorage opens the data file, asking for the passphrase when it is
+// encrypted. With noSave it works on a copy and never writes the file back.
+func openStorage(cfg *config.Config, noSave bool) (*storage.Storage, error) {
+	if !noSave && !cfg.Encrypted {
+		return storage.New(cfg)
+	}
+
+	var p storage.Persister = storage.NewFilePersister(cfg)
+	if noSave {
+		data, err := p.Load()
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		p = storage.NewMemoryPersister(data)
+	}
+	if cfg.Encrypted {
+		passphrase, err := readPassphrase(p)
+		if err != nil {
+			return nil, err
+		}
+		p = storage.NewEncryptedPersister(p, passphrase)
+	}
+	return storage.NewWithPersister(cfg, p)
+}
+
+// readPassphrase asks for the data file passphrase, twice when the file is
+// about to be encrypted for the first time. Without a terminal it reads one
+// line from stdin so scripts can pipe it in.
+func readPassphrase(p storage.Persister) (string, error) {
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("reading passphrase: %w", err)
+		}
+		passphrase := strings.TrimRight(line, "\r\n")
+		if passphrase == "" {
+			return "", fmt.Errorf("the passphrase cannot be empty")
+		}
+		return passphrase, nil
+	}
+
+	prompt := func(label string) (string, error) {
+		fmt.Fprint(os.Stderr, label)
+		passphrase, err := term.ReadPassword(os.Stdin.Fd())
+		fmt.Fprintln(os.Stderr)
+		return string(passphrase), err
+	}
+
+	passphrase, err := prompt("Passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("the passphrase cannot be empty")
+	}
+	if data, err := p.Load(); err == nil && storage.IsEncrypted(data) {
+		return passphrase, nil
+	}
+	again, err := prompt("Repeat passphrase to encrypt the data file: ")
+	if err != nil {
+		return "", err
+	}
+	if again != passphrase {
+		return "", fmt.Errorf("the passphrases do not match")
+	}
+	return passphrase, nil
+}
@@ -18,7 +18,8 @@ if ! command -v go &> /dev/null; then
 fi
 
 echo "Building $BINARY_NAME..."
-go build -ldflags="-s -w" -o "$BINARY_NAME" ./cmd/main.go
+VERSION=$(git describe --tags --always --dirty 2>/dev/null || echo dev)
+go build -ldflags="-s -w -X main.version=$VERSION" -o "$BINARY_NAME" ./cmd/main.go
 
 if [ ! -f "$BINARY_NAME" ]; then
     echo "Error: Build failed."
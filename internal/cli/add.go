@@ -0,0 +1,285 @@
+// Package cli implements debtq's non-interactive subcommands
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/debtq/debtq/internal/config"
+	"github.com/debtq/debtq/internal/models"
+	"github.com/debtq/debtq/internal/storage"
+)
+
+// addKinds lists what `debtq add` can record
+var addKinds = []string{"expense", "debt", "investment", "savings"}
+
+// Add runs `debtq add <kind> [flags]`, records the entry and writes a status
+// line to out
+func Add(args []string, cfg *config.Config, store *storage.Storage, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: debtq add <%s> [flags]", strings.Join(addKinds, "|"))
+	}
+
+	switch args[0] {
+	case "expense":
+		return addExpense(args[1:], cfg, store, out)
+	case "debt":
+		return addDebt(args[1:], cfg, store, out)
+	case "investment":
+		return addInvestment(args[1:], cfg, store, out)
+	case "savings":
+		return addSavings(args[1:], cfg, store, out)
+	default:
+		return fmt.Errorf("unknown kind %q, use one of: %s", args[0], strings.Join(addKinds, ", "))
+	}
+}
+
+func addExpense(args []string, cfg *config.Config, store *storage.Storage, out io.Writer) error {
+	fs := flag.NewFlagSet("add expense", flag.ContinueOnError)
+	amount := fs.Float64("amount", 0, "amount spent (required)")
+	desc := fs.String("desc", "", "description (required)")
+	category := fs.String("category", string(cfg.ExpenseCategory()), "category: "+categoryOptions())
+	date := fs.String("date", "", "date as YYYY-MM-DD (default today)")
+	currency := fs.String("currency", "", "currency code (default the configured currency)")
+	tags := fs.String("tags", "", "comma-separated tags, e.g. vacation,work")
+	notes := fs.String("notes", "", "longer notes, e.g. receipt details")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *amount <= 0 {
+		return fmt.Errorf("--amount must be positive")
+	}
+	if strings.TrimSpace(*desc) == "" {
+		return fmt.Errorf("--desc is required")
+	}
+	cat := models.ExpenseCategory(strings.ToLower(strings.TrimSpace(*category)))
+	if !models.IsValidCategory(cat) {
+		return fmt.Errorf("unknown category %q, use one of: %s", *category, categoryOptions())
+	}
+	when, err := parseDate("--date", *date, time.Now())
+	if err != nil {
+		return err
+	}
+	code, err := cfg.ResolveCurrency(*currency)
+	if err != nil {
+		return fmt.Errorf("--currency: %w", err)
+	}
+
+	expense, err := store.AddExpense(*amount, *desc, cat, when, code, models.ParseTags(*tags), *notes)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Added expense %s: %s %s (%s)\n", expense.ID, formatAmount(cfg, expense.Amount, expense.Currency), expense.Description, expense.Category)
+
+	// Same round-up behaviour as adding an expense in the TUI
+	if expense.Currency == "" {
+		stashed, target, err := store.StashRoundUp(expense.Amount, expense.Description)
+		if err != nil {
+			return fmt.Errorf("round-up failed: %w", err)
+		}
+		if stashed > 0 {
+			fmt.Fprintf(out, "Stashed %s into %s\n", formatAmount(cfg, stashed, ""), target.ProductName)
+		}
+	}
+	return nil
+}
+
+func addDebt(args []string, cfg *config.Config, store *storage.Storage, out io.Writer) error {
+	fs := flag.NewFlagSet("add debt", flag.ContinueOnError)
+	txType := fs.String("type", "", "borrowed or lent (required)")
+	person := fs.String("person", "", "person name (required)")
+	amount := fs.Float64("amount", 0, "amount (required)")
+	desc := fs.String("desc", "", "description")
+	date := fs.String("date", "", "date borrowed/lent as YYYY-MM-DD (default today)")
+	due := fs.String("due", "", "due date as YYYY-MM-DD")
+	interest := fs.Float64("interest", 0, "annual interest rate in percent")
+	interestType := fs.String("interest-type", string(models.InterestSimple), "simple or compound")
+	grace := fs.Int("grace", 0, "interest-free days after --date before interest accrues")
+	installments := fs.Int("installments", 0, "repay as an EMI in this many monthly installments (at least 2)")
+	currency := fs.String("currency", "", "currency code (default the configured currency)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	t := models.TransactionType(strings.ToLower(strings.TrimSpace(*txType)))
+	if t != models.Borrowed && t != models.Lent {
+		return fmt.Errorf("--type must be 'borrowed' or 'lent'")
+	}
+	if strings.TrimSpace(*person) == "" {
+		return fmt.Errorf("--person is required")
+	}
+	if *amount <= 0 {
+		return fmt.Errorf("--amount must be positive")
+	}
+	when, err := parseDate("--date", *date, time.Now())
+	if err != nil {
+		return err
+	}
+	var dueDate *time.Time
+	if *due != "" {
+		parsed, err := parseDate("--due", *due, time.Time{})
+		if err != nil {
+			return err
+		}
+		dueDate = &parsed
+	}
+	if *interest < 0 {
+		return fmt.Errorf("--interest cannot be negative")
+	}
+	if *grace < 0 {
+		return fmt.Errorf("--grace cannot be negative")
+	}
+	it := models.InterestType(strings.ToLower(*interestType))
+	if it != models.InterestSimple && it != models.InterestCompound {
+		return fmt.Errorf("--interest-type must be 'simple' or 'compound'")
+	}
+	code, err := cfg.ResolveCurrency(*currency)
+	if err != nil {
+		return fmt.Errorf("--currency: %w", err)
+	}
+
+	if *installments != 0 {
+		if *interest > 0 {
+			return fmt.Errorf("--installments cannot be combined with --interest; include the interest in --amount")
+		}
+		tx, err := store.AddInstallmentDebt(t, *person, *amount, *installments, *desc, when, dueDate, code)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Added debt %s: %s %s %s in %d installments of %s\n", tx.ID, tx.Type, formatAmount(cfg, tx.Amount, tx.Currency), tx.PersonName, tx.TotalInstallments, formatAmount(cfg, tx.InstallmentAmount, tx.Currency))
+		return nil
+	}
+
+	tx, err := store.AddDebtTransactionWithInterest(t, *person, *amount, *desc, when, dueDate, *interest, it, *grace, code)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Added debt %s: %s %s %s\n", tx.ID, tx.Type, formatAmount(cfg, tx.Amount, tx.Currency), tx.PersonName)
+	return nil
+}
+
+func addInvestment(args []string, cfg *config.Config, store *storage.Storage, out io.Writer) error {
+	fs := flag.NewFlagSet("add investment", flag.ContinueOnError)
+	invType := fs.String("type", "", "investment type: "+investmentTypeOptions()+" (required)")
+	name := fs.String("name", "", "name (required)")
+	invested := fs.Float64("invested", 0, "amount invested (required)")
+	current := fs.Float64("current", 0, "current value (default the invested amount)")
+	units := fs.Float64("units", 0, "units held")
+	date := fs.String("date", "", "purchase date as YYYY-MM-DD (default today)")
+	notes := fs.String("notes", "", "notes")
+	currency := fs.String("currency", "", "currency code (default the configured currency)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	t := models.InvestmentType(strings.ToLower(strings.TrimSpace(*invType)))
+	if !models.IsValidInvestmentType(t) {
+		return fmt.Errorf("unknown investment type %q, use one of: %s", *invType, investmentTypeOptions())
+	}
+	if strings.TrimSpace(*name) == "" {
+		return fmt.Errorf("--name is required")
+	}
+	if *invested <= 0 {
+		return fmt.Errorf("--invested must be positive")
+	}
+	if *current < 0 || *units < 0 {
+		return fmt.Errorf("--current and --units cannot be negative")
+	}
+	if *current == 0 {
+		*current = *invested
+	}
+	purchased, err := parseDate("--date", *date, time.Now())
+	if err != nil {
+		return err
+	}
+	code, err := cfg.ResolveCurrency(*currency)
+	if err != nil {
+		return fmt.Errorf("--currency: %w", err)
+	}
+
+	inv, err := store.AddInvestment(t, *name, *invested, *current, *units, purchased, *notes, code)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Added investment %s: %s [%s] %s\n", inv.ID, inv.Name, inv.Type, formatAmount(cfg, inv.CurrentValue, inv.Currency))
+	return nil
+}
+
+func addSavings(args []string, cfg *config.Config, store *storage.Storage, out io.Writer) error {
+	fs := flag.NewFlagSet("add savings", flag.ContinueOnError)
+	name := fs.String("name", "", "what you are saving for (required)")
+	target := fs.Float64("target", 0, "target amount (required)")
+	date := fs.String("date", "", "target date as YYYY-MM-DD (required)")
+	desc := fs.String("desc", "", "description")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(*name) == "" {
+		return fmt.Errorf("--name is required")
+	}
+	if *target <= 0 {
+		return fmt.Errorf("--target must be positive")
+	}
+	if *date == "" {
+		return fmt.Errorf("--date is required")
+	}
+	targetDate, err := parseDate("--date", *date, time.Time{})
+	if err != nil {
+		return err
+	}
+
+	goal, err := store.AddSavingsTarget(*name, *target, targetDate, *desc)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Added savings goal %s: %s %s by %s\n", goal.ID, goal.ProductName, formatAmount(cfg, goal.TargetAmount, ""), goal.TargetDate.Format("2006-01-02"))
+	return nil
+}
+
+// parseDate parses a YYYY-MM-DD flag value, returning fallback when it is empty
+func parseDate(flagName, value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be YYYY-MM-DD", flagName)
+	}
+	return parsed, nil
+}
+
+// currencyOf returns the currency an entry is recorded in
+func currencyOf(cfg *config.Config, code string) string {
+	if code == "" {
+		return cfg.Currency
+	}
+	return code
+}
+
+// formatAmount formats amount in the entry's currency (code, or the base
+// currency when empty) with digits grouped in the configured number format
+func formatAmount(cfg *config.Config, amount float64, code string) string {
+	currency := currencyOf(cfg, code)
+	return currency + " " + cfg.FormatNumber(amount, currency)
+}
+
+func categoryOptions() string {
+	names := make([]string, len(models.ExpenseCategories))
+	for i, c := range models.ExpenseCategories {
+		names[i] = string(c)
+	}
+	return strings.Join(names, ", ")
+}
+
+func investmentTypeOptions() string {
+	names := make([]string, len(models.InvestmentTypes))
+	for i, t := range models.InvestmentTypes {
+		names[i] = string(t)
+	}
+	return strings.Join(names, ", ")
+}
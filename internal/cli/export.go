@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/debtq/debtq/internal/storage"
+)
+
+// Export runs `debtq export --format json|csv --out path`. JSON is the whole
+// dataset in one file (stdout when --out is omitted); CSV is one file per
+// entity type in the --out directory.
+func Export(args []string, store *storage.Storage, out io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "json", "json or csv")
+	path := fs.String("out", "", "output file for json (default stdout) or directory for csv (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	switch *format {
+	case "json":
+		if *path == "" {
+			return store.ExportJSON(out)
+		}
+		f, err := os.Create(*path)
+		if err != nil {
+			return err
+		}
+		if err := store.ExportJSON(f); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Exported data to %s\n", *path)
+		return nil
+	case "csv":
+		if *path == "" {
+			return fmt.Errorf("--out is required for csv: a directory to write one file per entity type into")
+		}
+		if err := store.ExportCSVDir(*path); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Exported %s, %s, %s and %s to %s\n",
+			storage.ExpensesCSVFile, storage.DebtsCSVFile, storage.InvestmentsCSVFile, storage.SavingsCSVFile, *path)
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q, use json or csv", *format)
+	}
+}
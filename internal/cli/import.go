@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/debtq/debtq/internal/storage"
+)
+
+// Import runs `debtq import [--mode merge|replace] file`, reading a dataset
+// written by `debtq export --format json`. Merging is the default since
+// replacing discards everything stored.
+func Import(args []string, store *storage.Storage, out io.Writer) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	mode := fs.String("mode", string(storage.MergeAppend), "merge (add records not already stored) or replace (discard stored data)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected one JSON file to import, e.g. debtq import backup.json")
+	}
+
+	path := fs.Arg(0)
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := store.ImportJSON(f, storage.MergeMode(*mode)); err != nil {
+		return fmt.Errorf("importing %s: %w", path, err)
+	}
+	fmt.Fprintf(out, "Imported %s (%s)\n", path, *mode)
+	return nil
+}
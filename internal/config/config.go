@@ -2,20 +2,155 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/debtq/debtq/internal/models"
 )
 
 const (
 	DefaultConfigDir  = ".config/debtq"
 	DefaultConfigFile = "config.json"
+	// TemplatesDir, inside the config directory, holds custom Obsidian note templates
+	TemplatesDir = "templates"
+
+	DefaultDueDateReminderDays = 30
+	DefaultRoundUpStep         = 10
+
+	DefaultConcentrationThresholdPct = 60
+
+	// DataFileEnv overrides Config.DataFile when set
+	DataFileEnv = "DEBTQ_DATA_FILE"
+	// VaultPathEnv overrides Config.ObsidianVaultPath when set
+	VaultPathEnv = "DEBTQ_VAULT_PATH"
+
+	// DefaultObsidianPeopleFolder holds the per-person debt notes
+	DefaultObsidianPeopleFolder = "People"
+
+	// Digit grouping styles for NumberFormat
+	NumberFormatWestern = "western" // 1,234,567.50
+	NumberFormatIndian  = "indian"  // 12,34,567.50
+
+	// Periods for ExpenseGrouping
+	ExpenseGroupingMonth = "month"
+	ExpenseGroupingWeek  = "week" // ISO 8601 weeks, Monday to Sunday
 )
 
-// Config holds application configuration
+// Config holds application configuration.
+//
+// ObsidianVaultPath and DataFile are resolved with the precedence environment
+// (VaultPathEnv, DataFileEnv) over config file over defaults. Overrides are
+// never written back to the config file.
 type Config struct {
 	ObsidianVaultPath string `json:"obsidian_vault_path"`
 	DataFile          string `json:"data_file"`
 	Currency          string `json:"currency"`
+	// DefaultCategory pre-fills the category of new expenses (unset uses "other")
+	DefaultCategory models.ExpenseCategory `json:"default_category,omitempty"`
+	// DefaultToToday pre-fills today's date when adding an expense; false leaves
+	// the date empty and requires one to be typed (unset means true)
+	DefaultToToday *bool `json:"default_to_today,omitempty"`
+	// ExcludedCategories are left out of the monthly/all-time expense totals
+	// (the expense list still shows them)
+	ExcludedCategories []models.ExpenseCategory `json:"excluded_categories,omitempty"`
+	// RemainderAssignment picks which part receives the rounding residual when an
+	// amount is split (e.g. 100/3 = 33.34 + 33.33 + 33.33): "first" (default), "last" or "largest"
+	RemainderAssignment models.RemainderAssignment `json:"remainder_assignment,omitempty"`
+	// NetWorthGoal is an optional target net worth (0 disables it) to reach by
+	// NetWorthGoalDate (YYYY-MM-DD)
+	NetWorthGoal     float64 `json:"net_worth_goal,omitempty"`
+	NetWorthGoalDate string  `json:"net_worth_goal_date,omitempty"`
+	// DueDateReminderDays flags unsettled debts older than this many days that
+	// have no due date (0 uses DefaultDueDateReminderDays)
+	DueDateReminderDays int `json:"due_date_reminder_days,omitempty"`
+	// GoalCompletionThresholdPct marks a savings goal complete once it reaches this
+	// percentage of its target (e.g. 99). 0 or anything above 100 means 100.
+	GoalCompletionThresholdPct float64 `json:"goal_completion_threshold_pct,omitempty"`
+	// RoundUpSavings rounds every new expense up to the next RoundUpStep
+	// (default 10) and contributes the difference to savings goal RoundUpTargetID
+	RoundUpSavings  bool    `json:"round_up_savings,omitempty"`
+	RoundUpStep     float64 `json:"round_up_step,omitempty"`
+	RoundUpTargetID string  `json:"round_up_target_id,omitempty"`
+	// HideCompletedAfterDays drops completed savings goals from the Savings list,
+	// and fully settled people from the People list, this many days after they
+	// were finished (0 keeps everything visible)
+	HideCompletedAfterDays int `json:"hide_completed_after_days,omitempty"`
+	// ShowStartupDigest shows a summary of overdue debts and goals on launch
+	// (unset means true)
+	ShowStartupDigest *bool `json:"show_startup_digest,omitempty"`
+	// MonthlyBudget is a soft cap on total spending per month (0 disables it)
+	MonthlyBudget float64 `json:"monthly_budget,omitempty"`
+	// MonthlySavingsBudget is how much goes into savings goals each month; the
+	// Savings view suggests how to split it (0 disables the suggestion)
+	MonthlySavingsBudget float64 `json:"monthly_savings_budget,omitempty"`
+	// ConcentrationThresholdPct flags an investment type holding more than this
+	// percentage of the portfolio (0 uses DefaultConcentrationThresholdPct)
+	ConcentrationThresholdPct float64 `json:"concentration_threshold_pct,omitempty"`
+	// ExchangeRates gives the value in Currency of one unit of each other currency
+	// code (e.g. {"USD": 83.2}), used to convert entries in those currencies in totals
+	ExchangeRates map[string]float64 `json:"exchange_rates,omitempty"`
+	// NumberFormat groups digits of displayed amounts: "western" or "indian".
+	// Unset picks indian grouping for INR and western for everything else.
+	NumberFormat string `json:"number_format,omitempty"`
+	// ObsidianSubdir writes every note into this folder of the vault (e.g.
+	// "Finance") instead of the vault root
+	ObsidianSubdir string `json:"obsidian_subdir,omitempty"`
+	// ObsidianFolders optionally puts each kind of note in its own folder under
+	// ObsidianSubdir (unset keeps them flat)
+	ObsidianFolders *ObsidianFolders `json:"obsidian_folders,omitempty"`
+	// ExpenseGrouping groups the Obsidian expenses note by "month" (default) or
+	// "week"
+	ExpenseGrouping string `json:"expense_grouping,omitempty"`
+	// AutoSync re-syncs the Obsidian notes shortly after every change made in the TUI
+	AutoSync bool `json:"auto_sync,omitempty"`
+	// Encrypted keeps the data file encrypted with a passphrase asked for at
+	// startup
+	Encrypted bool `json:"encrypted,omitempty"`
+
+	// Environment overrides applied by Load, kept so Save can write back the
+	// config-file values they replaced
+	dataFileOverride, vaultPathOverride *envOverride
+}
+
+// ObsidianFolders are folders, relative to Config.ObsidianSubdir, for each kind
+// of note. Empty keeps the note directly in ObsidianSubdir, except People which
+// defaults to DefaultObsidianPeopleFolder.
+type ObsidianFolders struct {
+	Dashboard string `json:"dashboard,omitempty"`
+	Expenses  string `json:"expenses,omitempty"`
+	Debts     string `json:"debts,omitempty"`
+	People    string `json:"people,omitempty"`
+	NetWorth  string `json:"net_worth,omitempty"`
+	Savings   string `json:"savings,omitempty"`
+}
+
+func (f ObsidianFolders) all() []string {
+	return []string{f.Dashboard, f.Expenses, f.Debts, f.People, f.NetWorth, f.Savings}
+}
+
+// NoteFolders returns the vault-relative folder for each kind of note
+func (c *Config) NoteFolders() ObsidianFolders {
+	var f ObsidianFolders
+	if c.ObsidianFolders != nil {
+		f = *c.ObsidianFolders
+	}
+	if f.People == "" {
+		f.People = DefaultObsidianPeopleFolder
+	}
+	for _, dir := range []*string{&f.Dashboard, &f.Expenses, &f.Debts, &f.People, &f.NetWorth, &f.Savings} {
+		*dir = filepath.Join(c.ObsidianSubdir, *dir)
+	}
+	return f
+}
+
+// envOverride is a config value taken from the environment and the config-file
+// value it replaced
+type envOverride struct {
+	value, fileValue string
 }
 
 // DefaultConfig returns default configuration
@@ -25,7 +160,109 @@ func DefaultConfig() *Config {
 		ObsidianVaultPath: filepath.Join(homeDir, "Documents", "obsidian-notes", "debtq"),
 		DataFile:          filepath.Join(homeDir, DefaultConfigDir, "data.json"),
 		Currency:          "INR",
+
+		GoalCompletionThresholdPct: 100,
+	}
+}
+
+// GoalCompletionThreshold returns the effective goal completion threshold as a fraction of the target
+func (c *Config) GoalCompletionThreshold() float64 {
+	if c.GoalCompletionThresholdPct <= 0 || c.GoalCompletionThresholdPct > 100 {
+		return 1
+	}
+	return c.GoalCompletionThresholdPct / 100
+}
+
+// ConcentrationThreshold returns the effective concentration threshold as a fraction of the portfolio
+func (c *Config) ConcentrationThreshold() float64 {
+	if c.ConcentrationThresholdPct <= 0 || c.ConcentrationThresholdPct > 100 {
+		return DefaultConcentrationThresholdPct / 100.0
+	}
+	return c.ConcentrationThresholdPct / 100
+}
+
+// Rates returns the exchange rates for converting entries to Currency
+func (c *Config) Rates() models.ExchangeRates {
+	rates := make(map[string]float64, len(c.ExchangeRates))
+	for code, rate := range c.ExchangeRates {
+		rates[strings.ToUpper(code)] = rate
+	}
+	return models.ExchangeRates{Base: c.Currency, Rates: rates}
+}
+
+// ResolveCurrency checks that amounts in code can be converted to Currency and
+// returns the code to store on an entry: upper-cased, and empty for Currency
+// itself. A code without an exchange rate is an error, as it would otherwise be
+// counted 1:1 in every total.
+func (c *Config) ResolveCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" || code == strings.ToUpper(c.Currency) {
+		return "", nil
+	}
+	if _, ok := c.Rates().Rates[code]; !ok {
+		return "", fmt.Errorf("no exchange rate configured for %s", code)
+	}
+	return code, nil
+}
+
+// NumberFormatFor returns the digit grouping style for amounts in currency
+func (c *Config) NumberFormatFor(currency string) string {
+	if c.NumberFormat != "" {
+		return c.NumberFormat
 	}
+	if strings.EqualFold(currency, "INR") {
+		return NumberFormatIndian
+	}
+	return NumberFormatWestern
+}
+
+// FormatNumber formats amount with two decimals, grouping digits in the number
+// format for currency. Every amount shown to the user goes through here.
+func (c *Config) FormatNumber(amount float64, currency string) string {
+	return GroupDigits(amount, c.NumberFormatFor(currency))
+}
+
+// GroupDigits formats f with two decimals and thousands separators: groups of
+// three (1,234,567.50), or for the indian style three then twos (12,34,567.50)
+func GroupDigits(f float64, style string) string {
+	s := strconv.FormatFloat(math.Abs(f), 'f', 2, 64)
+	intPart, frac := s[:len(s)-3], s[len(s)-3:]
+
+	if len(intPart) > 3 {
+		size := 3
+		if style == NumberFormatIndian {
+			size = 2
+		}
+		head, groups := intPart[:len(intPart)-3], []string{intPart[len(intPart)-3:]}
+		for len(head) > size {
+			groups = append([]string{head[len(head)-size:]}, groups...)
+			head = head[:len(head)-size]
+		}
+		intPart = strings.Join(append([]string{head}, groups...), ",")
+	}
+
+	if f < 0 && s != "0.00" {
+		return "-" + intPart + frac
+	}
+	return intPart + frac
+}
+
+// ExpenseCategory returns the category new expenses default to
+func (c *Config) ExpenseCategory() models.ExpenseCategory {
+	if c.DefaultCategory == "" {
+		return models.CategoryOther
+	}
+	return c.DefaultCategory
+}
+
+// DateDefaultsToToday reports whether new expenses are dated today unless another date is typed
+func (c *Config) DateDefaultsToToday() bool {
+	return c.DefaultToToday == nil || *c.DefaultToToday
+}
+
+// StartupDigestEnabled reports whether the startup digest should be shown
+func (c *Config) StartupDigestEnabled() bool {
+	return c.ShowStartupDigest == nil || *c.ShowStartupDigest
 }
 
 // GetConfigPath returns the config file path
@@ -37,6 +274,15 @@ func GetConfigPath() (string, error) {
 	return filepath.Join(homeDir, DefaultConfigDir, DefaultConfigFile), nil
 }
 
+// GetTemplatesDir returns the directory searched for custom Obsidian note templates
+func GetTemplatesDir() (string, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), TemplatesDir), nil
+}
+
 // Load loads configuration from file
 func Load() (*Config, error) {
 	configPath, err := GetConfigPath()
@@ -51,6 +297,7 @@ func Load() (*Config, error) {
 		if err := cfg.Save(); err != nil {
 			return nil, err
 		}
+		cfg.applyEnv()
 		return cfg, nil
 	}
 
@@ -63,10 +310,27 @@ func Load() (*Config, error) {
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return nil, err
 	}
+	cfg.DefaultCategory = models.ExpenseCategory(strings.ToLower(strings.TrimSpace(string(cfg.DefaultCategory))))
+	if err := cfg.checkDefaultCategory(); err != nil {
+		return nil, err
+	}
 
+	cfg.applyEnv()
 	return &cfg, nil
 }
 
+// applyEnv applies environment variable overrides on top of the config file
+func (c *Config) applyEnv() {
+	if path := strings.TrimSpace(os.Getenv(DataFileEnv)); path != "" {
+		c.dataFileOverride = &envOverride{value: path, fileValue: c.DataFile}
+		c.DataFile = path
+	}
+	if path := strings.TrimSpace(os.Getenv(VaultPathEnv)); path != "" {
+		c.vaultPathOverride = &envOverride{value: path, fileValue: c.ObsidianVaultPath}
+		c.ObsidianVaultPath = path
+	}
+}
+
 // Save saves configuration to file
 func (c *Config) Save() error {
 	configPath, err := GetConfigPath()
@@ -80,7 +344,16 @@ func (c *Config) Save() error {
 		return err
 	}
 
-	data, err := json.MarshalIndent(c, "", "  ")
+	// Keep environment overrides out of the file unless the value was changed since
+	out := *c
+	if o := c.dataFileOverride; o != nil && out.DataFile == o.value {
+		out.DataFile = o.fileValue
+	}
+	if o := c.vaultPathOverride; o != nil && out.ObsidianVaultPath == o.value {
+		out.ObsidianVaultPath = o.fileValue
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -88,7 +361,75 @@ func (c *Config) Save() error {
 	return os.WriteFile(configPath, data, 0644)
 }
 
-// EnsureObsidianDir ensures the obsidian directory exists
+// EnsureObsidianDir ensures the obsidian directory (and ObsidianSubdir) exists
 func (c *Config) EnsureObsidianDir() error {
-	return os.MkdirAll(c.ObsidianVaultPath, 0755)
+	return os.MkdirAll(filepath.Join(c.ObsidianVaultPath, c.ObsidianSubdir), 0755)
+}
+
+// Validate checks that the configuration is usable before it is saved
+func (c *Config) Validate() error {
+	if strings.TrimSpace(c.Currency) == "" {
+		return fmt.Errorf("currency is required")
+	}
+	if strings.TrimSpace(c.DataFile) == "" {
+		return fmt.Errorf("data file is required")
+	}
+	switch c.NumberFormat {
+	case "", NumberFormatWestern, NumberFormatIndian:
+	default:
+		return fmt.Errorf("number format must be %q or %q", NumberFormatWestern, NumberFormatIndian)
+	}
+	switch c.ExpenseGrouping {
+	case "", ExpenseGroupingMonth, ExpenseGroupingWeek:
+	default:
+		return fmt.Errorf("expense grouping must be %q or %q", ExpenseGroupingMonth, ExpenseGroupingWeek)
+	}
+	if err := c.checkDefaultCategory(); err != nil {
+		return err
+	}
+	if err := CheckWritableDir(c.ObsidianVaultPath); err != nil {
+		return fmt.Errorf("obsidian vault path: %w", err)
+	}
+	for _, dir := range c.NoteFolders().all() {
+		if dir != "" && !filepath.IsLocal(dir) {
+			return fmt.Errorf("obsidian folder %q must stay inside the vault", dir)
+		}
+	}
+	return nil
+}
+
+// checkDefaultCategory rejects a DefaultCategory that is not a known category
+func (c *Config) checkDefaultCategory() error {
+	if c.DefaultCategory == "" || models.IsValidCategory(c.DefaultCategory) {
+		return nil
+	}
+	names := make([]string, len(models.ExpenseCategories))
+	for i, category := range models.ExpenseCategories {
+		names[i] = string(category)
+	}
+	return fmt.Errorf("default category %q is not one of: %s", c.DefaultCategory, strings.Join(names, ", "))
+}
+
+// CheckWritableDir ensures dir exists (creating it if needed) and can be written to
+func CheckWritableDir(dir string) error {
+	if strings.TrimSpace(dir) == "" {
+		return fmt.Errorf("path is required")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	f, err := os.CreateTemp(dir, ".debtq-write-check-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", dir, err)
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
 }
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGroupDigits(t *testing.T) {
+	tests := []struct {
+		f     float64
+		style string
+		want  string
+	}{
+		{0, NumberFormatWestern, "0.00"},
+		{999.5, NumberFormatWestern, "999.50"},
+		{1000, NumberFormatWestern, "1,000.00"},
+		{1234567.5, NumberFormatWestern, "1,234,567.50"},
+		{999.5, NumberFormatIndian, "999.50"},
+		{1000, NumberFormatIndian, "1,000.00"},
+		{100000, NumberFormatIndian, "1,00,000.00"},
+		{1234567.5, NumberFormatIndian, "12,34,567.50"},
+		{123456789, NumberFormatIndian, "12,34,56,789.00"},
+		{-1234567.5, NumberFormatWestern, "-1,234,567.50"},
+		{-1234567.5, NumberFormatIndian, "-12,34,567.50"},
+		{-12.34, NumberFormatWestern, "-12.34"},
+		// Rounds to zero: no "-0.00"
+		{-0.001, NumberFormatWestern, "0.00"},
+	}
+	for _, tt := range tests {
+		if got := GroupDigits(tt.f, tt.style); got != tt.want {
+			t.Errorf("GroupDigits(%v, %s) = %q, want %q", tt.f, tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	cfg := DefaultConfig()
+	// Without a configured format, INR groups the indian way
+	if got := cfg.FormatNumber(1234567.5, "INR"); got != "12,34,567.50" {
+		t.Errorf("INR = %q", got)
+	}
+	if got := cfg.FormatNumber(1234567.5, "USD"); got != "1,234,567.50" {
+		t.Errorf("USD = %q", got)
+	}
+
+	cfg.NumberFormat = NumberFormatWestern
+	if got := cfg.FormatNumber(1234567.5, "INR"); got != "1,234,567.50" {
+		t.Errorf("INR with western format = %q", got)
+	}
+}
+
+// writeConfigFile writes a config.json into a fresh HOME and returns its path
+func writeConfigFile(t *testing.T, cfg map[string]string) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	path, err := GetConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestResolveCurrency(t *testing.T) {
+	c := DefaultConfig()
+	c.Currency = "INR"
+	c.ExchangeRates = map[string]float64{"usd": 80}
+
+	for code, want := range map[string]string{"": "", "INR": "", " inr ": "", "USD": "USD", "usd": "USD"} {
+		if got, err := c.ResolveCurrency(code); err != nil || got != want {
+			t.Errorf("ResolveCurrency(%q) = %q, %v; want %q", code, got, err, want)
+		}
+	}
+	if got, err := c.ResolveCurrency("XYZ"); err == nil || err.Error() != "no exchange rate configured for XYZ" {
+		t.Errorf("ResolveCurrency(XYZ) = %q, %v; want a missing rate error", got, err)
+	}
+}
+
+func TestEnvOverridesPaths(t *testing.T) {
+	path := writeConfigFile(t, map[string]string{
+		"data_file":           "/file/data.json",
+		"obsidian_vault_path": "/file/vault",
+		"currency":            "INR",
+	})
+
+	// Unset: the config file wins over the defaults
+	t.Setenv(DataFileEnv, "")
+	t.Setenv(VaultPathEnv, "")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DataFile != "/file/data.json" || cfg.ObsidianVaultPath != "/file/vault" {
+		t.Errorf("without env: %q, %q", cfg.DataFile, cfg.ObsidianVaultPath)
+	}
+
+	// Set: the environment wins over the config file
+	t.Setenv(DataFileEnv, "/env/data.json")
+	t.Setenv(VaultPathEnv, " /env/vault ")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DataFile != "/env/data.json" || cfg.ObsidianVaultPath != "/env/vault" {
+		t.Errorf("with env: %q, %q", cfg.DataFile, cfg.ObsidianVaultPath)
+	}
+
+	// Saving keeps the overrides out of the file
+	cfg.Currency = "USD"
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved Config
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.DataFile != "/file/data.json" || saved.ObsidianVaultPath != "/file/vault" || saved.Currency != "USD" {
+		t.Errorf("saved %q, %q, %q; want the file's paths and the new currency", saved.DataFile, saved.ObsidianVaultPath, saved.Currency)
+	}
+}
+
+func TestEnvOverridesDefaults(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(DataFileEnv, "/env/data.json")
+	t.Setenv(VaultPathEnv, "")
+
+	// No config file yet: one is created from the defaults, the env still applies
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DataFile != "/env/data.json" {
+		t.Errorf("DataFile = %q, want the env value", cfg.DataFile)
+	}
+	if want := DefaultConfig().ObsidianVaultPath; cfg.ObsidianVaultPath != want {
+		t.Errorf("ObsidianVaultPath = %q, want the default %q", cfg.ObsidianVaultPath, want)
+	}
+}
@@ -1,6 +1,14 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
 
 // ExpenseCategory represents expense categories
 type ExpenseCategory string
@@ -16,6 +24,28 @@ const (
 	CategoryOther         ExpenseCategory = "other"
 )
 
+// ExpenseCategories lists every known expense category
+var ExpenseCategories = []ExpenseCategory{
+	CategoryFood,
+	CategoryTransport,
+	CategoryEntertainment,
+	CategoryUtilities,
+	CategoryShopping,
+	CategoryHealth,
+	CategoryEducation,
+	CategoryOther,
+}
+
+// IsValidCategory reports whether c is one of ExpenseCategories
+func IsValidCategory(c ExpenseCategory) bool {
+	for _, known := range ExpenseCategories {
+		if c == known {
+			return true
+		}
+	}
+	return false
+}
+
 // Expense represents a single expense entry
 type Expense struct {
 	ID          string          `json:"id"`
@@ -23,9 +53,73 @@ type Expense struct {
 	Description string          `json:"description"`
 	Category    ExpenseCategory `json:"category"`
 	Date        time.Time       `json:"date"`
+	Currency    string          `json:"currency,omitempty"`     // Empty means the base currency
+	Tags        []string        `json:"tags,omitempty"`         // Normalized by ParseTags, e.g. "vacation"
+	Notes       string          `json:"notes,omitempty"`        // Longer context, e.g. receipt details or who you were with
+	RecurringID string          `json:"recurring_id,omitempty"` // Set when generated from a RecurringExpense
 	CreatedAt   time.Time       `json:"created_at"`
 }
 
+// HasTag reports whether the expense carries tag, given with or without the #
+func (e *Expense) HasTag(tag string) bool {
+	tag = normalizeTag(tag)
+	for _, t := range e.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTags turns comma-separated input such as "#Vacation, work trip" into
+// tags: lower-case, without the leading #, spaces replaced by dashes so they
+// stay valid Obsidian tags, and without duplicates
+func ParseTags(input string) []string {
+	var tags []string
+	for _, part := range strings.Split(input, ",") {
+		tag := normalizeTag(part)
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func normalizeTag(tag string) string {
+	tag = strings.TrimLeft(strings.TrimSpace(tag), "#")
+	return strings.ToLower(strings.Join(strings.Fields(tag), "-"))
+}
+
+// RecurringExpense is an expense that repeats every month on DayOfMonth
+type RecurringExpense struct {
+	ID          string          `json:"id"`
+	Amount      float64         `json:"amount"`
+	Description string          `json:"description"`
+	Category    ExpenseCategory `json:"category"`
+	DayOfMonth  int             `json:"day_of_month"`          // 1-31; clamped to the last day of shorter months
+	Active      bool            `json:"active"`                // False while paused
+	EndDate     *time.Time      `json:"end_date,omitempty"`    // No expenses are created for days after it; nil repeats indefinitely
+	LastPeriod  string          `json:"last_period,omitempty"` // Last month (YYYY-MM) an expense was created for
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// HasEnded reports whether the recurring expense's end date is before its
+// occurrence in the month of t, so it creates no more expenses
+func (r *RecurringExpense) HasEnded(t time.Time) bool {
+	return r.EndDate != nil && r.DueDate(t).After(*r.EndDate)
+}
+
+// DueDate returns the date the recurring expense falls on in the month of t
+func (r *RecurringExpense) DueDate(t time.Time) time.Time {
+	lastDay := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	day := r.DayOfMonth
+	if day > lastDay {
+		day = lastDay
+	}
+	// Midnight UTC, like dates entered as YYYY-MM-DD
+	return time.Date(t.Year(), t.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
 // TransactionType for borrowing/lending
 type TransactionType string
 
@@ -34,20 +128,148 @@ const (
 	Lent     TransactionType = "lent"
 )
 
+// InterestType represents how interest on a debt accrues
+type InterestType string
+
+const (
+	InterestNone     InterestType = "none"
+	InterestSimple   InterestType = "simple"
+	InterestCompound InterestType = "compound" // Compounded annually
+)
+
 // DebtTransaction represents money borrowed or lent
 type DebtTransaction struct {
-	ID             string          `json:"id"`
-	Type           TransactionType `json:"type"`
-	PersonName     string          `json:"person_name"`
-	Amount         float64         `json:"amount"`          // Remaining amount (decreases with partial settlements)
-	OriginalAmount float64         `json:"original_amount"` // Original amount (never changes)
-	Description    string          `json:"description"`
-	Date           time.Time       `json:"date"`
-	DueDate        *time.Time      `json:"due_date,omitempty"`
-	IsSettled      bool            `json:"is_settled"`
-	SettledDate    *time.Time      `json:"settled_date,omitempty"`
-	SettlementNote string          `json:"settlement_note,omitempty"`
-	CreatedAt      time.Time       `json:"created_at"`
+	ID                string          `json:"id"`
+	Type              TransactionType `json:"type"`
+	PersonName        string          `json:"person_name"`
+	Amount            float64         `json:"amount"`          // Remaining amount (decreases with partial settlements)
+	OriginalAmount    float64         `json:"original_amount"` // Original amount (never changes)
+	Description       string          `json:"description"`
+	Date              time.Time       `json:"date"`
+	DueDate           *time.Time      `json:"due_date,omitempty"`
+	IsSettled         bool            `json:"is_settled"`
+	SettledDate       *time.Time      `json:"settled_date,omitempty"`
+	SettlementNote    string          `json:"settlement_note,omitempty"`
+	InterestRate      float64         `json:"interest_rate,omitempty"`       // Annual interest in percent (0 = interest-free)
+	InterestType      InterestType    `json:"interest_type,omitempty"`       // Empty means simple when a rate is set
+	InterestGraceDays int             `json:"interest_grace_days,omitempty"` // Interest-free days after Date before interest starts accruing
+	Currency          string          `json:"currency,omitempty"`            // Empty means the base currency
+	TotalInstallments int             `json:"total_installments,omitempty"`  // Fixed payments an installment (EMI) debt is repaid in; 0 for one-off debts
+	InstallmentAmount float64         `json:"installment_amount,omitempty"`  // Each installment; the last one pays whatever remains
+	CreatedAt         time.Time       `json:"created_at"`
+}
+
+// IsInstallment reports whether the transaction is repaid in fixed installments
+func (dt *DebtTransaction) IsInstallment() bool {
+	return dt.TotalInstallments > 0 && dt.InstallmentAmount > 0
+}
+
+// InstallmentsPaid returns how many installments have been paid, counting
+// whole installments of the amount repaid so far
+func (dt *DebtTransaction) InstallmentsPaid() int {
+	if !dt.IsInstallment() {
+		return 0
+	}
+	if dt.IsSettled {
+		return dt.TotalInstallments
+	}
+	// The small epsilon keeps 2.9999999 installments from rounding down to 2
+	paid := int(math.Floor((dt.Principal()-dt.Amount)/dt.InstallmentAmount + 1e-6))
+	if paid > dt.TotalInstallments {
+		paid = dt.TotalInstallments
+	}
+	return paid
+}
+
+// IsOverdue reports whether an unsettled transaction is past its due date
+func (dt *DebtTransaction) IsOverdue(now time.Time) bool {
+	return !dt.IsSettled && dt.DueDate != nil && dt.DueDate.Before(now)
+}
+
+// Settlement kinds returned by DebtTransaction.SettlementKind
+const (
+	SettlementNone    = "none"    // Nothing paid yet
+	SettlementFull    = "full"    // Settled in one payment
+	SettlementPartial = "partial" // One or more partial payments (possibly since completed)
+)
+
+// SettlementKind classifies how a transaction has been settled using the
+// settlement records that reference it. Transactions settled before records
+// were kept count as settled in full.
+func (dt *DebtTransaction) SettlementKind(settlements []Settlement) string {
+	payments := 0
+	for _, st := range settlements {
+		if st.TransactionID == dt.ID {
+			payments++
+		}
+	}
+	switch {
+	case payments == 0 && dt.IsSettled:
+		return SettlementFull
+	case payments == 0:
+		return SettlementNone
+	case payments == 1 && dt.IsSettled:
+		return SettlementFull
+	default:
+		return SettlementPartial
+	}
+}
+
+// EffectiveInterestType returns how interest accrues, treating records that
+// have a rate but no type (written before types existed) as simple interest
+func (dt *DebtTransaction) EffectiveInterestType() InterestType {
+	if dt.InterestRate <= 0 {
+		return InterestNone
+	}
+	if dt.InterestType == "" {
+		return InterestSimple
+	}
+	return dt.InterestType
+}
+
+// InterestStart returns when interest starts accruing: Date, or the end of the
+// grace period when there is one
+func (dt *DebtTransaction) InterestStart() time.Time {
+	return dt.Date.AddDate(0, 0, dt.InterestGraceDays)
+}
+
+// InGracePeriod reports whether an interest-bearing transaction is still
+// interest-free at asOf
+func (dt *DebtTransaction) InGracePeriod(asOf time.Time) bool {
+	return dt.EffectiveInterestType() != InterestNone && dt.InterestGraceDays > 0 && asOf.Before(dt.InterestStart())
+}
+
+// AccruedInterest returns interest on the remaining amount from InterestStart to asOf
+func (dt *DebtTransaction) AccruedInterest(asOf time.Time) float64 {
+	start := dt.InterestStart()
+	if !asOf.After(start) {
+		return 0
+	}
+	years := asOf.Sub(start).Hours() / 24 / 365
+	rate := dt.InterestRate / 100
+
+	switch dt.EffectiveInterestType() {
+	case InterestSimple:
+		return dt.Amount * rate * years
+	case InterestCompound:
+		return dt.Amount * (math.Pow(1+rate, years) - 1)
+	default:
+		return 0
+	}
+}
+
+// Principal returns the amount originally lent or borrowed, falling back to
+// Amount for records written before OriginalAmount was kept
+func (dt *DebtTransaction) Principal() float64 {
+	if dt.OriginalAmount == 0 {
+		return dt.Amount
+	}
+	return dt.OriginalAmount
+}
+
+// AccruedAmount returns the remaining principal plus interest accrued up to asOf
+func (dt *DebtTransaction) AccruedAmount(asOf time.Time) float64 {
+	return dt.Amount + dt.AccruedInterest(asOf)
 }
 
 // Settlement represents a payment/settlement record
@@ -58,6 +280,7 @@ type Settlement struct {
 	Type          TransactionType `json:"type"` // borrowed or lent (from original transaction)
 	Amount        float64         `json:"amount"`
 	Note          string          `json:"note,omitempty"`
+	BatchID       string          `json:"batch_id,omitempty"` // Shared by every settlement one settle action recorded
 	Date          time.Time       `json:"date"`
 	CreatedAt     time.Time       `json:"created_at"`
 }
@@ -77,6 +300,29 @@ const (
 	InvestmentOther       InvestmentType = "other"
 )
 
+// InvestmentTypes lists every known investment type
+var InvestmentTypes = []InvestmentType{
+	InvestmentStocks,
+	InvestmentMutualFunds,
+	InvestmentGold,
+	InvestmentSilver,
+	InvestmentFD,
+	InvestmentPPF,
+	InvestmentCrypto,
+	InvestmentRealEstate,
+	InvestmentOther,
+}
+
+// IsValidInvestmentType reports whether t is one of InvestmentTypes
+func IsValidInvestmentType(t InvestmentType) bool {
+	for _, known := range InvestmentTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
 // Investment represents an investment entry
 type Investment struct {
 	ID             string         `json:"id"`
@@ -87,23 +333,76 @@ type Investment struct {
 	Units          float64        `json:"units,omitempty"`
 	PurchaseDate   time.Time      `json:"purchase_date"`
 	Notes          string         `json:"notes,omitempty"`
+	ValueHistory   []ValuePoint   `json:"value_history,omitempty"` // Oldest first
+	Currency       string         `json:"currency,omitempty"`      // Empty means the base currency
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
 }
 
+// AvgPricePerUnit returns the average purchase price per unit (0 without units)
+func (inv *Investment) AvgPricePerUnit() float64 {
+	if inv.Units <= 0 {
+		return 0
+	}
+	return inv.InvestedAmount / inv.Units
+}
+
+// CurrentPricePerUnit returns the current price per unit (0 without units)
+func (inv *Investment) CurrentPricePerUnit() float64 {
+	if inv.Units <= 0 {
+		return 0
+	}
+	return inv.CurrentValue / inv.Units
+}
+
+// ValuePoint records an investment's value on a date
+type ValuePoint struct {
+	Date  time.Time `json:"date"`
+	Value float64   `json:"value"`
+}
+
+// InvestmentIncomeType represents kinds of income paid out by an investment
+type InvestmentIncomeType string
+
+const (
+	IncomeDividend InvestmentIncomeType = "dividend"
+	IncomeInterest InvestmentIncomeType = "interest"
+	IncomeOther    InvestmentIncomeType = "other"
+)
+
+// InvestmentIncome represents a dividend/interest payout received from an investment
+type InvestmentIncome struct {
+	ID           string               `json:"id"`
+	InvestmentID string               `json:"investment_id"`
+	Type         InvestmentIncomeType `json:"type"`
+	Amount       float64              `json:"amount"`
+	Date         time.Time            `json:"date"`
+	Notes        string               `json:"notes,omitempty"`
+	CreatedAt    time.Time            `json:"created_at"`
+}
+
 // SavingsTarget represents a savings goal
 type SavingsTarget struct {
-	ID            string    `json:"id"`
-	ProductName   string    `json:"product_name"`
-	TargetAmount  float64   `json:"target_amount"`
-	CurrentAmount float64   `json:"current_amount"`
-	TargetDate    time.Time `json:"target_date"`
-	Description   string    `json:"description,omitempty"`
-	IsCompleted   bool      `json:"is_completed"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            string     `json:"id"`
+	ProductName   string     `json:"product_name"`
+	TargetAmount  float64    `json:"target_amount"`
+	CurrentAmount float64    `json:"current_amount"`
+	TargetDate    time.Time  `json:"target_date"`
+	Description   string     `json:"description,omitempty"`
+	Priority      int        `json:"priority,omitempty"` // MinGoalPriority to MaxGoalPriority; 0 means DefaultGoalPriority
+	IsCompleted   bool       `json:"is_completed"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
 }
 
+// Savings goal priorities; higher priorities get a larger share of SuggestContribution
+const (
+	MinGoalPriority     = 1
+	MaxGoalPriority     = 5
+	DefaultGoalPriority = 3
+)
+
 // SavingsContribution represents a contribution towards a savings target
 type SavingsContribution struct {
 	ID        string    `json:"id"`
@@ -122,23 +421,240 @@ type Data struct {
 	Investments          []Investment          `json:"investments"`
 	SavingsTargets       []SavingsTarget       `json:"savings_targets"`
 	SavingsContributions []SavingsContribution `json:"savings_contributions"`
+	InvestmentIncomes    []InvestmentIncome    `json:"investment_incomes"`
+	RecurringExpenses    []RecurringExpense    `json:"recurring_expenses"`
+	NetWorthSnapshots    []NetWorthSnapshot    `json:"net_worth_snapshots"`
+	// Archives summarize records moved out to archive files, so all-time totals
+	// still count them
+	Archives []ArchiveRollup `json:"archives,omitempty"`
+
+	// Rates converts entries to the base currency in totals. It comes from the
+	// config and is not saved with the data.
+	Rates ExchangeRates `json:"-"`
+
+	summary summaryCache
+}
+
+// ArchiveRollup is what stays in the live data of one archiving run: enough
+// to keep all-time expense totals whole without the archived records
+type ArchiveRollup struct {
+	File         string                      `json:"file"`   // Archive file name, e.g. data-2024.json
+	Before       time.Time                   `json:"before"` // Records dated before this were archived
+	ArchivedAt   time.Time                   `json:"archived_at"`
+	Expenses     int                         `json:"expenses"`
+	ExpenseTotal float64                     `json:"expense_total"` // In the base currency at the time of archiving
+	ByCategory   map[ExpenseCategory]float64 `json:"by_category,omitempty"`
+	ByTag        map[string]float64          `json:"by_tag,omitempty"`
+	Debts        int                         `json:"debts"` // Settled debt transactions, archived with their settlements
+	Settlements  int                         `json:"settlements"`
+}
+
+// Summary holds the aggregates shown on dashboards, converted to the base currency
+type Summary struct {
+	NetWorth              float64
+	TotalInvested         float64
+	TotalBorrowed         float64
+	TotalLent             float64
+	TotalExpenses         float64 // All time, without the excluded categories
+	MonthlyExpenses       float64 // The requested month, without the excluded categories
+	ActiveSavingsGoals    int
+	CompletedSavingsGoals int
+	TotalSavingsTarget    float64
+	TotalSaved            float64
+}
+
+// NetDebtPosition is what others owe you minus what you owe
+func (s Summary) NetDebtPosition() float64 {
+	return s.TotalLent - s.TotalBorrowed
+}
+
+// PortfolioReturn matches Data.PortfolioReturn
+func (s Summary) PortfolioReturn() (absolute, percent float64) {
+	absolute = s.NetWorth - s.TotalInvested
+	if s.TotalInvested == 0 {
+		return absolute, 0
+	}
+	return absolute, absolute / s.TotalInvested * 100
+}
+
+// SavingsProgress returns the total saved as a percent of all savings targets
+func (s Summary) SavingsProgress() float64 {
+	if s.TotalSavingsTarget <= 0 {
+		return 0
+	}
+	return s.TotalSaved / s.TotalSavingsTarget * 100
+}
+
+// summaryCache keeps the last Summary until the data changes
+type summaryCache struct {
+	mu       sync.Mutex
+	valid    bool
+	year     int
+	month    time.Month
+	excluded string
+	value    Summary
+}
+
+// Summary returns the dashboard aggregates for year/month, leaving excluded
+// categories out of the expense totals. The result is cached, so whoever
+// changes the data must call InvalidateSummary.
+func (d *Data) Summary(year int, month time.Month, excluded []ExpenseCategory) Summary {
+	key := fmt.Sprint(excluded)
+
+	c := &d.summary
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.valid && c.year == year && c.month == month && c.excluded == key {
+		return c.value
+	}
+
+	s := Summary{
+		NetWorth:        d.NetWorth(),
+		TotalInvested:   d.TotalInvested(),
+		TotalBorrowed:   d.TotalBorrowed(),
+		TotalLent:       d.TotalLent(),
+		TotalExpenses:   d.TotalExpensesExcluding(excluded),
+		MonthlyExpenses: d.MonthlyExpensesExcluding(year, month, excluded),
+	}
+	for _, t := range d.SavingsTargets {
+		if t.IsCompleted {
+			s.CompletedSavingsGoals++
+		} else {
+			s.ActiveSavingsGoals++
+		}
+		s.TotalSavingsTarget += t.TargetAmount
+		s.TotalSaved += t.CurrentAmount
+	}
+
+	c.valid, c.year, c.month, c.excluded, c.value = true, year, month, key, s
+	return s
+}
+
+// InvalidateSummary drops the cached Summary after the data or rates change
+func (d *Data) InvalidateSummary() {
+	d.summary.mu.Lock()
+	d.summary.valid = false
+	d.summary.mu.Unlock()
+}
+
+// ExchangeRates converts amounts in other currencies to a base currency
+type ExchangeRates struct {
+	Base  string
+	Rates map[string]float64 // Value of one unit of each currency code in Base
+}
+
+// ToBase converts amount in currency to the base currency. Amounts with no
+// currency or in the base currency are unchanged, as are currencies without a rate.
+func (r ExchangeRates) ToBase(amount float64, currency string) float64 {
+	if currency == "" || strings.EqualFold(currency, r.Base) {
+		return amount
+	}
+	if rate, ok := r.Rates[strings.ToUpper(currency)]; ok && rate > 0 {
+		return amount * rate
+	}
+	return amount
+}
+
+// NetWorthSnapshot records the net worth on a given day
+type NetWorthSnapshot struct {
+	Date  time.Time `json:"date"`
+	Value float64   `json:"value"`
 }
 
 // NetWorth calculates total net worth from investments
 func (d *Data) NetWorth() float64 {
 	var total float64
 	for _, inv := range d.Investments {
-		total += inv.CurrentValue
+		total += d.Rates.ToBase(inv.CurrentValue, inv.Currency)
+	}
+	return total
+}
+
+// TotalInvested returns the amount put into all investments
+func (d *Data) TotalInvested() float64 {
+	var total float64
+	for _, inv := range d.Investments {
+		total += d.Rates.ToBase(inv.InvestedAmount, inv.Currency)
+	}
+	return total
+}
+
+// PortfolioReturn returns the capital gain across all investments, as an amount
+// and as a percent of the total invested (0 when nothing is invested)
+func (d *Data) PortfolioReturn() (absolute, percent float64) {
+	invested := d.TotalInvested()
+	absolute = d.NetWorth() - invested
+	if invested == 0 {
+		return absolute, 0
+	}
+	return absolute, absolute / invested * 100
+}
+
+// PortfolioCAGR returns the annualized growth rate of the portfolio in percent.
+// The holding period is each investment's time since PurchaseDate, weighted by
+// the amount invested. Returns 0 when nothing is invested or nothing has been
+// held for any time yet.
+func (d *Data) PortfolioCAGR(asOf time.Time) float64 {
+	invested := d.TotalInvested()
+	if invested <= 0 {
+		return 0
+	}
+
+	var weightedYears float64
+	for _, inv := range d.Investments {
+		if asOf.After(inv.PurchaseDate) {
+			weightedYears += asOf.Sub(inv.PurchaseDate).Hours() / 24 / 365 * d.Rates.ToBase(inv.InvestedAmount, inv.Currency)
+		}
+	}
+	years := weightedYears / invested
+	if years <= 0 {
+		return 0
+	}
+	return (math.Pow(d.NetWorth()/invested, 1/years) - 1) * 100
+}
+
+// IncomeForInvestment returns total income received from a single investment
+func (d *Data) IncomeForInvestment(investmentID string) float64 {
+	var total float64
+	for _, inc := range d.InvestmentIncomes {
+		if inc.InvestmentID == investmentID {
+			total += inc.Amount
+		}
+	}
+	return total
+}
+
+// TotalInvestmentIncome returns total income received across all investments.
+// Income is in the currency of the investment that paid it.
+func (d *Data) TotalInvestmentIncome() float64 {
+	currencies := make(map[string]string, len(d.Investments))
+	for _, inv := range d.Investments {
+		currencies[inv.ID] = inv.Currency
+	}
+
+	var total float64
+	for _, inc := range d.InvestmentIncomes {
+		// Skip income orphaned by investments deleted in older versions
+		currency, ok := currencies[inc.InvestmentID]
+		if !ok {
+			continue
+		}
+		total += d.Rates.ToBase(inc.Amount, currency)
 	}
 	return total
 }
 
+// TotalReturn returns capital gain plus income received across all investments
+func (d *Data) TotalReturn() float64 {
+	return d.NetWorth() - d.TotalInvested() + d.TotalInvestmentIncome()
+}
+
 // TotalBorrowed returns total amount borrowed (unsettled)
 func (d *Data) TotalBorrowed() float64 {
 	var total float64
 	for _, dt := range d.DebtTransactions {
 		if dt.Type == Borrowed && !dt.IsSettled {
-			total += dt.Amount
+			total += d.Rates.ToBase(dt.Amount, dt.Currency)
 		}
 	}
 	return total
@@ -149,23 +665,313 @@ func (d *Data) TotalLent() float64 {
 	var total float64
 	for _, dt := range d.DebtTransactions {
 		if dt.Type == Lent && !dt.IsSettled {
-			total += dt.Amount
+			total += d.Rates.ToBase(dt.Amount, dt.Currency)
 		}
 	}
 	return total
 }
 
+// PersonNetBalance returns the outstanding (unsettled) balance with a person.
+// Positive means they owe you, negative means you owe them.
+// personName must already be normalized.
+func (d *Data) PersonNetBalance(personName string) float64 {
+	var total float64
+	for _, dt := range d.DebtTransactions {
+		if dt.PersonName != personName || dt.IsSettled {
+			continue
+		}
+		amount := d.Rates.ToBase(dt.Amount, dt.Currency)
+		if dt.Type == Lent {
+			total += amount
+		} else {
+			total -= amount
+		}
+	}
+	return total
+}
+
+// PersonLifetimeNet returns everything ever lent to a person minus everything
+// borrowed from them, at original amounts and including settled transactions.
+// Unlike PersonNetBalance it does not move as debts are repaid.
+// personName must already be normalized.
+func (d *Data) PersonLifetimeNet(personName string) float64 {
+	var total float64
+	for _, dt := range d.DebtTransactions {
+		if dt.PersonName != personName {
+			continue
+		}
+		amount := d.Rates.ToBase(dt.Principal(), dt.Currency)
+		if dt.Type == Lent {
+			total += amount
+		} else {
+			total -= amount
+		}
+	}
+	return total
+}
+
+// TotalAccruedInterest returns interest accrued as of asOf on unsettled debts
+// past their grace period, split into interest owed to you (receivable) and by
+// you (payable)
+func (d *Data) TotalAccruedInterest(asOf time.Time) (receivable, payable float64) {
+	for _, dt := range d.DebtTransactions {
+		if dt.IsSettled || dt.InGracePeriod(asOf) {
+			continue
+		}
+		interest := d.Rates.ToBase(dt.AccruedInterest(asOf), dt.Currency)
+		if dt.Type == Lent {
+			receivable += interest
+		} else {
+			payable += interest
+		}
+	}
+	return receivable, payable
+}
+
 // MonthlyExpenses returns total expenses for a given month
 func (d *Data) MonthlyExpenses(year int, month time.Month) float64 {
 	var total float64
 	for _, exp := range d.Expenses {
 		if exp.Date.Year() == year && exp.Date.Month() == month {
-			total += exp.Amount
+			total += d.Rates.ToBase(exp.Amount, exp.Currency)
+		}
+	}
+	return total
+}
+
+// AverageMonthlySpend returns the average monthly expense total over the last
+// months full calendar months before asOf's month. When tracking started more
+// recently, only the months since the first expense are counted.
+func (d *Data) AverageMonthlySpend(asOf time.Time, months int) float64 {
+	if months <= 0 || len(d.Expenses) == 0 {
+		return 0
+	}
+
+	end := time.Date(asOf.Year(), asOf.Month(), 1, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, -months, 0)
+
+	first := d.Expenses[0].Date
+	for _, exp := range d.Expenses {
+		if exp.Date.Before(first) {
+			first = exp.Date
+		}
+	}
+	if firstMonth := time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, time.UTC); firstMonth.After(start) {
+		start = firstMonth
+	}
+	if !start.Before(end) {
+		return 0
+	}
+
+	var total float64
+	for _, exp := range d.Expenses {
+		if !exp.Date.Before(start) && exp.Date.Before(end) {
+			total += d.Rates.ToBase(exp.Amount, exp.Currency)
+		}
+	}
+	counted := (end.Year()-start.Year())*12 + int(end.Month()-start.Month())
+	return total / float64(counted)
+}
+
+// CategoryTotal is the amount spent in one expense category
+type CategoryTotal struct {
+	Category ExpenseCategory
+	Total    float64
+}
+
+// TopCategories returns the n highest-spend categories in year/month, highest
+// first (n <= 0 returns them all)
+func (d *Data) TopCategories(year int, month time.Month, n int) []CategoryTotal {
+	return d.topCategories(n, func(exp Expense) bool {
+		return exp.Date.Year() == year && exp.Date.Month() == month
+	})
+}
+
+// CategoryTotals returns spend per category in year/month, highest first
+func (d *Data) CategoryTotals(year int, month time.Month) []CategoryTotal {
+	return d.TopCategories(year, month, 0)
+}
+
+// RollupCategories folds categories below minShare of the combined total into
+// a single CategoryOther entry, listed last. Nothing is folded unless at least
+// two categories are that small, since a rollup of one hides its name for no
+// gain.
+func RollupCategories(totals []CategoryTotal, minShare float64) []CategoryTotal {
+	var sum float64
+	for _, ct := range totals {
+		sum += ct.Total
+	}
+
+	var kept, small []CategoryTotal
+	var other float64
+	for _, ct := range totals {
+		switch {
+		case ct.Category == CategoryOther:
+			other += ct.Total
+		case sum > 0 && ct.Total/sum < minShare:
+			small = append(small, ct)
+		default:
+			kept = append(kept, ct)
+		}
+	}
+	if len(small) < 2 {
+		return totals
+	}
+
+	for _, ct := range small {
+		other += ct.Total
+	}
+	return append(kept, CategoryTotal{Category: CategoryOther, Total: other})
+}
+
+// TopCategoriesAllTime is TopCategories over every expense, archived ones included
+func (d *Data) TopCategoriesAllTime(n int) []CategoryTotal {
+	totals := d.categoryTotals(func(Expense) bool { return true })
+	for _, archive := range d.Archives {
+		for c, total := range archive.ByCategory {
+			totals[c] += total
+		}
+	}
+	return rankCategories(totals, n)
+}
+
+func (d *Data) topCategories(n int, include func(Expense) bool) []CategoryTotal {
+	return rankCategories(d.categoryTotals(include), n)
+}
+
+func (d *Data) categoryTotals(include func(Expense) bool) map[ExpenseCategory]float64 {
+	totals := make(map[ExpenseCategory]float64)
+	for _, exp := range d.Expenses {
+		if include(exp) {
+			totals[exp.Category] += d.Rates.ToBase(exp.Amount, exp.Currency)
+		}
+	}
+	return totals
+}
+
+func rankCategories(totals map[ExpenseCategory]float64, n int) []CategoryTotal {
+	ranked := make([]CategoryTotal, 0, len(totals))
+	for c, total := range totals {
+		ranked = append(ranked, CategoryTotal{Category: c, Total: total})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Total != ranked[j].Total {
+			return ranked[i].Total > ranked[j].Total
+		}
+		return ranked[i].Category < ranked[j].Category
+	})
+	if n > 0 && len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
+
+// TagTotal is the amount spent on expenses carrying one tag
+type TagTotal struct {
+	Tag   string
+	Total float64
+}
+
+// TagTotals returns spend per tag in year/month, highest first. An expense
+// with several tags counts toward each of them.
+func (d *Data) TagTotals(year int, month time.Month) []TagTotal {
+	return d.tagTotals(func(exp Expense) bool {
+		return exp.Date.Year() == year && exp.Date.Month() == month
+	}, false)
+}
+
+// TagTotalsAllTime is TagTotals over every expense, archived ones included
+func (d *Data) TagTotalsAllTime() []TagTotal {
+	return d.tagTotals(func(Expense) bool { return true }, true)
+}
+
+func (d *Data) tagTotals(include func(Expense) bool, archived bool) []TagTotal {
+	totals := make(map[string]float64)
+	for _, exp := range d.Expenses {
+		if !include(exp) {
+			continue
+		}
+		for _, tag := range exp.Tags {
+			totals[tag] += d.Rates.ToBase(exp.Amount, exp.Currency)
+		}
+	}
+	if archived {
+		for _, archive := range d.Archives {
+			for tag, total := range archive.ByTag {
+				totals[tag] += total
+			}
+		}
+	}
+
+	ranked := make([]TagTotal, 0, len(totals))
+	for tag, total := range totals {
+		ranked = append(ranked, TagTotal{Tag: tag, Total: total})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Total != ranked[j].Total {
+			return ranked[i].Total > ranked[j].Total
+		}
+		return ranked[i].Tag < ranked[j].Tag
+	})
+	return ranked
+}
+
+// MonthlySpendProjection returns the average daily spend so far in now's month
+// and that pace projected to the end of the month
+func (d *Data) MonthlySpendProjection(now time.Time) (avgDaily, projected float64) {
+	return SpendProjection(d.MonthlyExpenses(now.Year(), now.Month()), now)
+}
+
+// SpendProjection spreads a month-to-date total over the days elapsed (today
+// included) and extends that pace to the whole of now's month
+func SpendProjection(monthToDate float64, now time.Time) (avgDaily, projected float64) {
+	daysElapsed := now.Day()
+	if daysElapsed <= 0 {
+		return 0, 0
+	}
+	daysInMonth := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, now.Location()).Day()
+	avgDaily = monthToDate / float64(daysElapsed)
+	return avgDaily, avgDaily * float64(daysInMonth)
+}
+
+// MonthlyExpensesExcluding returns total expenses for a given month, skipping excluded categories
+func (d *Data) MonthlyExpensesExcluding(year int, month time.Month, excluded []ExpenseCategory) float64 {
+	var total float64
+	for _, exp := range d.Expenses {
+		if exp.Date.Year() == year && exp.Date.Month() == month && !categoryIn(exp.Category, excluded) {
+			total += d.Rates.ToBase(exp.Amount, exp.Currency)
+		}
+	}
+	return total
+}
+
+// TotalExpensesExcluding returns all-time expenses, skipping excluded categories
+func (d *Data) TotalExpensesExcluding(excluded []ExpenseCategory) float64 {
+	var total float64
+	for _, exp := range d.Expenses {
+		if !categoryIn(exp.Category, excluded) {
+			total += d.Rates.ToBase(exp.Amount, exp.Currency)
+		}
+	}
+	for _, archive := range d.Archives {
+		for c, amount := range archive.ByCategory {
+			if !categoryIn(c, excluded) {
+				total += amount
+			}
 		}
 	}
 	return total
 }
 
+func categoryIn(c ExpenseCategory, list []ExpenseCategory) bool {
+	for _, l := range list {
+		if l == c {
+			return true
+		}
+	}
+	return false
+}
+
 // GetSavingsProgress returns progress percentage for a savings target
 func (st *SavingsTarget) GetProgress() float64 {
 	if st.TargetAmount == 0 {
@@ -179,6 +985,14 @@ func (st *SavingsTarget) DaysRemaining() int {
 	return int(time.Until(st.TargetDate).Hours() / 24)
 }
 
+// EffectivePriority returns the goal's priority, treating unset as DefaultGoalPriority
+func (st *SavingsTarget) EffectivePriority() int {
+	if st.Priority == 0 {
+		return DefaultGoalPriority
+	}
+	return st.Priority
+}
+
 // RequiredMonthlySavings calculates how much needs to be saved per month
 func (st *SavingsTarget) RequiredMonthlySavings() float64 {
 	remaining := st.TargetAmount - st.CurrentAmount
@@ -191,3 +1005,143 @@ func (st *SavingsTarget) RequiredMonthlySavings() float64 {
 	}
 	return remaining / months
 }
+
+// RoundUpDifference returns how much must be added to amount to reach the next
+// multiple of step (0 when amount is already a multiple or step is not positive)
+func RoundUpDifference(amount, step float64) float64 {
+	if step <= 0 || amount <= 0 {
+		return 0
+	}
+	amountCents := int64(math.Round(amount * 100))
+	stepCents := int64(math.Round(step * 100))
+	if stepCents == 0 {
+		return 0
+	}
+	remainder := amountCents % stepCents
+	if remainder == 0 {
+		return 0
+	}
+	return float64(stepCents-remainder) / 100
+}
+
+// RemainderAssignment decides which part receives the rounding residual when an amount is split
+type RemainderAssignment string
+
+const (
+	RemainderFirst   RemainderAssignment = "first"
+	RemainderLast    RemainderAssignment = "last"
+	RemainderLargest RemainderAssignment = "largest"
+)
+
+// SplitAmount splits total across parts proportional to weights, rounding each part
+// down to two decimals. The residual left over by rounding is given to the part picked
+// by assign (the first part when empty), so the parts always sum exactly to total.
+func SplitAmount(total float64, weights []float64, assign RemainderAssignment) []float64 {
+	if len(weights) == 0 {
+		return nil
+	}
+
+	var sumWeights float64
+	for _, w := range weights {
+		sumWeights += w
+	}
+	if sumWeights <= 0 {
+		return make([]float64, len(weights))
+	}
+
+	// Work in whole cents so the residual is exact
+	sign := 1.0
+	if total < 0 {
+		sign = -1
+	}
+	totalCents := int64(math.Round(math.Abs(total) * 100))
+
+	cents := make([]int64, len(weights))
+	var allocated int64
+	for i, w := range weights {
+		cents[i] = int64(math.Floor(float64(totalCents) * w / sumWeights))
+		allocated += cents[i]
+	}
+
+	target := 0
+	switch assign {
+	case RemainderLast:
+		target = len(weights) - 1
+	case RemainderLargest:
+		for i, w := range weights {
+			if w > weights[target] {
+				target = i
+			}
+		}
+	}
+	cents[target] += totalCents - allocated
+
+	parts := make([]float64, len(weights))
+	for i, c := range cents {
+		parts[i] = sign * float64(c) / 100
+	}
+	return parts
+}
+
+// SuggestContribution splits a monthly savings budget across active goals,
+// keyed by goal ID. Each goal is weighted by its priority times its urgency (what
+// it still needs per month to make its date), and never gets more than it still
+// needs; what a capped goal leaves over goes to the others. Completed goals and
+// goals past their date are left out.
+func (d *Data) SuggestContribution(budget float64) map[string]float64 {
+	type candidate struct {
+		id        string
+		weight    float64
+		remaining float64
+	}
+	var open []candidate
+	for _, target := range d.SavingsTargets {
+		remaining := target.TargetAmount - target.CurrentAmount
+		if target.IsCompleted || remaining <= 0 || target.DaysRemaining() < 0 {
+			continue
+		}
+		weight := float64(target.EffectivePriority()) * target.RequiredMonthlySavings()
+		open = append(open, candidate{target.ID, weight, remaining})
+	}
+
+	suggested := make(map[string]float64)
+	pool := budget
+	for pool >= 0.01 && len(open) > 0 {
+		weights := make([]float64, len(open))
+		for i, c := range open {
+			weights[i] = c.weight
+		}
+		shares := SplitAmount(pool, weights, RemainderLargest)
+
+		// Goals whose share covers what they still need are capped and
+		// dropped; the rest is split again among the others
+		var uncapped []candidate
+		for i, c := range open {
+			if shares[i] >= c.remaining {
+				suggested[c.id] = c.remaining
+				pool -= c.remaining
+			} else {
+				uncapped = append(uncapped, c)
+			}
+		}
+		if len(uncapped) == len(open) {
+			for i, c := range open {
+				if shares[i] > 0 {
+					suggested[c.id] = shares[i]
+				}
+			}
+			break
+		}
+		open = uncapped
+	}
+	return suggested
+}
+
+// SplitEqually splits total into n equal parts, see SplitAmount
+func SplitEqually(total float64, n int, assign RemainderAssignment) []float64 {
+	weights := make([]float64, n)
+	for i := range weights {
+		weights[i] = 1
+	}
+	return SplitAmount(total, weights, assign)
+}
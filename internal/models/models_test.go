@@ -0,0 +1,434 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// approx reports whether a and b are equal to the cent
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 0.005
+}
+
+func TestExpenseTotalsExcludingCategories(t *testing.T) {
+	march := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	d := &Data{
+		Expenses: []Expense{
+			{Amount: 100, Category: CategoryFood, Date: march},
+			{Amount: 1200, Category: CategoryUtilities, Date: march},
+			{Amount: 40, Category: CategoryTransport, Date: march},
+			{Amount: 75, Category: CategoryFood, Date: march.AddDate(0, -1, 0)},
+		},
+		Archives: []ArchiveRollup{{
+			ByCategory: map[ExpenseCategory]float64{CategoryFood: 30, CategoryUtilities: 900},
+		}},
+	}
+
+	tests := []struct {
+		name         string
+		excluded     []ExpenseCategory
+		month, total float64
+	}{
+		{"nothing excluded", nil, 1340, 2345},
+		{"one category", []ExpenseCategory{CategoryUtilities}, 140, 245},
+		{"two categories", []ExpenseCategory{CategoryUtilities, CategoryFood}, 40, 40},
+		{"unused category", []ExpenseCategory{CategoryHealth}, 1340, 2345},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.MonthlyExpensesExcluding(2026, time.March, tt.excluded); !approx(got, tt.month) {
+				t.Errorf("MonthlyExpensesExcluding = %.2f, want %.2f", got, tt.month)
+			}
+			if got := d.TotalExpensesExcluding(tt.excluded); !approx(got, tt.total) {
+				t.Errorf("TotalExpensesExcluding = %.2f, want %.2f", got, tt.total)
+			}
+			d.InvalidateSummary()
+			if got := d.Summary(2026, time.March, tt.excluded).MonthlyExpenses; !approx(got, tt.month) {
+				t.Errorf("Summary().MonthlyExpenses = %.2f, want %.2f", got, tt.month)
+			}
+		})
+	}
+}
+
+func TestTotalReturnIncludesIncome(t *testing.T) {
+	d := &Data{
+		Rates: ExchangeRates{Base: "INR", Rates: map[string]float64{"USD": 80}},
+		Investments: []Investment{
+			{ID: "fd", InvestedAmount: 10000, CurrentValue: 10000},
+			{ID: "stock", InvestedAmount: 5000, CurrentValue: 4500},
+			{ID: "us", InvestedAmount: 100, CurrentValue: 120, Currency: "USD"},
+		},
+		InvestmentIncomes: []InvestmentIncome{
+			{InvestmentID: "fd", Type: IncomeInterest, Amount: 700},
+			{InvestmentID: "stock", Type: IncomeDividend, Amount: 150},
+			{InvestmentID: "us", Type: IncomeDividend, Amount: 2},
+			{InvestmentID: "sold", Type: IncomeDividend, Amount: 999},
+		},
+	}
+
+	// Income: 700 + 150 + 2 USD; the orphaned 999 does not count
+	if got, want := d.TotalInvestmentIncome(), 1010.0; !approx(got, want) {
+		t.Errorf("TotalInvestmentIncome = %.2f, want %.2f", got, want)
+	}
+	// Capital gain: 0 - 500 + 20 USD = 1100
+	if got, want := d.TotalReturn(), 1100.0+1010.0; !approx(got, want) {
+		t.Errorf("TotalReturn = %.2f, want %.2f", got, want)
+	}
+
+	d.InvestmentIncomes = nil
+	if got, want := d.TotalReturn(), 1100.0; !approx(got, want) {
+		t.Errorf("TotalReturn without income = %.2f, want %.2f", got, want)
+	}
+}
+
+func TestSplitAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   float64
+		weights []float64
+		assign  RemainderAssignment
+		want    []float64
+	}{
+		{"100 three ways, first", 100, []float64{1, 1, 1}, RemainderFirst, []float64{33.34, 33.33, 33.33}},
+		{"100 three ways, default", 100, []float64{1, 1, 1}, "", []float64{33.34, 33.33, 33.33}},
+		{"100 three ways, last", 100, []float64{1, 1, 1}, RemainderLast, []float64{33.33, 33.33, 33.34}},
+		{"100 three ways, largest", 100, []float64{1, 2, 1}, RemainderLargest, []float64{25, 50, 25}},
+		{"10 by 1:1:1:4, largest", 10, []float64{1, 1, 1, 4}, RemainderLargest, []float64{1.42, 1.42, 1.42, 5.74}},
+		{"5 cents seven ways", 0.05, []float64{1, 1, 1, 1, 1, 1, 1}, RemainderLast, []float64{0, 0, 0, 0, 0, 0, 0.05}},
+		{"200 three ways, last", 200, []float64{1, 1, 1}, RemainderLast, []float64{66.66, 66.66, 66.68}},
+		{"negative", -100, []float64{1, 1, 1}, RemainderFirst, []float64{-33.34, -33.33, -33.33}},
+		{"divisible", 90, []float64{1, 1, 1}, RemainderFirst, []float64{30, 30, 30}},
+		{"zero weights", 100, []float64{0, 0}, RemainderFirst, []float64{0, 0}},
+		{"no parts", 100, nil, RemainderFirst, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitAmount(tt.total, tt.weights, tt.assign)
+			if len(got) != len(tt.want) {
+				t.Fatalf("SplitAmount = %v, want %v", got, tt.want)
+			}
+			var sum float64
+			for i := range got {
+				if !approx(got[i], tt.want[i]) {
+					t.Fatalf("SplitAmount = %v, want %v", got, tt.want)
+				}
+				sum += got[i]
+			}
+			var weights float64
+			for _, w := range tt.weights {
+				weights += w
+			}
+			if weights > 0 && !approx(sum, tt.total) {
+				t.Errorf("parts sum to %.4f, want %.2f", sum, tt.total)
+			}
+		})
+	}
+}
+
+func TestTotalAccruedInterest(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	asOf := start.AddDate(0, 0, 365)
+	d := &Data{
+		Rates: ExchangeRates{Base: "INR", Rates: map[string]float64{"USD": 80}},
+		DebtTransactions: []DebtTransaction{
+			// A year at 10% simple on 1000: 100
+			{Type: Lent, Amount: 1000, Date: start, InterestRate: 10},
+			// A year at 10% compound annually on 2000: 200
+			{Type: Lent, Amount: 2000, Date: start, InterestRate: 10, InterestType: InterestCompound},
+			// Half a year at 12% on 100 USD: 6 USD
+			{Type: Borrowed, Amount: 100, Date: asOf.Add(-365 * 12 * time.Hour), InterestRate: 12, Currency: "USD"},
+			// Grace ended 73 days (a fifth of a year) ago: 5000 * 5% / 5 = 50
+			{Type: Borrowed, Amount: 5000, Date: start, InterestRate: 5, InterestGraceDays: 365 - 73},
+			// Still in its grace period
+			{Type: Lent, Amount: 9000, Date: start, InterestRate: 20, InterestGraceDays: 400},
+			// Settled and interest-free debts accrue nothing
+			{Type: Lent, Amount: 0, OriginalAmount: 500, Date: start, InterestRate: 30, IsSettled: true},
+			{Type: Borrowed, Amount: 700, Date: start},
+		},
+	}
+
+	receivable, payable := d.TotalAccruedInterest(asOf)
+	if !approx(receivable, 300) {
+		t.Errorf("receivable = %.2f, want 300.00", receivable)
+	}
+	if want := 6*80.0 + 50; !approx(payable, want) {
+		t.Errorf("payable = %.2f, want %.2f", payable, want)
+	}
+
+	grace := d.DebtTransactions[4]
+	if !grace.InGracePeriod(asOf) || grace.AccruedInterest(asOf) != 0 {
+		t.Errorf("in grace: InGracePeriod = %v, interest %.2f", grace.InGracePeriod(asOf), grace.AccruedInterest(asOf))
+	}
+	// Once the grace period ends interest runs from its end, not from Date
+	after := grace.InterestStart().AddDate(0, 0, 73)
+	if got := grace.AccruedInterest(after); grace.InGracePeriod(after) || !approx(got, 360) {
+		t.Errorf("after grace: interest %.2f, want 360.00", got)
+	}
+	if free := d.DebtTransactions[6]; free.InGracePeriod(asOf) {
+		t.Error("an interest-free debt is never in a grace period")
+	}
+}
+
+func TestRoundUpDifference(t *testing.T) {
+	tests := []struct {
+		amount, step, want float64
+	}{
+		{237, 10, 3},
+		{237, 100, 63},
+		{240, 10, 0},
+		{0.01, 10, 9.99},
+		{99.99, 100, 0.01},
+		{19.9, 0.5, 0.1},
+		{237, 0, 0},
+		{237, -10, 0},
+		{0, 10, 0},
+		{-5, 10, 0},
+	}
+	for _, tt := range tests {
+		if got := RoundUpDifference(tt.amount, tt.step); !approx(got, tt.want) {
+			t.Errorf("RoundUpDifference(%v, %v) = %v, want %v", tt.amount, tt.step, got, tt.want)
+		}
+	}
+}
+
+func TestAverageMonthlySpend(t *testing.T) {
+	asOf := time.Date(2026, 5, 20, 0, 0, 0, 0, time.UTC)
+	day := func(month time.Month, d int) time.Time { return time.Date(2026, month, d, 0, 0, 0, 0, time.UTC) }
+	data := &Data{Expenses: []Expense{
+		{Amount: 900, Date: day(time.February, 1)},
+		{Amount: 1000, Date: day(time.March, 31)},
+		{Amount: 200, Date: day(time.March, 1)},
+		{Amount: 1500, Date: day(time.April, 15)},
+		{Amount: 5000, Date: day(time.May, 2)},      // This month is not over yet
+		{Amount: 7000, Date: day(time.January, 10)}, // Before the window
+	}}
+
+	if got := data.AverageMonthlySpend(asOf, 3); !approx(got, 1200) {
+		t.Errorf("3 months = %.2f, want 1200 (Feb-Apr)", got)
+	}
+	if got := data.AverageMonthlySpend(asOf, 1); !approx(got, 1500) {
+		t.Errorf("1 month = %.2f, want 1500 (April)", got)
+	}
+	if got := data.AverageMonthlySpend(asOf, 0); got != 0 {
+		t.Errorf("0 months = %.2f, want 0", got)
+	}
+
+	// Tracking started in March: only March and April count
+	recent := &Data{Expenses: data.Expenses[1:4]}
+	if got := recent.AverageMonthlySpend(asOf, 3); !approx(got, 1350) {
+		t.Errorf("since March = %.2f, want 1350", got)
+	}
+	// Only this month's expenses: nothing complete to average
+	fresh := &Data{Expenses: []Expense{{Amount: 5000, Date: day(time.May, 2)}}}
+	if got := fresh.AverageMonthlySpend(asOf, 3); got != 0 {
+		t.Errorf("current month only = %.2f, want 0", got)
+	}
+}
+
+func TestIsValidCategory(t *testing.T) {
+	for _, c := range ExpenseCategories {
+		if !IsValidCategory(c) {
+			t.Errorf("IsValidCategory(%q) = false", c)
+		}
+	}
+	for _, c := range []ExpenseCategory{"foodd", "Food", "", "bills"} {
+		if IsValidCategory(c) {
+			t.Errorf("IsValidCategory(%q) = true", c)
+		}
+	}
+}
+
+func TestSettlementKind(t *testing.T) {
+	paid := func(ids ...string) []Settlement {
+		var settlements []Settlement
+		for _, id := range ids {
+			settlements = append(settlements, Settlement{TransactionID: id, Amount: 10})
+		}
+		return settlements
+	}
+	tests := []struct {
+		name        string
+		settled     bool
+		settlements []Settlement
+		want        string
+	}{
+		{"open, nothing paid", false, nil, SettlementNone},
+		{"payments on other debts only", false, paid("other", "other"), SettlementNone},
+		{"settled in one payment", true, paid("tx", "other"), SettlementFull},
+		{"settled before records were kept", true, nil, SettlementFull},
+		{"open with a part payment", false, paid("tx"), SettlementPartial},
+		{"settled over several payments", true, paid("tx", "tx", "tx"), SettlementPartial},
+	}
+	for _, tt := range tests {
+		dt := DebtTransaction{ID: "tx", IsSettled: tt.settled}
+		if got := dt.SettlementKind(tt.settlements); got != tt.want {
+			t.Errorf("%s: SettlementKind = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAccruedAmount(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	asOf := start.AddDate(0, 0, 730)
+	tests := []struct {
+		name string
+		dt   DebtTransaction
+		want float64
+	}{
+		{"no rate behaves as before", DebtTransaction{Amount: 1000, Date: start}, 1000},
+		{"explicit none ignores the rate", DebtTransaction{Amount: 1000, Date: start, InterestRate: 10, InterestType: InterestNone}, 1000},
+		{"simple", DebtTransaction{Amount: 1000, Date: start, InterestRate: 10, InterestType: InterestSimple}, 1200},
+		{"compound", DebtTransaction{Amount: 1000, Date: start, InterestRate: 10, InterestType: InterestCompound}, 1210},
+		{"rate without a type is simple", DebtTransaction{Amount: 1000, Date: start, InterestRate: 10}, 1200},
+		{"not started yet", DebtTransaction{Amount: 1000, Date: asOf.AddDate(0, 0, 1), InterestRate: 10}, 1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dt.AccruedAmount(asOf); !approx(got, tt.want) {
+				t.Errorf("AccruedAmount = %.2f, want %.2f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPortfolioReturnAndCAGR(t *testing.T) {
+	asOf := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	twoYears := asOf.AddDate(0, 0, -730)
+	d := &Data{
+		Investments: []Investment{
+			{InvestedAmount: 1000, CurrentValue: 1210, PurchaseDate: twoYears},
+			{InvestedAmount: 3000, CurrentValue: 3630, PurchaseDate: twoYears},
+		},
+	}
+
+	if got := d.TotalInvested(); got != 4000 {
+		t.Errorf("TotalInvested = %.2f, want 4000", got)
+	}
+	absolute, percent := d.PortfolioReturn()
+	if !approx(absolute, 840) || !approx(percent, 21) {
+		t.Errorf("PortfolioReturn = %.2f, %.2f%%; want 840.00, 21.00%%", absolute, percent)
+	}
+	// 21% over two years is 10% a year
+	if got := d.PortfolioCAGR(asOf); !approx(got, 10) {
+		t.Errorf("PortfolioCAGR = %.2f%%, want 10.00%%", got)
+	}
+	// Bought today: no holding period to annualize over
+	if got := d.PortfolioCAGR(twoYears); got != 0 {
+		t.Errorf("PortfolioCAGR on the purchase date = %.2f%%, want 0", got)
+	}
+
+	empty := &Data{}
+	if absolute, percent := empty.PortfolioReturn(); absolute != 0 || percent != 0 {
+		t.Errorf("empty PortfolioReturn = %.2f, %.2f%%; want 0, 0", absolute, percent)
+	}
+	if got := empty.PortfolioCAGR(asOf); got != 0 {
+		t.Errorf("empty PortfolioCAGR = %.2f%%, want 0", got)
+	}
+}
+
+func TestPricePerUnit(t *testing.T) {
+	inv := Investment{InvestedAmount: 1000, CurrentValue: 1250, Units: 40}
+	if got := inv.AvgPricePerUnit(); !approx(got, 25) {
+		t.Errorf("AvgPricePerUnit = %.2f, want 25.00", got)
+	}
+	if got := inv.CurrentPricePerUnit(); !approx(got, 31.25) {
+		t.Errorf("CurrentPricePerUnit = %.2f, want 31.25", got)
+	}
+
+	inv.Units = 0
+	if avg, cur := inv.AvgPricePerUnit(), inv.CurrentPricePerUnit(); avg != 0 || cur != 0 {
+		t.Errorf("without units: avg %.2f, current %.2f; want 0, 0", avg, cur)
+	}
+}
+
+// benchData returns n of each kind of record, spread over two years
+func benchData(n int) *Data {
+	d := &Data{Rates: ExchangeRates{Base: "INR", Rates: map[string]float64{"USD": 80}}}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		date := start.AddDate(0, 0, i%730)
+		currency := ""
+		if i%10 == 0 {
+			currency = "USD"
+		}
+		d.Expenses = append(d.Expenses, Expense{Amount: float64(i%500 + 1), Category: ExpenseCategories[i%len(ExpenseCategories)], Date: date, Currency: currency})
+		d.DebtTransactions = append(d.DebtTransactions, DebtTransaction{Type: []TransactionType{Lent, Borrowed}[i%2], Amount: float64(i%300 + 1), Date: date, Currency: currency})
+		d.Investments = append(d.Investments, Investment{InvestedAmount: 1000, CurrentValue: float64(900 + i%300), PurchaseDate: date, Currency: currency})
+		d.SavingsTargets = append(d.SavingsTargets, SavingsTarget{TargetAmount: 1000, CurrentAmount: float64(i % 1200), IsCompleted: i%1200 >= 1000})
+	}
+	return d
+}
+
+func TestSummaryCacheInvalidation(t *testing.T) {
+	d := benchData(50)
+	excluded := []ExpenseCategory{CategoryFood}
+	first := d.Summary(2024, time.March, excluded)
+	if first.NetWorth != d.NetWorth() || first.MonthlyExpenses != d.MonthlyExpensesExcluding(2024, time.March, excluded) {
+		t.Fatalf("summary %+v does not match the direct totals", first)
+	}
+
+	// Cached until invalidated
+	d.Investments[1].CurrentValue += 1000
+	if got := d.Summary(2024, time.March, excluded); got != first {
+		t.Errorf("summary changed without invalidation")
+	}
+	d.InvalidateSummary()
+	if got := d.Summary(2024, time.March, excluded); !approx(got.NetWorth, first.NetWorth+1000) {
+		t.Errorf("NetWorth after invalidation = %.2f, want %.2f", got.NetWorth, first.NetWorth+1000)
+	}
+
+	// A different month or exclusion list is computed afresh
+	if got := d.Summary(2024, time.April, excluded); got.MonthlyExpenses != d.MonthlyExpensesExcluding(2024, time.April, excluded) {
+		t.Errorf("April expenses = %.2f, want %.2f", got.MonthlyExpenses, d.MonthlyExpensesExcluding(2024, time.April, excluded))
+	}
+	if got := d.Summary(2024, time.April, nil); got.TotalExpenses != d.TotalExpensesExcluding(nil) {
+		t.Errorf("total without exclusions = %.2f, want %.2f", got.TotalExpenses, d.TotalExpensesExcluding(nil))
+	}
+}
+
+// BenchmarkSummary compares recomputing the dashboard totals on every redraw,
+// as the views did before Summary, with reading the cached Summary
+func BenchmarkSummary(b *testing.B) {
+	d := benchData(5000)
+	b.Run("recompute", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			d.InvalidateSummary()
+			d.Summary(2025, time.March, nil)
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			d.Summary(2025, time.March, nil)
+		}
+	})
+}
+
+func TestPersonBalanceDefinitions(t *testing.T) {
+	d := &Data{
+		Rates: ExchangeRates{Base: "INR", Rates: map[string]float64{"USD": 80}},
+		DebtTransactions: []DebtTransaction{
+			// Lent 500, 50 of it repaid
+			{PersonName: "ASHA", Type: Lent, Amount: 450, OriginalAmount: 500},
+			{PersonName: "ASHA", Type: Borrowed, Amount: 150, OriginalAmount: 150},
+			// Fully repaid
+			{PersonName: "ASHA", Type: Lent, Amount: 0, OriginalAmount: 80, IsSettled: true},
+			// Saved before original amounts were kept
+			{PersonName: "ASHA", Type: Lent, Amount: 20},
+			{PersonName: "ASHA", Type: Borrowed, Amount: 1, OriginalAmount: 2, Currency: "USD"},
+			{PersonName: "RAVI", Type: Lent, Amount: 999, OriginalAmount: 999},
+		},
+	}
+
+	// Outstanding: unsettled remaining amounts only
+	if got, want := d.PersonNetBalance("ASHA"), 450.0-150+20-80; !approx(got, want) {
+		t.Errorf("PersonNetBalance = %.2f, want %.2f", got, want)
+	}
+	// Lifetime: original amounts of everything, settled included
+	if got, want := d.PersonLifetimeNet("ASHA"), 500.0-150+80+20-160; !approx(got, want) {
+		t.Errorf("PersonLifetimeNet = %.2f, want %.2f", got, want)
+	}
+	if got := d.PersonNetBalance("Asha"); got != 0 {
+		t.Errorf("PersonNetBalance takes normalized names, got %.2f for an unnormalized one", got)
+	}
+}
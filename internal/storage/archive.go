@@ -0,0 +1,249 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/debtq/debtq/internal/models"
+)
+
+// ArchiveDir is the folder, next to the data file, that ArchiveBefore writes to
+const ArchiveDir = "archive"
+
+// archiveDir returns the archive folder for the configured data file
+func (s *Storage) archiveDir() string {
+	return filepath.Join(filepath.Dir(s.config.DataFile), ArchiveDir)
+}
+
+// ArchiveBefore moves expenses dated before date, and debts settled before it
+// together with their settlements, into archive/data-<year>.json, one file for
+// each year the records are dated in; settlements go with their debt. Files
+// from an earlier run are added to. For each file the live data keeps a
+// models.ArchiveRollup so all-time totals stay whole. It returns the paths
+// written, oldest year first. Investments, income, savings goals and net worth
+// history are never archived.
+func (s *Storage) ArchiveBefore(date time.Time) (archivedFiles []string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Archive files sit next to the data file, which only a FilePersister has,
+	// and are plain JSON
+	if _, ok := basePersister(s.persister).(*FilePersister); !ok {
+		return nil, fmt.Errorf("archiving needs the data file; changes are not being saved")
+	}
+	if _, ok := s.persister.(*EncryptedPersister); ok {
+		return nil, fmt.Errorf("archive files are not encrypted, so archiving is off while the data file is")
+	}
+
+	cutoff := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	byYear := make(map[int]*models.Data)
+	archiveFor := func(year int) *models.Data {
+		if byYear[year] == nil {
+			byYear[year] = &models.Data{}
+		}
+		return byYear[year]
+	}
+
+	var keptExpenses []models.Expense
+	for _, exp := range s.data.Expenses {
+		if exp.Date.Before(cutoff) {
+			archived := archiveFor(exp.Date.Year())
+			archived.Expenses = append(archived.Expenses, exp)
+		} else {
+			keptExpenses = append(keptExpenses, exp)
+		}
+	}
+
+	archivedTx := make(map[string]int) // Debt ID to the year it is archived under
+	var keptDebts []models.DebtTransaction
+	for _, dt := range s.data.DebtTransactions {
+		if settledBefore(dt, cutoff) {
+			archived := archiveFor(dt.Date.Year())
+			archived.DebtTransactions = append(archived.DebtTransactions, dt)
+			archivedTx[dt.ID] = dt.Date.Year()
+		} else {
+			keptDebts = append(keptDebts, dt)
+		}
+	}
+	var keptSettlements []models.Settlement
+	for _, st := range s.data.Settlements {
+		if year, ok := archivedTx[st.TransactionID]; ok {
+			archived := byYear[year]
+			archived.Settlements = append(archived.Settlements, st)
+		} else {
+			keptSettlements = append(keptSettlements, st)
+		}
+	}
+
+	if len(byYear) == 0 {
+		return nil, fmt.Errorf("nothing dated before %s to archive", cutoff.Format("2006-01-02"))
+	}
+
+	years := make([]int, 0, len(byYear))
+	for year := range byYear {
+		years = append(years, year)
+	}
+	sort.Ints(years)
+
+	// Every file is written before the live data changes, so a failure part
+	// way leaves the records live; a later run skips what was already written
+	now := time.Now()
+	var rollups []models.ArchiveRollup
+	for _, year := range years {
+		archived := byYear[year]
+		name := fmt.Sprintf("data-%d.json", year)
+		path := filepath.Join(s.archiveDir(), name)
+		if err := appendArchive(path, archived); err != nil {
+			return nil, err
+		}
+		archivedFiles = append(archivedFiles, path)
+
+		rollup := models.ArchiveRollup{
+			File:        name,
+			Before:      cutoff,
+			ArchivedAt:  now,
+			Expenses:    len(archived.Expenses),
+			ByCategory:  make(map[models.ExpenseCategory]float64),
+			ByTag:       make(map[string]float64),
+			Debts:       len(archived.DebtTransactions),
+			Settlements: len(archived.Settlements),
+		}
+		for _, exp := range archived.Expenses {
+			amount := s.data.Rates.ToBase(exp.Amount, exp.Currency)
+			rollup.ExpenseTotal += amount
+			rollup.ByCategory[exp.Category] += amount
+			for _, tag := range exp.Tags {
+				rollup.ByTag[tag] += amount
+			}
+		}
+		rollups = append(rollups, rollup)
+	}
+
+	s.data.Expenses = nonNil(keptExpenses)
+	s.data.DebtTransactions = nonNil(keptDebts)
+	s.data.Settlements = nonNil(keptSettlements)
+	s.data.Archives = append(s.data.Archives, rollups...)
+	return archivedFiles, s.save()
+}
+
+// settledBefore reports whether dt was taken out and fully settled before cutoff
+func settledBefore(dt models.DebtTransaction, cutoff time.Time) bool {
+	if !dt.IsSettled || !dt.Date.Before(cutoff) {
+		return false
+	}
+	return dt.SettledDate == nil || dt.SettledDate.Before(cutoff)
+}
+
+// appendArchive adds records to the archive file at path, creating it if
+// needed. Records already in the file, say from a run whose live save failed,
+// are not written twice.
+func appendArchive(path string, records *models.Data) error {
+	existing, err := readArchive(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		existing = &models.Data{}
+	} else if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	for _, exp := range existing.Expenses {
+		seen[exp.ID] = true
+	}
+	for _, dt := range existing.DebtTransactions {
+		seen[dt.ID] = true
+	}
+	for _, st := range existing.Settlements {
+		seen[st.ID] = true
+	}
+	for _, exp := range records.Expenses {
+		if !seen[exp.ID] {
+			existing.Expenses = append(existing.Expenses, exp)
+		}
+	}
+	for _, dt := range records.DebtTransactions {
+		if !seen[dt.ID] {
+			existing.DebtTransactions = append(existing.DebtTransactions, dt)
+		}
+	}
+	for _, st := range records.Settlements {
+		if !seen[st.ID] {
+			existing.Settlements = append(existing.Settlements, st)
+		}
+	}
+	sort.SliceStable(existing.Expenses, func(i, j int) bool {
+		return existing.Expenses[i].Date.Before(existing.Expenses[j].Date)
+	})
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return dataDirError(dir, err)
+	}
+	data, err := json.MarshalIndent(existing, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return dataDirError(dir, err)
+	}
+	return nil
+}
+
+func readArchive(path string) (*models.Data, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var data models.Data
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("reading archive %s: %w", path, err)
+	}
+	return &data, nil
+}
+
+// nonNil keeps emptied collections saved as [] rather than null
+func nonNil[T any](records []T) []T {
+	if records == nil {
+		return []T{}
+	}
+	return records
+}
+
+// ListArchives returns the archive file names, newest year first
+func (s *Storage) ListArchives() ([]string, error) {
+	entries, err := os.ReadDir(s.archiveDir())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), "data-") && filepath.Ext(entry.Name()) == ".json" {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	return names, nil
+}
+
+// LoadArchive reads the archive file name (as listed by ListArchives). The
+// result is a copy for browsing; changes to it are never saved.
+func (s *Storage) LoadArchive(name string) (*models.Data, error) {
+	if filepath.Base(name) != name {
+		return nil, fmt.Errorf("invalid archive name %q", name)
+	}
+	data, err := readArchive(filepath.Join(s.archiveDir(), name))
+	if err != nil {
+		return nil, err
+	}
+	data.Rates = s.config.Rates()
+	return data, nil
+}
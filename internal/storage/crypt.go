@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// ErrWrongPassphrase is returned when encrypted data cannot be opened with the
+// passphrase given
+var ErrWrongPassphrase = errors.New("wrong passphrase, or the encrypted data file is damaged")
+
+// errEncryptedData is returned when an encrypted data file is read without a
+// passphrase
+var errEncryptedData = errors.New("the data file is encrypted; set \"encrypted\": true in config.json to be asked for the passphrase")
+
+// Encrypted data is encryptedMagic, a format version, the key salt, the GCM
+// nonce and the sealed JSON. Version 1 derives a 256-bit AES key with scrypt
+// (N=2^15, r=8, p=1).
+const (
+	encryptedMagic   = "DEBTQENC"
+	encryptedVersion = 1
+	saltSize         = 16
+	scryptN          = 1 << 15
+	scryptR          = 8
+	scryptP          = 1
+)
+
+// IsEncrypted reports whether data was written by an EncryptedPersister
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(encryptedMagic))
+}
+
+// EncryptedPersister seals everything saved to an underlying Persister with
+// AES-GCM under a key derived from a passphrase. Unencrypted data is still
+// read, so turning encryption on converts the data file on the next save.
+type EncryptedPersister struct {
+	inner      Persister
+	passphrase string
+
+	mu   sync.Mutex
+	salt []byte
+	key  []byte // Derived from passphrase and salt; deriving is slow on purpose
+}
+
+// NewEncryptedPersister returns a Persister encrypting what inner stores
+func NewEncryptedPersister(inner Persister, passphrase string) *EncryptedPersister {
+	return &EncryptedPersister{inner: inner, passphrase: passphrase}
+}
+
+// Unwrap returns the Persister the encrypted data is stored in
+func (p *EncryptedPersister) Unwrap() Persister {
+	return p.inner
+}
+
+// Load reads and decrypts the stored data
+func (p *EncryptedPersister) Load() ([]byte, error) {
+	data, err := p.inner.Load()
+	if err != nil {
+		return nil, err
+	}
+	return p.open(data)
+}
+
+// open decrypts data as written by Save; unencrypted data is returned as is
+func (p *EncryptedPersister) open(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+
+	header := len(encryptedMagic) + 1
+	if len(data) < header+saltSize || data[len(encryptedMagic)] != encryptedVersion {
+		return nil, fmt.Errorf("unsupported encrypted data format")
+	}
+	salt := data[header : header+saltSize]
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	gcm, err := p.cipher(salt)
+	if err != nil {
+		return nil, err
+	}
+	sealed := data[header+saltSize:]
+	if len(sealed) < gcm.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], data[:header+saltSize])
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plain, nil
+}
+
+// Save encrypts data with a fresh nonce and stores it
+func (p *EncryptedPersister) Save(data []byte) error {
+	p.mu.Lock()
+	salt := p.salt
+	if salt == nil {
+		salt = make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			p.mu.Unlock()
+			return err
+		}
+	}
+	gcm, err := p.cipher(salt)
+	p.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	out := append([]byte(encryptedMagic), encryptedVersion)
+	out = append(out, salt...)
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	out = append(out, nonce...)
+	out = gcm.Seal(out, nonce, data, out[:len(encryptedMagic)+1+saltSize])
+	return p.inner.Save(out)
+}
+
+// cipher returns the AES-GCM cipher for salt, deriving the key only when the
+// salt changed; callers must hold mu
+func (p *EncryptedPersister) cipher(salt []byte) (cipher.AEAD, error) {
+	if p.key == nil || !bytes.Equal(p.salt, salt) {
+		key, err := scrypt.Key([]byte(p.passphrase), salt, scryptN, scryptR, scryptP, 32)
+		if err != nil {
+			return nil, err
+		}
+		p.salt, p.key = bytes.Clone(salt), key
+	}
+	block, err := aes.NewCipher(p.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/debtq/debtq/internal/config"
+	"github.com/debtq/debtq/internal/models"
+)
+
+func TestEncryptedPersisterRoundTrip(t *testing.T) {
+	inner := NewMemoryPersister(nil)
+	p := NewEncryptedPersister(inner, "correct horse")
+	plain := []byte(`{"expenses": [{"description": "Coffee"}]}`)
+	if err := p.Save(plain); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, _ := inner.Load()
+	if !IsEncrypted(stored) || bytes.Contains(stored, []byte("Coffee")) {
+		t.Fatalf("stored data is not encrypted: %q", stored)
+	}
+	got, err := NewEncryptedPersister(inner, "correct horse").Load()
+	if err != nil || !bytes.Equal(got, plain) {
+		t.Errorf("Load = %q, %v; want the saved JSON", got, err)
+	}
+}
+
+func TestEncryptedPersisterWrongPassphrase(t *testing.T) {
+	inner := NewMemoryPersister(nil)
+	if err := NewEncryptedPersister(inner, "correct horse").Save([]byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewEncryptedPersister(inner, "battery staple").Load(); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Load with the wrong passphrase: %v, want ErrWrongPassphrase", err)
+	}
+	stored, _ := inner.Load()
+	stored[len(stored)-1] ^= 1
+	inner.Save(stored)
+	if _, err := NewEncryptedPersister(inner, "correct horse").Load(); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Load of damaged data: %v, want ErrWrongPassphrase", err)
+	}
+}
+
+func TestEncryptedStorageConvertsPlainData(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := config.DefaultConfig()
+	cfg.DataFile = filepath.Join(t.TempDir(), "data.json")
+	inner := NewMemoryPersister([]byte(`{"expenses": []}`))
+
+	s, err := NewWithPersister(cfg, NewEncryptedPersister(inner, "correct horse"))
+	if err != nil {
+		t.Fatalf("opening plain data with encryption on: %v", err)
+	}
+	if _, err := s.AddExpense(120, "Coffee", models.CategoryFood, time.Now(), "", nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := inner.Load(); !IsEncrypted(stored) {
+		t.Fatalf("saved data is not encrypted: %q", stored)
+	}
+
+	if _, err := NewWithPersister(cfg, inner); err == nil {
+		t.Error("opening encrypted data without a passphrase succeeded")
+	}
+	reopened, err := NewWithPersister(cfg, NewEncryptedPersister(inner, "correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reopened.GetExpenses(); len(got) != 1 || got[0].Description != "Coffee" {
+		t.Errorf("reopened: %+v, want the saved expense", got)
+	}
+}
@@ -0,0 +1,350 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/debtq/debtq/internal/models"
+)
+
+// ExportJSON writes the full dataset as indented JSON, in the same shape as the
+// data file
+func (s *Storage) ExportJSON(w io.Writer) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// MergeMode picks how ImportJSON combines imported data with the stored data
+type MergeMode string
+
+const (
+	// MergeReplace discards the stored data in favour of the imported data
+	MergeReplace MergeMode = "replace"
+	// MergeAppend adds the imported records under fresh IDs, skipping those whose
+	// content matches a stored record
+	MergeAppend MergeMode = "merge"
+)
+
+// ImportJSON reads a dataset written by ExportJSON (or a data file) and saves
+// it according to mode. Unknown fields are rejected so that a file which is not
+// debtq data cannot replace everything.
+//
+// When merging, records match on their content without IDs and timestamps,
+// and each stored record absorbs at most one imported duplicate, so merging a
+// backup of the same data adds nothing while repeated entries in the import
+// (two identical coffees) are kept. References between records, e.g. a
+// settlement's transaction, follow the IDs the referenced records end up with.
+func (s *Storage) ImportJSON(r io.Reader, mode MergeMode) error {
+	if mode != MergeReplace && mode != MergeAppend {
+		return fmt.Errorf("unknown merge mode %q, use %q or %q", mode, MergeReplace, MergeAppend)
+	}
+
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	var imported models.Data
+	if err := decoder.Decode(&imported); err != nil {
+		return fmt.Errorf("reading JSON: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if mode == MergeReplace {
+		imported.Rates = s.data.Rates
+		s.data = &imported
+		s.backfillValueHistory()
+		return s.save()
+	}
+
+	// Parents first, so their children can be pointed at the IDs they end up with
+	ids := make(map[string]string)
+	mergeRecords(&s.data.RecurringExpenses, imported.RecurringExpenses, ids, func(r *models.RecurringExpense) (*string, []*time.Time) {
+		return &r.ID, []*time.Time{&r.CreatedAt}
+	})
+	mergeRecords(&s.data.DebtTransactions, imported.DebtTransactions, ids, func(dt *models.DebtTransaction) (*string, []*time.Time) {
+		return &dt.ID, []*time.Time{&dt.CreatedAt}
+	})
+	mergeRecords(&s.data.Investments, imported.Investments, ids, func(inv *models.Investment) (*string, []*time.Time) {
+		return &inv.ID, []*time.Time{&inv.CreatedAt, &inv.UpdatedAt}
+	})
+	mergeRecords(&s.data.SavingsTargets, imported.SavingsTargets, ids, func(t *models.SavingsTarget) (*string, []*time.Time) {
+		return &t.ID, []*time.Time{&t.CreatedAt, &t.UpdatedAt}
+	})
+
+	for i := range imported.Expenses {
+		remapID(&imported.Expenses[i].RecurringID, ids)
+	}
+	for i := range imported.Settlements {
+		remapID(&imported.Settlements[i].TransactionID, ids)
+	}
+	for i := range imported.InvestmentIncomes {
+		remapID(&imported.InvestmentIncomes[i].InvestmentID, ids)
+	}
+	for i := range imported.SavingsContributions {
+		remapID(&imported.SavingsContributions[i].TargetID, ids)
+	}
+	mergeRecords(&s.data.Expenses, imported.Expenses, ids, func(e *models.Expense) (*string, []*time.Time) {
+		return &e.ID, []*time.Time{&e.CreatedAt}
+	})
+	mergeRecords(&s.data.Settlements, imported.Settlements, ids, func(st *models.Settlement) (*string, []*time.Time) {
+		return &st.ID, []*time.Time{&st.CreatedAt}
+	})
+	mergeRecords(&s.data.InvestmentIncomes, imported.InvestmentIncomes, ids, func(inc *models.InvestmentIncome) (*string, []*time.Time) {
+		return &inc.ID, []*time.Time{&inc.CreatedAt}
+	})
+	mergeRecords(&s.data.SavingsContributions, imported.SavingsContributions, ids, func(c *models.SavingsContribution) (*string, []*time.Time) {
+		return &c.ID, []*time.Time{&c.CreatedAt}
+	})
+
+	// Snapshots are one per day; the stored value wins
+	days := make(map[string]bool, len(s.data.NetWorthSnapshots))
+	for _, snap := range s.data.NetWorthSnapshots {
+		days[snap.Date.Format("2006-01-02")] = true
+	}
+	for _, snap := range imported.NetWorthSnapshots {
+		if day := snap.Date.Format("2006-01-02"); !days[day] {
+			days[day] = true
+			s.data.NetWorthSnapshots = append(s.data.NetWorthSnapshots, snap)
+		}
+	}
+	sort.SliceStable(s.data.NetWorthSnapshots, func(i, j int) bool {
+		return s.data.NetWorthSnapshots[i].Date.Before(s.data.NetWorthSnapshots[j].Date)
+	})
+
+	// Archive rollups are kept once per archiving run
+	for _, archive := range imported.Archives {
+		if !slices.ContainsFunc(s.data.Archives, func(a models.ArchiveRollup) bool {
+			return a.File == archive.File && a.ArchivedAt.Equal(archive.ArchivedAt)
+		}) {
+			s.data.Archives = append(s.data.Archives, archive)
+		}
+	}
+
+	s.backfillValueHistory()
+	return s.save()
+}
+
+// mergeRecords appends the imported records that are not already stored under
+// fresh IDs, recording in ids the ID each imported record now has (its stored
+// match's, or the fresh one). fields returns a record's ID and the timestamps
+// left out when comparing content.
+func mergeRecords[T any](stored *[]T, imported []T, ids map[string]string, fields func(*T) (*string, []*time.Time)) {
+	matches := make(map[string][]string)
+	for i := range *stored {
+		hash, id := contentHash((*stored)[i], fields)
+		matches[hash] = append(matches[hash], id)
+	}
+
+	for _, record := range imported {
+		hash, oldID := contentHash(record, fields)
+		if same := matches[hash]; len(same) > 0 {
+			ids[oldID], matches[hash] = same[0], same[1:]
+			continue
+		}
+		id, _ := fields(&record)
+		*id = GenerateID()
+		ids[oldID] = *id
+		*stored = append(*stored, record)
+	}
+}
+
+// contentHash hashes a copy of record without its ID and timestamps, and
+// returns the ID alongside
+func contentHash[T any](record T, fields func(*T) (*string, []*time.Time)) (hash, id string) {
+	idField, stamps := fields(&record)
+	id = *idField
+	*idField = ""
+	for _, stamp := range stamps {
+		*stamp = time.Time{}
+	}
+	// Records are plain data, so marshalling cannot fail
+	content, _ := json.Marshal(record)
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), id
+}
+
+// remapID points ref at the ID its record was merged under, if it was
+func remapID(ref *string, ids map[string]string) {
+	if id, ok := ids[*ref]; ok {
+		*ref = id
+	}
+}
+
+// CSV files written by ExportCSVDir, one per entity type
+const (
+	ExpensesCSVFile    = "expenses.csv"
+	DebtsCSVFile       = "debts.csv"
+	InvestmentsCSVFile = "investments.csv"
+	SavingsCSVFile     = "savings.csv"
+)
+
+// ExportCSVDir writes one CSV file per entity type into dir, creating it if needed
+func (s *Storage) ExportCSVDir(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	writers := []struct {
+		name  string
+		write func(io.Writer) error
+	}{
+		{ExpensesCSVFile, s.ExportExpensesCSV},
+		{DebtsCSVFile, s.ExportDebtsCSV},
+		{InvestmentsCSVFile, s.ExportInvestmentsCSV},
+		{SavingsCSVFile, s.ExportSavingsCSV},
+	}
+	for _, w := range writers {
+		path := filepath.Join(dir, w.name)
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		if err := w.write(f); err != nil {
+			f.Close()
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// ExportExpensesCSV writes expenses as date, category, description, amount,
+// currency, notes rows, the columns ImportExpensesCSV reads, so an export can
+// be imported again.
+func (s *Storage) ExportExpensesCSV(w io.Writer) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows := [][]string{{"date", "category", "description", "amount", "currency", "notes"}}
+	for _, e := range s.data.Expenses {
+		rows = append(rows, []string{
+			csvDate(e.Date),
+			string(e.Category),
+			e.Description,
+			csvAmount(e.Amount),
+			s.csvCurrency(e.Currency),
+			e.Notes,
+		})
+	}
+	return writeCSV(w, rows)
+}
+
+// ExportDebtsCSV writes one row per debt transaction
+func (s *Storage) ExportDebtsCSV(w io.Writer) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows := [][]string{{"date", "type", "person", "description", "original_amount", "remaining_amount", "currency", "due_date", "interest_rate", "interest_type", "settled", "settled_date"}}
+	for _, dt := range s.data.DebtTransactions {
+		interestType := ""
+		if dt.InterestRate > 0 {
+			interestType = string(dt.EffectiveInterestType())
+		}
+		rows = append(rows, []string{
+			csvDate(dt.Date),
+			string(dt.Type),
+			dt.PersonName,
+			dt.Description,
+			csvAmount(dt.Principal()),
+			csvAmount(dt.Amount),
+			s.csvCurrency(dt.Currency),
+			csvOptionalDate(dt.DueDate),
+			strconv.FormatFloat(dt.InterestRate, 'f', -1, 64),
+			interestType,
+			strconv.FormatBool(dt.IsSettled),
+			csvOptionalDate(dt.SettledDate),
+		})
+	}
+	return writeCSV(w, rows)
+}
+
+// ExportInvestmentsCSV writes one row per investment
+func (s *Storage) ExportInvestmentsCSV(w io.Writer) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows := [][]string{{"purchase_date", "type", "name", "invested_amount", "current_value", "units", "currency", "notes"}}
+	for _, inv := range s.data.Investments {
+		rows = append(rows, []string{
+			csvDate(inv.PurchaseDate),
+			string(inv.Type),
+			inv.Name,
+			csvAmount(inv.InvestedAmount),
+			csvAmount(inv.CurrentValue),
+			strconv.FormatFloat(inv.Units, 'f', -1, 64),
+			s.csvCurrency(inv.Currency),
+			inv.Notes,
+		})
+	}
+	return writeCSV(w, rows)
+}
+
+// ExportSavingsCSV writes one row per savings target
+func (s *Storage) ExportSavingsCSV(w io.Writer) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows := [][]string{{"name", "target_amount", "current_amount", "target_date", "description", "completed", "completed_at"}}
+	for _, t := range s.data.SavingsTargets {
+		rows = append(rows, []string{
+			t.ProductName,
+			csvAmount(t.TargetAmount),
+			csvAmount(t.CurrentAmount),
+			csvDate(t.TargetDate),
+			t.Description,
+			strconv.FormatBool(t.IsCompleted),
+			csvOptionalDate(t.CompletedAt),
+		})
+	}
+	return writeCSV(w, rows)
+}
+
+func writeCSV(w io.Writer, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func csvDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+func csvOptionalDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return csvDate(*t)
+}
+
+func csvAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// csvCurrency spells out the base currency so every exported row is explicit
+func (s *Storage) csvCurrency(currency string) string {
+	if currency == "" {
+		return s.config.Currency
+	}
+	return currency
+}
@@ -2,10 +2,16 @@ package storage
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strings"
 	"text/template"
 	"time"
 
@@ -16,23 +22,95 @@ import (
 // ObsidianWriter handles writing markdown files to Obsidian vault
 type ObsidianWriter struct {
 	config *config.Config
+	// templates are the user's custom templates by name, loaded by LoadTemplates
+	templates map[string]*template.Template
+	// now is the clock notes are dated by, swapped in tests for stable output
+	now func() time.Time
 }
 
+// Names of the note templates. A file <name>.tmpl in config.GetTemplatesDir
+// replaces the built-in template of that name.
+const (
+	TemplateDashboard = "dashboard"
+	TemplateExpenses  = "expenses"
+	TemplateDebts     = "debts"
+	TemplatePerson    = "person"
+	TemplateNetWorth  = "networth"
+	TemplateSavings   = "savings"
+)
+
+var templateNames = []string{TemplateDashboard, TemplateExpenses, TemplateDebts, TemplatePerson, TemplateNetWorth, TemplateSavings}
+
 // NewObsidianWriter creates a new ObsidianWriter
 func NewObsidianWriter(cfg *config.Config) *ObsidianWriter {
-	return &ObsidianWriter{config: cfg}
+	return &ObsidianWriter{config: cfg, now: time.Now}
 }
 
 // EnsureDirs ensures all required directories exist
 func (o *ObsidianWriter) EnsureDirs() error {
-	if err := os.MkdirAll(o.config.ObsidianVaultPath, 0755); err != nil {
+	for _, dir := range []string{"", o.config.ObsidianSubdir} {
+		if err := os.MkdirAll(filepath.Join(o.config.ObsidianVaultPath, dir), 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadTemplates (re)loads custom templates from config.GetTemplatesDir. Every
+// file is parsed up front, so a broken or unknown template is reported before
+// any note is written.
+func (o *ObsidianWriter) LoadTemplates() error {
+	dir, err := config.GetTemplatesDir()
+	if err != nil {
 		return err
 	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		o.templates = nil
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading templates: %w", err)
+	}
+
+	templates := make(map[string]*template.Template)
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".tmpl" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		name := strings.TrimSuffix(entry.Name(), ".tmpl")
+		if !slices.Contains(templateNames, name) {
+			errs = append(errs, fmt.Errorf("%s: unknown template, use one of: %s", path, strings.Join(templateNames, ", ")))
+			continue
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		tmpl, err := template.New(name).Funcs(templateFuncs(o.now(), o.config)).Parse(string(src))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		templates[name] = tmpl
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("custom templates: %w", errors.Join(errs...))
+	}
+	o.templates = templates
 	return nil
 }
 
 // SyncAllNotes syncs all data to Obsidian vault as summarized files
 func (o *ObsidianWriter) SyncAllNotes(data *models.Data) error {
+	// Pick up template edits on every sync
+	if err := o.LoadTemplates(); err != nil {
+		return err
+	}
+
 	if err := o.EnsureDirs(); err != nil {
 		return err
 	}
@@ -52,6 +130,11 @@ func (o *ObsidianWriter) SyncAllNotes(data *models.Data) error {
 		return err
 	}
 
+	// Write one note per person into the people folder
+	if err := o.writePersonDebtNotes(data); err != nil {
+		return err
+	}
+
 	// Write net worth summary
 	if err := o.writeNetWorthSummary(data); err != nil {
 		return err
@@ -67,7 +150,7 @@ func (o *ObsidianWriter) SyncAllNotes(data *models.Data) error {
 
 // writeDashboard writes the main dashboard file
 func (o *ObsidianWriter) writeDashboard(data *models.Data) error {
-	now := time.Now()
+	now := o.now()
 
 	type Dashboard struct {
 		NetWorth           float64
@@ -80,42 +163,31 @@ func (o *ObsidianWriter) writeDashboard(data *models.Data) error {
 		TotalSavingsTarget float64
 		TotalSaved         float64
 		SavingsProgress    float64
+		NetWorthGoal       float64
+		NetWorthGoalDate   time.Time
 		UpdatedAt          time.Time
 	}
 
-	var totalExpenses, totalSavingsTarget, totalSaved float64
-	var activeSavings int
-
-	for _, e := range data.Expenses {
-		totalExpenses += e.Amount
-	}
-
-	for _, t := range data.SavingsTargets {
-		if !t.IsCompleted {
-			activeSavings++
-		}
-		totalSavingsTarget += t.TargetAmount
-		totalSaved += t.CurrentAmount
-	}
-
-	savingsProgress := float64(0)
-	if totalSavingsTarget > 0 {
-		savingsProgress = (totalSaved / totalSavingsTarget) * 100
-	}
-
+	summary := data.Summary(now.Year(), now.Month(), nil)
 	dashboard := Dashboard{
-		NetWorth:           data.NetWorth(),
-		TotalBorrowed:      data.TotalBorrowed(),
-		TotalLent:          data.TotalLent(),
-		NetDebtPosition:    data.TotalLent() - data.TotalBorrowed(),
-		MonthlyExpenses:    data.MonthlyExpenses(now.Year(), now.Month()),
-		TotalExpenses:      totalExpenses,
-		ActiveSavingsGoals: activeSavings,
-		TotalSavingsTarget: totalSavingsTarget,
-		TotalSaved:         totalSaved,
-		SavingsProgress:    savingsProgress,
+		NetWorth:           summary.NetWorth,
+		TotalBorrowed:      summary.TotalBorrowed,
+		TotalLent:          summary.TotalLent,
+		NetDebtPosition:    summary.NetDebtPosition(),
+		MonthlyExpenses:    summary.MonthlyExpenses,
+		TotalExpenses:      summary.TotalExpenses,
+		ActiveSavingsGoals: summary.ActiveSavingsGoals,
+		TotalSavingsTarget: summary.TotalSavingsTarget,
+		TotalSaved:         summary.TotalSaved,
+		SavingsProgress:    summary.SavingsProgress(),
+		NetWorthGoal:       o.config.NetWorthGoal,
 		UpdatedAt:          now,
 	}
+	if o.config.NetWorthGoalDate != "" {
+		if goalDate, err := time.Parse("2006-01-02", o.config.NetWorthGoalDate); err == nil {
+			dashboard.NetWorthGoalDate = goalDate
+		}
+	}
 
 	tmpl := `---
 tags: [debtq, dashboard, finance]
@@ -130,26 +202,43 @@ updated: {{.UpdatedAt.Format "2006-01-02 15:04:05"}}
 
 | Category | Amount |
 |----------|--------|
-| **Net Worth** | {{printf "%.2f" .NetWorth}} |
-| **Net Debt Position** | {{printf "%.2f" .NetDebtPosition}} |
-| **This Month Expenses** | {{printf "%.2f" .MonthlyExpenses}} |
+| **Net Worth** | {{amount .NetWorth}} |
+| **Net Debt Position** | {{amount .NetDebtPosition}} |
+| **This Month Expenses** | {{amount .MonthlyExpenses}} |
 
 ---
 
 ## Net Worth
-Total investments value: **{{printf "%.2f" .NetWorth}}**
+Total investments value: **{{amount .NetWorth}}**
 
 [[NetWorth|View Details →]]
+{{if gt .NetWorthGoal 0.0}}
+### Net Worth Goal
+
+| Metric | Value |
+|--------|-------|
+| Goal | {{amount .NetWorthGoal}} |
+| Current | {{amount .NetWorth}} |
+| Remaining | {{amount (sub .NetWorthGoal .NetWorth)}} |
+{{- if not .NetWorthGoalDate.IsZero}}
+| Target Date | {{.NetWorthGoalDate.Format "2006-01-02"}} |
+| Days Left | {{daysRemaining .NetWorthGoalDate}} |
+| Monthly Growth Required | {{amount (monthlyRequired .NetWorthGoal .NetWorth .NetWorthGoalDate)}} |
+{{- end}}
 
+` + "```" + `
+{{progressBar .NetWorth .NetWorthGoal 30}}
+` + "```" + `
+{{end}}
 ---
 
 ## Debts & Lending
 
 | Metric | Amount |
 |--------|--------|
-| Total Lent (others owe you) | {{printf "%.2f" .TotalLent}} |
-| Total Borrowed (you owe) | {{printf "%.2f" .TotalBorrowed}} |
-| **Net Position** | {{printf "%.2f" .NetDebtPosition}} |
+| Total Lent (others owe you) | {{amount .TotalLent}} |
+| Total Borrowed (you owe) | {{amount .TotalBorrowed}} |
+| **Net Position** | {{amount .NetDebtPosition}} |
 
 [[Debts|View Details →]]
 
@@ -159,8 +248,8 @@ Total investments value: **{{printf "%.2f" .NetWorth}}**
 
 | Metric | Amount |
 |--------|--------|
-| This Month | {{printf "%.2f" .MonthlyExpenses}} |
-| All Time Total | {{printf "%.2f" .TotalExpenses}} |
+| This Month | {{amount .MonthlyExpenses}} |
+| All Time Total | {{amount .TotalExpenses}} |
 
 [[Expenses|View Details →]]
 
@@ -171,18 +260,34 @@ Total investments value: **{{printf "%.2f" .NetWorth}}**
 | Metric | Value |
 |--------|-------|
 | Active Goals | {{.ActiveSavingsGoals}} |
-| Total Target | {{printf "%.2f" .TotalSavingsTarget}} |
-| Total Saved | {{printf "%.2f" .TotalSaved}} |
+| Total Target | {{amount .TotalSavingsTarget}} |
+| Total Saved | {{amount .TotalSaved}} |
 | Progress | {{printf "%.1f" .SavingsProgress}}% |
 
 [[Savings|View Details →]]
 `
 
-	return o.writeNoteWithFuncs("", "Dashboard.md", tmpl, dashboard)
+	return o.writeNoteWithFuncs(o.config.NoteFolders().Dashboard, "Dashboard.md", TemplateDashboard, tmpl, dashboard)
+}
+
+// expensePeriod returns the sortable key and heading of the month, or with
+// weekly grouping the ISO week, that date falls in. ISO weeks are keyed by
+// their ISO year, so a week running from December into January is one bucket.
+func (o *ObsidianWriter) expensePeriod(date time.Time) (key, label string) {
+	if o.config.ExpenseGrouping != config.ExpenseGroupingWeek {
+		return date.Format("2006-01"), date.Format("January 2006")
+	}
+	year, week := date.ISOWeek()
+	monday := date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+	sunday := monday.AddDate(0, 0, 6)
+	return fmt.Sprintf("%04d-W%02d", year, week),
+		fmt.Sprintf("Week %d, %d (%s – %s)", week, year, monday.Format("2 Jan"), sunday.Format("2 Jan"))
 }
 
-// writeExpensesSummary writes expenses grouped by month and category
+// writeExpensesSummary writes expenses grouped by month (or ISO week) and category
 func (o *ObsidianWriter) writeExpensesSummary(data *models.Data) error {
+	// MonthData is one period's expenses; with weekly grouping Month holds the
+	// week's heading
 	type MonthData struct {
 		Month      string
 		Total      float64
@@ -190,35 +295,54 @@ func (o *ObsidianWriter) writeExpensesSummary(data *models.Data) error {
 		Expenses   []models.Expense
 	}
 
+	type MonthTotal struct {
+		Label string
+		Total float64
+	}
+
 	type ExpensesSummary struct {
-		Months     []MonthData
+		Period     string       // "Month" or "Week"
+		DateFormat string       // Date column layout in the per-period tables
+		ThisWeek   *MonthData   // Only with weekly grouping, nil without expenses this week
+		Months     []MonthData  // Newest first
+		Trend      []MonthTotal // Oldest first, at most the last 12 periods
 		TotalAll   float64
 		ByCategory map[string]float64
+		ByTag      []models.TagTotal
 		UpdatedAt  time.Time
 	}
 
-	// Group expenses by month
+	// Group expenses by month or week
 	monthMap := make(map[string]*MonthData)
 	var monthOrder []string
 	totalByCategory := make(map[string]float64)
 	var totalAll float64
 
 	for _, exp := range data.Expenses {
-		monthKey := exp.Date.Format("2006-01")
+		monthKey, label := o.expensePeriod(exp.Date)
 		if _, exists := monthMap[monthKey]; !exists {
 			monthMap[monthKey] = &MonthData{
-				Month:      exp.Date.Format("January 2006"),
+				Month:      label,
 				Total:      0,
 				ByCategory: make(map[string]float64),
 				Expenses:   []models.Expense{},
 			}
 			monthOrder = append(monthOrder, monthKey)
 		}
-		monthMap[monthKey].Total += exp.Amount
-		monthMap[monthKey].ByCategory[string(exp.Category)] += exp.Amount
+		amount := data.Rates.ToBase(exp.Amount, exp.Currency)
+		monthMap[monthKey].Total += amount
+		monthMap[monthKey].ByCategory[string(exp.Category)] += amount
 		monthMap[monthKey].Expenses = append(monthMap[monthKey].Expenses, exp)
-		totalByCategory[string(exp.Category)] += exp.Amount
-		totalAll += exp.Amount
+		totalByCategory[string(exp.Category)] += amount
+		totalAll += amount
+	}
+
+	// Archived expenses only remain as totals
+	for _, archive := range data.Archives {
+		for category, amount := range archive.ByCategory {
+			totalByCategory[string(category)] += amount
+			totalAll += amount
+		}
 	}
 
 	// Sort months in reverse order (newest first)
@@ -229,11 +353,29 @@ func (o *ObsidianWriter) writeExpensesSummary(data *models.Data) error {
 		months = append(months, *monthMap[key])
 	}
 
+	// Chart the newest 12 periods, oldest first
+	var trend []MonthTotal
+	for i := min(len(monthOrder), 12) - 1; i >= 0; i-- {
+		m := monthMap[monthOrder[i]]
+		trend = append(trend, MonthTotal{Label: m.Month, Total: m.Total})
+	}
+
+	now := o.now()
 	summary := ExpensesSummary{
+		Period:     "Month",
+		DateFormat: "02",
 		Months:     months,
+		Trend:      trend,
 		TotalAll:   totalAll,
 		ByCategory: totalByCategory,
-		UpdatedAt:  time.Now(),
+		ByTag:      data.TagTotalsAllTime(),
+		UpdatedAt:  now,
+	}
+	if o.config.ExpenseGrouping == config.ExpenseGroupingWeek {
+		summary.Period, summary.DateFormat = "Week", "Mon 02 Jan"
+		if key, _ := o.expensePeriod(now); monthMap[key] != nil {
+			summary.ThisWeek = monthMap[key]
+		}
 	}
 
 	tmpl := `---
@@ -245,38 +387,80 @@ updated: {{.UpdatedAt.Format "2006-01-02 15:04:05"}}
 
 > Last Updated: {{.UpdatedAt.Format "2006-01-02 15:04:05"}}
 
-## Total: {{printf "%.2f" .TotalAll}}
+## Total: {{amount .TotalAll}}
 
 ### By Category (All Time)
 
 | Category | Amount |
 |----------|--------|
 {{- range $cat, $amt := .ByCategory}}
-| {{$cat}} | {{printf "%.2f" $amt}} |
+| {{$cat}} | {{amount $amt}} |
+{{- end}}
+{{if .ByTag}}
+### By Tag (All Time)
+
+| Tag | Amount |
+|-----|--------|
+{{- range .ByTag}}
+| #{{.Tag}} | {{amount .Total}} |
+{{- end}}
+{{end}}
+{{- if .ThisWeek}}
+### This Week
+
+**{{.ThisWeek.Month}}: {{amount .ThisWeek.Total}}**
+
+| Category | Amount |
+|----------|--------|
+{{- range $cat, $amt := .ThisWeek.ByCategory}}
+| {{$cat}} | {{amount $amt}} |
 {{- end}}
 
+{{end}}
+{{- if .Trend}}
+### {{.Period}}ly Trend
+
+` + "```mermaid" + `
+xychart-beta
+    title "{{.Period}}ly expenses"
+    x-axis [{{range $i, $m := .Trend}}{{if $i}}, {{end}}"{{$m.Label}}"{{end}}]
+    y-axis "Amount"
+    bar [{{range $i, $m := .Trend}}{{if $i}}, {{end}}{{printf "%.2f" $m.Total}}{{end}}]
+` + "```" + `
+
+### Category Breakdown
+
+` + "```mermaid" + `
+pie showData
+    title Expenses by category
+{{- range $cat, $amt := .ByCategory}}
+    "{{$cat}}" : {{printf "%.2f" $amt}}
+{{- end}}
+` + "```" + `
+{{end}}
 ---
 {{range .Months}}
 ## {{.Month}}
 
-**Total: {{printf "%.2f" .Total}}**
+**Total: {{amount .Total}}**
 
-| Date | Description | Category | Amount |
-|------|-------------|----------|--------|
+| Date | Description | Category | Tags | Amount | Notes |
+|------|-------------|----------|------|--------|-------|
 {{- range .Expenses}}
-| {{.Date.Format "02"}} | {{.Description}} | {{.Category}} | {{printf "%.2f" .Amount}} |
+| {{.Date.Format $.DateFormat}} | {{.Description}} | {{.Category}} | {{range $i, $t := .Tags}}{{if $i}} {{end}}#{{$t}}{{end}} | {{amount .Amount}} | {{cell .Notes}} |
 {{- end}}
 
 {{end}}
 `
 
-	return o.writeNoteWithFuncs("", "Expenses.md", tmpl, summary)
+	return o.writeNoteWithFuncs(o.config.NoteFolders().Expenses, "Expenses.md", TemplateExpenses, tmpl, summary)
 }
 
 // writeDebtsSummary writes debts grouped by person
 func (o *ObsidianWriter) writeDebtsSummary(data *models.Data) error {
 	type PersonDebt struct {
 		Name          string
+		Note          string // Person note to link to
 		TotalLent     float64
 		TotalBorrowed float64
 		NetBalance    float64
@@ -284,8 +468,14 @@ func (o *ObsidianWriter) writeDebtsSummary(data *models.Data) error {
 		BorrowedTxns  []models.DebtTransaction
 	}
 
+	type SettledDebt struct {
+		models.DebtTransaction
+		Note string // Person note to link to
+	}
+
 	type DebtsSummary struct {
 		People        []PersonDebt
+		Settled       []SettledDebt
 		TotalLent     float64
 		TotalBorrowed float64
 		NetPosition   float64
@@ -295,9 +485,12 @@ func (o *ObsidianWriter) writeDebtsSummary(data *models.Data) error {
 	// Group by person
 	personMap := make(map[string]*PersonDebt)
 	var personOrder []string
+	notes := o.personNoteLinks(data)
+	var settled []SettledDebt
 
 	for _, tx := range data.DebtTransactions {
 		if tx.IsSettled {
+			settled = append(settled, SettledDebt{DebtTransaction: tx, Note: notes[NormalizeName(tx.PersonName)]})
 			continue
 		}
 		key := NormalizeName(tx.PersonName)
@@ -311,11 +504,12 @@ func (o *ObsidianWriter) writeDebtsSummary(data *models.Data) error {
 			}
 			personOrder = append(personOrder, key)
 		}
+		amount := data.Rates.ToBase(tx.Amount, tx.Currency)
 		if tx.Type == models.Lent {
-			personMap[key].TotalLent += tx.Amount
+			personMap[key].TotalLent += amount
 			personMap[key].LentTxns = append(personMap[key].LentTxns, tx)
 		} else {
-			personMap[key].TotalBorrowed += tx.Amount
+			personMap[key].TotalBorrowed += amount
 			personMap[key].BorrowedTxns = append(personMap[key].BorrowedTxns, tx)
 		}
 	}
@@ -323,7 +517,8 @@ func (o *ObsidianWriter) writeDebtsSummary(data *models.Data) error {
 	var people []PersonDebt
 	for _, name := range personOrder {
 		p := personMap[name]
-		p.NetBalance = p.TotalLent - p.TotalBorrowed
+		p.Note = notes[name]
+		p.NetBalance = data.PersonNetBalance(name)
 		people = append(people, *p)
 	}
 
@@ -332,12 +527,18 @@ func (o *ObsidianWriter) writeDebtsSummary(data *models.Data) error {
 		return people[i].NetBalance > people[j].NetBalance
 	})
 
+	// Most recently settled first
+	sort.SliceStable(settled, func(i, j int) bool {
+		return settledAt(settled[i].DebtTransaction).After(settledAt(settled[j].DebtTransaction))
+	})
+
 	summary := DebtsSummary{
 		People:        people,
+		Settled:       settled,
 		TotalLent:     data.TotalLent(),
 		TotalBorrowed: data.TotalBorrowed(),
 		NetPosition:   data.TotalLent() - data.TotalBorrowed(),
-		UpdatedAt:     time.Now(),
+		UpdatedAt:     o.now(),
 	}
 
 	tmpl := `---
@@ -353,9 +554,9 @@ updated: {{.UpdatedAt.Format "2006-01-02 15:04:05"}}
 
 | Metric | Amount |
 |--------|--------|
-| Total Lent (others owe you) | {{printf "%.2f" .TotalLent}} |
-| Total Borrowed (you owe) | {{printf "%.2f" .TotalBorrowed}} |
-| **Net Position** | {{printf "%.2f" .NetPosition}} |
+| Total Lent (others owe you) | {{amount .TotalLent}} |
+| Total Borrowed (you owe) | {{amount .TotalBorrowed}} |
+| **Net Position** | {{amount .NetPosition}} |
 
 ---
 
@@ -366,14 +567,16 @@ updated: {{.UpdatedAt.Format "2006-01-02 15:04:05"}}
 {{range .People}}
 ### {{.Name}}
 
-{{if gt .NetBalance 0.0}}**Owes you: {{printf "%.2f" .NetBalance}}**{{else if lt .NetBalance 0.0}}**You owe: {{printf "%.2f" (neg .NetBalance)}}**{{else}}**Settled**{{end}}
+[[{{.Note}}|Full history →]]
+
+{{if gt .NetBalance 0.0}}**Outstanding - owes you: {{amount .NetBalance}}**{{else if lt .NetBalance 0.0}}**Outstanding - you owe: {{amount (neg .NetBalance)}}**{{else}}**Settled**{{end}}
 
 {{if .LentTxns}}
 **Lent:**
 | Date | Amount | Reason |
 |------|--------|--------|
 {{- range .LentTxns}}
-| {{.Date.Format "2006-01-02"}} | +{{printf "%.2f" .Amount}} | {{.Description}} |
+| {{.Date.Format "2006-01-02"}} | +{{amount .Amount}} | {{.Description}} |
 {{- end}}
 {{end}}
 {{if .BorrowedTxns}}
@@ -381,15 +584,190 @@ updated: {{.UpdatedAt.Format "2006-01-02 15:04:05"}}
 | Date | Amount | Reason |
 |------|--------|--------|
 {{- range .BorrowedTxns}}
-| {{.Date.Format "2006-01-02"}} | -{{printf "%.2f" .Amount}} | {{.Description}} |
+| {{.Date.Format "2006-01-02"}} | -{{amount .Amount}} | {{.Description}} |
 {{- end}}
 {{end}}
 
 ---
 {{end}}
+{{if .Settled}}
+## Settlement History
+
+| Settled | Person | Type | Amount | Reason | Note |
+|---------|--------|------|--------|--------|------|
+{{- range .Settled}}
+| {{if .SettledDate}}{{.SettledDate.Format "2006-01-02"}}{{end}} | [[{{.Note}}\|{{.PersonName}}]] | {{.Type}} | {{amount .Principal}} | {{cell .Description}} | {{cell .SettlementNote}} |
+{{- end}}
+{{end}}
 `
 
-	return o.writeNoteWithFuncs("", "Debts.md", tmpl, summary)
+	return o.writeNoteWithFuncs(o.config.NoteFolders().Debts, "Debts.md", TemplateDebts, tmpl, summary)
+}
+
+// settledAt is when a settled transaction was closed, falling back to its
+// date for records without a settled date
+func settledAt(tx models.DebtTransaction) time.Time {
+	if tx.SettledDate != nil {
+		return *tx.SettledDate
+	}
+	return tx.Date
+}
+
+// personNoteNames maps each person (by NormalizeName) to the file name of
+// their note, without the .md extension. Names that sanitize to the same
+// thing, or to nothing, get a short hash of the name appended so every
+// person keeps their own note.
+func personNoteNames(data *models.Data) map[string]string {
+	byFile := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, tx := range data.DebtTransactions {
+		key := NormalizeName(tx.PersonName)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		file := sanitizeFilename(key)
+		byFile[file] = append(byFile[file], key)
+	}
+
+	names := make(map[string]string, len(seen))
+	for file, keys := range byFile {
+		for _, key := range keys {
+			if file == "" || len(keys) > 1 {
+				base := file
+				if base == "" {
+					base = "person"
+				}
+				h := fnv.New32a()
+				h.Write([]byte(key))
+				names[key] = fmt.Sprintf("%s-%06x", base, h.Sum32()&0xffffff)
+				continue
+			}
+			names[key] = file
+		}
+	}
+	return names
+}
+
+// personNoteLinks maps each person (by NormalizeName) to the vault-relative
+// wikilink target of their note
+func (o *ObsidianWriter) personNoteLinks(data *models.Data) map[string]string {
+	dir := filepath.ToSlash(o.config.NoteFolders().People)
+	links := personNoteNames(data)
+	for key, name := range links {
+		links[key] = path.Join(dir, name)
+	}
+	return links
+}
+
+// writePersonDebtNotes writes <people folder>/<name>.md for everyone with a debt
+// transaction, covering open and settled transactions and every payment
+func (o *ObsidianWriter) writePersonDebtNotes(data *models.Data) error {
+	type PersonNote struct {
+		Name          string
+		TotalLent     float64
+		TotalBorrowed float64
+		NetBalance    float64
+		LifetimeNet   float64
+		Transactions  []models.DebtTransaction
+		Settled       []models.DebtTransaction
+		Settlements   []models.Settlement
+		Now           time.Time
+		UpdatedAt     time.Time
+	}
+
+	now := o.now()
+	people := make(map[string]*PersonNote)
+	for _, tx := range data.DebtTransactions {
+		key := NormalizeName(tx.PersonName)
+		p, ok := people[key]
+		if !ok {
+			p = &PersonNote{Name: key, Now: now, UpdatedAt: now}
+			people[key] = p
+		}
+		principal := data.Rates.ToBase(tx.Principal(), tx.Currency)
+		if tx.Type == models.Lent {
+			p.TotalLent += principal
+		} else {
+			p.TotalBorrowed += principal
+		}
+		p.Transactions = append(p.Transactions, tx)
+		if tx.IsSettled {
+			p.Settled = append(p.Settled, tx)
+		}
+	}
+	for _, st := range data.Settlements {
+		if p, ok := people[NormalizeName(st.PersonName)]; ok {
+			p.Settlements = append(p.Settlements, st)
+		}
+	}
+
+	tmpl := `---
+tags: [debtq, debts, person, finance]
+person: "{{.Name}}"
+updated: {{.UpdatedAt.Format "2006-01-02 15:04:05"}}
+---
+
+# {{.Name}}
+
+> Last Updated: {{.UpdatedAt.Format "2006-01-02 15:04:05"}}
+
+[[Debts|← All debts]]
+
+{{if gt .NetBalance 0.0}}**Outstanding - owes you: {{amount .NetBalance}}**{{else if lt .NetBalance 0.0}}**Outstanding - you owe: {{amount (neg .NetBalance)}}**{{else}}**Settled**{{end}}
+
+| Metric | Amount |
+|--------|--------|
+| Lent (all time) | {{amount .TotalLent}} |
+| Borrowed (all time) | {{amount .TotalBorrowed}} |
+| Lifetime net (lent - borrowed) | {{amount .LifetimeNet}} |
+
+## Transactions
+
+| Date | Type | Amount | Remaining | Status | Reason |
+|------|------|--------|-----------|--------|--------|
+{{- range .Transactions}}
+| {{.Date.Format "2006-01-02"}} | {{.Type}} | {{amount .Principal}} | {{amount .Amount}} | {{if .IsSettled}}Settled{{if .SettledDate}} {{.SettledDate.Format "2006-01-02"}}{{end}}{{else if .IsOverdue $.Now}}Overdue{{else}}Open{{end}} | {{cell .Description}} |
+{{- end}}
+{{if .Settled}}
+## Settlement History
+
+| Settled | Type | Amount | Reason | Note |
+|---------|------|--------|--------|------|
+{{- range .Settled}}
+| {{if .SettledDate}}{{.SettledDate.Format "2006-01-02"}}{{end}} | {{.Type}} | {{amount .Principal}} | {{cell .Description}} | {{cell .SettlementNote}} |
+{{- end}}
+{{end}}
+{{- if .Settlements}}
+## Payments
+
+| Date | Type | Amount | Note |
+|------|------|--------|------|
+{{- range .Settlements}}
+| {{.Date.Format "2006-01-02"}} | {{.Type}} | {{amount .Amount}} | {{cell .Note}} |
+{{- end}}
+{{end}}
+`
+
+	notes := personNoteNames(data)
+	peopleDir := o.config.NoteFolders().People
+	for key, p := range people {
+		sort.SliceStable(p.Transactions, func(i, j int) bool {
+			return p.Transactions[i].Date.Before(p.Transactions[j].Date)
+		})
+		sort.SliceStable(p.Settled, func(i, j int) bool {
+			return settledAt(p.Settled[i]).After(settledAt(p.Settled[j]))
+		})
+		sort.SliceStable(p.Settlements, func(i, j int) bool {
+			return p.Settlements[i].Date.Before(p.Settlements[j].Date)
+		})
+		p.NetBalance = data.PersonNetBalance(key)
+		p.LifetimeNet = data.PersonLifetimeNet(key)
+		if err := o.writeNoteWithFuncs(peopleDir, notes[key]+".md", TemplatePerson, tmpl, p); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // writeNetWorthSummary writes investments summary
@@ -409,6 +787,7 @@ func (o *ObsidianWriter) writeNetWorthSummary(data *models.Data) error {
 		TotalCurrent   float64
 		TotalGain      float64
 		GainPercentage float64
+		History        []models.NetWorthSnapshot // Last 30 snapshots, oldest first
 		UpdatedAt      time.Time
 	}
 
@@ -426,11 +805,13 @@ func (o *ObsidianWriter) writeNetWorthSummary(data *models.Data) error {
 			}
 			typeOrder = append(typeOrder, typeKey)
 		}
-		typeMap[typeKey].TotalInvested += inv.InvestedAmount
-		typeMap[typeKey].TotalCurrent += inv.CurrentValue
+		invested := data.Rates.ToBase(inv.InvestedAmount, inv.Currency)
+		current := data.Rates.ToBase(inv.CurrentValue, inv.Currency)
+		typeMap[typeKey].TotalInvested += invested
+		typeMap[typeKey].TotalCurrent += current
 		typeMap[typeKey].Investments = append(typeMap[typeKey].Investments, inv)
-		totalInvested += inv.InvestedAmount
-		totalCurrent += inv.CurrentValue
+		totalInvested += invested
+		totalCurrent += current
 	}
 
 	var groups []InvestmentGroup
@@ -460,7 +841,8 @@ func (o *ObsidianWriter) writeNetWorthSummary(data *models.Data) error {
 		TotalCurrent:   totalCurrent,
 		TotalGain:      totalGain,
 		GainPercentage: gainPercentage,
-		UpdatedAt:      time.Now(),
+		History:        data.NetWorthSnapshots[max(len(data.NetWorthSnapshots)-30, 0):],
+		UpdatedAt:      o.now(),
 	}
 
 	tmpl := `---
@@ -476,11 +858,27 @@ updated: {{.UpdatedAt.Format "2006-01-02 15:04:05"}}
 
 | Metric | Value |
 |--------|-------|
-| Total Invested | {{printf "%.2f" .TotalInvested}} |
-| Current Value | {{printf "%.2f" .TotalCurrent}} |
-| Total Gain/Loss | {{printf "%.2f" .TotalGain}} |
+| Total Invested | {{amount .TotalInvested}} |
+| Current Value | {{amount .TotalCurrent}} |
+| Total Gain/Loss | {{amount .TotalGain}} |
 | Return | {{printf "%.2f" .GainPercentage}}% |
+{{if .History}}
+## Net Worth History
+
+` + "```mermaid" + `
+xychart-beta
+    title "Net worth"
+    x-axis [{{range $i, $s := .History}}{{if $i}}, {{end}}"{{$s.Date.Format "Jan 02"}}"{{end}}]
+    y-axis "Value"
+    line [{{range $i, $s := .History}}{{if $i}}, {{end}}{{amount $s.Value}}{{end}}]
+` + "```" + `
 
+| Date | Net Worth |
+|------|-----------|
+{{- range .History}}
+| {{.Date.Format "2006-01-02"}} | {{amount .Value}} |
+{{- end}}
+{{end}}
 ---
 
 ## By Investment Type
@@ -488,7 +886,7 @@ updated: {{.UpdatedAt.Format "2006-01-02 15:04:05"}}
 | Type | Invested | Current | Gain/Loss | Return % |
 |------|----------|---------|-----------|----------|
 {{- range .Groups}}
-| **{{.Type}}** | {{printf "%.2f" .TotalInvested}} | {{printf "%.2f" .TotalCurrent}} | {{printf "%.2f" .Gain}} | {{printf "%.2f" .GainPercentage}}% |
+| **{{.Type}}** | {{amount .TotalInvested}} | {{amount .TotalCurrent}} | {{amount .Gain}} | {{printf "%.2f" .GainPercentage}}% |
 {{- end}}
 
 ---
@@ -498,13 +896,13 @@ updated: {{.UpdatedAt.Format "2006-01-02 15:04:05"}}
 | Name | Invested | Current | Gain/Loss | Return % |
 |------|----------|---------|-----------|----------|
 {{- range .Investments}}
-| {{.Name}} | {{printf "%.2f" .InvestedAmount}} | {{printf "%.2f" .CurrentValue}} | {{printf "%.2f" (sub .CurrentValue .InvestedAmount)}} | {{if gt .InvestedAmount 0}}{{printf "%.2f" (percentage .CurrentValue .InvestedAmount)}}%{{else}}N/A{{end}} |
+| {{.Name}} | {{amount .InvestedAmount}} | {{amount .CurrentValue}} | {{amount (sub .CurrentValue .InvestedAmount)}} | {{if gt .InvestedAmount 0}}{{printf "%.2f" (gainPct .CurrentValue .InvestedAmount)}}%{{else}}N/A{{end}} |
 {{- end}}
 
 {{end}}
 `
 
-	return o.writeNoteWithFuncs("", "NetWorth.md", tmpl, summary)
+	return o.writeNoteWithFuncs(o.config.NoteFolders().NetWorth, "NetWorth.md", TemplateNetWorth, tmpl, summary)
 }
 
 // writeSavingsSummary writes savings goals summary
@@ -542,7 +940,7 @@ func (o *ObsidianWriter) writeSavingsSummary(data *models.Data) error {
 		TotalTarget:    totalTarget,
 		TotalSaved:     totalSaved,
 		Progress:       progress,
-		UpdatedAt:      time.Now(),
+		UpdatedAt:      o.now(),
 	}
 
 	tmpl := `---
@@ -558,8 +956,8 @@ updated: {{.UpdatedAt.Format "2006-01-02 15:04:05"}}
 
 | Metric | Value |
 |--------|-------|
-| Total Target | {{printf "%.2f" .TotalTarget}} |
-| Total Saved | {{printf "%.2f" .TotalSaved}} |
+| Total Target | {{amount .TotalTarget}} |
+| Total Saved | {{amount .TotalSaved}} |
 | Overall Progress | {{printf "%.1f" .Progress}}% |
 | Active Goals | {{len .ActiveGoals}} |
 | Completed Goals | {{len .CompletedGoals}} |
@@ -575,13 +973,13 @@ updated: {{.UpdatedAt.Format "2006-01-02 15:04:05"}}
 
 | Metric | Value |
 |--------|-------|
-| Target | {{printf "%.2f" .TargetAmount}} |
-| Saved | {{printf "%.2f" .CurrentAmount}} |
-| Remaining | {{printf "%.2f" (sub .TargetAmount .CurrentAmount)}} |
-| Progress | {{printf "%.1f" (percentage .CurrentAmount .TargetAmount)}}% |
+| Target | {{amount .TargetAmount}} |
+| Saved | {{amount .CurrentAmount}} |
+| Remaining | {{amount (sub .TargetAmount .CurrentAmount)}} |
+| Progress | {{printf "%.1f" (progressPct .CurrentAmount .TargetAmount)}}% |
 | Target Date | {{.TargetDate.Format "2006-01-02"}} |
 | Days Left | {{daysRemaining .TargetDate}} |
-| Monthly Required | {{printf "%.2f" (monthlyRequired .TargetAmount .CurrentAmount .TargetDate)}} |
+| Monthly Required | {{amount (monthlyRequired .TargetAmount .CurrentAmount .TargetDate)}} |
 
 ` + "```" + `
 {{progressBar .CurrentAmount .TargetAmount 30}}
@@ -598,38 +996,80 @@ updated: {{.UpdatedAt.Format "2006-01-02 15:04:05"}}
 | Product | Target | Saved | Completed |
 |---------|--------|-------|-----------|
 {{- range .CompletedGoals}}
-| {{.ProductName}} | {{printf "%.2f" .TargetAmount}} | {{printf "%.2f" .CurrentAmount}} | ✅ |
+| {{.ProductName}} | {{amount .TargetAmount}} | {{amount .CurrentAmount}} | ✅ |
 {{- end}}
 {{end}}
 `
 
-	return o.writeNoteWithFuncs("", "Savings.md", tmpl, summary)
+	return o.writeNoteWithFuncs(o.config.NoteFolders().Savings, "Savings.md", TemplateSavings, tmpl, summary)
 }
 
 // Helper functions
 
-func (o *ObsidianWriter) writeNoteWithFuncs(subdir, filename, tmplStr string, data interface{}) error {
-	funcMap := template.FuncMap{
+// writeNoteWithFuncs renders the user's template called name, or tmplStr when
+// there is none, into subdir/filename of the vault
+func (o *ObsidianWriter) writeNoteWithFuncs(subdir, filename, name, tmplStr string, data interface{}) error {
+	tmpl, ok := o.templates[name]
+	if !ok {
+		var err error
+		tmpl, err = template.New(name).Funcs(templateFuncs(o.now(), o.config)).Parse(tmplStr)
+		if err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("rendering %s: %w", filename, err)
+	}
+
+	dir := filepath.Join(o.config.ObsidianVaultPath, subdir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, filename), buf.Bytes(), 0644)
+}
+
+// templateFuncs are the helpers available to built-in and custom templates;
+// date helpers count from now and amount groups digits as cfg says
+func templateFuncs(now time.Time, cfg *config.Config) template.FuncMap {
+	return template.FuncMap{
+		// amount formats a base currency amount for display (12,34,567.50)
+		"amount": func(f float64) string {
+			return cfg.FormatNumber(f, cfg.Currency)
+		},
 		"sub": func(a, b float64) float64 {
 			return a - b
 		},
 		"neg": func(a float64) float64 {
 			return -a
 		},
+		// cell makes free text safe inside a markdown table cell
+		"cell": func(s string) string {
+			return strings.NewReplacer("|", "\\|", "\r\n", "<br>", "\n", "<br>").Replace(s)
+		},
 		"gt": func(a, b float64) bool {
 			return a > b
 		},
 		"lt": func(a, b float64) bool {
 			return a < b
 		},
-		"percentage": func(current, total float64) float64 {
+		// progressPct is how far current is towards total (50 = halfway)
+		"progressPct": func(current, total float64) float64 {
 			if total == 0 {
 				return 0
 			}
-			return ((current-total)/total)*100 + 100
+			return current / total * 100
+		},
+		// gainPct is the return on invested (10 = up 10%, -5 = down 5%)
+		"gainPct": func(current, invested float64) float64 {
+			if invested == 0 {
+				return 0
+			}
+			return (current - invested) / invested * 100
 		},
 		"daysRemaining": func(targetDate time.Time) int {
-			days := int(time.Until(targetDate).Hours() / 24)
+			days := int(targetDate.Sub(now).Hours() / 24)
 			if days < 0 {
 				return 0
 			}
@@ -640,7 +1080,7 @@ func (o *ObsidianWriter) writeNoteWithFuncs(subdir, filename, tmplStr string, da
 			if remaining <= 0 {
 				return 0
 			}
-			months := float64(time.Until(targetDate).Hours()) / (24 * 30)
+			months := targetDate.Sub(now).Hours() / (24 * 30)
 			if months <= 0 {
 				return remaining
 			}
@@ -666,24 +1106,6 @@ func (o *ObsidianWriter) writeNoteWithFuncs(subdir, filename, tmplStr string, da
 			return fmt.Sprintf("%s %.1f%%", bar, pct*100)
 		},
 	}
-
-	tmpl, err := template.New("note").Funcs(funcMap).Parse(tmplStr)
-	if err != nil {
-		return err
-	}
-
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return err
-	}
-
-	var filePath string
-	if subdir == "" {
-		filePath = filepath.Join(o.config.ObsidianVaultPath, filename)
-	} else {
-		filePath = filepath.Join(o.config.ObsidianVaultPath, subdir, filename)
-	}
-	return os.WriteFile(filePath, buf.Bytes(), 0644)
 }
 
 func sanitizeFilename(s string) string {
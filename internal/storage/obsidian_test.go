@@ -0,0 +1,218 @@
+package storage
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/debtq/debtq/internal/models"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// testNow is the fixed clock notes are rendered at in tests
+var testNow = time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+
+// newTestWriter returns an ObsidianWriter for s's config dated at testNow
+func newTestWriter(s *Storage) *ObsidianWriter {
+	o := NewObsidianWriter(s.config)
+	o.now = func() time.Time { return testNow }
+	return o
+}
+
+// checkGolden compares got with testdata/name, rewriting it under -update
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs from the golden file (go test -update rewrites it):\n%s", name, got)
+	}
+}
+
+func TestDashboardNetWorthGoalGolden(t *testing.T) {
+	s := newTestStorage(t)
+	s.config.NetWorthGoal = 1000000
+	s.config.NetWorthGoalDate = "2026-12-31"
+	bought := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	s.AddInvestment(models.InvestmentMutualFunds, "Index fund", 200000, 250000, 100, bought, "", "")
+	s.AddInvestment(models.InvestmentFD, "FD", 150000, 150000, 0, bought, "", "")
+
+	o := newTestWriter(s)
+	if err := o.writeDashboard(s.GetData()); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "dashboard_goal.golden", readNote(t, s, "Dashboard.md"))
+}
+
+func TestDashboardWithoutGoalOmitsSection(t *testing.T) {
+	s := newTestStorage(t)
+	o := newTestWriter(s)
+	if err := o.writeDashboard(s.GetData()); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "dashboard_no_goal.golden", readNote(t, s, "Dashboard.md"))
+}
+
+func TestNetWorthNoteGainPercent(t *testing.T) {
+	s := newTestStorage(t)
+	bought := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	s.AddInvestment(models.InvestmentStocks, "Winner", 1000, 1100, 0, bought, "", "")
+	s.AddInvestment(models.InvestmentGold, "Loser", 2000, 1500, 0, bought, "", "")
+
+	o := newTestWriter(s)
+	if err := o.writeNetWorthSummary(s.GetData()); err != nil {
+		t.Fatal(err)
+	}
+	note := readNote(t, s, "NetWorth.md")
+	// Gain is relative to what was invested, not a share of the total
+	for _, want := range []string{"| Winner | 1,000.00 | 1,100.00 | 100.00 | 10.00% |", "| Loser | 2,000.00 | 1,500.00 | -500.00 | -25.00% |"} {
+		if !strings.Contains(note, want) {
+			t.Errorf("NetWorth.md is missing %q:\n%s", want, note)
+		}
+	}
+}
+
+func TestSavingsNoteProgressPercent(t *testing.T) {
+	s := newTestStorage(t)
+	goal, err := s.AddSavingsTarget("Laptop", 1000, testNow.AddDate(0, 6, 0), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.AddSavingsContribution(goal.ID, 250, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	o := newTestWriter(s)
+	if err := o.writeSavingsSummary(s.GetData()); err != nil {
+		t.Fatal(err)
+	}
+	note := readNote(t, s, "Savings.md")
+	// Progress is the share of the target saved, not a gain over it
+	for _, want := range []string{"| Progress | 25.0% |", "░ 25.0%"} {
+		if !strings.Contains(note, want) {
+			t.Errorf("Savings.md is missing %q:\n%s", want, note)
+		}
+	}
+	if strings.Contains(note, "-75.0") {
+		t.Errorf("Savings.md shows progress as a loss:\n%s", note)
+	}
+}
+
+func TestSettledDebtsInNotes(t *testing.T) {
+	s := newTestStorage(t)
+	day := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	open, _ := s.AddDebtTransaction(models.Lent, "Asha", 300, "rent share", day, nil)
+	settled, _ := s.AddDebtTransaction(models.Lent, "Asha", 1200, "concert", day, nil)
+	if open == nil || settled == nil {
+		t.Fatal("adding debts failed")
+	}
+	if err := s.SettleTransactionWithNote(settled.ID, 0, "paid via upi"); err != nil {
+		t.Fatal(err)
+	}
+
+	o := newTestWriter(s)
+	data := s.GetData()
+	if err := o.writeDebtsSummary(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.writePersonDebtNotes(data); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"Debts.md", filepath.Join("People", "ASHA.md")} {
+		note := readNote(t, s, path)
+		history := strings.Index(note, "## Settlement History")
+		if history < 0 {
+			t.Fatalf("%s has no settlement history:\n%s", path, note)
+		}
+		row := note[history:]
+		for _, want := range []string{"1,200.00", "concert", "paid via upi", time.Now().Format("2006-01-02")} {
+			if !strings.Contains(row, want) {
+				t.Errorf("%s settlement history is missing %q:\n%s", path, want, row)
+			}
+		}
+		// Open debts come first
+		if active := strings.Index(note, "rent share"); active < 0 || active > history {
+			t.Errorf("%s lists the open debt after the settlement history:\n%s", path, note)
+		}
+	}
+}
+
+func TestDebtTablesEscapeFreeText(t *testing.T) {
+	s := newTestStorage(t)
+	day := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	settled, err := s.AddDebtTransaction(models.Lent, "Asha", 1200, "tickets | parking", day, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SettleTransactionWithNote(settled.ID, 0, "upi\nref 42"); err != nil {
+		t.Fatal(err)
+	}
+
+	o := newTestWriter(s)
+	data := s.GetData()
+	if err := o.writeDebtsSummary(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.writePersonDebtNotes(data); err != nil {
+		t.Fatal(err)
+	}
+
+	person := readNote(t, s, filepath.Join("People", "ASHA.md"))
+	for path, note := range map[string]string{"Debts.md": readNote(t, s, "Debts.md"), "ASHA.md": person} {
+		if strings.Contains(note, "tickets | parking") || strings.Contains(note, "upi\nref 42") {
+			t.Errorf("%s has unescaped free text in a table:\n%s", path, note)
+		}
+		if !strings.Contains(note, `tickets \| parking`) || !strings.Contains(note, "upi<br>ref 42") {
+			t.Errorf("%s lacks the escaped description or note:\n%s", path, note)
+		}
+	}
+	// The payments table escapes each settlement's own note too
+	if !strings.Contains(person, "| 1,200.00 | upi<br>ref 42 |") {
+		t.Errorf("ASHA.md payments row is not escaped:\n%s", person)
+	}
+}
+
+func TestNoteTotalsMatchDashboardAcrossCurrencies(t *testing.T) {
+	s := newTestStorage(t)
+	s.config.ExchangeRates = map[string]float64{"USD": 80}
+	s.RefreshExchangeRates()
+	day := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	s.AddExpense(1000, "Groceries", models.CategoryFood, day, "", nil, "")
+	s.AddExpense(10, "Ebook", models.CategoryShopping, day, "USD", nil, "")
+	s.AddInvestment(models.InvestmentStocks, "Local", 1000, 1100, 0, day, "", "")
+	s.AddInvestment(models.InvestmentStocks, "Overseas", 10, 20, 0, day, "", "USD")
+	s.AddDebtTransaction(models.Lent, "Asha", 100, "lunch", day, nil)
+	s.AddDebtTransactionWithInterest(models.Lent, "Asha", 5, "taxi", day, nil, 0, "", 0, "USD")
+
+	o := newTestWriter(s)
+	if err := o.SyncAllNotes(s.GetData()); err != nil {
+		t.Fatal(err)
+	}
+	// 10 USD of expenses is 800, 20 USD of stock 1,600 and 5 USD lent 400
+	dashboard := readNote(t, s, "Dashboard.md")
+	for _, tc := range []struct{ note, dashboardLine, noteLine string }{
+		{"Expenses.md", "| All Time Total | 1,800.00 |", "## Total: 1,800.00"},
+		{"NetWorth.md", "| **Net Worth** | 2,700.00 |", "| Current Value | 2,700.00 |"},
+		{"Debts.md", "| Total Lent (others owe you) | 500.00 |", "**Outstanding - owes you: 500.00**"},
+	} {
+		if !strings.Contains(dashboard, tc.dashboardLine) {
+			t.Errorf("Dashboard.md is missing %q:\n%s", tc.dashboardLine, dashboard)
+		}
+		if note := readNote(t, s, tc.note); !strings.Contains(note, tc.noteLine) {
+			t.Errorf("%s is missing %q:\n%s", tc.note, tc.noteLine, note)
+		}
+	}
+}
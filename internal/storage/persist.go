@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/debtq/debtq/internal/config"
+)
+
+// Persister stores the serialized dataset. Load returns an error satisfying
+// errors.Is(err, fs.ErrNotExist) when nothing has been saved yet.
+type Persister interface {
+	Load() ([]byte, error)
+	Save(data []byte) error
+}
+
+// FilePersister keeps the dataset in config.DataFile. The path is read on
+// every call, so a data file changed in Settings is used from the next save.
+type FilePersister struct {
+	config *config.Config
+}
+
+// NewFilePersister returns a Persister for cfg.DataFile
+func NewFilePersister(cfg *config.Config) *FilePersister {
+	return &FilePersister{config: cfg}
+}
+
+// Load reads the data file. When it does not exist yet, the directory is
+// checked to be writable first so the first save cannot fail on it.
+func (p *FilePersister) Load() ([]byte, error) {
+	dir := filepath.Dir(p.config.DataFile)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, dataDirError(dir, err)
+	}
+
+	data, err := os.ReadFile(p.config.DataFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		if err := config.CheckWritableDir(dir); err != nil {
+			return nil, dataDirError(dir, err)
+		}
+	}
+	return data, err
+}
+
+// Save replaces the data file atomically, keeping the file it replaces as the
+// backup if that was readable
+func (p *FilePersister) Save(data []byte) error {
+	dir := filepath.Dir(p.config.DataFile)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return dataDirError(dir, err)
+	}
+	if old, err := os.ReadFile(p.config.DataFile); err == nil && (json.Valid(old) || IsEncrypted(old)) {
+		if err := writeFileAtomic(p.backupFile(), old, 0644); err != nil {
+			return dataDirError(dir, err)
+		}
+	}
+	if err := writeFileAtomic(p.config.DataFile, data, 0644); err != nil {
+		return dataDirError(dir, err)
+	}
+	return nil
+}
+
+// backupFile returns where Save keeps the previous data file, e.g.
+// data.backup.json next to data.json
+func (p *FilePersister) backupFile() string {
+	ext := filepath.Ext(p.config.DataFile)
+	return strings.TrimSuffix(p.config.DataFile, ext) + ".backup" + ext
+}
+
+// quarantine moves the data file aside to data.corrupt-<timestamp>.json and
+// returns its new path
+func (p *FilePersister) quarantine() (string, error) {
+	ext := filepath.Ext(p.config.DataFile)
+	moved := strings.TrimSuffix(p.config.DataFile, ext) + ".corrupt-" + time.Now().Format("20060102-150405") + ext
+	return moved, os.Rename(p.config.DataFile, moved)
+}
+
+// MemoryPersister keeps the dataset in memory only, for tests and sessions
+// whose changes should be thrown away
+type MemoryPersister struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+// NewMemoryPersister returns a MemoryPersister starting from data, which may be
+// nil for an empty dataset
+func NewMemoryPersister(data []byte) *MemoryPersister {
+	return &MemoryPersister{data: append([]byte(nil), data...)}
+}
+
+// Load returns a copy of the last saved data
+func (p *MemoryPersister) Load() ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.data) == 0 {
+		return nil, fs.ErrNotExist
+	}
+	return append([]byte(nil), p.data...), nil
+}
+
+// Save keeps a copy of data
+func (p *MemoryPersister) Save(data []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.data = append([]byte(nil), data...)
+	return nil
+}
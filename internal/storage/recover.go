@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ErrCorruptData is returned when the saved data is not valid JSON
+var ErrCorruptData = errors.New("the data file is not valid JSON")
+
+// recoverCorruptData moves a data file that failed to load with loadErr aside
+// and loads the backup FilePersister.Save keeps instead. Without a usable
+// backup it returns an fs.ErrNotExist error so New starts empty. Either way
+// Notice explains what happened; nothing is deleted. Callers must hold mu.
+func (s *Storage) recoverCorruptData(loadErr error) error {
+	// Only a data file can be moved aside and has a backup
+	fp, ok := basePersister(s.persister).(*FilePersister)
+	if !ok {
+		return loadErr
+	}
+	moved, err := fp.quarantine()
+	if err != nil {
+		return fmt.Errorf("%w (and it could not be moved aside: %v)", loadErr, err)
+	}
+
+	backup := fp.backupFile()
+	info, err := os.Stat(backup)
+	raw, readErr := os.ReadFile(backup)
+	if err == nil && readErr == nil {
+		if ep, ok := s.persister.(*EncryptedPersister); ok {
+			raw, readErr = ep.open(raw)
+		}
+	}
+	if err != nil || readErr != nil || s.decode(raw) != nil {
+		s.notice = fmt.Sprintf("The data file could not be read and there was no usable backup, so debtq started empty. "+
+			"The damaged file was kept as %s.", filepath.Base(moved))
+		return fs.ErrNotExist
+	}
+
+	s.notice = fmt.Sprintf("The data file could not be read, so debtq loaded the backup from %s. "+
+		"The damaged file was kept as %s; anything changed after the backup is only in that file.",
+		info.ModTime().Format("2 Jan 2006 15:04"), filepath.Base(moved))
+	return nil
+}
+
+// Notice returns a warning about how the data was loaded, e.g. from a backup
+// after the data file was found damaged, or "" if it loaded normally
+func (s *Storage) Notice() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.notice
+}
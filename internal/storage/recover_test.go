@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/debtq/debtq/internal/config"
+	"github.com/debtq/debtq/internal/models"
+)
+
+// newCorruptDataFile returns a config whose data file holds invalid JSON
+func newCorruptDataFile(t *testing.T) *config.Config {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	cfg := config.DefaultConfig()
+	cfg.DataFile = filepath.Join(t.TempDir(), "data.json")
+	if err := os.WriteFile(cfg.DataFile, []byte(`{"expenses": [`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+// corruptFiles returns the data files moved aside next to cfg.DataFile
+func corruptFiles(t *testing.T, cfg *config.Config) []string {
+	t.Helper()
+	moved, err := filepath.Glob(filepath.Join(filepath.Dir(cfg.DataFile), "data.corrupt-*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return moved
+}
+
+func TestCorruptDataFileLoadsBackup(t *testing.T) {
+	cfg := newCorruptDataFile(t)
+	p := NewFilePersister(cfg)
+	backup := `{"expenses": [{"id": "e1", "amount": 120, "description": "Coffee", "category": "food"}]}`
+	if err := os.WriteFile(p.backupFile(), []byte(backup), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New with a corrupt data file: %v", err)
+	}
+	if got := s.GetExpenses(); len(got) != 1 || got[0].Description != "Coffee" {
+		t.Errorf("expenses = %+v, want the backup's", got)
+	}
+	if !strings.Contains(s.Notice(), "loaded the backup") {
+		t.Errorf("Notice() = %q", s.Notice())
+	}
+	moved := corruptFiles(t, cfg)
+	if len(moved) != 1 {
+		t.Fatalf("moved aside: %v, want the damaged file", moved)
+	}
+	if data, _ := os.ReadFile(moved[0]); string(data) != `{"expenses": [` {
+		t.Errorf("moved file = %q, want the damaged data kept", data)
+	}
+}
+
+func TestCorruptDataFileWithoutBackupStartsEmpty(t *testing.T) {
+	cfg := newCorruptDataFile(t)
+
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New with a corrupt data file: %v", err)
+	}
+	if len(s.GetExpenses()) != 0 || !strings.Contains(s.Notice(), "no usable backup") {
+		t.Errorf("%d expenses, Notice() = %q; want empty data and a warning", len(s.GetExpenses()), s.Notice())
+	}
+	p := NewFilePersister(cfg)
+	if len(corruptFiles(t, cfg)) != 1 {
+		t.Error("the damaged file was not kept")
+	}
+
+	// Saving writes a fresh data file and does not back up the damaged one
+	if _, err := s.AddExpense(120, "Coffee", models.CategoryFood, time.Now(), "", nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reopened.GetExpenses()) != 1 || reopened.Notice() != "" {
+		t.Errorf("reopened: %d expenses, Notice() = %q", len(reopened.GetExpenses()), reopened.Notice())
+	}
+	if _, err := os.Stat(p.backupFile()); err == nil {
+		t.Error("the damaged data was kept as the backup")
+	}
+}
+
+func TestCorruptMemoryDataIsAnError(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if _, err := NewWithPersister(config.DefaultConfig(), NewMemoryPersister([]byte(`{"expenses": [`))); err == nil {
+		t.Error("opening invalid JSON from memory succeeded, want an error")
+	}
+}
@@ -1,33 +1,88 @@
 package storage
 
 import (
+	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"github.com/debtq/debtq/internal/config"
 	"github.com/debtq/debtq/internal/models"
 	"github.com/google/uuid"
 )
 
-// Storage handles data persistence
+// Storage handles data persistence. It is safe for concurrent use: readers take
+// mu's read lock and every mutation takes the write lock.
 type Storage struct {
-	config *config.Config
-	data   *models.Data
+	mu        sync.RWMutex
+	config    *config.Config
+	persister Persister
+	data      *models.Data
+	revision  uint64 // Bumped by every successful save
+	notice    string // Set when a damaged data file was recovered from
 }
 
-// New creates a new storage instance
+// New creates a new storage instance backed by cfg.DataFile
 func New(cfg *config.Config) (*Storage, error) {
+	return NewWithPersister(cfg, NewFilePersister(cfg))
+}
+
+// NewWithPersister creates a storage instance that loads from and saves to p,
+// e.g. a MemoryPersister for tests or a session that should not be saved
+func NewWithPersister(cfg *config.Config, p Persister) (*Storage, error) {
 	s := &Storage{
-		config: cfg,
-		data:   &models.Data{},
+		config:    cfg,
+		persister: p,
 	}
 
-	if err := s.Load(); err != nil {
-		// If file doesn't exist, initialize empty data
-		if os.IsNotExist(err) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload replaces the data in memory with what the persister holds, e.g. after
+// config.DataFile was changed. On error the data in memory is kept.
+func (s *Storage) Reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, notice := s.data, s.notice
+	if err := s.open(); err != nil {
+		s.data, s.notice = data, notice
+		return err
+	}
+	s.revision++
+	return nil
+}
+
+// open loads the data through the persister, starting empty when nothing has
+// been saved yet; callers must hold mu
+func (s *Storage) open() error {
+	s.data, s.notice = &models.Data{}, ""
+
+	err := s.load()
+	if errors.Is(err, ErrCorruptData) {
+		err = s.recoverCorruptData(err)
+	}
+	if err != nil {
+		// Nothing saved yet: start with empty data
+		if errors.Is(err, fs.ErrNotExist) {
 			s.data = &models.Data{
 				Expenses:             []models.Expense{},
 				DebtTransactions:     []models.DebtTransaction{},
@@ -35,13 +90,34 @@ func New(cfg *config.Config) (*Storage, error) {
 				Investments:          []models.Investment{},
 				SavingsTargets:       []models.SavingsTarget{},
 				SavingsContributions: []models.SavingsContribution{},
+				InvestmentIncomes:    []models.InvestmentIncome{},
+				RecurringExpenses:    []models.RecurringExpense{},
+				NetWorthSnapshots:    []models.NetWorthSnapshot{},
+				Rates:                s.config.Rates(),
 			}
-			return s, nil
+			return nil
 		}
-		return nil, err
+		return err
 	}
+	s.data.Rates = s.config.Rates()
 
-	return s, nil
+	return s.materializeRecurringExpenses(time.Now())
+}
+
+// RefreshExchangeRates picks up changes to the configured currency and exchange rates
+func (s *Storage) RefreshExchangeRates() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Rates = s.config.Rates()
+	s.data.InvalidateSummary()
+}
+
+// Revision changes whenever a mutation is saved, so callers can tell whether
+// the data changed since they last looked
+func (s *Storage) Revision() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.revision
 }
 
 // NormalizeName normalizes a person name for consistent comparison (trims whitespace and converts to uppercase)
@@ -49,47 +125,154 @@ func NormalizeName(name string) string {
 	return strings.TrimSpace(strings.ToUpper(name))
 }
 
-// Load loads data from file
+// Load loads data from the persister
 func (s *Storage) Load() error {
-	dataPath := s.config.DataFile
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
 
-	// Ensure directory exists
-	dir := filepath.Dir(dataPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+// load loads data from the persister; callers must hold mu
+func (s *Storage) load() error {
+	data, err := s.persister.Load()
+	if err != nil {
 		return err
 	}
+	return s.decode(data)
+}
 
-	data, err := os.ReadFile(dataPath)
-	if err != nil {
-		return err
+// decode replaces the data with the saved JSON in raw; callers must hold mu
+func (s *Storage) decode(raw []byte) error {
+	if IsEncrypted(raw) {
+		return errEncryptedData
 	}
 
-	return json.Unmarshal(data, s.data)
+	data := &models.Data{}
+	if err := json.Unmarshal(raw, data); err != nil {
+		return fmt.Errorf("%w: %v", ErrCorruptData, err)
+	}
+	s.data = data
+	s.backfillValueHistory()
+	return nil
+}
+
+// backfillValueHistory seeds investments saved before value history was kept
+// with their purchase; callers must hold mu
+func (s *Storage) backfillValueHistory() {
+	for i, inv := range s.data.Investments {
+		if len(inv.ValueHistory) == 0 {
+			s.data.Investments[i].ValueHistory = []models.ValuePoint{
+				{Date: inv.PurchaseDate, Value: inv.InvestedAmount},
+			}
+		}
+	}
 }
 
 // Save saves data to file
 func (s *Storage) Save() error {
-	dataPath := s.config.DataFile
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.save()
+}
+
+// save writes data through the persister; callers must hold mu for writing,
+// as it bumps revision and drops the cached summary
+func (s *Storage) save() error {
+	// Every mutation ends here, so this is where cached totals go stale
+	s.data.InvalidateSummary()
 
-	// Ensure directory exists
-	dir := filepath.Dir(dataPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	data, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
 		return err
 	}
 
-	data, err := json.MarshalIndent(s.data, "", "  ")
+	if err := s.persister.Save(data); err != nil {
+		return err
+	}
+	s.revision++
+	return nil
+}
+
+// Ephemeral reports whether changes are only kept in memory
+func (s *Storage) Ephemeral() bool {
+	_, inMemory := basePersister(s.persister).(*MemoryPersister)
+	return inMemory
+}
+
+// basePersister returns the Persister that p, say an EncryptedPersister,
+// finally stores its data in
+func basePersister(p Persister) Persister {
+	for {
+		wrapper, ok := p.(interface{ Unwrap() Persister })
+		if !ok {
+			return p
+		}
+		p = wrapper.Unwrap()
+	}
+}
+
+// dataDirError turns permission and read-only failures on the data directory into
+// an actionable message; other errors are returned unchanged
+func dataDirError(dir string, err error) error {
+	var reason string
+	switch {
+	case errors.Is(err, fs.ErrPermission):
+		reason = "permission denied (debtq needs to create and write files there)"
+	case errors.Is(err, syscall.EROFS):
+		reason = "the file system is read-only"
+	default:
+		return err
+	}
+	return fmt.Errorf("cannot write data directory %s: %s (%w)\nSet %s to a writable path, e.g. %s=/tmp/debtq/data.json",
+		dir, reason, err, config.DataFileEnv, config.DataFileEnv)
+}
+
+// writeFileAtomic writes data to a temp file next to path and renames it into
+// place, so a crash or full disk mid-write never leaves a truncated data file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
 	if err != nil {
 		return err
 	}
+	tmpName := tmp.Name()
 
-	return os.WriteFile(dataPath, data, 0644)
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return nil
 }
 
-// GetData returns the current data
+// GetData returns the current data. The pointer is shared with Storage, not a
+// copy: treat it as read-only and never hold on to it across mutations.
 func (s *Storage) GetData() *models.Data {
 	return s.data
 }
 
+// SplitAmount splits total across weights using the configured remainder assignment
+func (s *Storage) SplitAmount(total float64, weights []float64) []float64 {
+	return models.SplitAmount(total, weights, s.config.RemainderAssignment)
+}
+
 // GenerateID generates a unique ID
 func GenerateID() string {
 	return uuid.New().String()[:8]
@@ -98,40 +281,508 @@ func GenerateID() string {
 // ==================== Expense Operations ====================
 
 // AddExpense adds a new expense
-func (s *Storage) AddExpense(amount float64, description string, category models.ExpenseCategory, date time.Time) (*models.Expense, error) {
+func (s *Storage) AddExpense(amount float64, description string, category models.ExpenseCategory, date time.Time, currency string, tags []string, notes string) (*models.Expense, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	expense := models.Expense{
 		ID:          GenerateID(),
 		Amount:      amount,
 		Description: description,
 		Category:    category,
 		Date:        date,
+		Currency:    s.entryCurrency(currency),
+		Tags:        models.ParseTags(strings.Join(tags, ",")),
+		Notes:       strings.TrimSpace(notes),
 		CreatedAt:   time.Now(),
 	}
 	s.data.Expenses = append(s.data.Expenses, expense)
-	return &expense, s.Save()
+	return &expense, s.save()
+}
+
+// entryCurrency returns the currency code to store on a new entry: upper-cased,
+// and empty for the base currency
+func (s *Storage) entryCurrency(currency string) string {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if strings.EqualFold(currency, s.config.Currency) {
+		return ""
+	}
+	return currency
+}
+
+// ImportExpensesCSV imports expenses from CSV rows of date (YYYY-MM-DD), category,
+// description, amount, with optional fifth currency and sixth notes columns (as
+// written by ExportExpensesCSV). An optional header row is skipped. Malformed rows are
+// skipped and reported in errs; unknown categories are imported as "other" with
+// a warning in errs. Everything imported is saved once at the end.
+func (s *Storage) ImportExpensesCSV(r io.Reader) (imported int, errs []error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	now := time.Now()
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("row %d: %w", row, err))
+			continue
+		}
+
+		if row == 1 && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "date") {
+			continue
+		}
+		if len(record) < 4 || len(record) > 6 {
+			errs = append(errs, fmt.Errorf("row %d: expected 4 columns (date, category, description, amount) and optional currency and notes, got %d", row, len(record)))
+			continue
+		}
+
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(record[0]))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("row %d: invalid date %q, use YYYY-MM-DD", row, record[0]))
+			continue
+		}
+
+		description := strings.TrimSpace(record[2])
+		if description == "" {
+			errs = append(errs, fmt.Errorf("row %d: description is required", row))
+			continue
+		}
+
+		amount, err := strconv.ParseFloat(strings.TrimSpace(record[3]), 64)
+		if err != nil || amount <= 0 {
+			errs = append(errs, fmt.Errorf("row %d: invalid amount %q", row, record[3]))
+			continue
+		}
+
+		category := models.ExpenseCategory(strings.ToLower(strings.TrimSpace(record[1])))
+		if category == "" {
+			category = models.CategoryOther
+		} else if !models.IsValidCategory(category) {
+			errs = append(errs, fmt.Errorf("row %d: unknown category %q imported as %q", row, record[1], models.CategoryOther))
+			category = models.CategoryOther
+		}
+
+		currency, notes := "", ""
+		if len(record) >= 5 {
+			currency = s.entryCurrency(record[4])
+		}
+		if len(record) == 6 {
+			notes = strings.TrimSpace(record[5])
+		}
+
+		s.data.Expenses = append(s.data.Expenses, models.Expense{
+			ID:          GenerateID(),
+			Amount:      amount,
+			Description: description,
+			Category:    category,
+			Date:        date,
+			Currency:    currency,
+			Notes:       notes,
+			CreatedAt:   now,
+		})
+		imported++
+	}
+
+	if imported > 0 {
+		if err := s.save(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return imported, errs
+}
+
+// RepeatLastExpense re-adds the most recently added expense (same amount,
+// description and category) dated today
+func (s *Storage) RepeatLastExpense() (*models.Expense, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.data.Expenses) == 0 {
+		return nil, fmt.Errorf("no expenses to repeat yet")
+	}
+	last := s.data.Expenses[0]
+	for _, exp := range s.data.Expenses[1:] {
+		if !exp.CreatedAt.Before(last.CreatedAt) {
+			last = exp
+		}
+	}
+
+	expense := models.Expense{
+		ID:          GenerateID(),
+		Amount:      last.Amount,
+		Description: last.Description,
+		Category:    last.Category,
+		Currency:    last.Currency,
+		Tags:        slices.Clone(last.Tags),
+		Date:        time.Now(),
+		CreatedAt:   time.Now(),
+	}
+	s.data.Expenses = append(s.data.Expenses, expense)
+	return &expense, s.save()
 }
 
 // GetExpenses returns all expenses
 func (s *Storage) GetExpenses() []models.Expense {
-	return s.data.Expenses
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]models.Expense(nil), s.data.Expenses...)
+}
+
+// CurrentMonthSpend returns this month's expense total (excluded categories left
+// out) together with the configured monthly budget (0 when disabled)
+func (s *Storage) CurrentMonthSpend() (spent, budget float64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	return s.data.Summary(now.Year(), now.Month(), s.config.ExcludedCategories).MonthlyExpenses, s.config.MonthlyBudget
+}
+
+// GetExpensesBetween returns expenses dated from start through end, both days
+// inclusive. An inverted range is swapped.
+func (s *Storage) GetExpensesBetween(start, end time.Time) []models.Expense {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if end.Before(start) {
+		start, end = end, start
+	}
+	from := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	until := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location()).AddDate(0, 0, 1)
+
+	var matches []models.Expense
+	for _, exp := range s.data.Expenses {
+		if !exp.Date.Before(from) && exp.Date.Before(until) {
+			matches = append(matches, exp)
+		}
+	}
+	return matches
+}
+
+// FilterExpenses returns expenses whose description, category or one of whose
+// tags contains query (case-insensitive). An empty query matches everything.
+func (s *Storage) FilterExpenses(query string) []models.Expense {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	var matches []models.Expense
+	for _, exp := range s.data.Expenses {
+		if strings.Contains(strings.ToLower(exp.Description), query) ||
+			strings.Contains(strings.ToLower(string(exp.Category)), query) ||
+			slices.ContainsFunc(exp.Tags, func(tag string) bool { return strings.Contains(tag, query) }) {
+			matches = append(matches, exp)
+		}
+	}
+	return matches
+}
+
+// GetExpensesByTag returns the expenses carrying tag, given with or without the #
+func (s *Storage) GetExpensesByTag(tag string) []models.Expense {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matches []models.Expense
+	for _, exp := range s.data.Expenses {
+		if exp.HasTag(tag) {
+			matches = append(matches, exp)
+		}
+	}
+	return matches
+}
+
+// FindDuplicateExpenses groups expenses that look like one entry made more
+// than once: identical amount, currency, description (ignoring case) and
+// category, each entered within window of the one before. Every group holds at
+// least two expenses, in the order they were entered.
+func (s *Storage) FindDuplicateExpenses(window time.Duration) [][]models.Expense {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type entry struct {
+		amount      float64
+		currency    string
+		description string
+		category    models.ExpenseCategory
+	}
+	byEntry := make(map[entry][]models.Expense)
+	var order []entry
+	for _, exp := range s.data.Expenses {
+		key := entry{exp.Amount, exp.Currency, strings.ToLower(strings.TrimSpace(exp.Description)), exp.Category}
+		if _, seen := byEntry[key]; !seen {
+			order = append(order, key)
+		}
+		byEntry[key] = append(byEntry[key], exp)
+	}
+
+	var groups [][]models.Expense
+	for _, key := range order {
+		same := byEntry[key]
+		sort.SliceStable(same, func(i, j int) bool { return same[i].CreatedAt.Before(same[j].CreatedAt) })
+		start := 0
+		for i := 1; i <= len(same); i++ {
+			if i < len(same) && same[i].CreatedAt.Sub(same[i-1].CreatedAt) <= window {
+				continue
+			}
+			if i-start > 1 {
+				groups = append(groups, same[start:i])
+			}
+			start = i
+		}
+	}
+	return groups
 }
 
 // DeleteExpense deletes an expense by ID
 func (s *Storage) DeleteExpense(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	for i, exp := range s.data.Expenses {
 		if exp.ID == id {
 			s.data.Expenses = append(s.data.Expenses[:i], s.data.Expenses[i+1:]...)
-			return s.Save()
+			return s.save()
+		}
+	}
+	return nil
+}
+
+// ==================== Recurring Expense Operations ====================
+
+// AddRecurringExpense adds a monthly recurring expense. The first expense is
+// created on the next occurrence of dayOfMonth, never retroactively.
+func (s *Storage) AddRecurringExpense(amount float64, description string, category models.ExpenseCategory, dayOfMonth int) (*models.RecurringExpense, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if dayOfMonth < 1 || dayOfMonth > 31 {
+		return nil, fmt.Errorf("day of month must be between 1 and 31")
+	}
+
+	now := time.Now()
+	recurring := models.RecurringExpense{
+		ID:          GenerateID(),
+		Amount:      amount,
+		Description: description,
+		Category:    category,
+		DayOfMonth:  dayOfMonth,
+		Active:      true,
+		CreatedAt:   now,
+	}
+	// This month's occurrence has already passed; start next month
+	if !now.Before(recurring.DueDate(now)) {
+		recurring.LastPeriod = now.Format("2006-01")
+	}
+	s.data.RecurringExpenses = append(s.data.RecurringExpenses, recurring)
+	return &recurring, s.save()
+}
+
+// GetRecurringExpenses returns all recurring expenses
+func (s *Storage) GetRecurringExpenses() []models.RecurringExpense {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]models.RecurringExpense(nil), s.data.RecurringExpenses...)
+}
+
+// SetRecurringExpenseActive pauses or resumes a recurring expense
+func (s *Storage) SetRecurringExpenseActive(id string, active bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, r := range s.data.RecurringExpenses {
+		if r.ID == id {
+			s.data.RecurringExpenses[i].Active = active
+			return s.save()
 		}
 	}
 	return nil
 }
 
+// SetRecurringExpenseEndDate sets the last day a recurring expense creates
+// expenses for, e.g. when a subscription is cancelled; nil removes the end date
+func (s *Storage) SetRecurringExpenseEndDate(id string, end *time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, r := range s.data.RecurringExpenses {
+		if r.ID == id {
+			s.data.RecurringExpenses[i].EndDate = end
+			return s.save()
+		}
+	}
+	return nil
+}
+
+// DeleteRecurringExpense deletes a recurring expense; expenses it already created are kept
+func (s *Storage) DeleteRecurringExpense(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, r := range s.data.RecurringExpenses {
+		if r.ID == id {
+			s.data.RecurringExpenses = append(s.data.RecurringExpenses[:i], s.data.RecurringExpenses[i+1:]...)
+			return s.save()
+		}
+	}
+	return nil
+}
+
+// materializeRecurringExpenses creates this month's expense for every active
+// recurring expense whose day has come, that hasn't ended and that hasn't
+// been created yet; callers must hold mu
+func (s *Storage) materializeRecurringExpenses(now time.Time) error {
+	period := now.Format("2006-01")
+	created := 0
+	for i, r := range s.data.RecurringExpenses {
+		if !r.Active || r.LastPeriod >= period || r.HasEnded(now) {
+			continue
+		}
+		due := r.DueDate(now)
+		if now.Before(due) {
+			continue
+		}
+		s.data.Expenses = append(s.data.Expenses, models.Expense{
+			ID:          GenerateID(),
+			Amount:      r.Amount,
+			Description: r.Description,
+			Category:    r.Category,
+			Date:        due,
+			RecurringID: r.ID,
+			CreatedAt:   now,
+		})
+		s.data.RecurringExpenses[i].LastPeriod = period
+		created++
+	}
+
+	if created > 0 {
+		return s.save()
+	}
+	return nil
+}
+
 // ==================== Debt Transaction Operations ====================
 
 // AddDebtTransaction adds a new debt transaction
 func (s *Storage) AddDebtTransaction(txType models.TransactionType, personName string, amount float64, description string, date time.Time, dueDate *time.Time) (*models.DebtTransaction, error) {
-	tx := models.DebtTransaction{
+	return s.AddDebtTransactionWithInterest(txType, personName, amount, description, date, dueDate, 0, models.InterestNone, 0, "")
+}
+
+// AddDebtTransactionWithInterest adds a debt transaction that accrues interest
+// at an annual rate (in percent) from its date, or from graceDays after it
+func (s *Storage) AddDebtTransactionWithInterest(txType models.TransactionType, personName string, amount float64, description string, date time.Time, dueDate *time.Time, rate float64, interestType models.InterestType, graceDays int, currency string) (*models.DebtTransaction, error) {
+	if rate <= 0 {
+		rate = 0
+		interestType = ""
+	}
+	if rate == 0 || graceDays < 0 {
+		graceDays = 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx := s.newDebtTransaction(txType, personName, amount, description, date, dueDate, currency)
+	tx.InterestRate = rate
+	tx.InterestType = interestType
+	tx.InterestGraceDays = graceDays
+	s.data.DebtTransactions = append(s.data.DebtTransactions, tx)
+	return &tx, s.save()
+}
+
+// AddInstallmentDebt adds a debt repaid in equal monthly installments (an EMI),
+// e.g. 12000 over 12 installments of 1000. Each installment is rounded to the
+// paisa; the last one pays whatever remains.
+func (s *Storage) AddInstallmentDebt(txType models.TransactionType, personName string, amount float64, installments int, description string, date time.Time, dueDate *time.Time, currency string) (*models.DebtTransaction, error) {
+	if installments < 2 {
+		return nil, fmt.Errorf("an installment debt needs at least 2 installments")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx := s.newDebtTransaction(txType, personName, amount, description, date, dueDate, currency)
+	tx.TotalInstallments = installments
+	tx.InstallmentAmount = math.Round(amount/float64(installments)*100) / 100
+	s.data.DebtTransactions = append(s.data.DebtTransactions, tx)
+	return &tx, s.save()
+}
+
+// SplitSelf stands for your own share of a split bill, which is not recorded as a debt
+const SplitSelf = "ME"
+
+// AddSplitDebt records a bill you paid as Lent transactions, split equally among
+// people. Listing SplitSelf ("me") counts your own share without recording it.
+func (s *Storage) AddSplitDebt(total float64, description string, date time.Time, people []string) ([]models.DebtTransaction, error) {
+	return s.AddWeightedSplitDebt(total, description, date, people, nil)
+}
+
+// AddWeightedSplitDebt is AddSplitDebt with shares proportional to weights (one
+// per person; nil splits equally). Shares are rounded with SplitAmount, so they
+// always sum to total.
+func (s *Storage) AddWeightedSplitDebt(total float64, description string, date time.Time, people []string, weights []float64) ([]models.DebtTransaction, error) {
+	if total <= 0 {
+		return nil, fmt.Errorf("total must be positive")
+	}
+	if weights == nil {
+		weights = make([]float64, len(people))
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+	if len(weights) != len(people) {
+		return nil, fmt.Errorf("got %d weights for %d people", len(weights), len(people))
+	}
+
+	seen := make(map[string]bool)
+	others := 0
+	for i, person := range people {
+		name := NormalizeName(person)
+		if name == "" {
+			return nil, fmt.Errorf("person %d has no name", i+1)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%s is listed twice", name)
+		}
+		seen[name] = true
+		if weights[i] <= 0 {
+			return nil, fmt.Errorf("%s needs a positive weight", name)
+		}
+		if name != SplitSelf {
+			others++
+		}
+	}
+	if others == 0 {
+		return nil, fmt.Errorf("name at least one person besides yourself")
+	}
+
+	shares := s.SplitAmount(total, weights)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var added []models.DebtTransaction
+	for i, person := range people {
+		if NormalizeName(person) == SplitSelf {
+			continue
+		}
+		tx := s.newDebtTransaction(models.Lent, person, shares[i], description, date, nil, "")
+		s.data.DebtTransactions = append(s.data.DebtTransactions, tx)
+		added = append(added, tx)
+	}
+	return added, s.save()
+}
+
+// newDebtTransaction builds an unsettled transaction; callers must hold mu
+func (s *Storage) newDebtTransaction(txType models.TransactionType, personName string, amount float64, description string, date time.Time, dueDate *time.Time, currency string) models.DebtTransaction {
+	return models.DebtTransaction{
 		ID:             GenerateID(),
 		Type:           txType,
 		PersonName:     NormalizeName(personName),
@@ -141,289 +792,648 @@ func (s *Storage) AddDebtTransaction(txType models.TransactionType, personName s
 		Date:           date,
 		DueDate:        dueDate,
 		IsSettled:      false,
+		Currency:       s.entryCurrency(currency),
 		CreatedAt:      time.Now(),
 	}
-	s.data.DebtTransactions = append(s.data.DebtTransactions, tx)
-	return &tx, s.Save()
 }
 
-// SettleDebtTransaction marks a transaction as settled
-func (s *Storage) SettleDebtTransaction(id string) error {
+// EditDebtTransaction corrects the amount, description and date of a debt transaction.
+// Type, person and settlement state are preserved; amount is the corrected original
+// amount, and any part already settled stays settled.
+func (s *Storage) EditDebtTransaction(id string, amount float64, description string, date time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	for i, tx := range s.data.DebtTransactions {
 		if tx.ID == id {
-			now := time.Now()
-			s.data.DebtTransactions[i].IsSettled = true
-			s.data.DebtTransactions[i].SettledDate = &now
-			return s.Save()
+			original := tx.OriginalAmount
+			if original == 0 {
+				original = tx.Amount
+			}
+
+			if !tx.IsSettled {
+				settledSoFar := original - tx.Amount
+				if amount < settledSoFar {
+					return fmt.Errorf("amount is less than the %.2f already settled", settledSoFar)
+				}
+				s.data.DebtTransactions[i].Amount = amount - settledSoFar
+			}
+			s.data.DebtTransactions[i].OriginalAmount = amount
+			if tx.IsInstallment() {
+				s.data.DebtTransactions[i].InstallmentAmount = math.Round(amount/float64(tx.TotalInstallments)*100) / 100
+			}
+			s.data.DebtTransactions[i].Description = description
+			s.data.DebtTransactions[i].Date = date
+			return s.save()
 		}
 	}
-	return nil
+	return fmt.Errorf("transaction %s not found", id)
 }
 
-// PartialSettleDebt settles a specific amount for a person
-// It settles transactions in order until the amount is covered
-// Returns the actual amount settled
-func (s *Storage) PartialSettleDebt(personName string, amount float64, settleType models.TransactionType) (float64, error) {
-	var settled float64
-	now := time.Now()
-	normalizedName := NormalizeName(personName)
+// DeleteDebtTransaction deletes a debt transaction and the settlements recorded against it
+func (s *Storage) DeleteDebtTransaction(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
 	for i, tx := range s.data.DebtTransactions {
-		if tx.PersonName == normalizedName && tx.Type == settleType && !tx.IsSettled {
-			if settled >= amount {
-				break
-			}
+		if tx.ID == id {
+			s.data.DebtTransactions = append(s.data.DebtTransactions[:i], s.data.DebtTransactions[i+1:]...)
 
-			remaining := amount - settled
-			if tx.Amount <= remaining {
-				// Fully settle this transaction
-				s.data.DebtTransactions[i].IsSettled = true
-				s.data.DebtTransactions[i].SettledDate = &now
-				settled += tx.Amount
-			} else {
-				// Partially settle - reduce amount
-				s.data.DebtTransactions[i].Amount -= remaining
-				settled += remaining
+			settlements := s.data.Settlements[:0]
+			for _, st := range s.data.Settlements {
+				if st.TransactionID != id {
+					settlements = append(settlements, st)
+				}
 			}
+			s.data.Settlements = settlements
+			return s.save()
 		}
 	}
+	return nil
+}
 
-	if settled > 0 {
-		return settled, s.Save()
+// SettleAmountForPerson records a payment of amount (in the base currency)
+// against a person's outstanding net balance, with note on every settlement it
+// records. An amount of 0, or at least the net balance, settles everything with
+// them, offsetting what you lent against what you borrowed. A smaller amount
+// pays down whichever side is owed, oldest transaction first. It returns the
+// net amount that changed hands.
+func (s *Storage) SettleAmountForPerson(personName string, amount float64, note string) (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	normalizedName := NormalizeName(personName)
+	var open []int
+	for i, tx := range s.data.DebtTransactions {
+		if tx.PersonName == normalizedName && !tx.IsSettled {
+			open = append(open, i)
+		}
+	}
+	if len(open) == 0 {
+		return 0, fmt.Errorf("nothing to settle with %s", normalizedName)
 	}
-	return 0, nil
+	sort.SliceStable(open, func(a, b int) bool {
+		return s.data.DebtTransactions[open[a]].Date.Before(s.data.DebtTransactions[open[b]].Date)
+	})
+
+	// One batch, so undoing reverses the whole payment
+	batchID := GenerateID()
+
+	netBalance := s.data.PersonNetBalance(normalizedName)
+	if amount <= 0 || amount >= math.Abs(netBalance) {
+		for _, i := range open {
+			s.settleTransaction(i, 0, note, batchID)
+		}
+		return math.Abs(netBalance), s.save()
+	}
+
+	owed := models.Lent
+	if netBalance < 0 {
+		owed = models.Borrowed
+	}
+	remaining := amount
+	for _, i := range open {
+		tx := s.data.DebtTransactions[i]
+		if tx.Type != owed || remaining <= 0 {
+			continue
+		}
+		base := s.data.Rates.ToBase(tx.Amount, tx.Currency)
+		// The part is paid in the transaction's currency
+		if base <= remaining {
+			s.settleTransaction(i, 0, note, batchID)
+			remaining -= base
+		} else {
+			s.settleTransaction(i, tx.Amount*remaining/base, note, batchID)
+			remaining = 0
+		}
+	}
+	return amount - remaining, s.save()
 }
 
-// SettleAmountForPerson settles a specific amount for a person (handles both lent and borrowed)
-// It calculates net balance and settles appropriately
-func (s *Storage) SettleAmountForPerson(personName string, amount float64) (float64, error) {
-	normalizedName := NormalizeName(personName)
-	// Calculate current balances
-	var totalLent, totalBorrowed float64
+// RenamePerson rewrites the person name on every debt transaction and settlement
+// recorded under oldName. Renaming to an existing name merges the two people.
+func (s *Storage) RenamePerson(oldName, newName string) (updated int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	from, to := NormalizeName(oldName), NormalizeName(newName)
+	if to == "" {
+		return 0, fmt.Errorf("new name is required")
+	}
+	if from == to {
+		return 0, nil
+	}
+
+	for i, tx := range s.data.DebtTransactions {
+		if tx.PersonName == from {
+			s.data.DebtTransactions[i].PersonName = to
+			updated++
+		}
+	}
+	for i, st := range s.data.Settlements {
+		if st.PersonName == from {
+			s.data.Settlements[i].PersonName = to
+		}
+	}
+
+	if updated == 0 {
+		return 0, nil
+	}
+	return updated, s.save()
+}
+
+// PersonSummary aggregates everything recorded with one person
+type PersonSummary struct {
+	Name           string
+	TotalLent      float64 // Lifetime, including settled transactions
+	TotalBorrowed  float64 // Lifetime, including settled transactions
+	NetBalance     float64 // Outstanding (unsettled only); positive means they owe you
+	LifetimeNet    float64 // TotalLent - TotalBorrowed, settled included
+	UnsettledCount int
+	LastActivity   time.Time // Latest transaction, settlement or payment date
+}
+
+// GetPeople returns a summary of every person with a debt transaction, including
+// fully settled ones, sorted by absolute net balance (largest first) then name
+func (s *Storage) GetPeople() []PersonSummary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.people()
+}
+
+// people builds the GetPeople summaries; callers must hold mu
+func (s *Storage) people() []PersonSummary {
+	byName := make(map[string]*PersonSummary)
+	var people []*PersonSummary
+	person := func(name string) *PersonSummary {
+		p, ok := byName[name]
+		if !ok {
+			p = &PersonSummary{Name: name}
+			byName[name] = p
+			people = append(people, p)
+		}
+		return p
+	}
+	touch := func(p *PersonSummary, t time.Time) {
+		if t.After(p.LastActivity) {
+			p.LastActivity = t
+		}
+	}
+
 	for _, tx := range s.data.DebtTransactions {
-		if tx.PersonName == normalizedName && !tx.IsSettled {
-			if tx.Type == models.Lent {
-				totalLent += tx.Amount
-			} else {
-				totalBorrowed += tx.Amount
-			}
+		p := person(tx.PersonName)
+		original := s.data.Rates.ToBase(tx.Principal(), tx.Currency)
+		if tx.Type == models.Lent {
+			p.TotalLent += original
+		} else {
+			p.TotalBorrowed += original
+		}
+		if !tx.IsSettled {
+			p.UnsettledCount++
+		}
+		touch(p, tx.Date)
+		if tx.SettledDate != nil {
+			touch(p, *tx.SettledDate)
+		}
+	}
+	for _, st := range s.data.Settlements {
+		if p, ok := byName[st.PersonName]; ok {
+			touch(p, st.Date)
 		}
 	}
 
-	netBalance := totalLent - totalBorrowed
-	now := time.Now()
-	var settled float64
+	summaries := make([]PersonSummary, len(people))
+	for i, p := range people {
+		p.NetBalance = s.data.PersonNetBalance(p.Name)
+		p.LifetimeNet = p.TotalLent - p.TotalBorrowed
+		summaries[i] = *p
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		ai, aj := math.Abs(summaries[i].NetBalance), math.Abs(summaries[j].NetBalance)
+		if ai != aj {
+			return ai > aj
+		}
+		return summaries[i].Name < summaries[j].Name
+	})
+	return summaries
+}
+
+// GetVisiblePeople returns the People list: GetPeople leaving out anyone fully
+// settled whose last activity is more than HideCompletedAfterDays ago
+func (s *Storage) GetVisiblePeople() []PersonSummary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	if netBalance > 0 {
-		// They owe us - settle from lent transactions
-		remainingToSettle := amount
-		if remainingToSettle == 0 || remainingToSettle > netBalance {
-			remainingToSettle = netBalance
+	var visible []PersonSummary
+	for _, p := range s.people() {
+		if p.UnsettledCount == 0 && s.completedLongAgo(p.LastActivity) {
+			continue
 		}
-		for i, tx := range s.data.DebtTransactions {
-			if tx.PersonName == normalizedName && tx.Type == models.Lent && !tx.IsSettled && remainingToSettle > 0 {
-				if tx.Amount <= remainingToSettle {
-					s.data.DebtTransactions[i].IsSettled = true
-					s.data.DebtTransactions[i].SettledDate = &now
-					settled += tx.Amount
-					remainingToSettle -= tx.Amount
-				} else {
-					s.data.DebtTransactions[i].Amount -= remainingToSettle
-					settled += remainingToSettle
-					remainingToSettle = 0
-				}
-			}
+		visible = append(visible, p)
+	}
+	return visible
+}
+
+// FormatAllBalancesTable returns a plain-text table of everyone with an
+// outstanding balance, largest first, suitable for pasting into a chat
+func (s *Storage) FormatAllBalancesTable() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type row struct{ name, direction, amount string }
+	rows := []row{{"Person", "Status", "Amount"}}
+	for _, p := range s.people() {
+		switch {
+		case p.NetBalance > 0:
+			rows = append(rows, row{p.Name, "owes me", fmt.Sprintf("%s %.2f", s.config.Currency, p.NetBalance)})
+		case p.NetBalance < 0:
+			rows = append(rows, row{p.Name, "I owe", fmt.Sprintf("%s %.2f", s.config.Currency, -p.NetBalance)})
 		}
-		// Also settle borrowed transactions up to the same amount (offsetting)
-		offsetSettle := netBalance
-		if offsetSettle == 0 {
-			offsetSettle = netBalance
+	}
+	if len(rows) == 1 {
+		return "All settled up.\n"
+	}
+
+	nameWidth, directionWidth, amountWidth := 0, 0, 0
+	for _, r := range rows {
+		nameWidth = max(nameWidth, utf8.RuneCountInString(r.name))
+		directionWidth = max(directionWidth, utf8.RuneCountInString(r.direction))
+		amountWidth = max(amountWidth, utf8.RuneCountInString(r.amount))
+	}
+
+	var b strings.Builder
+	for i, r := range rows {
+		fmt.Fprintf(&b, "%s  %s  %s\n",
+			padRight(r.name, nameWidth),
+			padRight(r.direction, directionWidth),
+			padLeft(r.amount, amountWidth),
+		)
+		if i == 0 {
+			fmt.Fprintf(&b, "%s  %s  %s\n",
+				strings.Repeat("-", nameWidth),
+				strings.Repeat("-", directionWidth),
+				strings.Repeat("-", amountWidth),
+			)
 		}
-		for i, tx := range s.data.DebtTransactions {
-			if tx.PersonName == normalizedName && tx.Type == models.Borrowed && !tx.IsSettled && offsetSettle > 0 {
-				if tx.Amount <= offsetSettle {
-					s.data.DebtTransactions[i].IsSettled = true
-					s.data.DebtTransactions[i].SettledDate = &now
-					offsetSettle -= tx.Amount
-				} else {
-					s.data.DebtTransactions[i].Amount -= offsetSettle
-					offsetSettle = 0
-				}
-			}
+	}
+	return b.String()
+}
+
+// padRight pads s with spaces to width runes
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", width-utf8.RuneCountInString(s))
+}
+
+// padLeft right-aligns s within width runes
+func padLeft(s string, width int) string {
+	return strings.Repeat(" ", width-utf8.RuneCountInString(s)) + s
+}
+
+// GetPersonNetBalance returns the outstanding (unsettled) net balance for a
+// person: what is still owed today. This is the balance shown as "Outstanding".
+func (s *Storage) GetPersonNetBalance(personName string) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.data.PersonNetBalance(NormalizeName(personName))
+}
+
+// GetPersonLifetimeNet returns everything ever lent to a person minus everything
+// borrowed from them, settled or not. This is the balance shown as "Lifetime net".
+func (s *Storage) GetPersonLifetimeNet(personName string) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.data.PersonLifetimeNet(NormalizeName(personName))
+}
+
+// GetDebtTransactions returns all debt transactions
+func (s *Storage) GetDebtTransactions() []models.DebtTransaction {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]models.DebtTransaction(nil), s.data.DebtTransactions...)
+}
+
+// GetUnsettledDebts returns unsettled debt transactions
+func (s *Storage) GetUnsettledDebts() []models.DebtTransaction {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var unsettled []models.DebtTransaction
+	for _, tx := range s.data.DebtTransactions {
+		if !tx.IsSettled {
+			unsettled = append(unsettled, tx)
 		}
-	} else if netBalance < 0 {
-		// We owe them - settle from borrowed transactions
-		remainingToSettle := amount
-		if remainingToSettle == 0 || remainingToSettle > -netBalance {
-			remainingToSettle = -netBalance
+	}
+	return unsettled
+}
+
+// GetSettledDebts returns all settled debt transactions
+func (s *Storage) GetSettledDebts() []models.DebtTransaction {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var settled []models.DebtTransaction
+	for _, tx := range s.data.DebtTransactions {
+		if tx.IsSettled {
+			settled = append(settled, tx)
 		}
-		for i, tx := range s.data.DebtTransactions {
-			if tx.PersonName == normalizedName && tx.Type == models.Borrowed && !tx.IsSettled && remainingToSettle > 0 {
-				if tx.Amount <= remainingToSettle {
-					s.data.DebtTransactions[i].IsSettled = true
-					s.data.DebtTransactions[i].SettledDate = &now
-					settled += tx.Amount
-					remainingToSettle -= tx.Amount
-				} else {
-					s.data.DebtTransactions[i].Amount -= remainingToSettle
-					settled += remainingToSettle
-					remainingToSettle = 0
-				}
-			}
+	}
+	return settled
+}
+
+// GetUnsettledDebtsForPerson returns unsettled debts for a specific person
+func (s *Storage) GetUnsettledDebtsForPerson(personName string) []models.DebtTransaction {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	normalizedName := NormalizeName(personName)
+	var unsettled []models.DebtTransaction
+	for _, tx := range s.data.DebtTransactions {
+		if tx.PersonName == normalizedName && !tx.IsSettled {
+			unsettled = append(unsettled, tx)
 		}
-		// Also settle lent transactions up to the same amount (offsetting)
-		offsetSettle := netBalance
-		if offsetSettle == 0 {
-			offsetSettle = -netBalance
+	}
+	return unsettled
+}
+
+// SettleTransactionWithNote settles a specific transaction (full or partial) with a note
+// If amount is 0 or >= remaining amount, it fully settles. Otherwise partial settlement.
+func (s *Storage) SettleTransactionWithNote(id string, amount float64, note string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, tx := range s.data.DebtTransactions {
+		if tx.ID == id {
+			s.settleTransaction(i, amount, note, GenerateID())
+			return s.save()
 		}
-		for i, tx := range s.data.DebtTransactions {
-			if tx.PersonName == normalizedName && tx.Type == models.Lent && !tx.IsSettled && offsetSettle > 0 {
-				if tx.Amount <= offsetSettle {
-					s.data.DebtTransactions[i].IsSettled = true
-					s.data.DebtTransactions[i].SettledDate = &now
-					offsetSettle -= tx.Amount
-				} else {
-					s.data.DebtTransactions[i].Amount -= offsetSettle
-					offsetSettle = 0
-				}
-			}
+	}
+	return nil
+}
+
+// RecordInstallmentPayment pays the next installment of an installment debt,
+// settling it once the last installment is paid. It returns the amount paid.
+func (s *Storage) RecordInstallmentPayment(id string, note string) (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, tx := range s.data.DebtTransactions {
+		if tx.ID != id {
+			continue
+		}
+		if !tx.IsInstallment() {
+			return 0, fmt.Errorf("transaction is not paid in installments")
+		}
+		if tx.IsSettled {
+			return 0, fmt.Errorf("all %d installments are already paid", tx.TotalInstallments)
+		}
+
+		// The last installment clears whatever rounding left behind
+		amount := tx.InstallmentAmount
+		if tx.InstallmentsPaid()+1 >= tx.TotalInstallments {
+			amount = 0
 		}
-	} else if netBalance == 0 {
-		// Net is 0 but there might be unsettled transactions - settle all
-		var hasUnsettled bool
+		paid := s.settleTransaction(i, amount, note, GenerateID())
+		return paid, s.save()
+	}
+	return 0, fmt.Errorf("transaction %s not found", id)
+}
+
+// settleTransaction pays amount off DebtTransactions[i] and records the
+// settlement under batchID, settling it in full when amount is 0 or covers
+// what remains. It returns the amount settled; callers must hold mu and save.
+func (s *Storage) settleTransaction(i int, amount float64, note string, batchID string) float64 {
+	tx := s.data.DebtTransactions[i]
+	now := time.Now()
+
+	settleAmount := amount
+	if settleAmount <= 0 || settleAmount >= tx.Amount {
+		// Full settlement
+		settleAmount = tx.Amount
+		s.data.DebtTransactions[i].Amount = 0
+		s.data.DebtTransactions[i].IsSettled = true
+		s.data.DebtTransactions[i].SettledDate = &now
+		s.data.DebtTransactions[i].SettlementNote = note
+	} else {
+		// Partial settlement - reduce remaining amount
+		s.data.DebtTransactions[i].Amount -= settleAmount
+	}
+
+	s.data.Settlements = append(s.data.Settlements, models.Settlement{
+		ID:            GenerateID(),
+		TransactionID: tx.ID,
+		PersonName:    tx.PersonName,
+		Type:          tx.Type,
+		Amount:        settleAmount,
+		Note:          note,
+		BatchID:       batchID,
+		Date:          now,
+		CreatedAt:     now,
+	})
+	return settleAmount
+}
+
+// LastSettlementBatch returns the settlements UndoLastSettlement would reverse:
+// everything recorded by the most recent settle action
+func (s *Storage) LastSettlementBatch() []models.Settlement {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.data.Settlements[s.lastBatchStart():])
+}
+
+// lastBatchStart returns the index of the first settlement in the most recent
+// batch. Settlements recorded before batches existed are a batch of their own.
+// Callers must hold mu.
+func (s *Storage) lastBatchStart() int {
+	n := len(s.data.Settlements)
+	if n == 0 {
+		return 0
+	}
+	batchID := s.data.Settlements[n-1].BatchID
+	start := n - 1
+	for batchID != "" && start > 0 && s.data.Settlements[start-1].BatchID == batchID {
+		start--
+	}
+	return start
+}
+
+// UndoLastSettlement reverses the most recent settle action, which may have
+// recorded several settlements (e.g. settling everything with a person): each
+// amount is added back to its transaction, which becomes unsettled again
+func (s *Storage) UndoLastSettlement() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.data.Settlements)
+	if n == 0 {
+		return fmt.Errorf("no settlement to undo")
+	}
+	start := s.lastBatchStart()
+
+	for k := n - 1; k >= start; k-- {
+		st := s.data.Settlements[k]
 		for i, tx := range s.data.DebtTransactions {
-			if tx.PersonName == normalizedName && !tx.IsSettled {
-				s.data.DebtTransactions[i].IsSettled = true
-				s.data.DebtTransactions[i].SettledDate = &now
-				settled += tx.Amount
-				hasUnsettled = true
+			if tx.ID == st.TransactionID {
+				s.data.DebtTransactions[i].Amount += st.Amount
+				s.data.DebtTransactions[i].IsSettled = false
+				s.data.DebtTransactions[i].SettledDate = nil
+				s.data.DebtTransactions[i].SettlementNote = ""
+				break
 			}
 		}
-		if !hasUnsettled {
-			return 0, nil
-		}
 	}
+	s.data.Settlements = s.data.Settlements[:start]
 
-	if settled > 0 {
-		return settled, s.Save()
-	}
-	return 0, nil
+	return s.save()
 }
 
-// GetPersonNetBalance returns the net balance for a person
-func (s *Storage) GetPersonNetBalance(personName string) float64 {
+// GetSettlementsForPerson returns all settlements for a specific person
+func (s *Storage) GetSettlementsForPerson(personName string) []models.Settlement {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	normalizedName := NormalizeName(personName)
-	var totalLent, totalBorrowed float64
-	for _, tx := range s.data.DebtTransactions {
-		if tx.PersonName == normalizedName && !tx.IsSettled {
-			if tx.Type == models.Lent {
-				totalLent += tx.Amount
-			} else {
-				totalBorrowed += tx.Amount
-			}
+	var settlements []models.Settlement
+	for _, st := range s.data.Settlements {
+		if st.PersonName == normalizedName {
+			settlements = append(settlements, st)
 		}
 	}
-	return totalLent - totalBorrowed
+	return settlements
 }
 
-// GetDebtTransactions returns all debt transactions
-func (s *Storage) GetDebtTransactions() []models.DebtTransaction {
-	return s.data.DebtTransactions
+// GetAllSettlements returns all settlements
+func (s *Storage) GetAllSettlements() []models.Settlement {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]models.Settlement(nil), s.data.Settlements...)
 }
 
-// GetUnsettledDebts returns unsettled debt transactions
-func (s *Storage) GetUnsettledDebts() []models.DebtTransaction {
-	var unsettled []models.DebtTransaction
+// GetOverdueDebts returns unsettled debts whose due date has passed
+func (s *Storage) GetOverdueDebts() []models.DebtTransaction {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	var overdue []models.DebtTransaction
 	for _, tx := range s.data.DebtTransactions {
-		if !tx.IsSettled {
-			unsettled = append(unsettled, tx)
+		if tx.IsOverdue(now) {
+			overdue = append(overdue, tx)
 		}
 	}
-	return unsettled
+	return overdue
 }
 
-// GetSettledDebts returns all settled debt transactions
-func (s *Storage) GetSettledDebts() []models.DebtTransaction {
-	var settled []models.DebtTransaction
+// GetUpcomingAndOverdue returns unsettled debts due within the given window from
+// now and those already overdue, each sorted by due date (soonest first)
+func (s *Storage) GetUpcomingAndOverdue(within time.Duration) (upcoming, overdue []models.DebtTransaction) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	horizon := now.Add(within)
 	for _, tx := range s.data.DebtTransactions {
-		if tx.IsSettled {
-			settled = append(settled, tx)
+		switch {
+		case tx.IsSettled || tx.DueDate == nil:
+		case tx.IsOverdue(now):
+			overdue = append(overdue, tx)
+		case !tx.DueDate.After(horizon):
+			upcoming = append(upcoming, tx)
 		}
 	}
-	return settled
+
+	byDueDate := func(txs []models.DebtTransaction) func(i, j int) bool {
+		return func(i, j int) bool { return txs[i].DueDate.Before(*txs[j].DueDate) }
+	}
+	sort.SliceStable(upcoming, byDueDate(upcoming))
+	sort.SliceStable(overdue, byDueDate(overdue))
+	return upcoming, overdue
 }
 
-// GetUnsettledDebtsForPerson returns unsettled debts for a specific person
-func (s *Storage) GetUnsettledDebtsForPerson(personName string) []models.DebtTransaction {
-	normalizedName := NormalizeName(personName)
-	var unsettled []models.DebtTransaction
+// DebtsMissingDueDate returns unsettled debts without a due date that are older
+// than the configured reminder window
+func (s *Storage) DebtsMissingDueDate() []models.DebtTransaction {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	days := s.config.DueDateReminderDays
+	if days <= 0 {
+		days = config.DefaultDueDateReminderDays
+	}
+	cutoff := time.Now().AddDate(0, 0, -days)
+
+	var missing []models.DebtTransaction
 	for _, tx := range s.data.DebtTransactions {
-		if tx.PersonName == normalizedName && !tx.IsSettled {
-			unsettled = append(unsettled, tx)
+		if !tx.IsSettled && tx.DueDate == nil && tx.Date.Before(cutoff) {
+			missing = append(missing, tx)
 		}
 	}
-	return unsettled
+	return missing
 }
 
-// SettleTransactionWithNote settles a specific transaction (full or partial) with a note
-// If amount is 0 or >= remaining amount, it fully settles. Otherwise partial settlement.
-func (s *Storage) SettleTransactionWithNote(id string, amount float64, note string) error {
+// SetDebtDueDate sets the due date of a debt transaction (nil clears it)
+func (s *Storage) SetDebtDueDate(id string, dueDate *time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	for i, tx := range s.data.DebtTransactions {
 		if tx.ID == id {
-			now := time.Now()
-
-			// Determine settlement amount
-			settleAmount := amount
-			if settleAmount <= 0 || settleAmount >= tx.Amount {
-				// Full settlement
-				settleAmount = tx.Amount
-				s.data.DebtTransactions[i].Amount = 0
-				s.data.DebtTransactions[i].IsSettled = true
-				s.data.DebtTransactions[i].SettledDate = &now
-				s.data.DebtTransactions[i].SettlementNote = note
-			} else {
-				// Partial settlement - reduce remaining amount
-				s.data.DebtTransactions[i].Amount -= settleAmount
-			}
-
-			// Create settlement record
-			settlement := models.Settlement{
-				ID:            GenerateID(),
-				TransactionID: tx.ID,
-				PersonName:    tx.PersonName,
-				Type:          tx.Type,
-				Amount:        settleAmount,
-				Note:          note,
-				Date:          now,
-				CreatedAt:     now,
-			}
-			s.data.Settlements = append(s.data.Settlements, settlement)
-
-			return s.Save()
+			s.data.DebtTransactions[i].DueDate = dueDate
+			return s.save()
 		}
 	}
 	return nil
 }
 
-// GetSettlementsForPerson returns all settlements for a specific person
-func (s *Storage) GetSettlementsForPerson(personName string) []models.Settlement {
-	normalizedName := NormalizeName(personName)
-	var settlements []models.Settlement
-	for _, st := range s.data.Settlements {
-		if st.PersonName == normalizedName {
-			settlements = append(settlements, st)
-		}
+// UnspecifiedReason is the group used for debts without a description
+const UnspecifiedReason = "(unspecified)"
+
+// ReasonTotals holds the outstanding amounts lent and borrowed for one reason
+type ReasonTotals struct {
+	Lent     float64
+	Borrowed float64
+}
+
+// NormalizeReason normalizes a debt description for grouping (lowercase, single spaces)
+func NormalizeReason(description string) string {
+	reason := strings.Join(strings.Fields(strings.ToLower(description)), " ")
+	if reason == "" {
+		return UnspecifiedReason
 	}
-	return settlements
+	return reason
 }
 
-// GetAllSettlements returns all settlements
-func (s *Storage) GetAllSettlements() []models.Settlement {
-	return s.data.Settlements
+// DebtsByReason groups unsettled transactions across all people by normalized description
+func (s *Storage) DebtsByReason() map[string]ReasonTotals {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	totals := make(map[string]ReasonTotals)
+	for _, tx := range s.data.DebtTransactions {
+		if tx.IsSettled {
+			continue
+		}
+		reason := NormalizeReason(tx.Description)
+		t := totals[reason]
+		if tx.Type == models.Lent {
+			t.Lent += s.data.Rates.ToBase(tx.Amount, tx.Currency)
+		} else {
+			t.Borrowed += s.data.Rates.ToBase(tx.Amount, tx.Currency)
+		}
+		totals[reason] = t
+	}
+	return totals
 }
 
 // GetSettledDebtsForPerson returns settled debts for a specific person
 func (s *Storage) GetSettledDebtsForPerson(personName string) []models.DebtTransaction {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	normalizedName := NormalizeName(personName)
 	var settled []models.DebtTransaction
 	for _, tx := range s.data.DebtTransactions {
@@ -437,7 +1447,10 @@ func (s *Storage) GetSettledDebtsForPerson(personName string) []models.DebtTrans
 // ==================== Investment Operations ====================
 
 // AddInvestment adds a new investment
-func (s *Storage) AddInvestment(invType models.InvestmentType, name string, investedAmount, currentValue, units float64, purchaseDate time.Time, notes string) (*models.Investment, error) {
+func (s *Storage) AddInvestment(invType models.InvestmentType, name string, investedAmount, currentValue, units float64, purchaseDate time.Time, notes string, currency string) (*models.Investment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	inv := models.Investment{
 		ID:             GenerateID(),
 		Type:           invType,
@@ -447,20 +1460,29 @@ func (s *Storage) AddInvestment(invType models.InvestmentType, name string, inve
 		Units:          units,
 		PurchaseDate:   purchaseDate,
 		Notes:          notes,
+		ValueHistory:   []models.ValuePoint{{Date: purchaseDate, Value: investedAmount}},
+		Currency:       s.entryCurrency(currency),
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
 	}
+	if currentValue != investedAmount {
+		inv.ValueHistory = append(inv.ValueHistory, models.ValuePoint{Date: inv.CreatedAt, Value: currentValue})
+	}
 	s.data.Investments = append(s.data.Investments, inv)
-	return &inv, s.Save()
+	s.recordNetWorthSnapshot()
+	return &inv, s.save()
 }
 
 // UpdateInvestmentValue updates the current value of an investment
 func (s *Storage) UpdateInvestmentValue(id string, currentValue float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	for i, inv := range s.data.Investments {
 		if inv.ID == id {
-			s.data.Investments[i].CurrentValue = currentValue
-			s.data.Investments[i].UpdatedAt = time.Now()
-			return s.Save()
+			s.recordInvestmentValue(i, currentValue)
+			s.recordNetWorthSnapshot()
+			return s.save()
 		}
 	}
 	return nil
@@ -468,12 +1490,93 @@ func (s *Storage) UpdateInvestmentValue(id string, currentValue float64) error {
 
 // UpdateInvestment updates both invested amount and current value of an investment
 func (s *Storage) UpdateInvestment(id string, investedAmount, currentValue float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	for i, inv := range s.data.Investments {
 		if inv.ID == id {
 			s.data.Investments[i].InvestedAmount = investedAmount
-			s.data.Investments[i].CurrentValue = currentValue
+			s.recordInvestmentValue(i, currentValue)
+			s.recordNetWorthSnapshot()
+			return s.save()
+		}
+	}
+	return nil
+}
+
+// recordInvestmentValue sets the current value of the investment at index i and
+// appends it to its value history; callers must hold mu
+func (s *Storage) recordInvestmentValue(i int, currentValue float64) {
+	now := time.Now()
+	inv := &s.data.Investments[i]
+	inv.CurrentValue = currentValue
+	inv.UpdatedAt = now
+	inv.ValueHistory = append(inv.ValueHistory, models.ValuePoint{Date: now, Value: currentValue})
+}
+
+// GetInvestmentHistory returns the recorded values of an investment, oldest first
+func (s *Storage) GetInvestmentHistory(id string) []models.ValuePoint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, inv := range s.data.Investments {
+		if inv.ID == id {
+			return append([]models.ValuePoint(nil), inv.ValueHistory...)
+		}
+	}
+	return nil
+}
+
+// UpdateInvestmentDetails corrects the type, name and notes of an investment,
+// keeping its values, history and CreatedAt
+func (s *Storage) UpdateInvestmentDetails(id string, t models.InvestmentType, name string, notes string) error {
+	if !models.IsValidInvestmentType(t) {
+		return fmt.Errorf("unknown investment type %q", t)
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, inv := range s.data.Investments {
+		if inv.ID == id {
+			s.data.Investments[i].Type = t
+			s.data.Investments[i].Name = name
+			s.data.Investments[i].Notes = notes
+			s.data.Investments[i].UpdatedAt = time.Now()
+			return s.save()
+		}
+	}
+	return fmt.Errorf("investment %s not found", id)
+}
+
+// UpdateExpenseNotes replaces the notes on an expense
+func (s *Storage) UpdateExpenseNotes(id string, notes string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, exp := range s.data.Expenses {
+		if exp.ID == id {
+			s.data.Expenses[i].Notes = notes
+			return s.save()
+		}
+	}
+	return fmt.Errorf("expense not found")
+}
+
+// UpdateInvestmentNotes replaces the notes of an investment
+func (s *Storage) UpdateInvestmentNotes(id string, notes string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, inv := range s.data.Investments {
+		if inv.ID == id {
+			s.data.Investments[i].Notes = notes
 			s.data.Investments[i].UpdatedAt = time.Now()
-			return s.Save()
+			return s.save()
 		}
 	}
 	return nil
@@ -481,11 +1584,34 @@ func (s *Storage) UpdateInvestment(id string, investedAmount, currentValue float
 
 // GetInvestments returns all investments
 func (s *Storage) GetInvestments() []models.Investment {
-	return s.data.Investments
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]models.Investment(nil), s.data.Investments...)
+}
+
+// GetAllocation returns each investment type's share (0-1) of the total current
+// value. The map is empty when the portfolio has no value.
+func (s *Storage) GetAllocation() map[models.InvestmentType]float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	allocation := make(map[models.InvestmentType]float64)
+	total := s.data.NetWorth()
+	if total <= 0 {
+		return allocation
+	}
+	for _, inv := range s.data.Investments {
+		allocation[inv.Type] += s.data.Rates.ToBase(inv.CurrentValue, inv.Currency) / total
+	}
+	return allocation
 }
 
 // GetInvestmentsByType returns investments of a specific type
 func (s *Storage) GetInvestmentsByType(invType models.InvestmentType) []models.Investment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	var investments []models.Investment
 	for _, inv := range s.data.Investments {
 		if inv.Type == invType {
@@ -495,21 +1621,133 @@ func (s *Storage) GetInvestmentsByType(invType models.InvestmentType) []models.I
 	return investments
 }
 
-// DeleteInvestment deletes an investment by ID
+// DeleteInvestment deletes an investment by ID, along with its income records
 func (s *Storage) DeleteInvestment(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	for i, inv := range s.data.Investments {
 		if inv.ID == id {
 			s.data.Investments = append(s.data.Investments[:i], s.data.Investments[i+1:]...)
-			return s.Save()
+			incomes := s.data.InvestmentIncomes[:0]
+			for _, inc := range s.data.InvestmentIncomes {
+				if inc.InvestmentID != id {
+					incomes = append(incomes, inc)
+				}
+			}
+			s.data.InvestmentIncomes = incomes
+			s.recordNetWorthSnapshot()
+			return s.save()
 		}
 	}
 	return nil
 }
 
+// AddInvestmentIncome records a dividend/interest payout for an investment
+func (s *Storage) AddInvestmentIncome(investmentID string, amount float64, incomeType models.InvestmentIncomeType, date time.Time, notes string) (*models.InvestmentIncome, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var found bool
+	for _, inv := range s.data.Investments {
+		if inv.ID == investmentID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("investment %s not found", investmentID)
+	}
+
+	income := models.InvestmentIncome{
+		ID:           GenerateID(),
+		InvestmentID: investmentID,
+		Type:         incomeType,
+		Amount:       amount,
+		Date:         date,
+		Notes:        notes,
+		CreatedAt:    time.Now(),
+	}
+	s.data.InvestmentIncomes = append(s.data.InvestmentIncomes, income)
+	return &income, s.save()
+}
+
+// GetInvestmentIncome returns income records for an investment
+func (s *Storage) GetInvestmentIncome(investmentID string) []models.InvestmentIncome {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var incomes []models.InvestmentIncome
+	for _, inc := range s.data.InvestmentIncomes {
+		if inc.InvestmentID == investmentID {
+			incomes = append(incomes, inc)
+		}
+	}
+	return incomes
+}
+
+// RecordNetWorthSnapshot records today's net worth, replacing an earlier snapshot
+// from today. It returns the snapshot and the most recent one from an earlier day
+// (nil if there is none). Nothing is saved when today's snapshot is unchanged.
+func (s *Storage) RecordNetWorthSnapshot() (models.NetWorthSnapshot, *models.NetWorthSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, changed := s.recordNetWorthSnapshot()
+
+	var previous *models.NetWorthSnapshot
+	if n := len(s.data.NetWorthSnapshots); n > 1 {
+		prev := s.data.NetWorthSnapshots[n-2]
+		previous = &prev
+	}
+	if !changed {
+		return snapshot, previous, nil
+	}
+	return snapshot, previous, s.save()
+}
+
+// recordNetWorthSnapshot sets today's snapshot to the current net worth and
+// reports whether that changed anything; callers must hold mu and save
+func (s *Storage) recordNetWorthSnapshot() (models.NetWorthSnapshot, bool) {
+	now := time.Now()
+	snapshot := models.NetWorthSnapshot{
+		Date:  time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+		Value: s.data.NetWorth(),
+	}
+
+	snapshots := s.data.NetWorthSnapshots
+	if n := len(snapshots); n > 0 && snapshots[n-1].Date.Equal(snapshot.Date) {
+		if snapshots[n-1].Value == snapshot.Value {
+			return snapshot, false
+		}
+		snapshots[n-1] = snapshot
+	} else {
+		s.data.NetWorthSnapshots = append(snapshots, snapshot)
+	}
+	return snapshot, true
+}
+
+// GetNetWorthSnapshots returns the recorded daily net worth values, oldest first
+func (s *Storage) GetNetWorthSnapshots() []models.NetWorthSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshots := make([]models.NetWorthSnapshot, len(s.data.NetWorthSnapshots))
+	copy(snapshots, s.data.NetWorthSnapshots)
+	return snapshots
+}
+
 // ==================== Savings Target Operations ====================
 
 // AddSavingsTarget adds a new savings target
 func (s *Storage) AddSavingsTarget(productName string, targetAmount float64, targetDate time.Time, description string) (*models.SavingsTarget, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addSavingsTarget(productName, targetAmount, targetDate, description)
+}
+
+// addSavingsTarget adds a savings target; callers must hold mu
+func (s *Storage) addSavingsTarget(productName string, targetAmount float64, targetDate time.Time, description string) (*models.SavingsTarget, error) {
 	target := models.SavingsTarget{
 		ID:            GenerateID(),
 		ProductName:   productName,
@@ -522,18 +1760,131 @@ func (s *Storage) AddSavingsTarget(productName string, targetAmount float64, tar
 		UpdatedAt:     time.Now(),
 	}
 	s.data.SavingsTargets = append(s.data.SavingsTargets, target)
-	return &target, s.Save()
+	return &target, s.save()
+}
+
+// GoalTemplate is a built-in savings goal whose target is a multiple of the
+// average monthly spend
+type GoalTemplate struct {
+	Name          string
+	Description   string
+	MonthsOfSpend float64 // Target = MonthsOfSpend x average monthly spend
+	MonthsToSave  int     // Target date is this many months from today
+}
+
+// GoalTemplates are the templates offered by CreateGoalFromTemplate
+var GoalTemplates = []GoalTemplate{
+	{Name: "Emergency Fund", Description: "Six months of expenses set aside", MonthsOfSpend: 6, MonthsToSave: 12},
+	{Name: "Vacation", Description: "A month of spending for a trip", MonthsOfSpend: 1, MonthsToSave: 6},
+	{Name: "Big Purchase", Description: "Three months of spending for a large purchase", MonthsOfSpend: 3, MonthsToSave: 12},
+}
+
+// averageSpendMonths is how many past months AverageMonthlySpend looks at for templates
+const averageSpendMonths = 3
+
+// GoalTemplateAmount returns the target amount a template would get from current data
+func (s *Storage) GoalTemplateAmount(t GoalTemplate) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return t.MonthsOfSpend * s.data.AverageMonthlySpend(time.Now(), averageSpendMonths)
+}
+
+// CreateGoalFromTemplate creates a savings target from the named built-in template
+func (s *Storage) CreateGoalFromTemplate(name string) (*models.SavingsTarget, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range GoalTemplates {
+		if !strings.EqualFold(t.Name, name) {
+			continue
+		}
+		now := time.Now()
+		amount := math.Round(t.MonthsOfSpend*s.data.AverageMonthlySpend(now, averageSpendMonths)*100) / 100
+		if amount <= 0 {
+			return nil, fmt.Errorf("not enough expense history to size %q; record a month of expenses first", t.Name)
+		}
+		due := now.AddDate(0, t.MonthsToSave, 0)
+		targetDate := time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, time.UTC)
+		return s.addSavingsTarget(t.Name, amount, targetDate, t.Description)
+	}
+	return nil, fmt.Errorf("unknown goal template %q", name)
+}
+
+// UpdateSavingsTarget changes a goal's name, target amount, date and description.
+// Saved amount, contributions and CreatedAt are kept; completion is re-evaluated
+// against the new target.
+func (s *Storage) UpdateSavingsTarget(id string, productName string, targetAmount float64, targetDate time.Time, description string) error {
+	productName = strings.TrimSpace(productName)
+	if productName == "" {
+		return fmt.Errorf("product name is required")
+	}
+	if targetAmount <= 0 {
+		return fmt.Errorf("target amount must be positive")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, target := range s.data.SavingsTargets {
+		if target.ID != id {
+			continue
+		}
+		now := time.Now()
+		t := &s.data.SavingsTargets[i]
+		t.ProductName = productName
+		t.TargetAmount = targetAmount
+		t.TargetDate = targetDate
+		t.Description = description
+		t.UpdatedAt = now
+
+		completed := t.CurrentAmount >= targetAmount*s.config.GoalCompletionThreshold()
+		if completed && !t.IsCompleted {
+			t.CompletedAt = &now
+		} else if !completed {
+			t.CompletedAt = nil
+		}
+		t.IsCompleted = completed
+		return s.save()
+	}
+	return fmt.Errorf("savings target %s not found", id)
+}
+
+// DuplicateSavingsTarget creates a fresh copy of a savings target with nothing saved yet
+func (s *Storage) DuplicateSavingsTarget(id string) (*models.SavingsTarget, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.data.SavingsTargets {
+		if t.ID == id {
+			return s.addSavingsTarget(t.ProductName+" (copy)", t.TargetAmount, t.TargetDate, t.Description)
+		}
+	}
+	return nil, fmt.Errorf("savings target %s not found", id)
+}
+
+// AddSavingsContribution adds a contribution to a savings target. completedGoal
+// reports whether this contribution brought the goal to completion.
+func (s *Storage) AddSavingsContribution(targetID string, amount float64, notes string) (contribution *models.SavingsContribution, completedGoal bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addSavingsContribution(targetID, amount, notes)
 }
 
-// AddSavingsContribution adds a contribution to a savings target
-func (s *Storage) AddSavingsContribution(targetID string, amount float64, notes string) (*models.SavingsContribution, error) {
+// addSavingsContribution records a contribution; callers must hold mu
+func (s *Storage) addSavingsContribution(targetID string, amount float64, notes string) (*models.SavingsContribution, bool, error) {
 	// Find and update the target
-	var targetFound bool
+	var targetFound, completedGoal bool
 	for i, target := range s.data.SavingsTargets {
 		if target.ID == targetID {
 			s.data.SavingsTargets[i].CurrentAmount += amount
 			s.data.SavingsTargets[i].UpdatedAt = time.Now()
-			if s.data.SavingsTargets[i].CurrentAmount >= s.data.SavingsTargets[i].TargetAmount {
+			if s.data.SavingsTargets[i].CurrentAmount >= s.data.SavingsTargets[i].TargetAmount*s.config.GoalCompletionThreshold() {
+				if !s.data.SavingsTargets[i].IsCompleted {
+					now := time.Now()
+					s.data.SavingsTargets[i].CompletedAt = &now
+					completedGoal = true
+				}
 				s.data.SavingsTargets[i].IsCompleted = true
 			}
 			targetFound = true
@@ -542,7 +1893,7 @@ func (s *Storage) AddSavingsContribution(targetID string, amount float64, notes
 	}
 
 	if !targetFound {
-		return nil, nil
+		return nil, false, nil
 	}
 
 	contribution := models.SavingsContribution{
@@ -554,16 +1905,100 @@ func (s *Storage) AddSavingsContribution(targetID string, amount float64, notes
 		CreatedAt: time.Now(),
 	}
 	s.data.SavingsContributions = append(s.data.SavingsContributions, contribution)
-	return &contribution, s.Save()
+	return &contribution, completedGoal, s.save()
+}
+
+// WithdrawSavingsContribution takes money back out of a savings goal, recording
+// it as a negative contribution. A goal that drops below its completion
+// threshold is no longer completed.
+func (s *Storage) WithdrawSavingsContribution(targetID string, amount float64, notes string) error {
+	if amount <= 0 {
+		return fmt.Errorf("amount must be positive")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, target := range s.data.SavingsTargets {
+		if target.ID != targetID {
+			continue
+		}
+		if amount > target.CurrentAmount {
+			return fmt.Errorf("only %.2f saved towards %s", target.CurrentAmount, target.ProductName)
+		}
+
+		now := time.Now()
+		s.data.SavingsTargets[i].CurrentAmount -= amount
+		s.data.SavingsTargets[i].UpdatedAt = now
+		if s.data.SavingsTargets[i].CurrentAmount < target.TargetAmount*s.config.GoalCompletionThreshold() {
+			s.data.SavingsTargets[i].IsCompleted = false
+			s.data.SavingsTargets[i].CompletedAt = nil
+		}
+
+		s.data.SavingsContributions = append(s.data.SavingsContributions, models.SavingsContribution{
+			ID:        GenerateID(),
+			TargetID:  targetID,
+			Amount:    -amount,
+			Date:      now,
+			Notes:     notes,
+			CreatedAt: now,
+		})
+		return s.save()
+	}
+	return fmt.Errorf("savings target %s not found", targetID)
+}
+
+// StashRoundUp contributes the round-up of an expense amount to the configured
+// savings goal when round-up savings are enabled. Returns the amount stashed and
+// the goal it went to (0 and nil when nothing was stashed).
+func (s *Storage) StashRoundUp(amount float64, description string) (float64, *models.SavingsTarget, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.config.RoundUpSavings || s.config.RoundUpTargetID == "" {
+		return 0, nil, nil
+	}
+
+	step := s.config.RoundUpStep
+	if step <= 0 {
+		step = config.DefaultRoundUpStep
+	}
+	diff := models.RoundUpDifference(amount, step)
+	if diff == 0 {
+		return 0, nil, nil
+	}
+
+	var target *models.SavingsTarget
+	for i := range s.data.SavingsTargets {
+		if s.data.SavingsTargets[i].ID == s.config.RoundUpTargetID {
+			target = &s.data.SavingsTargets[i]
+			break
+		}
+	}
+	if target == nil || target.IsCompleted {
+		return 0, nil, nil
+	}
+
+	if _, _, err := s.addSavingsContribution(target.ID, diff, "Round-up: "+description); err != nil {
+		return 0, nil, err
+	}
+	goal := *target
+	return diff, &goal, nil
 }
 
 // GetSavingsTargets returns all savings targets
 func (s *Storage) GetSavingsTargets() []models.SavingsTarget {
-	return s.data.SavingsTargets
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]models.SavingsTarget(nil), s.data.SavingsTargets...)
 }
 
 // GetActiveSavingsTargets returns non-completed savings targets
 func (s *Storage) GetActiveSavingsTargets() []models.SavingsTarget {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	var active []models.SavingsTarget
 	for _, target := range s.data.SavingsTargets {
 		if !target.IsCompleted {
@@ -573,8 +2008,66 @@ func (s *Storage) GetActiveSavingsTargets() []models.SavingsTarget {
 	return active
 }
 
+// SetSavingsPriority sets how much weight a goal gets in SuggestContribution
+func (s *Storage) SetSavingsPriority(id string, priority int) error {
+	if priority < models.MinGoalPriority || priority > models.MaxGoalPriority {
+		return fmt.Errorf("priority must be between %d and %d", models.MinGoalPriority, models.MaxGoalPriority)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, target := range s.data.SavingsTargets {
+		if target.ID == id {
+			s.data.SavingsTargets[i].Priority = priority
+			s.data.SavingsTargets[i].UpdatedAt = time.Now()
+			return s.save()
+		}
+	}
+	return fmt.Errorf("savings target not found")
+}
+
+// GetVisibleSavingsTargets returns savings targets for the Savings list, active
+// goals first, leaving out goals completed more than HideCompletedAfterDays ago
+func (s *Storage) GetVisibleSavingsTargets() []models.SavingsTarget {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var visible []models.SavingsTarget
+	for _, target := range s.data.SavingsTargets {
+		if target.IsCompleted {
+			// Goals completed before CompletedAt existed fall back to their last update
+			completedAt := target.UpdatedAt
+			if target.CompletedAt != nil {
+				completedAt = *target.CompletedAt
+			}
+			if s.completedLongAgo(completedAt) {
+				continue
+			}
+		}
+		visible = append(visible, target)
+	}
+	sort.SliceStable(visible, func(i, j int) bool {
+		return !visible[i].IsCompleted && visible[j].IsCompleted
+	})
+	return visible
+}
+
+// completedLongAgo reports whether something finished at doneAt is older than
+// the configured HideCompletedAfterDays
+func (s *Storage) completedLongAgo(doneAt time.Time) bool {
+	days := s.config.HideCompletedAfterDays
+	if days <= 0 {
+		return false
+	}
+	return doneAt.Before(time.Now().AddDate(0, 0, -days))
+}
+
 // GetSavingsContributions returns contributions for a target
 func (s *Storage) GetSavingsContributions(targetID string) []models.SavingsContribution {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	var contributions []models.SavingsContribution
 	for _, c := range s.data.SavingsContributions {
 		if c.TargetID == targetID {
@@ -586,10 +2079,13 @@ func (s *Storage) GetSavingsContributions(targetID string) []models.SavingsContr
 
 // DeleteSavingsTarget deletes a savings target by ID
 func (s *Storage) DeleteSavingsTarget(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	for i, target := range s.data.SavingsTargets {
 		if target.ID == id {
 			s.data.SavingsTargets = append(s.data.SavingsTargets[:i], s.data.SavingsTargets[i+1:]...)
-			return s.Save()
+			return s.save()
 		}
 	}
 	return nil
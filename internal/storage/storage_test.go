@@ -0,0 +1,1510 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/debtq/debtq/internal/config"
+	"github.com/debtq/debtq/internal/models"
+)
+
+// newTestStorage returns empty in-memory storage. HOME, the data file and the
+// vault all point into temp dirs, so tests never touch real files.
+func newTestStorage(t *testing.T) *Storage {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := config.DefaultConfig()
+	dir := t.TempDir()
+	cfg.DataFile = filepath.Join(dir, "data.json")
+	cfg.ObsidianVaultPath = filepath.Join(dir, "vault")
+	s, err := NewWithPersister(cfg, NewMemoryPersister(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// readNote returns the note written at path, relative to the vault
+func readNote(t *testing.T, s *Storage, path string) string {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join(s.config.ObsidianVaultPath, path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(raw)
+}
+
+func TestPersonNetBalanceMatchesObsidian(t *testing.T) {
+	s := newTestStorage(t)
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)
+	lent, _ := s.AddDebtTransaction(models.Lent, "Asha", 500, "rent share", day, nil)
+	s.AddDebtTransaction(models.Borrowed, "asha", 150, "cab", day, nil)
+	settled, _ := s.AddDebtTransaction(models.Lent, "Asha", 80, "snacks", day, nil)
+	if err := s.SettleTransactionWithNote(lent.ID, 50, "part"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SettleTransactionWithNote(settled.ID, 0, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// Outstanding only: 500 - 50 lent, 150 borrowed, the settled 80 not at all
+	net := s.GetPersonNetBalance("Asha")
+	if net != 300 {
+		t.Fatalf("GetPersonNetBalance = %.2f, want 300.00", net)
+	}
+
+	o := NewObsidianWriter(s.config)
+	if err := o.SyncAllNotes(s.GetData()); err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf("Outstanding - owes you: %.2f", net)
+	if debts := readNote(t, s, "Debts.md"); !strings.Contains(debts, want) {
+		t.Errorf("Debts.md lacks %q:\n%s", want, debts)
+	}
+	if person := readNote(t, s, filepath.Join(config.DefaultObsidianPeopleFolder, "ASHA.md")); !strings.Contains(person, want) {
+		t.Errorf("person note lacks %q:\n%s", want, person)
+	}
+}
+
+func TestDeleteInvestmentDeletesIncome(t *testing.T) {
+	s := newTestStorage(t)
+	now := time.Now()
+	fd, _ := s.AddInvestment(models.InvestmentFD, "FD", 10000, 10000, 0, now, "", "")
+	stock, _ := s.AddInvestment(models.InvestmentStocks, "ACME", 5000, 6000, 10, now, "", "")
+	s.AddInvestmentIncome(fd.ID, 700, models.IncomeInterest, now, "")
+	s.AddInvestmentIncome(stock.ID, 150, models.IncomeDividend, now, "")
+
+	if err := s.DeleteInvestment(fd.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.GetInvestmentIncome(fd.ID); len(got) != 0 {
+		t.Errorf("income of the deleted investment left: %+v", got)
+	}
+	if got := s.GetInvestmentIncome(stock.ID); len(got) != 1 {
+		t.Errorf("other investment has %d income records, want 1", len(got))
+	}
+	if got := s.GetData().TotalReturn(); got != 1000+150 {
+		t.Errorf("TotalReturn = %.2f, want 1150.00", got)
+	}
+}
+
+func TestSplitDebtPartsSumToTotal(t *testing.T) {
+	for _, assign := range []models.RemainderAssignment{models.RemainderFirst, models.RemainderLast, models.RemainderLargest} {
+		s := newTestStorage(t)
+		s.config.RemainderAssignment = assign
+		txs, err := s.AddSplitDebt(100, "dinner", time.Now(), []string{"Asha", "Ravi", "Meera"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sum float64
+		for _, tx := range txs {
+			sum += tx.Amount
+		}
+		if len(txs) != 3 || fmt.Sprintf("%.2f", sum) != "100.00" {
+			t.Errorf("%s: %d parts summing to %.4f, want 3 summing to 100", assign, len(txs), sum)
+		}
+	}
+}
+
+func TestDebtsMissingDueDate(t *testing.T) {
+	s := newTestStorage(t)
+	s.config.DueDateReminderDays = 30
+	now := time.Now()
+	due := now.AddDate(0, 1, 0)
+
+	old, _ := s.AddDebtTransaction(models.Lent, "Asha", 100, "old, undated", now.AddDate(0, 0, -45), nil)
+	s.AddDebtTransaction(models.Lent, "Asha", 100, "recent", now.AddDate(0, 0, -10), nil)
+	s.AddDebtTransaction(models.Lent, "Asha", 100, "old, dated", now.AddDate(0, 0, -45), &due)
+	settled, _ := s.AddDebtTransaction(models.Borrowed, "Ravi", 100, "old, settled", now.AddDate(0, 0, -60), nil)
+	s.SettleTransactionWithNote(settled.ID, 0, "")
+	borrowed, _ := s.AddDebtTransaction(models.Borrowed, "Ravi", 50, "old borrowed", now.AddDate(0, 0, -31), nil)
+
+	got := s.DebtsMissingDueDate()
+	ids := make(map[string]bool)
+	for _, tx := range got {
+		ids[tx.ID] = true
+	}
+	if len(got) != 2 || !ids[old.ID] || !ids[borrowed.ID] {
+		t.Fatalf("DebtsMissingDueDate = %+v, want the old undated lent and borrowed debts", got)
+	}
+
+	// Setting a due date takes a debt off the list
+	if err := s.SetDebtDueDate(old.ID, &due); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.DebtsMissingDueDate(); len(got) != 1 || got[0].ID != borrowed.ID {
+		t.Fatalf("after setting a due date: %+v", got)
+	}
+
+	// A longer threshold drops the 31-day-old debt
+	s.config.DueDateReminderDays = 40
+	if got := s.DebtsMissingDueDate(); len(got) != 0 {
+		t.Fatalf("with a 40-day threshold: %+v", got)
+	}
+
+	// Without a configured age the default applies
+	s.config.DueDateReminderDays = 0
+	want := 0
+	if config.DefaultDueDateReminderDays < 31 {
+		want = 1
+	}
+	if got := s.DebtsMissingDueDate(); len(got) != want {
+		t.Fatalf("with the default %d days: %+v", config.DefaultDueDateReminderDays, got)
+	}
+}
+
+func TestGoalCompletionThreshold(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold float64
+		first     float64 // just short of the threshold
+		last      float64 // reaches it exactly
+	}{
+		{"default", 100, 999.99, 0.01},
+		{"99 percent", 99, 989.99, 0.01},
+		{"out of range means 100", 150, 999.99, 0.01},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStorage(t)
+			s.config.GoalCompletionThresholdPct = tt.threshold
+			goal, err := s.AddSavingsTarget("Laptop", 1000, time.Now().AddDate(0, 6, 0), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			completed := func() bool {
+				for _, g := range s.GetSavingsTargets() {
+					if g.ID == goal.ID {
+						return g.IsCompleted
+					}
+				}
+				t.Fatal("goal not found")
+				return false
+			}
+
+			_, completedGoal, err := s.AddSavingsContribution(goal.ID, tt.first, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if completed() || completedGoal {
+				t.Fatalf("completed at %.2f", tt.first)
+			}
+			_, completedGoal, err = s.AddSavingsContribution(goal.ID, tt.last, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !completed() || !completedGoal {
+				t.Fatalf("not completed at %.2f (completedGoal %v)", tt.first+tt.last, completedGoal)
+			}
+			// Only the contribution that crossed the threshold reports it
+			if _, completedGoal, _ := s.AddSavingsContribution(goal.ID, 5, ""); completedGoal {
+				t.Error("a contribution to a completed goal reported completing it")
+			}
+			if err := s.WithdrawSavingsContribution(goal.ID, 5, ""); err != nil {
+				t.Fatal(err)
+			}
+
+			// Withdrawing below the threshold reopens the goal
+			if err := s.WithdrawSavingsContribution(goal.ID, 0.02, ""); err != nil {
+				t.Fatal(err)
+			}
+			if completed() {
+				t.Fatal("still completed after withdrawing below the threshold")
+			}
+		})
+	}
+}
+
+func TestPausedAndEndedRecurringExpensesGenerateNothing(t *testing.T) {
+	s := newTestStorage(t)
+	now := time.Date(2026, 4, 20, 12, 0, 0, 0, time.UTC)
+	ended := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	endsToday := time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC)
+	later := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	s.data.RecurringExpenses = []models.RecurringExpense{
+		{ID: "rent", Description: "Rent", Amount: 1000, DayOfMonth: 15, Active: true, LastPeriod: "2026-03"},
+		{ID: "gym", Description: "Gym", Amount: 50, DayOfMonth: 15, Active: false, LastPeriod: "2026-03"},
+		{ID: "tv", Description: "Streaming", Amount: 10, DayOfMonth: 15, Active: true, EndDate: &ended, LastPeriod: "2026-03"},
+		{ID: "paper", Description: "Newspaper", Amount: 5, DayOfMonth: 15, Active: true, EndDate: &endsToday, LastPeriod: "2026-03"},
+		{ID: "phone", Description: "Phone", Amount: 20, DayOfMonth: 15, Active: true, EndDate: &later, LastPeriod: "2026-03"},
+	}
+
+	if err := s.materializeRecurringExpenses(now); err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]int)
+	for _, exp := range s.data.Expenses {
+		got[exp.RecurringID]++
+	}
+	// The end date itself still gets its expense
+	want := map[string]int{"rent": 1, "paper": 1, "phone": 1}
+	if len(got) != len(want) {
+		t.Fatalf("created expenses for %v, want %v", got, want)
+	}
+	for id, n := range want {
+		if got[id] != n {
+			t.Errorf("%s created %d expenses, want %d", id, got[id], n)
+		}
+	}
+
+	// Next month the newspaper has ended too; resuming the gym starts it again
+	if err := s.SetRecurringExpenseActive("gym", true); err != nil {
+		t.Fatal(err)
+	}
+	before := len(s.data.Expenses)
+	if err := s.materializeRecurringExpenses(now.AddDate(0, 1, 0)); err != nil {
+		t.Fatal(err)
+	}
+	created := make(map[string]bool)
+	for _, exp := range s.data.Expenses[before:] {
+		created[exp.RecurringID] = true
+	}
+	if len(created) != 3 || !created["rent"] || !created["gym"] || !created["phone"] {
+		t.Errorf("next month created %v, want rent, gym and phone", created)
+	}
+
+	// Removing the end date starts it again
+	if err := s.SetRecurringExpenseEndDate("tv", nil); err != nil {
+		t.Fatal(err)
+	}
+	before = len(s.data.Expenses)
+	if err := s.materializeRecurringExpenses(now.AddDate(0, 2, 0)); err != nil {
+		t.Fatal(err)
+	}
+	created = make(map[string]bool)
+	for _, exp := range s.data.Expenses[before:] {
+		created[exp.RecurringID] = true
+	}
+	if !created["tv"] || created["paper"] {
+		t.Errorf("after clearing the end date created %v", created)
+	}
+}
+
+func TestStashRoundUp(t *testing.T) {
+	s := newTestStorage(t)
+	goal, err := s.AddSavingsTarget("Holiday", 1000, time.Now().AddDate(1, 0, 0), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	contributions := func() []models.SavingsContribution {
+		return s.GetData().SavingsContributions
+	}
+
+	// Disabled by default
+	if stashed, _, err := s.StashRoundUp(237, "groceries"); err != nil || stashed != 0 || len(contributions()) != 0 {
+		t.Fatalf("disabled: stashed %.2f (%v), %d contributions", stashed, err, len(contributions()))
+	}
+
+	s.config.RoundUpSavings = true
+	s.config.RoundUpTargetID = goal.ID
+	s.config.RoundUpStep = 100
+	stashed, target, err := s.StashRoundUp(237, "groceries")
+	if err != nil || stashed != 63 || target == nil || target.ID != goal.ID {
+		t.Fatalf("stashed %.2f into %+v (%v), want 63 into the goal", stashed, target, err)
+	}
+	got := contributions()
+	if len(got) != 1 || got[0].Amount != 63 || got[0].TargetID != goal.ID || got[0].Notes != "Round-up: groceries" {
+		t.Fatalf("contributions = %+v", got)
+	}
+	if target.CurrentAmount != 63 {
+		t.Errorf("goal has %.2f saved, want 63", target.CurrentAmount)
+	}
+
+	// An exact multiple has nothing to round up
+	if stashed, _, _ := s.StashRoundUp(300, "rent"); stashed != 0 || len(contributions()) != 1 {
+		t.Errorf("exact multiple stashed %.2f", stashed)
+	}
+
+	// Without a step the default applies
+	s.config.RoundUpStep = 0
+	if stashed, _, _ := s.StashRoundUp(237, "cab"); stashed != models.RoundUpDifference(237, config.DefaultRoundUpStep) {
+		t.Errorf("default step stashed %.2f", stashed)
+	}
+
+	// A missing or completed goal receives nothing
+	n := len(contributions())
+	s.config.RoundUpTargetID = "gone"
+	if stashed, target, err := s.StashRoundUp(237, "cab"); stashed != 0 || target != nil || err != nil {
+		t.Errorf("missing goal: stashed %.2f into %v (%v)", stashed, target, err)
+	}
+	s.config.RoundUpTargetID = goal.ID
+	s.AddSavingsContribution(goal.ID, 1000, "")
+	n++
+	if stashed, _, _ := s.StashRoundUp(237, "cab"); stashed != 0 || len(contributions()) != n {
+		t.Errorf("completed goal: stashed %.2f", stashed)
+	}
+}
+
+func TestNormalizeReason(t *testing.T) {
+	tests := map[string]string{
+		"Rent":             "rent",
+		"  Goa   TRIP ":    "goa trip",
+		"food\tand\ndrink": "food and drink",
+		"":                 UnspecifiedReason,
+		"   ":              UnspecifiedReason,
+	}
+	for in, want := range tests {
+		if got := NormalizeReason(in); got != want {
+			t.Errorf("NormalizeReason(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestDebtsByReason(t *testing.T) {
+	s := newTestStorage(t)
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)
+	s.AddDebtTransaction(models.Lent, "Asha", 500, "Rent", day, nil)
+	s.AddDebtTransaction(models.Lent, "Ravi", 300, "  rent ", day, nil)
+	s.AddDebtTransaction(models.Borrowed, "Ravi", 120, "RENT", day, nil)
+	s.AddDebtTransaction(models.Borrowed, "Meera", 40, "", day, nil)
+	part, _ := s.AddDebtTransaction(models.Lent, "Meera", 200, "Goa trip", day, nil)
+	settled, _ := s.AddDebtTransaction(models.Lent, "Asha", 90, "snacks", day, nil)
+	if err := s.SettleTransactionWithNote(part.ID, 50, "part"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SettleTransactionWithNote(settled.ID, 0, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]ReasonTotals{
+		"rent":            {Lent: 800, Borrowed: 120},
+		"goa trip":        {Lent: 150},
+		UnspecifiedReason: {Borrowed: 40},
+	}
+	got := s.DebtsByReason()
+	if len(got) != len(want) {
+		t.Fatalf("DebtsByReason = %v, want %v", got, want)
+	}
+	for reason, w := range want {
+		if got[reason] != w {
+			t.Errorf("%s: %+v, want %+v", reason, got[reason], w)
+		}
+	}
+}
+
+func TestSaveFailureKeepsDataFile(t *testing.T) {
+	// A 250-byte name leaves no room for the temp file next to it, so the
+	// write fails before the data file is touched
+	dir := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.DataFile = filepath.Join(dir, strings.Repeat("d", 245)+".json")
+	old := []byte(`{"expenses": []}`)
+	if err := os.WriteFile(cfg.DataFile, old, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewFilePersister(cfg).Save([]byte(`{"expenses": [{}]}`)); err == nil {
+		t.Fatal("Save succeeded, want a write error")
+	}
+	if data, err := os.ReadFile(cfg.DataFile); err != nil || string(data) != string(old) {
+		t.Errorf("data file = %q, %v; want it unchanged", data, err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("failed save left %d files behind, want only the data file", len(entries)-1)
+	}
+}
+
+func TestSaveReplacesDataFile(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.DataFile = filepath.Join(dir, "data.json")
+	p := NewFilePersister(cfg)
+	if err := p.Save([]byte(`{"v": 1}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Save([]byte(`{"v": 2}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, _ := os.ReadFile(cfg.DataFile); string(data) != `{"v": 2}` {
+		t.Errorf("data file = %q", data)
+	}
+	if data, _ := os.ReadFile(p.backupFile()); string(data) != `{"v": 1}` {
+		t.Errorf("backup = %q, want the replaced file", data)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("%d files in the data dir, want the data file and its backup", len(entries))
+	}
+}
+
+func TestHideCompletedAfterDays(t *testing.T) {
+	s := newTestStorage(t)
+	s.config.HideCompletedAfterDays = 30
+	now := time.Now()
+	longAgo := now.AddDate(0, 0, -45)
+	recently := now.AddDate(0, 0, -10)
+
+	// Goals: one finished long ago, one finished recently, one still open
+	old, _ := s.AddSavingsTarget("Phone", 100, now, "")
+	recent, _ := s.AddSavingsTarget("Bike", 100, now, "")
+	s.AddSavingsTarget("Laptop", 1000, now.AddDate(1, 0, 0), "")
+	for _, id := range []string{old.ID, recent.ID} {
+		if _, _, err := s.AddSavingsContribution(id, 100, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range s.data.SavingsTargets {
+		if s.data.SavingsTargets[i].ID == old.ID {
+			s.data.SavingsTargets[i].CompletedAt = &longAgo
+		}
+	}
+
+	// People: Asha settled long ago, Ravi settled recently, Meera still owes
+	asha, _ := s.AddDebtTransaction(models.Lent, "Asha", 100, "lunch", longAgo, nil)
+	ravi, _ := s.AddDebtTransaction(models.Lent, "Ravi", 100, "lunch", longAgo, nil)
+	s.AddDebtTransaction(models.Lent, "Meera", 100, "lunch", longAgo, nil)
+	for _, id := range []string{asha.ID, ravi.ID} {
+		if err := s.SettleTransactionWithNote(id, 0, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backdate := func(person string, at time.Time) {
+		for i := range s.data.DebtTransactions {
+			if s.data.DebtTransactions[i].PersonName == person {
+				s.data.DebtTransactions[i].SettledDate = &at
+			}
+		}
+		for i := range s.data.Settlements {
+			if s.data.Settlements[i].PersonName == person {
+				s.data.Settlements[i].Date = at
+			}
+		}
+	}
+	backdate("ASHA", longAgo)
+	backdate("RAVI", recently)
+
+	var goals []string
+	for _, g := range s.GetVisibleSavingsTargets() {
+		goals = append(goals, g.ProductName)
+	}
+	if strings.Join(goals, ",") != "Laptop,Bike" {
+		t.Errorf("visible goals = %v, want [Laptop Bike]", goals)
+	}
+	var people []string
+	for _, p := range s.GetVisiblePeople() {
+		people = append(people, p.Name)
+	}
+	if strings.Join(people, ",") != "MEERA,RAVI" {
+		t.Errorf("visible people = %v, want [MEERA RAVI]", people)
+	}
+
+	// History keeps everything; 0 shows everything again
+	if len(s.GetSettlementsForPerson("Asha")) == 0 {
+		t.Error("hidden person lost their payment history")
+	}
+	s.config.HideCompletedAfterDays = 0
+	if len(s.GetVisibleSavingsTargets()) != 3 || len(s.GetVisiblePeople()) != 3 {
+		t.Errorf("with 0 days: %d goals, %d people visible; want all", len(s.GetVisibleSavingsTargets()), len(s.GetVisiblePeople()))
+	}
+}
+
+// TestConcurrentAccess is meant for go test -race: writers and readers share
+// one Storage the way a background sync would share it with the TUI
+func TestConcurrentAccess(t *testing.T) {
+	s := newTestStorage(t)
+	goal, err := s.AddSavingsTarget("Laptop", 100000, time.Now().AddDate(1, 0, 0), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 50
+	var wg sync.WaitGroup
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			if _, err := s.AddExpense(10, "tea", models.CategoryFood, time.Now(), "", nil, ""); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			tx, err := s.AddDebtTransaction(models.Lent, fmt.Sprintf("P%d", i%5), 100, "lunch", time.Now(), nil)
+			if err != nil {
+				t.Error(err)
+				continue
+			}
+			if err := s.SettleTransactionWithNote(tx.ID, 40, "part"); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			if _, _, err := s.AddSavingsContribution(goal.ID, 10, ""); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			s.GetExpenses()
+			s.GetPeople()
+			s.GetUnsettledDebts()
+			s.GetAllSettlements()
+			s.DebtsByReason()
+			s.GetVisibleSavingsTargets()
+		}
+	}()
+	wg.Wait()
+
+	if got := len(s.GetExpenses()); got != n {
+		t.Errorf("%d expenses, want %d", got, n)
+	}
+	if got := len(s.GetAllSettlements()); got != n {
+		t.Errorf("%d settlements, want %d", got, n)
+	}
+	if got := s.GetSavingsTargets()[0].CurrentAmount; got != 10*n {
+		t.Errorf("goal saved %.2f, want %d", got, 10*n)
+	}
+}
+
+func TestDataDirErrorIsActionable(t *testing.T) {
+	for _, cause := range []error{fs.ErrPermission, syscall.EROFS} {
+		err := dataDirError("/home/u/.debtq", &fs.PathError{Op: "mkdir", Path: "/home/u/.debtq", Err: cause})
+		msg := err.Error()
+		for _, want := range []string{"/home/u/.debtq", config.DataFileEnv} {
+			if !strings.Contains(msg, want) {
+				t.Errorf("%v: %q is missing %q", cause, msg, want)
+			}
+		}
+		if !errors.Is(err, cause) {
+			t.Errorf("%v: cause lost from %q", cause, msg)
+		}
+	}
+
+	// Anything else passes through unchanged
+	other := errors.New("disk on fire")
+	if err := dataDirError("/x", other); err != other {
+		t.Errorf("dataDirError(other) = %v", err)
+	}
+}
+
+func TestUnwritableDataDir(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to read-only directories")
+	}
+	t.Setenv("HOME", t.TempDir())
+	locked := filepath.Join(t.TempDir(), "locked")
+	if err := os.Mkdir(locked, 0555); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.DataFile = filepath.Join(locked, "debtq", "data.json")
+
+	_, err := New(cfg)
+	if err == nil {
+		t.Fatal("New succeeded in a read-only directory")
+	}
+	for _, want := range []string{filepath.Join(locked, "debtq"), "permission denied", config.DataFileEnv} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q is missing %q", err, want)
+		}
+	}
+}
+
+func TestImportExpensesCSV(t *testing.T) {
+	t.Run("well-formed", func(t *testing.T) {
+		s := newTestStorage(t)
+		before := s.revision
+		imported, errs := s.ImportExpensesCSV(strings.NewReader(
+			"date,category,description,amount\n" +
+				"2026-02-01,food,Lunch,250\n" +
+				"2026-02-03, Transport ,Metro card, 500.50\n"))
+		if imported != 2 || len(errs) != 0 {
+			t.Fatalf("imported %d, errs %v; want 2, none", imported, errs)
+		}
+		if s.revision != before+1 {
+			t.Errorf("import saved %d times, want once", s.revision-before)
+		}
+		got := s.GetExpenses()
+		if len(got) != 2 || got[0].ID == got[1].ID || got[0].ID == "" {
+			t.Fatalf("expenses = %+v, want two with distinct IDs", got)
+		}
+		for _, e := range got {
+			if e.Description == "Metro card" && (e.Amount != 500.5 || e.Category != models.CategoryTransport) {
+				t.Errorf("Metro card = %+v", e)
+			}
+		}
+	})
+
+	t.Run("partially malformed", func(t *testing.T) {
+		s := newTestStorage(t)
+		imported, errs := s.ImportExpensesCSV(strings.NewReader(
+			"2026-02-01,food,Lunch,250\n" +
+				"01/02/2026,food,Bad date,100\n" +
+				"2026-02-02,food,Bad amount,abc\n" +
+				"2026-02-02,food,Negative,-5\n" +
+				"2026-02-02,food,Too few\n" +
+				"2026-02-02,food,,40\n" +
+				"2026-02-04,gadgets,Headphones,1999\n"))
+		if imported != 2 {
+			t.Errorf("imported %d, want 2", imported)
+		}
+		if len(errs) != 6 {
+			t.Fatalf("errs = %v, want 5 skipped rows and 1 category warning", errs)
+		}
+		if msg := errs[len(errs)-1].Error(); !strings.Contains(msg, "row 7") || !strings.Contains(msg, "gadgets") {
+			t.Errorf("category warning = %q", msg)
+		}
+		for _, e := range s.GetExpenses() {
+			if e.Description == "Headphones" && e.Category != models.CategoryOther {
+				t.Errorf("unknown category imported as %q, want other", e.Category)
+			}
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		s := newTestStorage(t)
+		before := s.revision
+		imported, errs := s.ImportExpensesCSV(strings.NewReader(""))
+		if imported != 0 || len(errs) != 0 {
+			t.Errorf("imported %d, errs %v; want nothing", imported, errs)
+		}
+		if s.revision != before {
+			t.Error("empty import saved")
+		}
+	})
+}
+
+func TestRecurringExpensesMaterializeOncePerMonth(t *testing.T) {
+	s := newTestStorage(t)
+	s.data.RecurringExpenses = []models.RecurringExpense{
+		{ID: "rent", Description: "Rent", Amount: 1000, Category: models.CategoryUtilities, DayOfMonth: 5, Active: true, LastPeriod: "2026-01"},
+		{ID: "card", Description: "Card fee", Amount: 30, DayOfMonth: 31, Active: true, LastPeriod: "2026-01"},
+	}
+
+	// Before the 5th nothing is due; day 31 falls on Feb 28
+	if err := s.materializeRecurringExpenses(time.Date(2026, 2, 4, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.data.Expenses) != 0 {
+		t.Fatalf("created %d expenses before their day", len(s.data.Expenses))
+	}
+	for w := 0; w < 2; w++ {
+		if err := s.materializeRecurringExpenses(time.Date(2026, 2, 28, 12, 0, 0, 0, time.UTC)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(s.data.Expenses) != 2 {
+		t.Fatalf("created %d expenses, want one per recurring expense", len(s.data.Expenses))
+	}
+	for _, exp := range s.data.Expenses {
+		day := map[string]int{"rent": 5, "card": 28}[exp.RecurringID]
+		if exp.Date.Day() != day || exp.Date.Month() != time.February {
+			t.Errorf("%s dated %s, want Feb %d", exp.Description, exp.Date.Format("2006-01-02"), day)
+		}
+	}
+	if exp := s.data.Expenses[0]; exp.Amount != 1000 || exp.Category != models.CategoryUtilities {
+		t.Errorf("rent expense = %+v", exp)
+	}
+}
+
+func TestRecurringExpensesMaterializeOnOpen(t *testing.T) {
+	s := newTestStorage(t)
+	// Last created a year ago, due on the 1st
+	rent := models.RecurringExpense{ID: "rent", Description: "Rent", Amount: 1000, DayOfMonth: 1, Active: true, LastPeriod: time.Now().AddDate(-1, 0, 0).Format("2006-01")}
+	if time.Now().Before(rent.DueDate(time.Now())) {
+		t.Skip("the 1st has not begun in UTC yet")
+	}
+	s.data.RecurringExpenses = []models.RecurringExpense{rent}
+	if err := s.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	for w := 0; w < 2; w++ {
+		if err := s.Reload(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := len(s.GetExpenses()); got != 1 {
+		t.Fatalf("opening twice created %d expenses, want 1 for this month only", got)
+	}
+}
+
+func TestAddAndDeleteRecurringExpense(t *testing.T) {
+	s := newTestStorage(t)
+	if _, err := s.AddRecurringExpense(100, "Gym", models.CategoryHealth, 32); err == nil {
+		t.Error("day 32 accepted")
+	}
+	r, err := s.AddRecurringExpense(100, "Gym", models.CategoryHealth, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.GetRecurringExpenses(); len(got) != 1 || got[0].ID != r.ID || !got[0].Active {
+		t.Fatalf("GetRecurringExpenses = %+v", got)
+	}
+
+	// Expenses it already created stay when it is deleted
+	s.data.Expenses = append(s.data.Expenses, models.Expense{ID: "e1", Amount: 100, RecurringID: r.ID})
+	if err := s.DeleteRecurringExpense(r.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.GetRecurringExpenses()) != 0 || len(s.GetExpenses()) != 1 {
+		t.Errorf("after delete: %d recurring, %d expenses; want 0 and 1", len(s.GetRecurringExpenses()), len(s.GetExpenses()))
+	}
+}
+
+func TestCreateGoalFromTemplate(t *testing.T) {
+	s := newTestStorage(t)
+	if _, err := s.CreateGoalFromTemplate("Emergency Fund"); err == nil {
+		t.Error("template sized without any expense history")
+	}
+
+	// 900, 1200 and 1500 in the last three full months average 1200; this
+	// month's spending does not count
+	thisMonth := time.Date(time.Now().Year(), time.Now().Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i, amount := range []float64{1500, 1200, 900, 4000} {
+		date := thisMonth.AddDate(0, -(i + 1), 0)
+		if i == 3 {
+			date = thisMonth
+		}
+		if _, err := s.AddExpense(amount, "spend", models.CategoryOther, date, "", nil, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := map[string]float64{"Emergency Fund": 7200, "vacation": 1200, "BIG PURCHASE": 3600}
+	for name, want := range tests {
+		goal, err := s.CreateGoalFromTemplate(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if goal.TargetAmount != want {
+			t.Errorf("%s target = %.2f, want %.2f", name, goal.TargetAmount, want)
+		}
+	}
+	for _, tmpl := range GoalTemplates {
+		if got, want := s.GoalTemplateAmount(tmpl), tmpl.MonthsOfSpend*1200; got != want {
+			t.Errorf("GoalTemplateAmount(%s) = %.2f, want %.2f", tmpl.Name, got, want)
+		}
+	}
+
+	if _, err := s.CreateGoalFromTemplate("Yacht"); err == nil {
+		t.Error("unknown template accepted")
+	}
+}
+
+func TestFilterExpenses(t *testing.T) {
+	s := newTestStorage(t)
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	s.AddExpense(250, "Lunch at cafe", models.CategoryFood, day, "", nil, "")
+	s.AddExpense(120, "Uber to office", models.CategoryTransport, day, "", []string{"work"}, "")
+	s.AddExpense(80, "Metro", models.CategoryTransport, day, "", nil, "")
+	s.AddExpense(500, "Movie night", models.CategoryEntertainment, day, "", nil, "")
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"lunch", []string{"Lunch at cafe"}},
+		{"  UBER ", []string{"Uber to office"}},
+		{"transport", []string{"Uber to office", "Metro"}},
+		{"o", []string{"Lunch at cafe", "Uber to office", "Metro", "Movie night"}},
+		{"work", []string{"Uber to office"}},
+		{"", []string{"Lunch at cafe", "Uber to office", "Metro", "Movie night"}},
+		{"groceries", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, exp := range s.FilterExpenses(tt.query) {
+			got = append(got, exp.Description)
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("FilterExpenses(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestGetExpensesBetween(t *testing.T) {
+	s := newTestStorage(t)
+	at := func(d, h int) time.Time { return time.Date(2026, 3, d, h, 0, 0, 0, time.UTC) }
+	s.AddExpense(1, "before", models.CategoryOther, at(9, 23), "", nil, "")
+	s.AddExpense(2, "first day", models.CategoryOther, at(10, 0), "", nil, "")
+	s.AddExpense(3, "middle", models.CategoryOther, at(15, 12), "", nil, "")
+	s.AddExpense(4, "last day, late", models.CategoryOther, at(20, 23), "", nil, "")
+	s.AddExpense(5, "after", models.CategoryOther, at(21, 0), "", nil, "")
+
+	want := "first day|middle|last day, late"
+	for name, bounds := range map[string][2]time.Time{
+		"in order": {at(10, 0), at(20, 0)},
+		"inverted": {at(20, 0), at(10, 0)},
+		"times":    {at(10, 18), at(20, 1)},
+	} {
+		var got []string
+		for _, exp := range s.GetExpensesBetween(bounds[0], bounds[1]) {
+			got = append(got, exp.Description)
+		}
+		if strings.Join(got, "|") != want {
+			t.Errorf("%s: got %v, want %s", name, got, want)
+		}
+	}
+
+	if got := s.GetExpensesBetween(at(15, 0), at(15, 0)); len(got) != 1 || got[0].Description != "middle" {
+		t.Errorf("single day = %+v, want middle", got)
+	}
+}
+
+func TestRenamePerson(t *testing.T) {
+	s := newTestStorage(t)
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	settled, _ := s.AddDebtTransaction(models.Lent, "Raj", 100, "lunch", day, nil)
+	s.AddDebtTransaction(models.Lent, "raj", 400, "rent", day, nil)
+	s.AddDebtTransaction(models.Borrowed, "Raj Kumar", 150, "cab", day, nil)
+	s.AddDebtTransaction(models.Lent, "Asha", 50, "tea", day, nil)
+	if err := s.SettleTransactionWithNote(settled.ID, 0, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// Merging: settled and open transactions and their payments all move
+	before := s.revision
+	updated, err := s.RenamePerson(" raj ", "raj kumar")
+	if err != nil || updated != 2 {
+		t.Fatalf("RenamePerson = %d, %v; want 2", updated, err)
+	}
+	if s.revision != before+1 {
+		t.Errorf("rename saved %d times, want once", s.revision-before)
+	}
+	if n := len(s.GetUnsettledDebtsForPerson("Raj")) + len(s.GetSettledDebtsForPerson("Raj")); n != 0 {
+		t.Errorf("%d transactions left under RAJ", n)
+	}
+	if got := s.GetPersonNetBalance("Raj Kumar"); got != 250 {
+		t.Errorf("merged balance = %.2f, want 400 - 150 = 250", got)
+	}
+	if got := s.GetSettlementsForPerson("Raj Kumar"); len(got) != 1 || got[0].TransactionID != settled.ID {
+		t.Errorf("payments did not move with the debt: %+v", got)
+	}
+	if got := s.GetPersonNetBalance("Asha"); got != 50 {
+		t.Errorf("Asha changed to %.2f", got)
+	}
+
+	// Nothing to rename, the same name, or no new name
+	before = s.revision
+	if n, err := s.RenamePerson("Nobody", "Asha"); n != 0 || err != nil {
+		t.Errorf("unknown person: %d, %v", n, err)
+	}
+	if n, err := s.RenamePerson("asha", "ASHA"); n != 0 || err != nil {
+		t.Errorf("same name: %d, %v", n, err)
+	}
+	if s.revision != before {
+		t.Error("a rename that changed nothing saved")
+	}
+	if _, err := s.RenamePerson("Asha", "  "); err == nil {
+		t.Error("empty new name accepted")
+	}
+}
+
+func TestFormatAllBalancesTable(t *testing.T) {
+	s := newTestStorage(t)
+	s.config.Currency = "INR"
+	if got := s.FormatAllBalancesTable(); got != "All settled up.\n" {
+		t.Errorf("empty table = %q", got)
+	}
+
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	s.AddDebtTransaction(models.Lent, "Asha", 50, "tea", day, nil)
+	s.AddDebtTransaction(models.Borrowed, "Ravishankar", 1250.5, "trip", day, nil)
+	s.AddDebtTransaction(models.Lent, "Zoë", 300, "rent", day, nil)
+	settled, _ := s.AddDebtTransaction(models.Lent, "Meera", 80, "cab", day, nil)
+	if err := s.SettleTransactionWithNote(settled.ID, 0, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// Largest balance first; settled people left out; multi-byte names still line up
+	want := "" +
+		"Person       Status        Amount\n" +
+		"-----------  -------  -----------\n" +
+		"RAVISHANKAR  I owe    INR 1250.50\n" +
+		"ZOË          owes me   INR 300.00\n" +
+		"ASHA         owes me    INR 50.00\n"
+	if got := s.FormatAllBalancesTable(); got != want {
+		t.Errorf("table:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRepeatLastExpense(t *testing.T) {
+	s := newTestStorage(t)
+	if _, err := s.RepeatLastExpense(); err == nil || !strings.Contains(err.Error(), "no expenses") {
+		t.Fatalf("empty: err = %v, want no expenses to repeat", err)
+	}
+
+	// The most recently added expense is repeated, even if it is dated earlier
+	s.AddExpense(500, "Groceries", models.CategoryFood, time.Now(), "", nil, "")
+	last, _ := s.AddExpense(120, "Coffee", models.CategoryFood, time.Now().AddDate(0, 0, -3), "", []string{"daily"}, "oat milk")
+
+	repeated, err := s.RepeatLastExpense()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repeated.ID == last.ID || repeated.Amount != 120 || repeated.Description != "Coffee" ||
+		repeated.Category != models.CategoryFood || !slices.Equal(repeated.Tags, []string{"daily"}) {
+		t.Errorf("repeated = %+v, want a new copy of Coffee", repeated)
+	}
+	if y, m, d := repeated.Date.Date(); y != time.Now().Year() || m != time.Now().Month() || d != time.Now().Day() {
+		t.Errorf("repeated dated %s, want today", repeated.Date.Format("2006-01-02"))
+	}
+	if got := len(s.GetExpenses()); got != 3 {
+		t.Errorf("%d expenses, want 3", got)
+	}
+
+	// Repeating again copies the copy
+	again, err := s.RepeatLastExpense()
+	if err != nil || again.Description != "Coffee" || again.ID == repeated.ID {
+		t.Errorf("second repeat = %+v, %v", again, err)
+	}
+}
+
+func TestUndoLastSettlementUndoesWholeBatch(t *testing.T) {
+	s := newTestStorage(t)
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	first, err := s.AddDebtTransaction(models.Lent, "Asha", 300, "dinner", day, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddDebtTransaction(models.Lent, "Asha", 200, "taxi", day.AddDate(0, 0, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddDebtTransaction(models.Lent, "Asha", 100, "coffee", day.AddDate(0, 0, 2), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// An earlier single settlement is its own batch
+	if err := s.SettleTransactionWithNote(first.ID, 50, "cash"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.SettleAmountForPerson("Asha", 500, "upi"); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(s.GetAllSettlements()); n != 4 {
+		t.Fatalf("%d settlements recorded, want 4", n)
+	}
+	if n := len(s.LastSettlementBatch()); n != 3 {
+		t.Fatalf("last batch has %d settlements, want 3", n)
+	}
+
+	if err := s.UndoLastSettlement(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(s.GetAllSettlements()); n != 1 {
+		t.Fatalf("%d settlements left after undo, want 1", n)
+	}
+	want := map[string]float64{"dinner": 250, "taxi": 200, "coffee": 100}
+	for _, tx := range s.GetDebtTransactions() {
+		if tx.IsSettled || tx.Amount != want[tx.Description] {
+			t.Errorf("%s: amount %.2f settled %v, want %.2f open", tx.Description, tx.Amount, tx.IsSettled, want[tx.Description])
+		}
+	}
+
+	// The next undo reverses the earlier single settlement only
+	if err := s.UndoLastSettlement(); err != nil {
+		t.Fatal(err)
+	}
+	for _, tx := range s.GetDebtTransactions() {
+		if tx.ID == first.ID && tx.Amount != 300 {
+			t.Errorf("dinner amount %.2f after second undo, want 300", tx.Amount)
+		}
+	}
+	if err := s.UndoLastSettlement(); err == nil {
+		t.Error("undo with no settlements left succeeded")
+	}
+}
+
+func TestInvestmentValueHistory(t *testing.T) {
+	s := newTestStorage(t)
+	bought := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	inv, err := s.AddInvestment(models.InvestmentStocks, "Index fund", 1000, 1000, 0, bought, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateInvestmentValue(inv.ID, 1100); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateInvestment(inv.ID, 1500, 1650); err != nil {
+		t.Fatal(err)
+	}
+
+	var values []float64
+	for _, p := range s.GetInvestmentHistory(inv.ID) {
+		values = append(values, p.Value)
+	}
+	if want := []float64{1000, 1100, 1650}; !slices.Equal(values, want) {
+		t.Errorf("history = %v, want %v", values, want)
+	}
+	if h := s.GetInvestmentHistory(inv.ID); !h[0].Date.Equal(bought) {
+		t.Errorf("first point dated %v, want the purchase date", h[0].Date)
+	}
+	if h := s.GetInvestmentHistory("missing"); h != nil {
+		t.Errorf("unknown investment has history %v", h)
+	}
+}
+
+func TestInvestmentValueHistoryBackfill(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := config.DefaultConfig()
+	cfg.DataFile = filepath.Join(t.TempDir(), "data.json")
+	saved := `{"investments": [{"id": "old", "type": "gold", "name": "Coins", "invested_amount": 800, "current_value": 950, "purchase_date": "2023-02-01T00:00:00Z"}]}`
+	s, err := NewWithPersister(cfg, NewMemoryPersister([]byte(saved)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := s.GetInvestmentHistory("old")
+	if len(h) != 1 || h[0].Value != 800 || !h[0].Date.Equal(time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("backfilled history = %v, want one purchase point of 800", h)
+	}
+}
+
+func TestGetAllocation(t *testing.T) {
+	s := newTestStorage(t)
+	if got := s.GetAllocation(); len(got) != 0 {
+		t.Errorf("empty portfolio allocation = %v", got)
+	}
+
+	now := time.Now()
+	for _, inv := range []struct {
+		t     models.InvestmentType
+		value float64
+	}{
+		{models.InvestmentStocks, 300},
+		{models.InvestmentStocks, 150},
+		{models.InvestmentGold, 200},
+		{models.InvestmentMutualFunds, 350},
+	} {
+		if _, err := s.AddInvestment(inv.t, "x", inv.value, inv.value, 0, now, "", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := s.GetAllocation()
+	want := map[models.InvestmentType]float64{
+		models.InvestmentStocks:      0.45,
+		models.InvestmentGold:        0.20,
+		models.InvestmentMutualFunds: 0.35,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("allocation = %v, want %v", got, want)
+	}
+	for typ, share := range want {
+		if diff := got[typ] - share; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("%s share = %.4f, want %.2f", typ, got[typ], share)
+		}
+	}
+}
+
+func TestUpdateInvestmentDetails(t *testing.T) {
+	s := newTestStorage(t)
+	inv, err := s.AddInvestment(models.InvestmentStocks, "Gold ETF", 1000, 1200, 0, time.Now(), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.UpdateInvestmentDetails(inv.ID, "crypto-ish", "Gold ETF", ""); err == nil {
+		t.Error("unknown investment type accepted")
+	}
+	if err := s.UpdateInvestmentDetails(inv.ID, models.InvestmentGold, "  ", ""); err == nil {
+		t.Error("blank name accepted")
+	}
+	if err := s.UpdateInvestmentDetails("missing", models.InvestmentGold, "Gold ETF", ""); err == nil {
+		t.Error("unknown investment accepted")
+	}
+
+	if err := s.UpdateInvestmentDetails(inv.ID, models.InvestmentGold, " Gold BeES ", "moved from stocks"); err != nil {
+		t.Fatal(err)
+	}
+	got := s.GetInvestments()[0]
+	if got.Type != models.InvestmentGold || got.Name != "Gold BeES" || got.Notes != "moved from stocks" {
+		t.Errorf("details = %s %q %q", got.Type, got.Name, got.Notes)
+	}
+	if got.InvestedAmount != 1000 || got.CurrentValue != 1200 || !got.CreatedAt.Equal(inv.CreatedAt) {
+		t.Errorf("values or CreatedAt changed: %+v", got)
+	}
+}
+
+func TestWithdrawSavingsContribution(t *testing.T) {
+	s := newTestStorage(t)
+	goal, err := s.AddSavingsTarget("iPhone", 1000, time.Now().AddDate(0, 6, 0), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.AddSavingsContribution(goal.ID, 1000, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.WithdrawSavingsContribution(goal.ID, 1000.01, ""); err == nil {
+		t.Error("withdrawing more than was saved succeeded")
+	}
+	if err := s.WithdrawSavingsContribution(goal.ID, 0, ""); err == nil {
+		t.Error("withdrawing nothing succeeded")
+	}
+	if err := s.WithdrawSavingsContribution("missing", 10, ""); err == nil {
+		t.Error("withdrawing from an unknown goal succeeded")
+	}
+
+	if err := s.WithdrawSavingsContribution(goal.ID, 250, "car repair"); err != nil {
+		t.Fatal(err)
+	}
+	got := s.GetSavingsTargets()[0]
+	if got.CurrentAmount != 750 || got.IsCompleted || got.CompletedAt != nil {
+		t.Errorf("after withdrawal: saved %.2f, completed %v (%v); want 750 and not completed", got.CurrentAmount, got.IsCompleted, got.CompletedAt)
+	}
+	contributions := s.GetSavingsContributions(goal.ID)
+	if last := contributions[len(contributions)-1]; last.Amount != -250 || last.Notes != "car repair" {
+		t.Errorf("withdrawal recorded as %.2f %q, want -250 \"car repair\"", last.Amount, last.Notes)
+	}
+
+	// Taking out everything that is left leaves zero, not less
+	if err := s.WithdrawSavingsContribution(goal.ID, 750, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.GetSavingsTargets()[0].CurrentAmount; got != 0 {
+		t.Errorf("saved %.2f after emptying the goal, want 0", got)
+	}
+}
+
+func TestUpdateSavingsTarget(t *testing.T) {
+	s := newTestStorage(t)
+	goal, err := s.AddSavingsTarget("iPhone", 1000, time.Now().AddDate(0, 6, 0), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.AddSavingsContribution(goal.ID, 800, "bonus"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.UpdateSavingsTarget(goal.ID, " ", 900, goal.TargetDate, ""); err == nil {
+		t.Error("blank name accepted")
+	}
+	if err := s.UpdateSavingsTarget(goal.ID, "iPhone", 0, goal.TargetDate, ""); err == nil {
+		t.Error("zero target accepted")
+	}
+	if err := s.UpdateSavingsTarget("missing", "iPhone", 900, goal.TargetDate, ""); err == nil {
+		t.Error("unknown goal accepted")
+	}
+
+	// Lowering the target below what is saved completes the goal
+	newDate := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := s.UpdateSavingsTarget(goal.ID, "iPhone (refurbished)", 750, newDate, "cheaper model"); err != nil {
+		t.Fatal(err)
+	}
+	got := s.GetSavingsTargets()[0]
+	if got.ProductName != "iPhone (refurbished)" || got.TargetAmount != 750 || !got.TargetDate.Equal(newDate) || got.Description != "cheaper model" {
+		t.Errorf("edit not applied: %+v", got)
+	}
+	if !got.IsCompleted || got.CompletedAt == nil {
+		t.Error("goal with 800 saved towards 750 is not completed")
+	}
+	if got.CurrentAmount != 800 || !got.CreatedAt.Equal(goal.CreatedAt) || len(s.GetSavingsContributions(goal.ID)) != 1 {
+		t.Errorf("saved amount, CreatedAt or contributions changed: %+v", got)
+	}
+
+	// Raising it again reopens the goal
+	if err := s.UpdateSavingsTarget(goal.ID, "iPhone Pro", 1200, newDate, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.GetSavingsTargets()[0]; got.IsCompleted || got.CompletedAt != nil {
+		t.Error("goal with 800 saved towards 1200 is still completed")
+	}
+}
+
+func TestExportDebtsCSVOriginalAmount(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := config.DefaultConfig()
+	cfg.DataFile = filepath.Join(t.TempDir(), "data.json")
+	// A record from before original amounts were kept has none
+	saved := `{"debt_transactions": [{"id": "old", "type": "lent", "person_name": "ASHA", "amount": 250, "date": "2024-05-01T00:00:00Z"}]}`
+	s, err := NewWithPersister(cfg, NewMemoryPersister([]byte(saved)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := s.AddDebtTransaction(models.Lent, "Ravi", 400, "rent", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SettleTransactionWithNote(tx.ID, 100, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf strings.Builder
+	if err := s.ExportDebtsCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"ASHA,,250.00,250.00,", "RAVI,rent,400.00,300.00,"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("debts CSV is missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+// fillTestData records at least one of every kind of record in s
+func fillTestData(t *testing.T, s *Storage) {
+	t.Helper()
+	day := time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC)
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := s.AddExpense(120, "Lunch", models.CategoryFood, day, "", []string{"work"}, "with team")
+	must(err)
+	_, err = s.AddRecurringExpense(999, "Internet", models.CategoryUtilities, 5)
+	must(err)
+	tx, err := s.AddDebtTransaction(models.Lent, "Asha", 500, "rent", day, nil)
+	must(err)
+	must(s.SettleTransactionWithNote(tx.ID, 200, "part"))
+	inv, err := s.AddInvestment(models.InvestmentStocks, "Index", 1000, 1100, 2, day, "", "")
+	must(err)
+	_, err = s.AddInvestmentIncome(inv.ID, 15, models.IncomeDividend, day, "")
+	must(err)
+	goal, err := s.AddSavingsTarget("Bike", 5000, day.AddDate(1, 0, 0), "")
+	must(err)
+	_, _, err = s.AddSavingsContribution(goal.ID, 700, "")
+	must(err)
+}
+
+func TestJSONReplaceRoundTrip(t *testing.T) {
+	src := newTestStorage(t)
+	fillTestData(t, src)
+	var exported bytes.Buffer
+	if err := src.ExportJSON(&exported); err != nil {
+		t.Fatal(err)
+	}
+
+	// Replace discards whatever the destination held
+	dst := newTestStorage(t)
+	if _, err := dst.AddExpense(5, "Gum", models.CategoryOther, time.Now(), "", nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.ImportJSON(bytes.NewReader(exported.Bytes()), MergeReplace); err != nil {
+		t.Fatal(err)
+	}
+	var reexported bytes.Buffer
+	if err := dst.ExportJSON(&reexported); err != nil {
+		t.Fatal(err)
+	}
+	if reexported.String() != exported.String() {
+		t.Errorf("round trip changed the data:\nexported:\n%s\nafter import:\n%s", exported.String(), reexported.String())
+	}
+
+	// Merging the same backup back in adds nothing
+	if err := dst.ImportJSON(bytes.NewReader(exported.Bytes()), MergeAppend); err != nil {
+		t.Fatal(err)
+	}
+	reexported.Reset()
+	if err := dst.ExportJSON(&reexported); err != nil {
+		t.Fatal(err)
+	}
+	if reexported.String() != exported.String() {
+		t.Errorf("merging a backup of the same data changed it:\n%s", reexported.String())
+	}
+}
+
+func TestImportJSONRejectsBadInput(t *testing.T) {
+	s := newTestStorage(t)
+	fillTestData(t, s)
+	before := s.Revision()
+
+	if err := s.ImportJSON(strings.NewReader(`{"expenses": []}`), "overwrite"); err == nil {
+		t.Error("unknown merge mode accepted")
+	}
+	if err := s.ImportJSON(strings.NewReader(`{"not_debtq": true}`), MergeReplace); err == nil {
+		t.Error("JSON that is not debtq data accepted")
+	}
+	if err := s.ImportJSON(strings.NewReader(`{"expenses": [`), MergeReplace); err == nil {
+		t.Error("truncated JSON accepted")
+	}
+	if s.Revision() != before || len(s.GetExpenses()) != 1 {
+		t.Errorf("rejected imports changed the data")
+	}
+}
+
+func TestMemoryPersisterKeepsCopies(t *testing.T) {
+	p := NewMemoryPersister(nil)
+	if _, err := p.Load(); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Load before any save: %v, want fs.ErrNotExist", err)
+	}
+
+	saved := []byte(`{"v": 1}`)
+	if err := p.Save(saved); err != nil {
+		t.Fatal(err)
+	}
+	saved[6] = '2'
+	loaded, err := p.Load()
+	if err != nil || string(loaded) != `{"v": 1}` {
+		t.Fatalf("Load = %q, %v; want the data as saved", loaded, err)
+	}
+	loaded[6] = '3'
+	if again, _ := p.Load(); string(again) != `{"v": 1}` {
+		t.Errorf("changing what Load returned changed the saved data to %q", again)
+	}
+}
+
+func TestMemoryStorageLeavesDataFileAlone(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := config.DefaultConfig()
+	cfg.DataFile = filepath.Join(t.TempDir(), "data.json")
+	fp := NewFilePersister(cfg)
+	if err := fp.Save([]byte(`{"expenses": []}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// As --no-save opens it: a copy of the data file in memory
+	raw, err := fp.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewMemoryPersister(raw)
+	s, err := NewWithPersister(cfg, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddExpense(120, "Coffee", models.CategoryFood, time.Now(), "", nil, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, _ := os.ReadFile(cfg.DataFile); string(data) != `{"expenses": []}` {
+		t.Errorf("data file = %q, want it untouched", data)
+	}
+	reopened, err := NewWithPersister(cfg, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reopened.GetExpenses(); len(got) != 1 || got[0].Description != "Coffee" {
+		t.Errorf("reopened from the same MemoryPersister: %+v, want the saved expense", got)
+	}
+}
+
+func TestFileStorageStartsEmptyWithoutDataFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := config.DefaultConfig()
+	cfg.DataFile = filepath.Join(t.TempDir(), "new", "data.json")
+	if _, err := NewFilePersister(cfg).Load(); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Load of a missing file: %v, want fs.ErrNotExist", err)
+	}
+
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.GetExpenses()) != 0 {
+		t.Fatalf("new storage has %d expenses", len(s.GetExpenses()))
+	}
+	if _, err := s.AddExpense(120, "Coffee", models.CategoryFood, time.Now(), "", nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(cfg.DataFile); err != nil || !bytes.Contains(data, []byte("Coffee")) {
+		t.Errorf("data file after adding an expense: %q, %v", data, err)
+	}
+}
+
+func TestSaveAlongsideRevision(t *testing.T) {
+	s := newTestStorage(t)
+	const n = 50
+	var wg sync.WaitGroup
+	wg.Add(3)
+	for w := 0; w < 2; w++ {
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				if err := s.Save(); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			s.Revision()
+		}
+	}()
+	wg.Wait()
+
+	if got := s.Revision(); got != 2*n {
+		t.Errorf("Revision() = %d after %d saves", got, 2*n)
+	}
+}
+
+func TestArchiveBeforeSplitsByYear(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := config.DefaultConfig()
+	cfg.DataFile = filepath.Join(t.TempDir(), "data.json")
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, year := range []int{2022, 2023, 2023, 2024, 2026} {
+		if _, err := s.AddExpense(100, fmt.Sprint(year), models.CategoryFood, time.Date(year, 6, 1, 0, 0, 0, 0, time.Local), "", nil, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lent, _ := s.AddDebtTransaction(models.Lent, "Asha", 500, "rent", time.Date(2023, 3, 1, 0, 0, 0, 0, time.Local), nil)
+	if err := s.SettleTransactionWithNote(lent.ID, 200, "part"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SettleTransactionWithNote(lent.ID, 0, ""); err != nil {
+		t.Fatal(err)
+	}
+	for i := range s.data.DebtTransactions {
+		settled := time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local)
+		s.data.DebtTransactions[i].SettledDate = &settled
+	}
+
+	paths, err := s.ArchiveBefore(time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 3 || filepath.Base(paths[0]) != "data-2022.json" || filepath.Base(paths[2]) != "data-2024.json" {
+		t.Fatalf("ArchiveBefore wrote %v, want data-2022.json to data-2024.json", paths)
+	}
+	if names, _ := s.ListArchives(); !slices.Equal(names, []string{"data-2024.json", "data-2023.json", "data-2022.json"}) {
+		t.Errorf("ListArchives() = %v", names)
+	}
+
+	// Each file holds only its own year; the debt goes with the year it was lent
+	want := map[string]struct{ expenses, debts, settlements int }{
+		"data-2022.json": {1, 0, 0},
+		"data-2023.json": {2, 1, 2},
+		"data-2024.json": {1, 0, 0},
+	}
+	for name, w := range want {
+		archive, err := s.LoadArchive(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		year := strings.TrimSuffix(strings.TrimPrefix(name, "data-"), ".json")
+		for _, exp := range archive.Expenses {
+			if exp.Description != year {
+				t.Errorf("%s holds the expense from %s", name, exp.Description)
+			}
+		}
+		if len(archive.Expenses) != w.expenses || len(archive.DebtTransactions) != w.debts || len(archive.Settlements) != w.settlements {
+			t.Errorf("%s: %d expenses, %d debts, %d settlements; want %+v", name, len(archive.Expenses), len(archive.DebtTransactions), len(archive.Settlements), w)
+		}
+	}
+
+	rollups := s.GetData().Archives
+	if len(rollups) != 3 || rollups[1].File != "data-2023.json" || rollups[1].Expenses != 2 || rollups[1].ExpenseTotal != 200 {
+		t.Errorf("rollups = %+v, want one per file", rollups)
+	}
+	if got := len(s.GetExpenses()); got != 1 {
+		t.Errorf("%d expenses left live, want the 2026 one", got)
+	}
+}
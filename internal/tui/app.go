@@ -2,6 +2,9 @@ package tui
 
 import (
 	"fmt"
+	"os"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -20,55 +23,137 @@ type View int
 
 const (
 	ViewMain View = iota
+	ViewDigest
 	ViewExpenses
 	ViewAddExpense
+	ViewQuickAddExpense
+	ViewImportExpenses
+	ViewExpenseRange
+	ViewExpenseDetail
+	ViewEditExpenseNotes
+	ViewRecurring
+	ViewAddRecurring
+	ViewRecurringEndDate
+	ViewExportCSV
+	ViewArchives
+	ViewArchiveBefore
+	ViewArchive
 	ViewDebts
 	ViewAddDebt
 	ViewSettleDebt
+	ViewSettlePerson
+	ViewSplitBill
 	ViewSelectTransaction
+	ViewEditDebt
 	ViewSettlementHistory
 	ViewPersonHistory
+	ViewPeople
+	ViewBalancesTable
+	ViewMissingDueDates
+	ViewDueDebts
+	ViewDebtsByReason
+	ViewRenamePerson
+	ViewSetDueDate
 	ViewNetWorth
+	ViewNetWorthHistory
 	ViewAddInvestment
 	ViewUpdateInvestment
-	ViewConfirmDelete
+	ViewInvestmentDetail
+	ViewEditInvestmentNotes
+	ViewInvestmentIncome
+	ViewAddInvestmentIncome
+	ViewConfirm
 	ViewSavings
 	ViewAddSavingsTarget
+	ViewEditSavingsTarget
+	ViewGoalTemplates
 	ViewAddContribution
+	ViewSavingsHistory
 	ViewStats
 	ViewSettings
+	ViewCommandPalette
 )
 
+// gainDisplay selects how investment gains are shown in Net Worth
+type gainDisplay int
+
+const (
+	gainBoth gainDisplay = iota
+	gainAbsolute
+	gainPercent
+)
+
+// next cycles both → absolute → percent → both
+func (g gainDisplay) next() gainDisplay {
+	return (g + 1) % 3
+}
+
 // Model is the main application model
 type Model struct {
 	config         *config.Config
 	storage        *storage.Storage
 	obsidian       *storage.ObsidianWriter
+	autoSync       *autoSync
 	currentView    View
-	previousView   View
+	viewStack      []View // Views to return to on Esc, innermost last; ViewMain when empty
 	cursor         int
+	offset         int // First visible row of scrolling lists
 	inputs         []textinput.Model
 	focusIndex     int
 	message        string
-	messageType    string // "success", "error", "info"
+	messageType    string // "success", "error", "warning", "info"
 	selectedID     string
 	selectedPerson string
-	selectedTxID   string // For tracking selected transaction during settlement
+	selectedTxID   string        // For tracking selected transaction during settlement
+	pendingSettle  string        // Transaction awaiting confirmation for quick full settle
+	duplicateID    string        // Expense just flagged as a likely double entry, removable with u until the next key
+	duplicateStash roundUp       // Round-up stashed for duplicateID, given back if it is removed
+	withdrawing    bool          // Whether the contribution form takes money out of the goal
+	confirm        *confirmation // Action awaiting a yes in ViewConfirm
+	applyExcluded  bool          // Whether config.ExcludedCategories are left out of totals (session toggle)
+	gainDisplay    gainDisplay
+	topAllTime     bool   // Whether Stats ranks categories over all time instead of this month ("c")
+	showHelp       bool   // Whether the "?" keybinding overlay covers the current view
+	helpOffset     int    // First visible line of the help overlay
+	expenseFilter  string // Expenses list filter ("/"), matched against description and category
+	expenseFrom    time.Time
+	expenseTo      time.Time    // Expenses list date range ("t"), inclusive; zero when unset
+	archive        *models.Data // Archive open in ViewArchive, read-only
+	archiveName    string
+	archiveDebts   bool // Whether ViewArchive lists settled debts instead of expenses
 	width          int
 	height         int
 }
 
 // New creates a new TUI model
 func New(cfg *config.Config, store *storage.Storage) *Model {
-	return &Model{
-		config:      cfg,
-		storage:     store,
-		obsidian:    storage.NewObsidianWriter(cfg),
-		currentView: ViewMain,
-		cursor:      0,
-		width:       80,
-		height:      24,
+	m := &Model{
+		config:        cfg,
+		storage:       store,
+		obsidian:      storage.NewObsidianWriter(cfg),
+		currentView:   ViewMain,
+		cursor:        0,
+		applyExcluded: true,
+		width:         80,
+		height:        24,
+	}
+	if notice := store.Notice(); notice != "" {
+		m.message = notice
+		m.messageType = "warning"
+	}
+	// Keep the daily net worth history going without the user pressing s
+	if len(store.GetInvestments()) > 0 {
+		if _, _, err := store.RecordNetWorthSnapshot(); err != nil {
+			m.message = "Error saving net worth snapshot: " + err.Error()
+			m.messageType = "error"
+		}
 	}
+	m.autoSync = &autoSync{synced: store.Revision()}
+	// A recovery notice matters more than the digest, which would hide it
+	if m.messageType != "warning" && cfg.StartupDigestEnabled() && len(m.startupDigest()) > 0 {
+		m.currentView = ViewDigest
+	}
+	return m
 }
 
 // Init implements tea.Model
@@ -78,7 +163,16 @@ func (m Model) Init() tea.Cmd {
 
 // Update implements tea.Model
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	return next, tea.Batch(cmd, m.scheduleAutoSync())
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case autoSyncMsg:
+		m.runAutoSync(msg)
+		return m, nil
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -90,54 +184,157 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.message != "" && m.messageType != "calc" && keyStr != "enter" {
 			m.message = ""
 		}
+		// A duplicate warning only offers u on the very next key
+		if m.duplicateID != "" && (keyStr != "u" || m.currentView != ViewExpenses) {
+			m.duplicateID = ""
+			m.duplicateStash = roundUp{}
+		}
+		// Any key other than a repeated "f" cancels a pending quick settle
+		if keyStr != "f" {
+			m.pendingSettle = ""
+		}
+
+		if m.showHelp {
+			return m.updateHelp(msg)
+		}
+		// "?" is a normal character while typing into a text input
+		if keyStr == "?" && len(m.inputs) == 0 {
+			m.showHelp = true
+			m.helpOffset = 0
+			return m, nil
+		}
 
 		switch keyStr {
-		case "ctrl+c", "q":
+		case "ctrl+c":
+			return m, tea.Quit
+		case "q":
+			// "q" is a normal character while typing into a text input
+			if len(m.inputs) > 0 {
+				break
+			}
 			if m.currentView == ViewMain {
 				return m, tea.Quit
 			}
-			m.currentView = ViewMain
+			m.resetView()
 			m.cursor = 0
-			m.inputs = nil
 			return m, nil
+		case "ctrl+k":
+			// Command palette is available from any view except forms, so form input isn't lost
+			if len(m.inputs) == 0 {
+				m.openPalette()
+				return m, nil
+			}
+		}
+
+		// Keys in the registry (keymap); while typing, keys go to the input instead
+		if len(m.inputs) == 0 {
+			if b := boundKey(m.currentView, keyStr); b != nil {
+				return m, m.runKey(b)
+			}
 		}
 
 		// Handle view-specific updates
 		switch m.currentView {
+		case ViewDigest:
+			// Any key dismisses the digest
+			m.resetView()
+			return m, nil
 		case ViewMain:
 			return m.updateMainView(msg)
 		case ViewExpenses:
 			return m.updateExpensesView(msg)
 		case ViewAddExpense:
 			return m.updateAddExpenseView(msg)
+		case ViewQuickAddExpense:
+			return m.updateQuickAddExpenseView(msg)
+		case ViewImportExpenses:
+			return m.updateImportExpensesView(msg)
+		case ViewExpenseRange:
+			return m.updateExpenseRangeView(msg)
+		case ViewExpenseDetail:
+			return m.updateExpenseDetailView(msg)
+		case ViewEditExpenseNotes:
+			return m.updateEditExpenseNotesView(msg)
+		case ViewRecurring:
+			return m.updateRecurringView(msg)
+		case ViewAddRecurring:
+			return m.updateAddRecurringView(msg)
+		case ViewRecurringEndDate:
+			return m.updateRecurringEndDateView(msg)
+		case ViewExportCSV:
+			return m.updateExportCSVView(msg)
+		case ViewArchives:
+			return m.updateArchivesView(msg)
+		case ViewArchiveBefore:
+			return m.updateArchiveBeforeView(msg)
+		case ViewArchive:
+			return m.updateArchiveView(msg)
 		case ViewDebts:
 			return m.updateDebtsView(msg)
 		case ViewAddDebt:
 			return m.updateAddDebtView(msg)
 		case ViewSettleDebt:
 			return m.updateSettleDebtView(msg)
+		case ViewSettlePerson:
+			return m.updateSettlePersonView(msg)
+		case ViewSplitBill:
+			return m.updateSplitBillView(msg)
 		case ViewSelectTransaction:
 			return m.updateSelectTransactionView(msg)
+		case ViewEditDebt:
+			return m.updateEditDebtView(msg)
 		case ViewSettlementHistory:
 			return m.updateSettlementHistoryView(msg)
 		case ViewPersonHistory:
 			return m.updatePersonHistoryView(msg)
+		case ViewPeople:
+			return m.updatePeopleView(msg)
+		case ViewBalancesTable:
+			return m.updateBalancesTableView(msg)
+		case ViewMissingDueDates:
+			return m.updateMissingDueDatesView(msg)
+		case ViewDueDebts:
+			return m.updateDueDebtsView(msg)
+		case ViewDebtsByReason:
+			return m.updateDebtsByReasonView(msg)
+		case ViewRenamePerson:
+			return m.updateRenamePersonView(msg)
+		case ViewSetDueDate:
+			return m.updateSetDueDateView(msg)
 		case ViewNetWorth:
 			return m.updateNetWorthView(msg)
+		case ViewNetWorthHistory:
+			return m.updateNetWorthHistoryView(msg)
 		case ViewAddInvestment:
 			return m.updateAddInvestmentView(msg)
 		case ViewUpdateInvestment:
 			return m.updateUpdateInvestmentView(msg)
-		case ViewConfirmDelete:
-			return m.updateConfirmDeleteView(msg)
+		case ViewInvestmentDetail:
+			return m.updateInvestmentDetailView(msg)
+		case ViewEditInvestmentNotes:
+			return m.updateEditInvestmentNotesView(msg)
+		case ViewInvestmentIncome:
+			return m.updateInvestmentIncomeView(msg)
+		case ViewAddInvestmentIncome:
+			return m.updateAddInvestmentIncomeView(msg)
+		case ViewConfirm:
+			return m.updateConfirmView(msg)
 		case ViewSavings:
 			return m.updateSavingsView(msg)
-		case ViewAddSavingsTarget:
+		case ViewAddSavingsTarget, ViewEditSavingsTarget:
 			return m.updateAddSavingsTargetView(msg)
+		case ViewGoalTemplates:
+			return m.updateGoalTemplatesView(msg)
 		case ViewAddContribution:
 			return m.updateAddContributionView(msg)
+		case ViewSavingsHistory:
+			return m.updateSavingsHistoryView(msg)
 		case ViewStats:
 			return m.updateStatsView(msg)
+		case ViewSettings:
+			return m.updateSettingsView(msg)
+		case ViewCommandPalette:
+			return m.updateCommandPaletteView(msg)
 		}
 	}
 
@@ -155,43 +352,111 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // View implements tea.Model
 func (m Model) View() string {
+	if m.showHelp {
+		return m.viewHelp()
+	}
+
 	var content string
 
 	switch m.currentView {
+	case ViewDigest:
+		content = m.viewDigest()
 	case ViewMain:
 		content = m.viewMain()
 	case ViewExpenses:
 		content = m.viewExpenses()
 	case ViewAddExpense:
 		content = m.viewAddExpense()
+	case ViewQuickAddExpense:
+		content = m.viewQuickAddExpense()
+	case ViewImportExpenses:
+		content = m.viewImportExpenses()
+	case ViewExpenseRange:
+		content = m.viewExpenseRange()
+	case ViewExpenseDetail:
+		content = m.viewExpenseDetail()
+	case ViewEditExpenseNotes:
+		content = m.viewEditExpenseNotes()
+	case ViewRecurring:
+		content = m.viewRecurring()
+	case ViewArchives:
+		content = m.viewArchives()
+	case ViewArchiveBefore:
+		content = m.viewArchiveBefore()
+	case ViewArchive:
+		content = m.viewArchive()
+	case ViewAddRecurring:
+		content = m.viewAddRecurring()
+	case ViewRecurringEndDate:
+		content = m.viewRecurringEndDate()
+	case ViewExportCSV:
+		content = m.viewExportCSV()
 	case ViewDebts:
 		content = m.viewDebts()
 	case ViewAddDebt:
 		content = m.viewAddDebt()
 	case ViewSettleDebt:
 		content = m.viewSettleDebt()
+	case ViewSettlePerson:
+		content = m.viewSettlePerson()
+	case ViewSplitBill:
+		content = m.viewSplitBill()
 	case ViewSelectTransaction:
 		content = m.viewSelectTransaction()
+	case ViewEditDebt:
+		content = m.viewEditDebt()
 	case ViewSettlementHistory:
 		content = m.viewSettlementHistory()
 	case ViewPersonHistory:
 		content = m.viewPersonHistory()
+	case ViewPeople:
+		content = m.viewPeople()
+	case ViewBalancesTable:
+		content = m.viewBalancesTable()
+	case ViewMissingDueDates:
+		content = m.viewMissingDueDates()
+	case ViewDueDebts:
+		content = m.viewDueDebts()
+	case ViewDebtsByReason:
+		content = m.viewDebtsByReason()
+	case ViewRenamePerson:
+		content = m.viewRenamePerson()
+	case ViewSetDueDate:
+		content = m.viewSetDueDate()
 	case ViewNetWorth:
 		content = m.viewNetWorth()
+	case ViewNetWorthHistory:
+		content = m.viewNetWorthHistory()
 	case ViewAddInvestment:
 		content = m.viewAddInvestment()
 	case ViewUpdateInvestment:
 		content = m.viewUpdateInvestment()
-	case ViewConfirmDelete:
-		content = m.viewConfirmDelete()
+	case ViewInvestmentDetail:
+		content = m.viewInvestmentDetail()
+	case ViewEditInvestmentNotes:
+		content = m.viewEditInvestmentNotes()
+	case ViewInvestmentIncome:
+		content = m.viewInvestmentIncome()
+	case ViewAddInvestmentIncome:
+		content = m.viewAddInvestmentIncome()
+	case ViewConfirm:
+		content = m.viewConfirm()
 	case ViewSavings:
 		content = m.viewSavings()
-	case ViewAddSavingsTarget:
+	case ViewAddSavingsTarget, ViewEditSavingsTarget:
 		content = m.viewAddSavingsTarget()
+	case ViewGoalTemplates:
+		content = m.viewGoalTemplates()
 	case ViewAddContribution:
 		content = m.viewAddContribution()
+	case ViewSavingsHistory:
+		content = m.viewSavingsHistory()
 	case ViewStats:
 		content = m.viewStats()
+	case ViewSettings:
+		content = m.viewSettings()
+	case ViewCommandPalette:
+		content = m.viewCommandPalette()
 	default:
 		content = m.viewMain()
 	}
@@ -204,6 +469,8 @@ func (m Model) View() string {
 			msgStyle = SuccessStyle
 		case "error":
 			msgStyle = ErrorStyle
+		case "warning":
+			msgStyle = WarningStyle
 		default:
 			msgStyle = MutedStyle
 		}
@@ -218,34 +485,24 @@ func (m Model) viewMain() string {
 	title := TitleStyle.Render("  DebtQ - Personal Money Tracker")
 	subtitle := SubtitleStyle.Render("Track expenses, debts, investments & savings goals")
 
-	menuItems := []string{
-		"Expenses",
-		"Borrowing & Lending",
-		"My Net Worth",
-		"Savings Goals",
-		"Stats & Dashboard",
-		"Sync to Obsidian",
-		"Quit",
-	}
-
 	menu := "\n"
-	for i, item := range menuItems {
+	for i, item := range mainMenu() {
 		cursor := "  "
 		style := MenuItemStyle
 		if i == m.cursor {
 			cursor = "▸ "
 			style = SelectedMenuItemStyle
 		}
-		menu += style.Render(cursor+item) + "\n"
+		menu += style.Render(cursor+item.name) + "\n"
 	}
 
-	help := HelpStyle.Render("↑/↓: Navigate • Enter: Select • q: Quit")
+	help := HelpStyle.Render("↑/↓: Navigate • Enter: Select • d: Due debts • e: Export CSV • ctrl+k: Commands • ?: Help • q: Quit")
 
-	return BoxStyle.Render(title + "\n" + subtitle + menu + "\n" + help)
+	return BoxStyle.Render(title + "\n" + subtitle + m.dueReminder() + menu + m.budgetLine() + "\n" + help)
 }
 
 func (m *Model) updateMainView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	menuLen := 7
+	menu := mainMenu()
 
 	switch msg.String() {
 	case "up", "k":
@@ -253,90 +510,203 @@ func (m *Model) updateMainView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.cursor--
 		}
 	case "down", "j":
-		if m.cursor < menuLen-1 {
+		if m.cursor < len(menu)-1 {
 			m.cursor++
 		}
 	case "enter":
-		switch m.cursor {
-		case 0:
-			m.currentView = ViewExpenses
-			m.cursor = 0
-		case 1:
-			m.currentView = ViewDebts
-			m.cursor = 0
-		case 2:
-			m.currentView = ViewNetWorth
-			m.cursor = 0
-		case 3:
-			m.currentView = ViewSavings
-			m.cursor = 0
-		case 4:
-			m.currentView = ViewStats
-			m.cursor = 0
-		case 5:
-			// Sync to Obsidian
-			if err := m.obsidian.SyncAllNotes(m.storage.GetData()); err != nil {
-				m.message = "Error syncing: " + err.Error()
-				m.messageType = "error"
-			} else {
-				m.message = "Successfully synced to Obsidian!"
-				m.messageType = "success"
-			}
-		case 6:
-			return m, tea.Quit
+		if item, ok := at(menu, m.cursor); ok {
+			return m, item.run(m)
 		}
 	}
 
 	return m, nil
 }
 
+// Digest view - shown once on startup when something needs attention
+func (m Model) viewDigest() string {
+	title := TitleStyle.Render("  Needs Your Attention")
+
+	content := "\n"
+	for _, item := range m.startupDigest() {
+		content += "  • " + item + "\n"
+	}
+
+	help := HelpStyle.Render("\n  Press any key to continue")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+// startupDigest lists overdue debts, savings goals past their date, an exceeded
+// monthly budget and older debts still missing a due date
+func (m Model) startupDigest() []string {
+	var items []string
+
+	for _, tx := range m.storage.GetOverdueDebts() {
+		who := "owes you"
+		if tx.Type == models.Borrowed {
+			who = "you owe"
+		}
+		items = append(items, fmt.Sprintf("%s %s %s - %s",
+			SelectedMenuItemStyle.Render(tx.PersonName),
+			who,
+			m.formatAmountPlain(tx.Amount, m.currencyOf(tx.Currency)),
+			ErrorStyle.Render("overdue since "+tx.DueDate.Format("2006-01-02")),
+		))
+	}
+
+	for _, target := range m.storage.GetActiveSavingsTargets() {
+		if target.DaysRemaining() < 0 {
+			items = append(items, fmt.Sprintf("Goal %s passed its date (%s) at %s of %s",
+				SelectedMenuItemStyle.Render(target.ProductName),
+				target.TargetDate.Format("2006-01-02"),
+				m.formatAmountPlain(target.CurrentAmount, m.config.Currency),
+				m.formatAmountPlain(target.TargetAmount, m.config.Currency),
+			))
+		}
+	}
+
+	if spent, budget := m.storage.CurrentMonthSpend(); budgetLevel(spent, budget) == "over" {
+		items = append(items, ErrorStyle.Render(fmt.Sprintf("Monthly budget exceeded: %s spent of %s",
+			m.formatAmountPlain(spent, m.config.Currency),
+			m.formatAmountPlain(budget, m.config.Currency),
+		)))
+	}
+
+	if missing := len(m.storage.DebtsMissingDueDate()); missing > 0 {
+		items = append(items, WarningStyle.Render(fmt.Sprintf("%d older debt(s) without a due date", missing)))
+	}
+
+	return items
+}
+
 // Expenses view
 func (m Model) viewExpenses() string {
 	title := TitleStyle.Render("  Expenses")
+	if !m.expenseFrom.IsZero() {
+		title = TitleStyle.Render(fmt.Sprintf("  Expenses (%s → %s)", m.expenseFrom.Format("2006-01-02"), m.expenseTo.Format("2006-01-02")))
+	}
 
-	expenses := m.storage.GetExpenses()
+	expenses := m.listedExpenses()
 
 	var content string
+	if len(m.inputs) > 0 {
+		content = "\n  / " + m.inputs[0].View() + "\n"
+	} else if m.expenseFilter != "" {
+		content = "\n  " + MutedStyle.Render(fmt.Sprintf("Filter: %q", m.expenseFilter)) + "\n"
+	}
+
 	if len(expenses) == 0 {
-		content = MutedStyle.Render("\n  No expenses recorded yet.\n")
-	} else {
-		content = "\n"
-		// Show last 10 expenses
-		start := 0
-		if len(expenses) > 10 {
-			start = len(expenses) - 10
+		if m.expenseFilter != "" {
+			content += MutedStyle.Render("\n  No expenses match the filter.\n")
+		} else {
+			content += MutedStyle.Render("\n  No expenses recorded yet.\n")
 		}
-		for i := len(expenses) - 1; i >= start; i-- {
-			exp := expenses[i]
+	} else {
+		content += "\n"
+		// Cursor, date, amount and separators take 34 characters; description and category share the rest
+		widths := distributeWidths(m.contentWidth()-34, []columnSpec{{Min: 15, Max: 60}, {Min: 12, Max: 16}})
+		// Rows are newest first; row r maps to expenses[len-1-r]
+		start, end := visibleWindow(m.offset, m.cursor, m.listPageSize(), len(expenses))
+		for row := start; row < end; row++ {
+			exp := expenseAtRow(expenses, row)
 			cursor := "  "
-			if i-start == m.cursor {
+			if row == m.cursor {
 				cursor = "▸ "
 			}
+			description := exp.Description
+			if len(exp.Tags) > 0 {
+				description += " " + formatTags(exp.Tags)
+			}
 			line := fmt.Sprintf("%s%s  %s  %s  %s",
 				cursor,
 				exp.Date.Format("2006-01-02"),
-				TableCellStyle.Width(15).Render(truncate(exp.Description, 15)),
-				TableCellStyle.Width(12).Render(string(exp.Category)),
-				FormatAmount(exp.Amount, m.config.Currency),
+				TableCellStyle.Width(widths[0]).Render(truncate(description, widths[0]-2)),
+				TableCellStyle.Width(widths[1]).Render(string(exp.Category)),
+				m.formatAmount(exp.Amount, m.currencyOf(exp.Currency)),
 			)
 			content += line + "\n"
 		}
+		content += MutedStyle.Render(fmt.Sprintf("  showing %d–%d of %d", start+1, end, len(expenses))) + "\n"
 	}
 
 	// Calculate totals
 	data := m.storage.GetData()
 	now := time.Now()
-	monthlyTotal := data.MonthlyExpenses(now.Year(), now.Month())
-
-	stats := fmt.Sprintf("\n  This Month: %s", FormatAmountPlain(monthlyTotal, m.config.Currency))
+	monthlyTotal := data.MonthlyExpensesExcluding(now.Year(), now.Month(), m.excludedCategories())
+
+	stats := fmt.Sprintf("\n  This Month: %s%s", m.formatAmountPlain(monthlyTotal, m.config.Currency), m.exclusionLabel())
+	stats += m.budgetLine()
+	if m.expenseFilter != "" || !m.expenseFrom.IsZero() {
+		var matched float64
+		for _, exp := range expenses {
+			matched += exp.Amount
+		}
+		label := "Matching:  "
+		if m.expenseFilter == "" {
+			label = "In Range:  "
+		}
+		stats += fmt.Sprintf("\n  %s %s", label, m.formatAmountPlain(matched, m.config.Currency))
+	}
 
-	help := HelpStyle.Render("\n  a: Add expense • d: Delete • Enter: Details • Esc: Back")
+	help := HelpStyle.Render("\n  a: Add expense • A: Quick add • .: Repeat last • /: Filter • t: Date range • r: Recurring • z: Archives • i: Import CSV • d: Delete • Enter: Details • x: Toggle exclusions • Esc: Back")
+	if len(m.inputs) > 0 {
+		help = HelpStyle.Render("\n  Type to filter • Enter: Apply • Esc: Clear filter")
+	}
 
 	return BoxStyle.Render(title + content + stats + help)
 }
 
-func (m *Model) updateExpensesView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+// listedExpenses returns the expenses shown in the Expenses list, honouring the filter
+func (m Model) listedExpenses() []models.Expense {
 	expenses := m.storage.GetExpenses()
+	if !m.expenseFrom.IsZero() {
+		expenses = m.storage.GetExpensesBetween(m.expenseFrom, m.expenseTo)
+	}
+	if m.expenseFilter == "" {
+		return expenses
+	}
+
+	// "#tag" matches that tag exactly; anything else is a substring search
+	matches := m.storage.FilterExpenses(m.expenseFilter)
+	if strings.HasPrefix(m.expenseFilter, "#") {
+		matches = m.storage.GetExpensesByTag(m.expenseFilter)
+	}
+	matching := make(map[string]bool)
+	for _, exp := range matches {
+		matching[exp.ID] = true
+	}
+	var filtered []models.Expense
+	for _, exp := range expenses {
+		if matching[exp.ID] {
+			filtered = append(filtered, exp)
+		}
+	}
+	return filtered
+}
+
+func (m *Model) updateExpensesView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Typing into the inline filter
+	if len(m.inputs) > 0 {
+		switch msg.String() {
+		case "enter":
+			m.inputs = nil
+		case "esc":
+			m.inputs = nil
+			m.expenseFilter = ""
+		default:
+			var cmd tea.Cmd
+			m.inputs[0], cmd = m.inputs[0].Update(msg)
+			m.expenseFilter = strings.TrimSpace(m.inputs[0].Value())
+			m.cursor = 0
+			m.offset = 0
+			return m, cmd
+		}
+		m.cursor = 0
+		m.offset = 0
+		return m, nil
+	}
+
+	expenses := m.listedExpenses()
 	maxCursor := len(expenses) - 1
 	if maxCursor < 0 {
 		maxCursor = 0
@@ -351,347 +721,158 @@ func (m *Model) updateExpensesView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.cursor < maxCursor {
 			m.cursor++
 		}
-	case "a":
-		m.currentView = ViewAddExpense
-		m.initExpenseInputs()
-	case "d":
-		if len(expenses) > 0 {
-			idx := len(expenses) - 1 - m.cursor
-			if idx >= 0 && idx < len(expenses) {
-				m.storage.DeleteExpense(expenses[idx].ID)
-				m.message = "Expense deleted"
-				m.messageType = "success"
-				if m.cursor > 0 {
-					m.cursor--
-				}
-			}
+	case "g", "G", "ctrl+d", "ctrl+u":
+		m.cursor = m.jumpCursor(msg.String(), m.cursor, maxCursor)
+	case "enter":
+		if exp := expenseAtRow(expenses, m.cursor); exp != nil {
+			m.selectedID = exp.ID
+			m.pushView(ViewExpenseDetail)
 		}
 	case "esc":
-		m.currentView = ViewMain
+		if m.expenseFilter != "" {
+			m.expenseFilter = ""
+			m.cursor = 0
+			break
+		}
+		if !m.expenseFrom.IsZero() {
+			m.expenseFrom, m.expenseTo = time.Time{}, time.Time{}
+			m.cursor = 0
+			break
+		}
+		m.popView()
 		m.cursor = 0
 	}
 
+	m.offset, _ = visibleWindow(m.offset, m.cursor, m.listPageSize(), len(m.listedExpenses()))
 	return m, nil
 }
 
-func (m *Model) initExpenseInputs() {
-	m.inputs = make([]textinput.Model, 4)
-
-	m.inputs[0] = textinput.New()
-	m.inputs[0].Placeholder = "Amount"
-	m.inputs[0].Focus()
-
-	m.inputs[1] = textinput.New()
-	m.inputs[1].Placeholder = "Description"
-
-	m.inputs[2] = textinput.New()
-	m.inputs[2].Placeholder = "Category (food/transport/shopping/utilities/health/other)"
-
-	m.inputs[3] = textinput.New()
-	m.inputs[3].Placeholder = "Date (YYYY-MM-DD, leave empty for today)"
-
-	m.focusIndex = 0
-}
-
-func (m Model) viewAddExpense() string {
-	title := TitleStyle.Render("  Add Expense")
+// Expense Range view - scopes the Expenses list to a date range
+func (m Model) viewExpenseRange() string {
+	title := TitleStyle.Render("  Expenses Date Range")
 
 	var content string
-	labels := []string{"Amount:", "Description:", "Category:", "Date:"}
-	hints := []string{
-		"",
-		"",
-		"Options: food, transport, shopping, utilities, health, entertainment, education, other",
-		"Format: YYYY-MM-DD (leave empty for today)",
-	}
-
+	labels := []string{"From:", "To:"}
 	for i, input := range m.inputs {
-		label := labels[i]
 		if i == m.focusIndex {
-			content += SelectedMenuItemStyle.Render("▸ "+label) + "\n"
-			content += "  " + FocusedInputStyle.Render(input.View()) + "\n"
-			if hints[i] != "" {
-				content += "  " + MutedStyle.Render(hints[i]) + "\n"
-			}
-			content += "\n"
+			content += SelectedMenuItemStyle.Render("▸ "+labels[i]) + "\n"
+			content += "  " + FocusedInputStyle.Render(input.View()) + "\n\n"
 		} else {
-			content += MenuItemStyle.Render("  "+label) + "\n"
-			content += "  " + InputStyle.Render(input.View()) + "\n"
-			if hints[i] != "" {
-				content += "  " + MutedStyle.Render(hints[i]) + "\n"
-			}
-			content += "\n"
+			content += MenuItemStyle.Render("  "+labels[i]) + "\n"
+			content += "  " + InputStyle.Render(input.View()) + "\n\n"
 		}
 	}
+	content += "  " + MutedStyle.Render("Both days are included. Leave both empty to show all expenses.") + "\n"
 
-	help := HelpStyle.Render("+: Calculate • Tab: Next field • Enter: Save • Esc: Cancel")
+	help := HelpStyle.Render("Tab: Next field • Enter: Apply • Esc: Cancel")
 
 	return BoxStyle.Render(title + "\n" + content + help)
 }
 
-func (m *Model) updateAddExpenseView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+func (m *Model) updateExpenseRangeView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.updateFormFocus(msg.String()) {
+		return m, nil
+	}
+
 	switch msg.String() {
-	case "tab", "down":
-		m.inputs[m.focusIndex].Blur()
-		m.focusIndex = (m.focusIndex + 1) % len(m.inputs)
-		m.inputs[m.focusIndex].Focus()
-	case "shift+tab", "up":
-		m.inputs[m.focusIndex].Blur()
-		m.focusIndex--
-		if m.focusIndex < 0 {
-			m.focusIndex = len(m.inputs) - 1
-		}
-		m.inputs[m.focusIndex].Focus()
 	case "enter":
-		amount, err := strconv.ParseFloat(m.inputs[0].Value(), 64)
-		if err != nil {
-			m.message = "Invalid amount"
-			m.messageType = "error"
-			return m, nil
-		}
-
-		description := m.inputs[1].Value()
-		if description == "" {
-			m.message = "Description is required"
-			m.messageType = "error"
-			return m, nil
-		}
-
-		category := models.ExpenseCategory(m.inputs[2].Value())
-		if category == "" {
-			category = models.CategoryOther
-		}
+		fromValue := strings.TrimSpace(m.inputs[0].Value())
+		toValue := strings.TrimSpace(m.inputs[1].Value())
 
-		date := time.Now()
-		if m.inputs[3].Value() != "" {
-			date, err = time.Parse("2006-01-02", m.inputs[3].Value())
+		if fromValue == "" && toValue == "" {
+			m.expenseFrom, m.expenseTo = time.Time{}, time.Time{}
+		} else {
+			from, err := time.Parse("2006-01-02", fromValue)
 			if err != nil {
-				m.message = "Invalid date format"
+				m.message = "Invalid from date. Use YYYY-MM-DD"
 				m.messageType = "error"
 				return m, nil
 			}
+			to := time.Now()
+			if toValue != "" {
+				to, err = time.Parse("2006-01-02", toValue)
+				if err != nil {
+					m.message = "Invalid to date. Use YYYY-MM-DD"
+					m.messageType = "error"
+					return m, nil
+				}
+			}
+			to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+			if to.Before(from) {
+				from, to = to, from
+			}
+			m.expenseFrom, m.expenseTo = from, to
 		}
 
-		_, err = m.storage.AddExpense(amount, description, category, date)
-		if err != nil {
-			m.message = "Error saving expense: " + err.Error()
-			m.messageType = "error"
-			return m, nil
-		}
-
-		m.message = "Expense added successfully!"
-		m.messageType = "success"
-		m.currentView = ViewExpenses
+		m.popView()
 		m.inputs = nil
 		m.cursor = 0
+		m.offset = 0
 		return m, nil
-	case "+":
-		if m.focusIndex == 0 && len(m.inputs) > 0 {
-			currentValue := m.inputs[0].Value()
-			calculatedValue, success := tryCalculateAmount(currentValue)
-			if success {
-				m.inputs[0].SetValue(calculatedValue)
-				m.message = "Calculated: " + calculatedValue
-				m.messageType = "info"
-			}
-		}
 	case "esc":
-		m.currentView = ViewExpenses
+		m.popView()
 		m.inputs = nil
-		m.cursor = 0
 		return m, nil
 	}
 
-	// Update text input
 	if len(m.inputs) > 0 && m.focusIndex < len(m.inputs) {
 		var cmd tea.Cmd
 		m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
-
-		// Auto-calculate in amount field (index 0) when trailing operator is typed
-		if m.focusIndex == 0 {
-			m.autoCalculateIfNeeded(0)
-		}
 		return m, cmd
 	}
 	return m, nil
 }
 
-// Debts view
-func (m Model) viewDebts() string {
-	title := TitleStyle.Render("  Borrowing & Lending")
+// Recurring view - monthly expenses that are created automatically
+func (m Model) viewRecurring() string {
+	title := TitleStyle.Render("  Recurring Expenses")
 
-	debts := m.storage.GetUnsettledDebts()
-	data := m.storage.GetData()
+	recurring := m.storage.GetRecurringExpenses()
 
 	var content string
-	if len(debts) == 0 {
-		content = MutedStyle.Render("\n  No unsettled debts.\n")
+	if len(recurring) == 0 {
+		content = MutedStyle.Render("\n  No recurring expenses yet.\n")
 	} else {
-		// Group debts by person (combine borrowed and lent)
-		type personGroup struct {
-			name          string
-			totalLent     float64
-			totalBorrowed float64
-			lentDebts     []models.DebtTransaction
-			borrowedDebts []models.DebtTransaction
-		}
-
-		// Use a map to group by person name only
-		groupMap := make(map[string]*personGroup)
-		var groupOrder []string
-
-		for _, debt := range debts {
-			key := storage.NormalizeName(debt.PersonName)
-			if _, exists := groupMap[key]; !exists {
-				groupMap[key] = &personGroup{
-					name:          key,
-					totalLent:     0,
-					totalBorrowed: 0,
-					lentDebts:     []models.DebtTransaction{},
-					borrowedDebts: []models.DebtTransaction{},
-				}
-				groupOrder = append(groupOrder, key)
+		content = "\n"
+		for i, r := range recurring {
+			cursor := "  "
+			if i == m.cursor {
+				cursor = "▸ "
 			}
-			if debt.Type == models.Lent {
-				groupMap[key].totalLent += debt.Amount
-				groupMap[key].lentDebts = append(groupMap[key].lentDebts, debt)
-			} else {
-				groupMap[key].totalBorrowed += debt.Amount
-				groupMap[key].borrowedDebts = append(groupMap[key].borrowedDebts, debt)
+			status := ""
+			switch {
+			case r.EndDate != nil && r.EndDate.Before(time.Now()):
+				status = "  " + RenderBadge("ENDED", "")
+			case !r.Active:
+				status = "  " + RenderBadge("PAUSED", "")
+			case r.EndDate != nil:
+				status = "  " + MutedStyle.Render("until "+r.EndDate.Format("2006-01-02"))
 			}
-		}
-
-		content = "\n"
-		visibleIndex := 0
-		for _, key := range groupOrder {
-			group := groupMap[key]
-
-			// Calculate net balance (positive = they owe you, negative = you owe them)
-			netBalance := group.totalLent - group.totalBorrowed
-
-			// Skip if net balance is 0 (all settled)
-			if netBalance == 0 {
-				continue
-			}
-
-			cursor := "  "
-			if visibleIndex == m.cursor {
-				cursor = "▸ "
-			}
-
-			var netStatus string
-			if netBalance > 0 {
-				netStatus = AmountPositiveStyle.Render(fmt.Sprintf("owes you %s", FormatAmountPlain(netBalance, m.config.Currency)))
-			} else {
-				netStatus = AmountNegativeStyle.Render(fmt.Sprintf("you owe %s", FormatAmountPlain(-netBalance, m.config.Currency)))
-			}
-
-			header := fmt.Sprintf("%s%s  [%s]",
-				cursor,
-				SelectedMenuItemStyle.Render(group.name),
-				netStatus,
-			)
-			content += header + "\n"
-
-			// Show lent transactions
-			if len(group.lentDebts) > 0 {
-				content += "      " + AmountPositiveStyle.Render("Lent:") + "\n"
-				for _, debt := range group.lentDebts {
-					reason := debt.Description
-					if reason == "" {
-						reason = "(no description)"
-					}
-					line := fmt.Sprintf("        + %s - %s  %s",
-						FormatAmountPlain(debt.Amount, m.config.Currency),
-						MutedStyle.Render(truncate(reason, 25)),
-						MutedStyle.Render(debt.Date.Format("2006-01-02")),
-					)
-					content += line + "\n"
-				}
-			}
-
-			// Show borrowed transactions
-			if len(group.borrowedDebts) > 0 {
-				content += "      " + AmountNegativeStyle.Render("Borrowed:") + "\n"
-				for _, debt := range group.borrowedDebts {
-					reason := debt.Description
-					if reason == "" {
-						reason = "(no description)"
-					}
-					line := fmt.Sprintf("        - %s - %s  %s",
-						FormatAmountPlain(debt.Amount, m.config.Currency),
-						MutedStyle.Render(truncate(reason, 25)),
-						MutedStyle.Render(debt.Date.Format("2006-01-02")),
-					)
-					content += line + "\n"
-				}
-			}
-			content += "\n"
-			visibleIndex++
+			line := fmt.Sprintf("%sDay %2d  %s  %s  %s%s",
+				cursor,
+				r.DayOfMonth,
+				TableCellStyle.Width(15).Render(truncate(r.Description, 15)),
+				TableCellStyle.Width(12).Render(string(r.Category)),
+				m.formatAmountPlain(r.Amount, m.config.Currency),
+				status,
+			)
+			content += line + "\n"
 		}
 	}
 
-	// Summary
-	stats := fmt.Sprintf("  Total Borrowed: %s | Total Lent: %s | Net: %s",
-		AmountNegativeStyle.Render(FormatAmountPlain(data.TotalBorrowed(), m.config.Currency)),
-		AmountPositiveStyle.Render(FormatAmountPlain(data.TotalLent(), m.config.Currency)),
-		FormatAmount(data.TotalLent()-data.TotalBorrowed(), m.config.Currency),
-	)
+	info := MutedStyle.Render("\n  Each active entry is added as an expense once a month, on its day.")
 
-	help := HelpStyle.Render("\n  a: Add debt • s: Settle • h: Person history • g: All payments • Esc: Back")
+	help := HelpStyle.Render("\n  a: Add • p: Pause/resume • e: End date • d: Delete • Esc: Back")
 
-	return BoxStyle.Render(title + content + stats + help)
+	return BoxStyle.Render(title + content + info + help)
 }
 
-func (m *Model) updateDebtsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	debts := m.storage.GetUnsettledDebts()
-
-	// Build the same grouped structure as the view (by person name)
-	type personGroup struct {
-		name          string
-		totalLent     float64
-		totalBorrowed float64
-		debts         []models.DebtTransaction
-	}
-
-	groupMap := make(map[string]*personGroup)
-	var groupOrder []string
-
-	for _, debt := range debts {
-		key := storage.NormalizeName(debt.PersonName)
-		if _, exists := groupMap[key]; !exists {
-			groupMap[key] = &personGroup{
-				name:          key,
-				totalLent:     0,
-				totalBorrowed: 0,
-				debts:         []models.DebtTransaction{},
-			}
-			groupOrder = append(groupOrder, key)
-		}
-		groupMap[key].debts = append(groupMap[key].debts, debt)
-		if debt.Type == models.Lent {
-			groupMap[key].totalLent += debt.Amount
-		} else {
-			groupMap[key].totalBorrowed += debt.Amount
-		}
-	}
-
-	// Count only visible persons (net balance != 0)
-	visibleCount := 0
-	for _, key := range groupOrder {
-		group := groupMap[key]
-		netBalance := group.totalLent - group.totalBorrowed
-		if netBalance != 0 {
-			visibleCount++
-		}
-	}
-
-	maxCursor := visibleCount - 1
+func (m *Model) updateRecurringView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	recurring := m.storage.GetRecurringExpenses()
+	maxCursor := len(recurring) - 1
 	if maxCursor < 0 {
 		maxCursor = 0
 	}
-
-	// Adjust cursor if it went out of bounds
 	if m.cursor > maxCursor {
 		m.cursor = maxCursor
 	}
@@ -705,69 +886,113 @@ func (m *Model) updateDebtsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.cursor < maxCursor {
 			m.cursor++
 		}
-	case "a":
-		m.currentView = ViewAddDebt
-		m.initDebtInputs()
-	case "s":
-		// Open transaction selector for selected person
-		if len(groupOrder) > 0 && m.cursor < len(groupOrder) {
-			m.selectedPerson = groupOrder[m.cursor]
-			m.currentView = ViewSelectTransaction
-			m.cursor = 0
+	case "esc":
+		m.popView()
+		m.cursor = 0
+	}
+
+	return m, nil
+}
+
+func (m Model) viewRecurringEndDate() string {
+	title := TitleStyle.Render("  Recurring End Date")
+
+	var content string
+	for _, r := range m.storage.GetRecurringExpenses() {
+		if r.ID == m.selectedID {
+			content = fmt.Sprintf("\n  %s  %s  %s\n\n",
+				SelectedMenuItemStyle.Render(r.Description),
+				m.formatAmountPlain(r.Amount, m.config.Currency),
+				MutedStyle.Render(fmt.Sprintf("day %d", r.DayOfMonth)),
+			)
+			break
 		}
-	case "h":
-		// Open payment history for selected person
-		if len(groupOrder) > 0 && m.cursor < len(groupOrder) {
-			m.selectedPerson = groupOrder[m.cursor]
-			m.currentView = ViewPersonHistory
-			m.cursor = 0
+	}
+
+	if len(m.inputs) > 0 {
+		content += SelectedMenuItemStyle.Render("▸ End Date:") + "\n"
+		content += "  " + FocusedInputStyle.Render(m.inputs[0].View()) + "\n"
+		content += "  " + MutedStyle.Render("Last day to add it for (YYYY-MM-DD); leave empty to repeat indefinitely") + "\n"
+	}
+
+	help := HelpStyle.Render("Enter: Save • Esc: Cancel")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateRecurringEndDateView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		var end *time.Time
+		if value := strings.TrimSpace(m.inputs[0].Value()); value != "" {
+			parsed, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				m.message = "Invalid date format. Use YYYY-MM-DD"
+				m.messageType = "error"
+				return m, nil
+			}
+			end = &parsed
 		}
-	case "g":
-		// Open global settlement history
-		m.currentView = ViewSettlementHistory
-		m.cursor = 0
+
+		if err := m.storage.SetRecurringExpenseEndDate(m.selectedID, end); err != nil {
+			m.message = "Error saving: " + err.Error()
+			m.messageType = "error"
+			return m, nil
+		}
+
+		m.message = "End date set!"
+		if end == nil {
+			m.message = "End date removed"
+		}
+		m.messageType = "success"
+		m.popView()
+		m.inputs = nil
+		m.selectedID = ""
+		return m, nil
 	case "esc":
-		m.currentView = ViewMain
-		m.cursor = 0
+		m.popView()
+		m.inputs = nil
+		m.selectedID = ""
+		return m, nil
 	}
 
+	if len(m.inputs) > 0 {
+		var cmd tea.Cmd
+		m.inputs[0], cmd = m.inputs[0].Update(msg)
+		return m, cmd
+	}
 	return m, nil
 }
 
-func (m *Model) initDebtInputs() {
-	m.inputs = make([]textinput.Model, 5)
+func (m *Model) initRecurringInputs() {
+	m.inputs = make([]textinput.Model, 4)
 
 	m.inputs[0] = textinput.New()
-	m.inputs[0].Placeholder = "Type (borrowed/lent)"
+	m.inputs[0].Placeholder = "Amount"
 	m.inputs[0].Focus()
 
 	m.inputs[1] = textinput.New()
-	m.inputs[1].Placeholder = "Person Name"
+	m.inputs[1].Placeholder = "Description (e.g., Rent, Netflix)"
 
 	m.inputs[2] = textinput.New()
-	m.inputs[2].Placeholder = "Amount"
+	m.inputs[2].Placeholder = "Category (food/transport/shopping/utilities/health/other)"
 
 	m.inputs[3] = textinput.New()
-	m.inputs[3].Placeholder = "Description"
-
-	m.inputs[4] = textinput.New()
-	m.inputs[4].Placeholder = "Transaction Date (YYYY-MM-DD)"
-	m.inputs[4].SetValue(time.Now().Format("2006-01-02"))
+	m.inputs[3].Placeholder = "Day of month (1-31)"
 
 	m.focusIndex = 0
 }
 
-func (m Model) viewAddDebt() string {
-	title := TitleStyle.Render("  Add Debt Transaction")
+func (m Model) viewAddRecurring() string {
+	title := TitleStyle.Render("  Add Recurring Expense")
 
 	var content string
-	labels := []string{"Type:", "Person:", "Amount:", "Description:", "Date:"}
+	labels := []string{"Amount:", "Description:", "Category:", "Day of Month:"}
 	hints := []string{
-		"Options: borrowed, lent",
 		"",
 		"",
-		"",
-		"Date when borrowed/lent (YYYY-MM-DD)",
+		"Options: food, transport, shopping, utilities, health, entertainment, education, other",
+		"Days past the end of a month fall on its last day",
 	}
 
 	for i, input := range m.inputs {
@@ -775,18 +1000,14 @@ func (m Model) viewAddDebt() string {
 		if i == m.focusIndex {
 			content += SelectedMenuItemStyle.Render("▸ "+label) + "\n"
 			content += "  " + FocusedInputStyle.Render(input.View()) + "\n"
-			if hints[i] != "" {
-				content += "  " + MutedStyle.Render(hints[i]) + "\n"
-			}
-			content += "\n"
 		} else {
 			content += MenuItemStyle.Render("  "+label) + "\n"
 			content += "  " + InputStyle.Render(input.View()) + "\n"
-			if hints[i] != "" {
-				content += "  " + MutedStyle.Render(hints[i]) + "\n"
-			}
-			content += "\n"
 		}
+		if hints[i] != "" {
+			content += "  " + MutedStyle.Render(hints[i]) + "\n"
+		}
+		content += "\n"
 	}
 
 	help := HelpStyle.Render("+: Calculate • Tab: Next field • Enter: Save • Esc: Cancel")
@@ -794,84 +1015,66 @@ func (m Model) viewAddDebt() string {
 	return BoxStyle.Render(title + "\n" + content + help)
 }
 
-func (m *Model) updateAddDebtView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+func (m *Model) updateAddRecurringView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.updateFormFocus(msg.String()) {
+		return m, nil
+	}
+
 	switch msg.String() {
-	case "tab", "down":
-		m.inputs[m.focusIndex].Blur()
-		m.focusIndex = (m.focusIndex + 1) % len(m.inputs)
-		m.inputs[m.focusIndex].Focus()
-	case "shift+tab", "up":
-		m.inputs[m.focusIndex].Blur()
-		m.focusIndex--
-		if m.focusIndex < 0 {
-			m.focusIndex = len(m.inputs) - 1
-		}
-		m.inputs[m.focusIndex].Focus()
 	case "enter":
-		txType := models.TransactionType(m.inputs[0].Value())
-		if txType != models.Borrowed && txType != models.Lent {
-			m.message = "Type must be 'borrowed' or 'lent'"
+		amount, err := parseAmount(m.inputs[0].Value())
+		if err != nil || amount <= 0 {
+			m.message = "Invalid amount"
 			m.messageType = "error"
 			return m, nil
 		}
 
-		personName := m.inputs[1].Value()
-		if personName == "" {
-			m.message = "Person name is required"
+		description := strings.TrimSpace(m.inputs[1].Value())
+		if description == "" {
+			m.message = "Description is required"
 			m.messageType = "error"
 			return m, nil
 		}
 
-		amount, err := strconv.ParseFloat(m.inputs[2].Value(), 64)
+		category, err := parseCategory(m.inputs[2].Value())
 		if err != nil {
-			m.message = "Invalid amount"
+			m.message = err.Error()
 			m.messageType = "error"
 			return m, nil
 		}
 
-		description := m.inputs[3].Value()
-
-		// Parse transaction date
-		dateStr := m.inputs[4].Value()
-		if dateStr == "" {
-			m.message = "Transaction date is required"
-			m.messageType = "error"
-			return m, nil
-		}
-		transactionDate, err := time.Parse("2006-01-02", dateStr)
-		if err != nil {
-			m.message = "Invalid date format. Use YYYY-MM-DD"
+		day, err := strconv.Atoi(strings.TrimSpace(m.inputs[3].Value()))
+		if err != nil || day < 1 || day > 31 {
+			m.message = "Day of month must be between 1 and 31"
 			m.messageType = "error"
 			return m, nil
 		}
 
-		_, err = m.storage.AddDebtTransaction(txType, personName, amount, description, transactionDate, nil)
-		if err != nil {
+		if _, err := m.storage.AddRecurringExpense(amount, description, category, day); err != nil {
 			m.message = "Error saving: " + err.Error()
 			m.messageType = "error"
 			return m, nil
 		}
 
-		m.message = "Debt transaction added!"
+		m.message = "Recurring expense added!"
 		m.messageType = "success"
-		m.currentView = ViewDebts
+		m.popView()
 		m.inputs = nil
 		m.cursor = 0
 		return m, nil
 	case "+":
-		if m.focusIndex == 2 && len(m.inputs) > 0 {
-			currentValue := m.inputs[2].Value()
+		if m.focusIndex == 0 && len(m.inputs) > 0 {
+			currentValue := m.inputs[0].Value()
 			calculatedValue, success := tryCalculateAmount(currentValue)
 			if success {
-				m.inputs[2].SetValue(calculatedValue)
+				m.inputs[0].SetValue(calculatedValue)
 				m.message = "Calculated: " + calculatedValue
 				m.messageType = "info"
 			}
 		}
 	case "esc":
-		m.currentView = ViewDebts
+		m.popView()
 		m.inputs = nil
-		m.cursor = 0
 		return m, nil
 	}
 
@@ -879,401 +1082,3247 @@ func (m *Model) updateAddDebtView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		var cmd tea.Cmd
 		m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
 
-		// Auto-calculate in amount field (index 2) when trailing operator is typed
-		if m.focusIndex == 2 {
-			m.autoCalculateIfNeeded(2)
+		// Auto-calculate in amount field (index 0)
+		if m.focusIndex == 0 {
+			m.autoCalculateIfNeeded(0)
 		}
 		return m, cmd
 	}
 	return m, nil
 }
 
-// Settle Debt functions - now settles a specific transaction with a note
-func (m *Model) initSettleDebtInputs() {
-	// Find the selected transaction to get the remaining amount
-	var remainingAmount float64
-	for _, tx := range m.storage.GetDebtTransactions() {
-		if tx.ID == m.selectedTxID {
-			remainingAmount = tx.Amount
-			break
-		}
-	}
-
-	m.inputs = make([]textinput.Model, 2)
-
+// Import Expenses view - bulk import from a CSV file
+// initQuickAddInput sets up the single line read by parseQuickExpense
+func (m *Model) initQuickAddInput() {
+	m.inputs = make([]textinput.Model, 1)
 	m.inputs[0] = textinput.New()
-	m.inputs[0].Placeholder = fmt.Sprintf("Amount (max: %.2f, leave empty for full)", remainingAmount)
+	m.inputs[0].Placeholder = "250 Lunch food"
 	m.inputs[0].Focus()
-
-	m.inputs[1] = textinput.New()
-	m.inputs[1].Placeholder = "Settlement note (e.g., Cash, Bank transfer, UPI)"
-
 	m.focusIndex = 0
 }
 
-func (m Model) viewSettleDebt() string {
-	title := TitleStyle.Render("  Settle Transaction")
+// Quick Add Expense view - one line such as "250 Lunch food", dated today
+func (m Model) viewQuickAddExpense() string {
+	title := TitleStyle.Render("  Quick Add Expense")
 
-	// Find the selected transaction
-	var selectedTx *models.DebtTransaction
-	for _, tx := range m.storage.GetDebtTransactions() {
-		if tx.ID == m.selectedTxID {
-			selectedTx = &tx
-			break
-		}
+	content := "\n  Amount, description, then optionally a category, e.g. 250 Lunch food\n"
+	content += MutedStyle.Render("  Add a currency code after the amount (20 USD Lunch) and #tags anywhere. Without a category it is filed under \""+string(m.config.ExpenseCategory())+"\".") + "\n\n"
+	if len(m.inputs) > 0 {
+		content += "  " + FocusedInputStyle.Render(m.inputs[0].View()) + "\n"
 	}
 
-	var content string
-	if selectedTx == nil {
-		content = MutedStyle.Render("\n  Transaction not found.\n")
-	} else {
-		txType := AmountPositiveStyle.Render("LENT to")
-		if selectedTx.Type == models.Borrowed {
-			txType = AmountNegativeStyle.Render("BORROWED from")
-		}
-		desc := selectedTx.Description
-		if desc == "" {
-			desc = "(no description)"
-		}
+	help := HelpStyle.Render("\n  Enter: Add for today • Esc: Cancel")
 
-		content = fmt.Sprintf("\n  %s %s\n", txType, SelectedMenuItemStyle.Render(selectedTx.PersonName))
-		content += fmt.Sprintf("  Remaining: %s", FormatAmountPlain(selectedTx.Amount, m.config.Currency))
-		if selectedTx.OriginalAmount > selectedTx.Amount {
-			content += fmt.Sprintf(" (of %s original)", FormatAmountPlain(selectedTx.OriginalAmount, m.config.Currency))
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateQuickAddExpenseView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		entry, err := m.parseQuickExpense(m.inputs[0].Value())
+		if err != nil {
+			m.message = "Could not read that: " + err.Error()
+			m.messageType = "error"
+			return m, nil
 		}
-		content += "\n"
-		content += fmt.Sprintf("  Date: %s\n", selectedTx.Date.Format("2006-01-02"))
-		content += fmt.Sprintf("  Description: %s\n\n", MutedStyle.Render(desc))
 
-		if len(m.inputs) >= 2 {
-			labels := []string{"Amount to settle:", "Settlement Note:"}
-			hints := []string{
-				"Leave empty for full settlement",
+		expense, err := m.storage.AddExpense(entry.Amount, entry.Description, entry.Category, time.Now(), entry.Currency, entry.Tags, "")
+		if err != nil {
+			m.message = "Error saving expense: " + err.Error()
+			m.messageType = "error"
+			return m, nil
+		}
+		m.message = fmt.Sprintf("Added %s - %s (%s)", expense.Description, m.formatAmountPlain(expense.Amount, m.currencyOf(expense.Currency)), expense.Category)
+		m.messageType = "success"
+		m.warnIfDuplicate(expense, m.stashRoundUp(expense))
+		m.popView()
+		m.inputs = nil
+		m.cursor = 0
+		return m, nil
+	case "esc":
+		m.popView()
+		m.inputs = nil
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.inputs[0], cmd = m.inputs[0].Update(msg)
+	return m, cmd
+}
+
+// parseQuickExpense reads "<amount> [currency] <description...> [category]",
+// with #tags allowed anywhere after the amount. The last word is the category
+// when it names one; otherwise the configured default category is used.
+func (m Model) parseQuickExpense(input string) (models.Expense, error) {
+	fields := strings.Fields(input)
+	if len(fields) < 2 {
+		return models.Expense{}, fmt.Errorf("type an amount and a description, e.g. 250 Lunch food")
+	}
+
+	amountText, rest := fields[0], fields[1:]
+	// Amounts may be expressions, e.g. "100+20 Taxi"
+	if result, ok := tryCalculateAmount(amountText); ok {
+		amountText = result
+	}
+	// A known currency code right after the amount, e.g. "20 USD Lunch"
+	if code := strings.ToUpper(rest[0]); len(rest) > 1 && rest[0] == code {
+		if _, known := m.config.Rates().Rates[code]; known || code == strings.ToUpper(m.config.Currency) {
+			amountText, rest = amountText+" "+rest[0], rest[1:]
+		}
+	}
+	amount, currency, err := m.parseMoney(amountText)
+	if err != nil {
+		return models.Expense{}, fmt.Errorf("%q is not an amount", fields[0])
+	}
+
+	var words, tags []string
+	for _, word := range rest {
+		if strings.HasPrefix(word, "#") {
+			tags = append(tags, word)
+		} else {
+			words = append(words, word)
+		}
+	}
+
+	category := m.config.ExpenseCategory()
+	if n := len(words); n > 1 {
+		if c := models.ExpenseCategory(strings.ToLower(words[n-1])); models.IsValidCategory(c) {
+			category, words = c, words[:n-1]
+		}
+	}
+	if len(words) == 0 {
+		return models.Expense{}, fmt.Errorf("a description is required")
+	}
+
+	return models.Expense{
+		Amount:      amount,
+		Currency:    currency,
+		Description: strings.Join(words, " "),
+		Category:    category,
+		Tags:        models.ParseTags(strings.Join(tags, ",")),
+	}, nil
+}
+
+// duplicateWindow is how soon after an identical expense a new one is flagged
+// as a likely double entry
+const duplicateWindow = 2 * time.Minute
+
+// warnIfDuplicate turns the status message into a warning when expense repeats
+// one entered moments before. Nothing is removed unless u is pressed next, which
+// also gives back stashed, the round-up taken from expense.
+func (m *Model) warnIfDuplicate(expense *models.Expense, stashed roundUp) {
+	for _, group := range m.storage.FindDuplicateExpenses(duplicateWindow) {
+		for i := 1; i < len(group); i++ {
+			if group[i].ID != expense.ID {
+				continue
+			}
+			ago := group[i].CreatedAt.Sub(group[i-1].CreatedAt).Round(time.Second)
+			m.message += fmt.Sprintf(" Looks like a duplicate of an entry %s ago (u: remove it, any other key: keep it).", ago)
+			m.messageType = "warning"
+			m.duplicateID = expense.ID
+			m.duplicateStash = stashed
+			return
+		}
+	}
+}
+
+// roundUp is an amount stashed into a savings goal from an expense
+type roundUp struct {
+	goalID string
+	amount float64
+}
+
+// stashRoundUp rounds a new expense up into the round-up savings goal, if
+// enabled, and notes it in the status message. Round-ups are only taken from
+// expenses in the base currency. It returns what was stashed, if anything.
+func (m *Model) stashRoundUp(expense *models.Expense) roundUp {
+	if expense.Currency != "" {
+		return roundUp{}
+	}
+	stashed, target, err := m.storage.StashRoundUp(expense.Amount, expense.Description)
+	if err != nil {
+		m.message += " (round-up failed: " + err.Error() + ")"
+		return roundUp{}
+	}
+	if stashed == 0 {
+		return roundUp{}
+	}
+	m.message += fmt.Sprintf(" Stashed %s into %s.", m.formatAmountPlain(stashed, m.config.Currency), target.ProductName)
+	return roundUp{goalID: target.ID, amount: stashed}
+}
+
+func (m Model) viewImportExpenses() string {
+	title := TitleStyle.Render("  Import Expenses from CSV")
+
+	content := "\n  Columns: date (YYYY-MM-DD), category, description, amount\n"
+	content += MutedStyle.Render("  A header row is optional. Unknown categories are imported as \"other\".") + "\n\n"
+	if len(m.inputs) > 0 {
+		content += "  " + FocusedInputStyle.Render(m.inputs[0].View()) + "\n"
+	}
+
+	help := HelpStyle.Render("\n  Enter: Import • Esc: Cancel")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateImportExpensesView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		path := strings.TrimSpace(m.inputs[0].Value())
+		if path == "" {
+			m.message = "File path is required"
+			m.messageType = "error"
+			return m, nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			m.message = "Error opening file: " + err.Error()
+			m.messageType = "error"
+			return m, nil
+		}
+		imported, errs := m.storage.ImportExpensesCSV(f)
+		f.Close()
+
+		m.message = fmt.Sprintf("Imported %d expense(s)", imported)
+		m.messageType = "success"
+		if len(errs) > 0 {
+			m.message += fmt.Sprintf(", %d problem(s) - first: %v", len(errs), errs[0])
+			m.messageType = "error"
+		}
+		m.popView()
+		m.inputs = nil
+		m.cursor = 0
+		return m, nil
+	case "esc":
+		m.popView()
+		m.inputs = nil
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.inputs[0], cmd = m.inputs[0].Update(msg)
+	return m, cmd
+}
+
+func (m *Model) initExpenseInputs() {
+	m.inputs = make([]textinput.Model, 6)
+
+	m.inputs[0] = textinput.New()
+	m.inputs[0].Placeholder = "Amount"
+	m.inputs[0].Focus()
+
+	m.inputs[1] = textinput.New()
+	m.inputs[1].Placeholder = "Description"
+
+	m.inputs[2] = newCategoryInput()
+	m.inputs[2].Placeholder = "Category (food/transport/shopping/utilities/health/other)"
+	m.inputs[2].SetValue(string(m.config.DefaultCategory))
+
+	m.inputs[3] = textinput.New()
+	m.inputs[3].Placeholder = "Date (YYYY-MM-DD)"
+	if m.config.DateDefaultsToToday() {
+		m.inputs[3].SetValue(time.Now().Format("2006-01-02"))
+	}
+
+	m.inputs[4] = textinput.New()
+	m.inputs[4].Placeholder = "Tags (optional, e.g. vacation, work)"
+
+	m.inputs[5] = textinput.New()
+	m.inputs[5].Placeholder = "Notes (optional)"
+
+	m.focusIndex = 0
+}
+
+func (m Model) viewAddExpense() string {
+	title := TitleStyle.Render("  Add Expense")
+
+	var content string
+	labels := []string{"Amount:", "Description:", "Category:", "Date:", "Tags:", "Notes:"}
+	dateHint := "Format: YYYY-MM-DD (leave empty for today)"
+	if !m.config.DateDefaultsToToday() {
+		dateHint = "Format: YYYY-MM-DD (required)"
+	}
+	hints := []string{
+		"Add a currency code for other currencies, e.g. 20 USD",
+		"",
+		"Options: food, transport, shopping, utilities, health, entertainment, education, other (Tab completes)",
+		dateHint,
+		"Comma-separated, e.g. vacation, work",
+		"Any extra context, e.g. who was there or what the receipt covered",
+	}
+
+	for i, input := range m.inputs {
+		label := labels[i]
+		if i == m.focusIndex {
+			content += SelectedMenuItemStyle.Render("▸ "+label) + "\n"
+			content += "  " + FocusedInputStyle.Render(input.View()) + "\n"
+			if hints[i] != "" {
+				content += "  " + MutedStyle.Render(hints[i]) + "\n"
+			}
+			content += "\n"
+		} else {
+			content += MenuItemStyle.Render("  "+label) + "\n"
+			content += "  " + InputStyle.Render(input.View()) + "\n"
+			if hints[i] != "" {
+				content += "  " + MutedStyle.Render(hints[i]) + "\n"
+			}
+			content += "\n"
+		}
+	}
+
+	help := HelpStyle.Render("+: Calculate • Tab: Next field • Enter: Save • Esc: Cancel")
+
+	return BoxStyle.Render(title + "\n" + content + help)
+}
+
+func (m *Model) updateAddExpenseView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Tab in the category field completes the category before moving on
+	if msg.String() == "tab" && m.focusIndex == 2 && hasCompletion(&m.inputs[2]) {
+		var cmd tea.Cmd
+		m.inputs[2], cmd = m.inputs[2].Update(msg)
+		return m, cmd
+	}
+	if m.updateFormFocus(msg.String()) {
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "enter":
+		amount, currency, err := m.parseMoney(m.inputs[0].Value())
+		if err != nil {
+			m.message = "Invalid amount: " + err.Error()
+			m.messageType = "error"
+			return m, nil
+		}
+
+		description := m.inputs[1].Value()
+		if description == "" {
+			m.message = "Description is required"
+			m.messageType = "error"
+			return m, nil
+		}
+
+		category, err := parseCategory(m.inputs[2].Value())
+		if err != nil {
+			m.message = err.Error()
+			m.messageType = "error"
+			return m, nil
+		}
+
+		date := time.Now()
+		if m.inputs[3].Value() != "" {
+			date, err = time.Parse("2006-01-02", m.inputs[3].Value())
+			if err != nil {
+				m.message = "Invalid date format"
+				m.messageType = "error"
+				return m, nil
+			}
+		} else if !m.config.DateDefaultsToToday() {
+			m.message = "Date is required"
+			m.messageType = "error"
+			return m, nil
+		}
+
+		expense, err := m.storage.AddExpense(amount, description, category, date, currency, models.ParseTags(m.inputs[4].Value()), m.inputs[5].Value())
+		if err != nil {
+			m.message = "Error saving expense: " + err.Error()
+			m.messageType = "error"
+			return m, nil
+		}
+
+		m.message = "Expense added successfully!"
+		m.messageType = "success"
+
+		m.warnIfDuplicate(expense, m.stashRoundUp(expense))
+		m.popView()
+		m.inputs = nil
+		m.cursor = 0
+		return m, nil
+	case "+":
+		if m.focusIndex == 0 && len(m.inputs) > 0 {
+			currentValue := m.inputs[0].Value()
+			calculatedValue, success := tryCalculateAmount(currentValue)
+			if success {
+				m.inputs[0].SetValue(calculatedValue)
+				m.message = "Calculated: " + calculatedValue
+				m.messageType = "info"
+			}
+		}
+	case "esc":
+		m.popView()
+		m.inputs = nil
+		m.cursor = 0
+		return m, nil
+	}
+
+	// Update text input
+	if len(m.inputs) > 0 && m.focusIndex < len(m.inputs) {
+		var cmd tea.Cmd
+		m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
+
+		// Auto-calculate in amount field (index 0) when trailing operator is typed
+		if m.focusIndex == 0 {
+			m.autoCalculateIfNeeded(0)
+		}
+		return m, cmd
+	}
+	return m, nil
+}
+
+// Expense Detail view - every field of one expense, including its notes
+func (m Model) viewExpenseDetail() string {
+	title := TitleStyle.Render("  Expense Details")
+
+	exp := m.selectedExpense()
+	if exp == nil {
+		return BoxStyle.Render(title + MutedStyle.Render("\n  Expense not found.\n") + HelpStyle.Render("\n  Esc: Back"))
+	}
+
+	content := fmt.Sprintf("\n  %s  %s\n\n", SelectedMenuItemStyle.Render(exp.Description), MutedStyle.Render("["+string(exp.Category)+"]"))
+	content += fmt.Sprintf("  Amount:    %s\n", m.formatAmountPlain(exp.Amount, m.currencyOf(exp.Currency)))
+	// Only show a converted amount when there is a rate for it; ToBase falls back to 1:1
+	rates := m.storage.GetData().Rates
+	if rate, ok := rates.Rates[strings.ToUpper(exp.Currency)]; ok && rate > 0 && !strings.EqualFold(exp.Currency, m.config.Currency) {
+		content += fmt.Sprintf("             %s\n", MutedStyle.Render("≈ "+m.formatAmountPlain(rates.ToBase(exp.Amount, exp.Currency), m.config.Currency)))
+	}
+	content += fmt.Sprintf("  Category:  %s\n", exp.Category)
+	content += fmt.Sprintf("  Date:      %s %s\n", exp.Date.Format("2006-01-02"), MutedStyle.Render(exp.Date.Format("Monday")))
+	if len(exp.Tags) > 0 {
+		content += fmt.Sprintf("  Tags:      %s\n", formatTags(exp.Tags))
+	}
+	if exp.RecurringID != "" {
+		source := "a recurring expense (since deleted)"
+		for _, rec := range m.storage.GetRecurringExpenses() {
+			if rec.ID == exp.RecurringID {
+				source = fmt.Sprintf("%s, day %d of each month", rec.Description, rec.DayOfMonth)
+				break
+			}
+		}
+		content += fmt.Sprintf("  From:      %s\n", source)
+	}
+	if !exp.CreatedAt.IsZero() {
+		content += fmt.Sprintf("  Recorded:  %s\n", exp.CreatedAt.Format("2006-01-02 15:04"))
+	}
+
+	content += "\n  Notes:\n"
+	if exp.Notes == "" {
+		content += MutedStyle.Render("  (none)") + "\n"
+	} else {
+		content += "  " + exp.Notes + "\n"
+	}
+
+	help := HelpStyle.Render("\n  n: Edit notes • Esc: Back")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateExpenseDetailView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.popView()
+		m.selectedID = ""
+	}
+
+	return m, nil
+}
+
+func (m Model) viewEditExpenseNotes() string {
+	title := TitleStyle.Render("  Edit Expense Notes")
+
+	var content string
+	if exp := m.selectedExpense(); exp != nil {
+		content = fmt.Sprintf("\n  %s\n\n", SelectedMenuItemStyle.Render(exp.Description))
+	}
+	if len(m.inputs) > 0 {
+		content += "  " + FocusedInputStyle.Render(m.inputs[0].View()) + "\n"
+	}
+
+	help := HelpStyle.Render("\n  Enter: Save • Esc: Cancel")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateEditExpenseNotesView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		if err := m.storage.UpdateExpenseNotes(m.selectedID, strings.TrimSpace(m.inputs[0].Value())); err != nil {
+			m.message = "Error saving: " + err.Error()
+			m.messageType = "error"
+			return m, nil
+		}
+		m.message = "Notes updated!"
+		m.messageType = "success"
+		m.popView()
+		m.inputs = nil
+		return m, nil
+	case "esc":
+		m.popView()
+		m.inputs = nil
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.inputs[0], cmd = m.inputs[0].Update(msg)
+	return m, cmd
+}
+
+// expenseAtRow maps a row of the newest-first Expenses list back to the
+// expense it shows, or nil when the row is out of range
+func expenseAtRow(expenses []models.Expense, row int) *models.Expense {
+	idx := len(expenses) - 1 - row
+	if idx < 0 || idx >= len(expenses) {
+		return nil
+	}
+	return &expenses[idx]
+}
+
+// selectedExpense returns the expense identified by selectedID, if any
+func (m Model) selectedExpense() *models.Expense {
+	for _, exp := range m.storage.GetExpenses() {
+		if exp.ID == m.selectedID {
+			return &exp
+		}
+	}
+	return nil
+}
+
+// Debts view
+func (m Model) viewDebts() string {
+	title := TitleStyle.Render("  Borrowing & Lending")
+
+	debts := m.storage.GetUnsettledDebts()
+	data := m.storage.GetData()
+
+	var content string
+	if len(debts) == 0 {
+		content = MutedStyle.Render("\n  No unsettled debts.\n")
+	} else {
+		// Group debts by person (combine borrowed and lent)
+		type personGroup struct {
+			name          string
+			totalLent     float64
+			totalBorrowed float64
+			lentDebts     []models.DebtTransaction
+			borrowedDebts []models.DebtTransaction
+		}
+
+		// Use a map to group by person name only
+		groupMap := make(map[string]*personGroup)
+
+		for _, debt := range debts {
+			key := storage.NormalizeName(debt.PersonName)
+			if _, exists := groupMap[key]; !exists {
+				groupMap[key] = &personGroup{
+					name:          key,
+					totalLent:     0,
+					totalBorrowed: 0,
+					lentDebts:     []models.DebtTransaction{},
+					borrowedDebts: []models.DebtTransaction{},
+				}
+			}
+			if debt.Type == models.Lent {
+				groupMap[key].totalLent += data.Rates.ToBase(debt.Amount, debt.Currency)
+				groupMap[key].lentDebts = append(groupMap[key].lentDebts, debt)
+			} else {
+				groupMap[key].totalBorrowed += data.Rates.ToBase(debt.Amount, debt.Currency)
+				groupMap[key].borrowedDebts = append(groupMap[key].borrowedDebts, debt)
+			}
+		}
+
+		content = "\n"
+		// Only people with a nonzero net balance, in the order the key handlers use
+		persons := m.visiblePersons()
+		selected := min(m.cursor, len(persons)-1)
+		for i, key := range persons {
+			group := groupMap[key]
+
+			// Calculate net balance (positive = they owe you, negative = you owe them)
+			netBalance := group.totalLent - group.totalBorrowed
+
+			cursor := "  "
+			if i == selected {
+				cursor = "▸ "
+			}
+
+			var netStatus string
+			if netBalance > 0 {
+				netStatus = AmountPositiveStyle.Render(fmt.Sprintf("owes you %s", m.formatAmountPlain(netBalance, m.config.Currency)))
+			} else {
+				netStatus = AmountNegativeStyle.Render(fmt.Sprintf("you owe %s", m.formatAmountPlain(-netBalance, m.config.Currency)))
+			}
+
+			header := fmt.Sprintf("%s%s  [%s]",
+				cursor,
+				SelectedMenuItemStyle.Render(group.name),
+				netStatus,
+			)
+			content += header + "\n"
+
+			// Show lent transactions
+			if len(group.lentDebts) > 0 {
+				content += "      " + AmountPositiveStyle.Render("Lent:") + "\n"
+				for _, debt := range group.lentDebts {
+					reason := debt.Description
+					if reason == "" {
+						reason = "(no description)"
+					}
+					line := fmt.Sprintf("        + %s - %s  %s%s",
+						m.formatAmountPlain(debt.Amount, m.currencyOf(debt.Currency)),
+						MutedStyle.Render(truncate(reason, 25)),
+						MutedStyle.Render(debt.Date.Format("2006-01-02")),
+						dueDateLabel(debt),
+					)
+					content += line + "\n"
+				}
+			}
+
+			// Show borrowed transactions
+			if len(group.borrowedDebts) > 0 {
+				content += "      " + AmountNegativeStyle.Render("Borrowed:") + "\n"
+				for _, debt := range group.borrowedDebts {
+					reason := debt.Description
+					if reason == "" {
+						reason = "(no description)"
+					}
+					line := fmt.Sprintf("        - %s - %s  %s%s",
+						m.formatAmountPlain(debt.Amount, m.currencyOf(debt.Currency)),
+						MutedStyle.Render(truncate(reason, 25)),
+						MutedStyle.Render(debt.Date.Format("2006-01-02")),
+						dueDateLabel(debt),
+					)
+					content += line + "\n"
+				}
+			}
+			content += "\n"
+		}
+	}
+
+	// Summary
+	stats := fmt.Sprintf("  Total Borrowed: %s | Total Lent: %s | Net: %s",
+		AmountNegativeStyle.Render(m.formatAmountPlain(data.TotalBorrowed(), m.config.Currency)),
+		AmountPositiveStyle.Render(m.formatAmountPlain(data.TotalLent(), m.config.Currency)),
+		m.formatAmount(data.TotalLent()-data.TotalBorrowed(), m.config.Currency),
+	)
+	if overdue := len(m.storage.GetOverdueDebts()); overdue > 0 {
+		stats += " | " + ErrorStyle.Render(fmt.Sprintf("%d overdue", overdue))
+	}
+	if missing := len(m.storage.DebtsMissingDueDate()); missing > 0 {
+		stats += "\n  " + WarningStyle.Render(fmt.Sprintf("%d debt(s) without a due date", missing))
+	}
+
+	help := HelpStyle.Render("\n  a: Add debt • B: Split bill • s: Settle • S: Settle all • h: Person history • g: All payments • m: Missing due dates • b: By reason • p: People • t: Balances table • r: Rename/merge • u: Undo last settlement • Esc: Back")
+
+	return BoxStyle.Render(title + content + stats + help)
+}
+
+// visiblePersons returns the people listed in the Debts view, in display order:
+// everyone with unsettled debts whose net balance is not zero. The view and its
+// key handlers both index into this, so the cursor always means the same person.
+func (m Model) visiblePersons() []string {
+	var order []string
+	seen := make(map[string]bool)
+	for _, debt := range m.storage.GetUnsettledDebts() {
+		key := storage.NormalizeName(debt.PersonName)
+		if !seen[key] {
+			seen[key] = true
+			order = append(order, key)
+		}
+	}
+	var persons []string
+	for _, name := range order {
+		if m.storage.GetPersonNetBalance(name) != 0 {
+			persons = append(persons, name)
+		}
+	}
+	return persons
+}
+
+func (m *Model) updateDebtsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	persons := m.visiblePersons()
+	maxCursor := len(persons) - 1
+	if maxCursor < 0 {
+		maxCursor = 0
+	}
+
+	// A settlement elsewhere may have removed people since the cursor last moved
+	if m.cursor > maxCursor {
+		m.cursor = maxCursor
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < maxCursor {
+			m.cursor++
+		}
+	case "esc":
+		m.popView()
+		m.cursor = 0
+	}
+
+	return m, nil
+}
+
+func (m *Model) initDebtInputs() {
+	m.inputs = make([]textinput.Model, 8)
+
+	m.inputs[0] = textinput.New()
+	m.inputs[0].Placeholder = "Type (borrowed/lent)"
+	m.inputs[0].Focus()
+
+	m.inputs[1] = textinput.New()
+	m.inputs[1].Placeholder = "Person Name"
+
+	m.inputs[2] = textinput.New()
+	m.inputs[2].Placeholder = "Amount"
+
+	m.inputs[3] = textinput.New()
+	m.inputs[3].Placeholder = "Description"
+
+	m.inputs[4] = textinput.New()
+	m.inputs[4].Placeholder = "Transaction Date (YYYY-MM-DD)"
+	m.inputs[4].SetValue(time.Now().Format("2006-01-02"))
+
+	m.inputs[5] = textinput.New()
+	m.inputs[5].Placeholder = "Due Date (YYYY-MM-DD, optional)"
+
+	m.inputs[6] = textinput.New()
+	m.inputs[6].Placeholder = "Interest % per year (optional)"
+
+	m.inputs[7] = textinput.New()
+	m.inputs[7].Placeholder = "Monthly installments (optional)"
+
+	m.focusIndex = 0
+}
+
+func (m Model) viewAddDebt() string {
+	title := TitleStyle.Render("  Add Debt Transaction")
+
+	var content string
+	labels := []string{"Type:", "Person:", "Amount:", "Description:", "Date:", "Due Date:", "Interest:", "Installments:"}
+	hints := []string{
+		"Options: borrowed, lent",
+		"Existing people are suggested as you type; a new name adds a new person",
+		"Add a currency code for other currencies, e.g. 20 USD",
+		"",
+		"Date when borrowed/lent (YYYY-MM-DD)",
+		"When it should be paid back (YYYY-MM-DD, leave empty for none)",
+		"Annual %, e.g. 12 or 8 compound; add 30d for 30 interest-free days (leave empty for none)",
+		"Repaid as an EMI in this many equal payments, e.g. 12 (leave empty for a one-off debt)",
+	}
+
+	for i, input := range m.inputs {
+		label := labels[i]
+		if i == m.focusIndex {
+			content += SelectedMenuItemStyle.Render("▸ "+label) + "\n"
+			content += "  " + FocusedInputStyle.Render(input.View()) + "\n"
+			if i == 1 {
+				if matches := m.personSuggestions(); len(matches) > 0 {
+					content += "  " + SelectedMenuItemStyle.Render("▸ "+matches[0]) + MutedStyle.Render("  Tab to use") + "\n"
+					for _, name := range matches[1:] {
+						content += "  " + MutedStyle.Render("  "+name) + "\n"
+					}
+				}
+			}
+			if hints[i] != "" {
+				content += "  " + MutedStyle.Render(hints[i]) + "\n"
+			}
+			content += "\n"
+		} else {
+			content += MenuItemStyle.Render("  "+label) + "\n"
+			content += "  " + InputStyle.Render(input.View()) + "\n"
+			if hints[i] != "" {
+				content += "  " + MutedStyle.Render(hints[i]) + "\n"
+			}
+			content += "\n"
+		}
+	}
+
+	help := HelpStyle.Render("+: Calculate • Tab: Next field (accepts a suggested name) • Enter: Save • Esc: Cancel")
+
+	return BoxStyle.Render(title + "\n" + content + help)
+}
+
+func (m *Model) updateAddDebtView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Tab in the person field takes the top suggestion before moving on
+	if msg.String() == "tab" && m.focusIndex == 1 && m.acceptSuggestion(1, m.personSuggestions()) {
+		return m, nil
+	}
+	if m.updateFormFocus(msg.String()) {
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "enter":
+		txType := models.TransactionType(m.inputs[0].Value())
+		if txType != models.Borrowed && txType != models.Lent {
+			m.message = "Type must be 'borrowed' or 'lent'"
+			m.messageType = "error"
+			return m, nil
+		}
+
+		personName := m.inputs[1].Value()
+		if personName == "" {
+			m.message = "Person name is required"
+			m.messageType = "error"
+			return m, nil
+		}
+
+		amount, currency, err := m.parseMoney(m.inputs[2].Value())
+		if err != nil {
+			m.message = "Invalid amount: " + err.Error()
+			m.messageType = "error"
+			return m, nil
+		}
+
+		description := m.inputs[3].Value()
+
+		// Parse transaction date
+		dateStr := m.inputs[4].Value()
+		if dateStr == "" {
+			m.message = "Transaction date is required"
+			m.messageType = "error"
+			return m, nil
+		}
+		transactionDate, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			m.message = "Invalid date format. Use YYYY-MM-DD"
+			m.messageType = "error"
+			return m, nil
+		}
+
+		var dueDate *time.Time
+		if dueStr := m.inputs[5].Value(); dueStr != "" {
+			parsed, err := time.Parse("2006-01-02", dueStr)
+			if err != nil {
+				m.message = "Invalid due date format. Use YYYY-MM-DD"
+				m.messageType = "error"
+				return m, nil
+			}
+			dueDate = &parsed
+		}
+
+		rate, interestType, graceDays, err := parseInterest(m.inputs[6].Value())
+		if err != nil {
+			m.message = "Invalid interest: " + err.Error()
+			m.messageType = "error"
+			return m, nil
+		}
+
+		installments := 0
+		if value := strings.TrimSpace(m.inputs[7].Value()); value != "" {
+			installments, err = strconv.Atoi(value)
+			if err != nil || installments < 2 {
+				m.message = "Installments must be a whole number of at least 2"
+				m.messageType = "error"
+				return m, nil
+			}
+			if rate > 0 {
+				m.message = "Installment debts cannot also accrue interest; include it in the amount"
+				m.messageType = "error"
+				return m, nil
+			}
+		}
+
+		if installments > 0 {
+			_, err = m.storage.AddInstallmentDebt(txType, personName, amount, installments, description, transactionDate, dueDate, currency)
+		} else {
+			_, err = m.storage.AddDebtTransactionWithInterest(txType, personName, amount, description, transactionDate, dueDate, rate, interestType, graceDays, currency)
+		}
+		if err != nil {
+			m.message = "Error saving: " + err.Error()
+			m.messageType = "error"
+			return m, nil
+		}
+
+		m.message = "Debt transaction added!"
+		m.messageType = "success"
+		m.popView()
+		m.inputs = nil
+		m.cursor = 0
+		return m, nil
+	case "+":
+		if m.focusIndex == 2 && len(m.inputs) > 0 {
+			currentValue := m.inputs[2].Value()
+			calculatedValue, success := tryCalculateAmount(currentValue)
+			if success {
+				m.inputs[2].SetValue(calculatedValue)
+				m.message = "Calculated: " + calculatedValue
+				m.messageType = "info"
+			}
+		}
+	case "esc":
+		m.popView()
+		m.inputs = nil
+		m.cursor = 0
+		return m, nil
+	}
+
+	if len(m.inputs) > 0 && m.focusIndex < len(m.inputs) {
+		var cmd tea.Cmd
+		m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
+
+		// Auto-calculate in amount field (index 2) when trailing operator is typed
+		if m.focusIndex == 2 {
+			m.autoCalculateIfNeeded(2)
+		}
+		return m, cmd
+	}
+	return m, nil
+}
+
+// Split Bill view - records a bill you paid as one Lent debt per person
+func (m *Model) initSplitBillInputs() {
+	m.inputs = make([]textinput.Model, 4)
+
+	m.inputs[0] = textinput.New()
+	m.inputs[0].Placeholder = "Total"
+	m.inputs[0].Focus()
+
+	m.inputs[1] = textinput.New()
+	m.inputs[1].Placeholder = "Description"
+
+	m.inputs[2] = textinput.New()
+	m.inputs[2].Placeholder = "Date (YYYY-MM-DD)"
+	m.inputs[2].SetValue(time.Now().Format("2006-01-02"))
+
+	m.inputs[3] = textinput.New()
+	m.inputs[3].Placeholder = "People (e.g. Raj, Priya, me)"
+
+	m.focusIndex = 0
+}
+
+func (m Model) viewSplitBill() string {
+	title := TitleStyle.Render("  Split a Bill")
+
+	var content string
+	labels := []string{"Total:", "Description:", "Date:", "People:"}
+	hints := []string{
+		"What you paid, in " + m.config.Currency,
+		"",
+		"Date of the bill (YYYY-MM-DD)",
+		"Comma-separated; add \"me\" for your own share and name:2 for a double share",
+	}
+
+	for i, input := range m.inputs {
+		label := labels[i]
+		if i == m.focusIndex {
+			content += SelectedMenuItemStyle.Render("▸ "+label) + "\n"
+			content += "  " + FocusedInputStyle.Render(input.View()) + "\n"
+			if hints[i] != "" {
+				content += "  " + MutedStyle.Render(hints[i]) + "\n"
+			}
+			content += "\n"
+		} else {
+			content += MenuItemStyle.Render("  "+label) + "\n"
+			content += "  " + InputStyle.Render(input.View()) + "\n"
+			if hints[i] != "" {
+				content += "  " + MutedStyle.Render(hints[i]) + "\n"
+			}
+			content += "\n"
+		}
+	}
+
+	if preview, err := m.splitBillPreview(); err == nil {
+		content += "  Preview:\n" + preview
+	}
+
+	help := HelpStyle.Render("\n+: Calculate • Tab: Next field • Enter: Review and save • Esc: Cancel")
+
+	return BoxStyle.Render(title + "\n" + content + help)
+}
+
+// splitBillPreview renders each person's share of the bill in the form
+func (m Model) splitBillPreview() (string, error) {
+	total, err := parseAmount(m.inputs[0].Value())
+	if err != nil {
+		return "", fmt.Errorf("invalid total: %w", err)
+	}
+	people, weights, err := parseSplitPeople(m.inputs[3].Value())
+	if err != nil {
+		return "", err
+	}
+
+	var preview string
+	for i, share := range m.storage.SplitAmount(total, weights) {
+		line := fmt.Sprintf("    %s  %s", TableCellStyle.Width(16).Render(truncate(people[i], 14)), m.formatAmountPlain(share, m.config.Currency))
+		if storage.NormalizeName(people[i]) == storage.SplitSelf {
+			line += MutedStyle.Render("  your share, not recorded")
+		} else {
+			line += AmountPositiveStyle.Render("  owes you")
+		}
+		preview += line + "\n"
+	}
+	return preview, nil
+}
+
+// forDescription returns " for <description>", or nothing when it is empty
+func forDescription(description string) string {
+	if description == "" {
+		return ""
+	}
+	return " for " + description
+}
+
+// parseSplitPeople parses "Raj, Priya:2, me" into names and their weights
+func parseSplitPeople(input string) ([]string, []float64, error) {
+	var people []string
+	var weights []float64
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, weight := part, 1.0
+		if i := strings.LastIndex(part, ":"); i >= 0 {
+			w, err := strconv.ParseFloat(strings.TrimSpace(part[i+1:]), 64)
+			if err != nil || w <= 0 {
+				return nil, nil, fmt.Errorf("invalid share for %q, use name:2", part)
+			}
+			name, weight = strings.TrimSpace(part[:i]), w
+		}
+		people = append(people, name)
+		weights = append(weights, weight)
+	}
+	if len(people) == 0 {
+		return nil, nil, fmt.Errorf("name the people to split with")
+	}
+	return people, weights, nil
+}
+
+func (m *Model) updateSplitBillView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.updateFormFocus(msg.String()) {
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "enter":
+		preview, err := m.splitBillPreview()
+		if err != nil {
+			m.message = err.Error()
+			m.messageType = "error"
+			return m, nil
+		}
+		total, _ := parseAmount(m.inputs[0].Value())
+		description := strings.TrimSpace(m.inputs[1].Value())
+		date, err := time.Parse("2006-01-02", m.inputs[2].Value())
+		if err != nil {
+			m.message = "Invalid date format. Use YYYY-MM-DD"
+			m.messageType = "error"
+			return m, nil
+		}
+		people, weights, _ := parseSplitPeople(m.inputs[3].Value())
+
+		m.askConfirm(confirmation{
+			title: "Confirm Split",
+			body:  fmt.Sprintf("\n  Split %s%s:\n\n%s\n", m.formatAmountPlain(total, m.config.Currency), forDescription(description), preview),
+			yes:   "Record debts",
+			run: func(m *Model) {
+				added, err := m.storage.AddWeightedSplitDebt(total, description, date, people, weights)
+				if err != nil {
+					m.message = "Error saving: " + err.Error()
+					m.messageType = "error"
+					return
+				}
+				m.message = fmt.Sprintf("Split %s: %d debts added", m.formatAmountPlain(total, m.config.Currency), len(added))
+				m.messageType = "success"
+				m.popView()
+				m.inputs = nil
+				m.cursor = 0
+			},
+		})
+		return m, nil
+	case "+":
+		if m.focusIndex == 0 {
+			if calculated, ok := tryCalculateAmount(m.inputs[0].Value()); ok {
+				m.inputs[0].SetValue(calculated)
+				m.message = "Calculated: " + calculated
+				m.messageType = "info"
+			}
+		}
+	case "esc":
+		m.popView()
+		m.inputs = nil
+		m.cursor = 0
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
+	if m.focusIndex == 0 {
+		m.autoCalculateIfNeeded(0)
+	}
+	return m, cmd
+}
+
+// Settle Person view - settles the whole net balance with one person, offsetting
+// what you lent against what you borrowed
+func (m Model) viewSettlePerson() string {
+	title := TitleStyle.Render("  Settle Everything")
+
+	netBalance := m.storage.GetPersonNetBalance(m.selectedPerson)
+	open := m.storage.GetUnsettledDebtsForPerson(m.selectedPerson)
+
+	content := fmt.Sprintf("\n  With %s:\n\n", SelectedMenuItemStyle.Render(m.selectedPerson))
+	switch {
+	case netBalance > 0:
+		content += "  " + AmountPositiveStyle.Render("Receive "+m.formatAmountPlain(netBalance, m.config.Currency)) + " to clear the balance\n"
+	case netBalance < 0:
+		content += "  " + AmountNegativeStyle.Render("Pay "+m.formatAmountPlain(-netBalance, m.config.Currency)) + " to clear the balance\n"
+	default:
+		content += "  " + MutedStyle.Render("Balances cancel out; no money changes hands") + "\n"
+	}
+	content += MutedStyle.Render(fmt.Sprintf("  All %d open transaction(s) will be marked settled.", len(open))) + "\n\n"
+
+	if len(m.inputs) > 0 {
+		content += SelectedMenuItemStyle.Render("▸ Settlement Note:") + "\n"
+		content += "  " + FocusedInputStyle.Render(m.inputs[0].View()) + "\n"
+	}
+
+	help := HelpStyle.Render("Enter: Settle all • Esc: Cancel")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateSettlePersonView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		netBalance := m.storage.GetPersonNetBalance(m.selectedPerson)
+		settled, err := m.storage.SettleAmountForPerson(m.selectedPerson, 0, strings.TrimSpace(m.inputs[0].Value()))
+		if err != nil {
+			m.message = "Error settling: " + err.Error()
+			m.messageType = "error"
+			return m, nil
+		}
+
+		switch {
+		case netBalance > 0:
+			m.message = fmt.Sprintf("Settled everything with %s: received %s", m.selectedPerson, m.formatAmountPlain(settled, m.config.Currency))
+		case netBalance < 0:
+			m.message = fmt.Sprintf("Settled everything with %s: paid %s", m.selectedPerson, m.formatAmountPlain(settled, m.config.Currency))
+		default:
+			m.message = fmt.Sprintf("Settled everything with %s", m.selectedPerson)
+		}
+		m.messageType = "success"
+		m.popView()
+		m.inputs = nil
+		m.selectedPerson = ""
+		m.cursor = 0
+		return m, nil
+	case "esc":
+		m.popView()
+		m.inputs = nil
+		m.selectedPerson = ""
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.inputs[0], cmd = m.inputs[0].Update(msg)
+	return m, cmd
+}
+
+// Settle Debt functions - now settles a specific transaction with a note
+func (m *Model) initSettleDebtInputs() {
+	// Find the selected transaction to get the remaining amount
+	var remainingAmount float64
+	for _, tx := range m.storage.GetDebtTransactions() {
+		if tx.ID == m.selectedTxID {
+			remainingAmount = tx.Amount
+			break
+		}
+	}
+
+	m.inputs = make([]textinput.Model, 2)
+
+	m.inputs[0] = textinput.New()
+	m.inputs[0].Placeholder = fmt.Sprintf("Amount (max: %.2f, leave empty for full)", remainingAmount)
+	m.inputs[0].Focus()
+
+	m.inputs[1] = textinput.New()
+	m.inputs[1].Placeholder = "Settlement note (e.g., Cash, Bank transfer, UPI)"
+
+	m.focusIndex = 0
+}
+
+func (m Model) viewSettleDebt() string {
+	title := TitleStyle.Render("  Settle Transaction")
+
+	// Find the selected transaction
+	var selectedTx *models.DebtTransaction
+	for _, tx := range m.storage.GetDebtTransactions() {
+		if tx.ID == m.selectedTxID {
+			selectedTx = &tx
+			break
+		}
+	}
+
+	var content string
+	if selectedTx == nil {
+		content = MutedStyle.Render("\n  Transaction not found.\n")
+	} else {
+		txType := AmountPositiveStyle.Render("LENT to")
+		if selectedTx.Type == models.Borrowed {
+			txType = AmountNegativeStyle.Render("BORROWED from")
+		}
+		desc := selectedTx.Description
+		if desc == "" {
+			desc = "(no description)"
+		}
+
+		content = fmt.Sprintf("\n  %s %s\n", txType, SelectedMenuItemStyle.Render(selectedTx.PersonName))
+		content += fmt.Sprintf("  Remaining: %s", m.formatAmountPlain(selectedTx.Amount, m.currencyOf(selectedTx.Currency)))
+		if selectedTx.OriginalAmount > selectedTx.Amount {
+			content += fmt.Sprintf(" (of %s original)", m.formatAmountPlain(selectedTx.OriginalAmount, m.currencyOf(selectedTx.Currency)))
+		}
+		content += "\n"
+		content += fmt.Sprintf("  Date: %s\n", selectedTx.Date.Format("2006-01-02"))
+		content += fmt.Sprintf("  Description: %s\n\n", MutedStyle.Render(desc))
+
+		if len(m.inputs) >= 2 {
+			labels := []string{"Amount to settle:", "Settlement Note:"}
+			hints := []string{
+				"Leave empty for full settlement",
 				"Optional: How was this settled? (Cash, UPI, Bank transfer)",
 			}
-			for i, input := range m.inputs {
-				if i == m.focusIndex {
-					content += "  " + SelectedMenuItemStyle.Render("▸ "+labels[i]) + "\n"
-					content += "  " + FocusedInputStyle.Render(input.View()) + "\n"
-					content += "  " + MutedStyle.Render(hints[i]) + "\n\n"
-				} else {
-					content += "  " + MenuItemStyle.Render("  "+labels[i]) + "\n"
-					content += "  " + InputStyle.Render(input.View()) + "\n"
-					content += "  " + MutedStyle.Render(hints[i]) + "\n\n"
-				}
+			for i, input := range m.inputs {
+				if i == m.focusIndex {
+					content += "  " + SelectedMenuItemStyle.Render("▸ "+labels[i]) + "\n"
+					content += "  " + FocusedInputStyle.Render(input.View()) + "\n"
+					content += "  " + MutedStyle.Render(hints[i]) + "\n\n"
+				} else {
+					content += "  " + MenuItemStyle.Render("  "+labels[i]) + "\n"
+					content += "  " + InputStyle.Render(input.View()) + "\n"
+					content += "  " + MutedStyle.Render(hints[i]) + "\n\n"
+				}
+			}
+		}
+	}
+
+	help := HelpStyle.Render("  +: Calculate • Tab: Next field • Enter: Confirm • Esc: Cancel")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateSettleDebtView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keyStr := msg.String()
+
+	if m.updateFormFocus(keyStr) {
+		return m, nil
+	}
+
+	switch keyStr {
+	case "enter":
+		// Auto-calculate if input contains math operators
+		if len(m.inputs) > 0 && m.inputs[0].Value() != "" {
+			inputVal := m.inputs[0].Value()
+			if strings.ContainsAny(inputVal, "+-*/()") {
+				calculatedValue, success := tryCalculateAmount(inputVal)
+				if success {
+					m.inputs[0].SetValue(calculatedValue)
+					m.message = "Calculated: " + calculatedValue
+					m.messageType = "info"
+				} else {
+					m.message = "Calc failed for: " + inputVal
+					if _, err := evaluateMathExpression(inputVal); err != nil {
+						m.message += " (" + err.Error() + ")"
+					}
+					m.messageType = "error"
+					return m, nil
+				}
+			}
+		}
+		// Parse amount
+		var amount float64
+		if len(m.inputs) > 0 && m.inputs[0].Value() != "" {
+			var err error
+			amount, err = parseAmount(m.inputs[0].Value())
+			if err != nil {
+				m.message = "Invalid amount"
+				m.messageType = "error"
+				return m, nil
+			}
+			if amount <= 0 {
+				m.message = "Amount must be positive"
+				m.messageType = "error"
+				return m, nil
+			}
+		}
+
+		note := ""
+		if len(m.inputs) > 1 {
+			note = m.inputs[1].Value()
+		}
+
+		err := m.storage.SettleTransactionWithNote(m.selectedTxID, amount, note)
+		if err != nil {
+			m.message = "Error settling: " + err.Error()
+			m.messageType = "error"
+			return m, nil
+		}
+
+		if amount > 0 {
+			m.message = fmt.Sprintf("Settled %s %.2f with %s!", m.config.Currency, amount, m.selectedPerson)
+		} else {
+			m.message = fmt.Sprintf("Fully settled with %s!", m.selectedPerson)
+		}
+		m.messageType = "success"
+		// Back to the person's transactions, or all the way to Debts once nothing is left to settle
+		m.popView()
+		if len(m.storage.GetUnsettledDebtsForPerson(m.selectedPerson)) == 0 {
+			m.popToView(ViewDebts)
+		}
+		m.inputs = nil
+		m.selectedPerson = ""
+		m.selectedTxID = ""
+		m.cursor = 0
+		return m, nil
+	case "esc":
+		m.popView()
+		m.inputs = nil
+		m.selectedTxID = ""
+		m.cursor = 0
+		return m, nil
+	}
+
+	if len(m.inputs) > 0 && m.focusIndex < len(m.inputs) {
+		// Get value before update
+		oldValue := m.inputs[m.focusIndex].Value()
+
+		var cmd tea.Cmd
+		m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
+
+		// Get value after update
+		newValue := m.inputs[m.focusIndex].Value()
+
+		// Auto-calculate in amount field (index 0) when trailing operator is typed
+		if m.focusIndex == 0 && newValue != oldValue {
+			m.autoCalculateIfNeeded(0)
+		}
+		return m, cmd
+	}
+	return m, nil
+}
+
+// Select Transaction view - shows unsettled transactions for a person to pick one
+func (m Model) viewSelectTransaction() string {
+	title := TitleStyle.Render("  Select Transaction to Settle")
+
+	transactions := m.storage.GetUnsettledDebtsForPerson(m.selectedPerson)
+
+	var content string
+	if len(transactions) == 0 {
+		content = MutedStyle.Render(fmt.Sprintf("\n  No unsettled transactions for %s.\n", m.selectedPerson))
+	} else {
+		content = fmt.Sprintf("\n  Transactions with %s:\n\n", SelectedMenuItemStyle.Render(m.selectedPerson))
+		for i, tx := range transactions {
+			cursor := "  "
+			if i == m.cursor {
+				cursor = "▸ "
+			}
+			txType := AmountPositiveStyle.Render("LENT")
+			if tx.Type == models.Borrowed {
+				txType = AmountNegativeStyle.Render("BORROWED")
+			}
+			desc := tx.Description
+			if desc == "" {
+				desc = "(no description)"
+			}
+			line := fmt.Sprintf("%s%s  %s  %s  %s",
+				cursor,
+				tx.Date.Format("2006-01-02"),
+				txType,
+				m.formatAmountPlain(tx.Amount, m.currencyOf(tx.Currency)),
+				MutedStyle.Render(truncate(desc, 30)),
+			)
+			content += line + "\n"
+		}
+	}
+
+	help := HelpStyle.Render("\n  Enter: Settle • f: Settle in full • e: Edit • d: Delete • h: Settlement history • Esc: Back")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateSelectTransactionView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	transactions := m.storage.GetUnsettledDebtsForPerson(m.selectedPerson)
+	maxCursor := len(transactions) - 1
+	if maxCursor < 0 {
+		maxCursor = 0
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < maxCursor {
+			m.cursor++
+		}
+	case "enter":
+		if len(transactions) > 0 && m.cursor < len(transactions) {
+			m.selectedTxID = transactions[m.cursor].ID
+			m.pushView(ViewSettleDebt)
+			m.initSettleDebtInputs()
+		}
+	case "f":
+		// Quick full settle - first press asks for confirmation, second press settles
+		if len(transactions) > 0 && m.cursor < len(transactions) {
+			tx := transactions[m.cursor]
+			if m.pendingSettle != tx.ID {
+				m.pendingSettle = tx.ID
+				m.message = fmt.Sprintf("Press f again to fully settle %s with %s",
+					m.formatAmountPlain(tx.Amount, m.currencyOf(tx.Currency)), tx.PersonName)
+				m.messageType = "info"
+				return m, nil
+			}
+
+			m.pendingSettle = ""
+			if err := m.storage.SettleTransactionWithNote(tx.ID, tx.Amount, ""); err != nil {
+				m.message = "Error settling: " + err.Error()
+				m.messageType = "error"
+				return m, nil
+			}
+
+			m.message = fmt.Sprintf("Fully settled %s with %s!",
+				m.formatAmountPlain(tx.Amount, m.currencyOf(tx.Currency)), tx.PersonName)
+			m.messageType = "success"
+			if m.cursor >= len(transactions)-1 && m.cursor > 0 {
+				m.cursor--
+			}
+		}
+	case "esc":
+		m.popView()
+		m.selectedPerson = ""
+		m.cursor = 0
+	}
+
+	return m, nil
+}
+
+// Edit Debt view - corrects amount, description and date of a transaction
+func (m *Model) initEditDebtInputs(tx models.DebtTransaction) {
+	original := tx.OriginalAmount
+	if original == 0 {
+		original = tx.Amount
+	}
+
+	m.inputs = make([]textinput.Model, 3)
+
+	m.inputs[0] = textinput.New()
+	m.inputs[0].Placeholder = "Amount"
+	m.inputs[0].SetValue(fmt.Sprintf("%.2f", original))
+	m.inputs[0].Focus()
+
+	m.inputs[1] = textinput.New()
+	m.inputs[1].Placeholder = "Description"
+	m.inputs[1].SetValue(tx.Description)
+
+	m.inputs[2] = textinput.New()
+	m.inputs[2].Placeholder = "Transaction Date (YYYY-MM-DD)"
+	m.inputs[2].SetValue(tx.Date.Format("2006-01-02"))
+
+	m.focusIndex = 0
+}
+
+func (m Model) viewEditDebt() string {
+	title := TitleStyle.Render("  Edit Debt Transaction")
+
+	var content string
+	labels := []string{"Amount:", "Description:", "Date:"}
+	hints := []string{
+		"Original amount; anything already settled stays settled",
+		"",
+		"Date when borrowed/lent (YYYY-MM-DD)",
+	}
+
+	for i, input := range m.inputs {
+		label := labels[i]
+		if i == m.focusIndex {
+			content += SelectedMenuItemStyle.Render("▸ "+label) + "\n"
+			content += "  " + FocusedInputStyle.Render(input.View()) + "\n"
+		} else {
+			content += MenuItemStyle.Render("  "+label) + "\n"
+			content += "  " + InputStyle.Render(input.View()) + "\n"
+		}
+		if hints[i] != "" {
+			content += "  " + MutedStyle.Render(hints[i]) + "\n"
+		}
+		content += "\n"
+	}
+
+	help := HelpStyle.Render("+: Calculate • Tab: Next field • Enter: Save • Esc: Cancel")
+
+	return BoxStyle.Render(title + "\n" + content + help)
+}
+
+func (m *Model) updateEditDebtView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.updateFormFocus(msg.String()) {
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "enter":
+		amount, err := parseAmount(m.inputs[0].Value())
+		if err != nil || amount <= 0 {
+			m.message = "Invalid amount"
+			m.messageType = "error"
+			return m, nil
+		}
+
+		date, err := time.Parse("2006-01-02", m.inputs[2].Value())
+		if err != nil {
+			m.message = "Invalid date format. Use YYYY-MM-DD"
+			m.messageType = "error"
+			return m, nil
+		}
+
+		if err := m.storage.EditDebtTransaction(m.selectedTxID, amount, m.inputs[1].Value(), date); err != nil {
+			m.message = "Error saving: " + err.Error()
+			m.messageType = "error"
+			return m, nil
+		}
+
+		m.message = "Transaction updated!"
+		m.messageType = "success"
+		m.popView()
+		m.inputs = nil
+		m.selectedTxID = ""
+		return m, nil
+	case "+":
+		if m.focusIndex == 0 && len(m.inputs) > 0 {
+			currentValue := m.inputs[0].Value()
+			calculatedValue, success := tryCalculateAmount(currentValue)
+			if success {
+				m.inputs[0].SetValue(calculatedValue)
+				m.message = "Calculated: " + calculatedValue
+				m.messageType = "info"
+			}
+		}
+	case "esc":
+		m.popView()
+		m.inputs = nil
+		m.selectedTxID = ""
+		return m, nil
+	}
+
+	if len(m.inputs) > 0 && m.focusIndex < len(m.inputs) {
+		var cmd tea.Cmd
+		m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
+
+		// Auto-calculate in amount field (index 0)
+		if m.focusIndex == 0 {
+			m.autoCalculateIfNeeded(0)
+		}
+		return m, cmd
+	}
+	return m, nil
+}
+
+// Person History view - shows payment/settlement history for a specific person
+func (m Model) viewPersonHistory() string {
+	title := TitleStyle.Render("  Payment History")
+
+	settlements := m.storage.GetSettlementsForPerson(m.selectedPerson)
+
+	var content string
+	content = fmt.Sprintf("\n  Payments with %s:\n", SelectedMenuItemStyle.Render(m.selectedPerson))
+
+	// Outstanding (unsettled only) and lifetime net (everything, settled included)
+	// are the two balances shown everywhere, including the Obsidian export
+	netBalance := m.storage.GetPersonNetBalance(m.selectedPerson)
+	switch {
+	case netBalance > 0:
+		content += "  Outstanding: " + AmountPositiveStyle.Render("owes you "+m.formatAmountPlain(netBalance, m.config.Currency)) + "\n"
+	case netBalance < 0:
+		content += "  Outstanding: " + AmountNegativeStyle.Render("you owe "+m.formatAmountPlain(-netBalance, m.config.Currency)) + "\n"
+	default:
+		content += "  Outstanding: " + MutedStyle.Render("settled") + "\n"
+	}
+	content += "  Lifetime net: " + m.formatAmount(m.storage.GetPersonLifetimeNet(m.selectedPerson), m.config.Currency) +
+		MutedStyle.Render("  (all lent - all borrowed, settled included)") + "\n\n"
+
+	if open := m.storage.GetUnsettledDebtsForPerson(m.selectedPerson); len(open) > 0 {
+		// Part-payments recorded against each open transaction
+		partPayments := make(map[string][]models.Settlement)
+		for _, st := range settlements {
+			partPayments[st.TransactionID] = append(partPayments[st.TransactionID], st)
+		}
+
+		content += "  Open transactions:\n"
+		for _, tx := range open {
+			sign := AmountPositiveStyle.Render("+")
+			if tx.Type == models.Borrowed {
+				sign = AmountNegativeStyle.Render("-")
+			}
+			content += fmt.Sprintf("    %s %s  %s%s\n",
+				sign,
+				m.formatAmountPlain(tx.Amount, m.currencyOf(tx.Currency)),
+				MutedStyle.Render(tx.Date.Format("2006-01-02")),
+				dueDateLabel(tx),
+			)
+			if tx.IsInstallment() {
+				content += fmt.Sprintf("        %s  %s\n",
+					MutedStyle.Render(fmt.Sprintf("EMI %d/%d paid • %s each", tx.InstallmentsPaid(), tx.TotalInstallments, m.formatAmountPlain(tx.InstallmentAmount, m.currencyOf(tx.Currency)))),
+					ProgressBar(float64(tx.InstallmentsPaid()), float64(tx.TotalInstallments), 12),
+				)
+			}
+			if tx.InGracePeriod(time.Now()) {
+				content += MutedStyle.Render(fmt.Sprintf("        %g%% %s interest from %s\n",
+					tx.InterestRate,
+					tx.EffectiveInterestType(),
+					tx.InterestStart().Format("2006-01-02"),
+				))
+			} else if tx.EffectiveInterestType() != models.InterestNone {
+				content += MutedStyle.Render(fmt.Sprintf("        principal %s + %g%% %s interest = %s today\n",
+					m.formatAmountPlain(tx.Amount, m.currencyOf(tx.Currency)),
+					tx.InterestRate,
+					tx.EffectiveInterestType(),
+					m.formatAmountPlain(tx.AccruedAmount(time.Now()), m.currencyOf(tx.Currency)),
+				))
+			}
+			for _, st := range partPayments[tx.ID] {
+				content += MutedStyle.Render(fmt.Sprintf("        ↳ %s paid on %s\n",
+					m.formatAmountPlain(st.Amount, m.currencyOf(tx.Currency)),
+					st.Date.Format("2006-01-02"),
+				))
+			}
+		}
+		content += "\n"
+	}
+
+	if len(settlements) == 0 {
+		content += MutedStyle.Render("  No payments recorded with this person yet.\n")
+	} else {
+		kinds := m.settlementKinds()
+		debts := make(map[string]models.DebtTransaction)
+		for _, tx := range m.storage.GetDebtTransactions() {
+			debts[tx.ID] = tx
+		}
+		// Show most recent first
+		for i := len(settlements) - 1; i >= 0; i-- {
+			st := settlements[i]
+			cursor := "  "
+			if len(settlements)-1-i == m.cursor {
+				cursor = "▸ "
+			}
+			// For settlements: if original was LENT, settlement means "received payment"
+			// if original was BORROWED, settlement means "paid back"
+			action := AmountPositiveStyle.Render("RECEIVED")
+			if st.Type == models.Borrowed {
+				action = AmountNegativeStyle.Render("PAID")
+			}
+			note := st.Note
+			if note == "" {
+				note = "(no note)"
+			}
+			line := fmt.Sprintf("%s%s  %s  %s  %s  %s",
+				cursor,
+				st.Date.Format("2006-01-02"),
+				action,
+				m.formatAmountPlain(st.Amount, m.currencyOf(debts[st.TransactionID].Currency)),
+				settlementBadge(kinds[st.TransactionID]),
+				MutedStyle.Render(truncate(note, 25)),
+			)
+			content += line + "\n"
+		}
+	}
+
+	help := HelpStyle.Render("\n  e: Pay next installment • Esc: Back to transactions")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updatePersonHistoryView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	settlements := m.storage.GetSettlementsForPerson(m.selectedPerson)
+	maxCursor := len(settlements) - 1
+	if maxCursor < 0 {
+		maxCursor = 0
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < maxCursor {
+			m.cursor++
+		}
+	case "g", "G", "ctrl+d", "ctrl+u":
+		m.cursor = m.jumpCursor(msg.String(), m.cursor, maxCursor)
+	case "esc":
+		m.popView()
+		m.cursor = 0
+	}
+
+	return m, nil
+}
+
+// confirmInstallmentPayment asks before paying the next installment of the
+// selected person's oldest open installment debt
+func (m *Model) confirmInstallmentPayment() {
+	var tx *models.DebtTransaction
+	for _, open := range m.storage.GetUnsettledDebtsForPerson(m.selectedPerson) {
+		if open.IsInstallment() {
+			tx = &open
+			break
+		}
+	}
+	if tx == nil {
+		m.message = "No open installment debts with " + m.selectedPerson
+		m.messageType = "error"
+		return
+	}
+
+	next := tx.InstallmentsPaid() + 1
+	amount := tx.InstallmentAmount
+	if next >= tx.TotalInstallments {
+		amount = tx.Amount
+	}
+	id, note := tx.ID, fmt.Sprintf("Installment %d/%d", next, tx.TotalInstallments)
+	m.askConfirm(confirmation{
+		title: "Pay Installment",
+		body: fmt.Sprintf("\n  Pay installment %d/%d (%s) of the %s EMI with %s.\n\n",
+			next, tx.TotalInstallments, m.formatAmountPlain(amount, m.currencyOf(tx.Currency)), m.formatAmountPlain(tx.Principal(), m.currencyOf(tx.Currency)), tx.PersonName),
+		yes: "Record payment",
+		run: func(m *Model) {
+			paid, err := m.storage.RecordInstallmentPayment(id, note)
+			if err != nil {
+				m.message = "Error recording installment: " + err.Error()
+				m.messageType = "error"
+				return
+			}
+			m.message = fmt.Sprintf("%s paid (%s)", note, m.formatAmountPlain(paid, m.currencyOf(tx.Currency)))
+			m.messageType = "success"
+		},
+	})
+}
+
+// People view - everyone you have lent to or borrowed from, settled or not
+func (m Model) viewPeople() string {
+	title := TitleStyle.Render("  People")
+
+	people := m.storage.GetVisiblePeople()
+
+	var content string
+	if len(people) == 0 {
+		content = MutedStyle.Render("\n  No one yet. Add a debt to get started.\n")
+	} else {
+		content = "\n"
+		start, end := visibleWindow(m.offset, m.cursor, m.listPageSize(), len(people))
+		for i := start; i < end; i++ {
+			p := people[i]
+			cursor := "  "
+			if i == m.cursor {
+				cursor = "▸ "
+			}
+
+			var balance string
+			switch {
+			case p.NetBalance > 0:
+				balance = AmountPositiveStyle.Render("owes you " + m.formatAmountPlain(p.NetBalance, m.config.Currency))
+			case p.NetBalance < 0:
+				balance = AmountNegativeStyle.Render("you owe " + m.formatAmountPlain(-p.NetBalance, m.config.Currency))
+			default:
+				balance = MutedStyle.Render("settled")
+			}
+
+			content += fmt.Sprintf("%s%s  %s\n", cursor, TableCellStyle.Width(16).Render(truncate(p.Name, 14)), balance)
+			content += "    " + MutedStyle.Render(fmt.Sprintf("lent %s • borrowed %s • lifetime net %s • %d open • last activity %s",
+				m.formatAmountPlain(p.TotalLent, m.config.Currency),
+				m.formatAmountPlain(p.TotalBorrowed, m.config.Currency),
+				m.formatAmountPlain(p.LifetimeNet, m.config.Currency),
+				p.UnsettledCount,
+				p.LastActivity.Format("2006-01-02"),
+			)) + "\n"
+		}
+	}
+
+	if hidden := len(m.storage.GetPeople()) - len(people); hidden > 0 {
+		content += MutedStyle.Render(fmt.Sprintf("\n  %d settled %s hidden (older than %d days)\n", hidden, plural(hidden, "person", "people"), m.config.HideCompletedAfterDays))
+	}
+
+	help := HelpStyle.Render("\n  Enter: Payment history • Esc: Back")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updatePeopleView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	people := m.storage.GetVisiblePeople()
+	maxCursor := len(people) - 1
+	if maxCursor < 0 {
+		maxCursor = 0
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < maxCursor {
+			m.cursor++
+		}
+	case "enter":
+		if len(people) > 0 && m.cursor < len(people) {
+			m.selectedPerson = people[m.cursor].Name
+			m.pushView(ViewPersonHistory)
+			m.cursor = 0
+		}
+	case "esc":
+		m.popView()
+		m.cursor = 0
+	}
+
+	m.offset, _ = visibleWindow(m.offset, m.cursor, m.listPageSize(), len(people))
+	return m, nil
+}
+
+// Balances Table view - plain-text summary of who owes whom, for sharing
+func (m Model) viewBalancesTable() string {
+	title := TitleStyle.Render("  Balances Table")
+
+	content := "\n" + m.storage.FormatAllBalancesTable()
+
+	help := HelpStyle.Render("\n  w: Write to file • Esc: Back")
+	if len(m.inputs) > 0 {
+		content += "\n  Save to:\n  " + FocusedInputStyle.Render(m.inputs[0].View()) + "\n"
+		help = HelpStyle.Render("\n  Enter: Write • Esc: Cancel")
+	}
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateBalancesTableView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Typing the file path
+	if len(m.inputs) > 0 {
+		switch msg.String() {
+		case "enter":
+			path := strings.TrimSpace(m.inputs[0].Value())
+			if path == "" {
+				m.message = "File path is required"
+				m.messageType = "error"
+				return m, nil
+			}
+			if _, err := os.Stat(path); err == nil {
+				m.askConfirm(confirmation{
+					title: "Overwrite File?",
+					body:  fmt.Sprintf("\n  %s already exists.\n\n", path),
+					yes:   "Overwrite",
+					run: func(m *Model) {
+						m.writeBalancesTable(path)
+					},
+				})
+				return m, nil
+			}
+			m.writeBalancesTable(path)
+			return m, nil
+		case "esc":
+			m.inputs = nil
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.inputs[0], cmd = m.inputs[0].Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "w":
+		m.inputs = make([]textinput.Model, 1)
+		m.inputs[0] = textinput.New()
+		m.inputs[0].Placeholder = "balances.txt"
+		m.inputs[0].Focus()
+		m.focusIndex = 0
+	case "esc":
+		m.popView()
+	}
+
+	return m, nil
+}
+
+// writeBalancesTable writes the balances table to path and closes the path input
+func (m *Model) writeBalancesTable(path string) {
+	if err := os.WriteFile(path, []byte(m.storage.FormatAllBalancesTable()), 0644); err != nil {
+		m.message = "Error writing file: " + err.Error()
+		m.messageType = "error"
+		return
+	}
+	m.message = "Balances written to " + path
+	m.messageType = "success"
+	m.inputs = nil
+}
+
+// Rename Person view - renames a person everywhere, or merges them into another
+func (m Model) viewRenamePerson() string {
+	title := TitleStyle.Render("  Rename or Merge Person")
+
+	content := fmt.Sprintf("\n  Renaming %s\n\n", SelectedMenuItemStyle.Render(m.selectedPerson))
+	if len(m.inputs) > 0 {
+		content += "  " + FocusedInputStyle.Render(m.inputs[0].View()) + "\n"
+	}
+
+	help := HelpStyle.Render("\n  Enter: Rename • Esc: Cancel")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateRenamePersonView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		newName := storage.NormalizeName(m.inputs[0].Value())
+		if newName == "" {
+			m.message = "Name is required"
+			m.messageType = "error"
+			return m, nil
+		}
+		if newName == m.selectedPerson {
+			m.popView()
+			m.inputs = nil
+			return m, nil
+		}
+
+		m.confirmRename(newName)
+		return m, nil
+	case "esc":
+		m.popView()
+		m.inputs = nil
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.inputs[0], cmd = m.inputs[0].Update(msg)
+	return m, cmd
+}
+
+// confirmRename asks before renaming the selected person, spelling out how
+// many transactions move and whether they merge into someone existing
+func (m *Model) confirmRename(newName string) {
+	oldName := m.selectedPerson
+	count := len(m.storage.GetUnsettledDebtsForPerson(oldName)) + len(m.storage.GetSettledDebtsForPerson(oldName))
+	title, action, yes := "Confirm Rename", "renamed to", "Yes, rename"
+	if len(m.storage.GetUnsettledDebtsForPerson(newName))+len(m.storage.GetSettledDebtsForPerson(newName)) > 0 {
+		title, action, yes = "Confirm Merge", "merged into", "Yes, merge"
+	}
+
+	m.askConfirm(confirmation{
+		title: title,
+		body: fmt.Sprintf("\n  %s → %s\n\n  %s\n",
+			SelectedMenuItemStyle.Render(oldName),
+			SelectedMenuItemStyle.Render(newName),
+			WarningStyle.Render(fmt.Sprintf("%d transaction(s) will be %s %s.", count, action, newName)),
+		),
+		yes: yes,
+		run: func(m *Model) {
+			updated, err := m.storage.RenamePerson(oldName, newName)
+			if err != nil {
+				m.message = "Error renaming: " + err.Error()
+				m.messageType = "error"
+				return
+			}
+			m.message = fmt.Sprintf("Updated %d transaction(s): %s is now %s", updated, oldName, newName)
+			m.messageType = "success"
+			// Leave the rename form as well
+			m.popView()
+			m.inputs = nil
+			m.selectedPerson = ""
+			m.cursor = 0
+		},
+	})
+}
+
+// Debts By Reason view - outstanding amounts grouped by description across people
+func (m Model) viewDebtsByReason() string {
+	title := TitleStyle.Render("  Debts by Reason")
+
+	totals := m.storage.DebtsByReason()
+	reasons := make([]string, 0, len(totals))
+	for reason := range totals {
+		reasons = append(reasons, reason)
+	}
+	// Largest outstanding amount first
+	sort.Slice(reasons, func(i, j int) bool {
+		ti, tj := totals[reasons[i]], totals[reasons[j]]
+		if ti.Lent+ti.Borrowed != tj.Lent+tj.Borrowed {
+			return ti.Lent+ti.Borrowed > tj.Lent+tj.Borrowed
+		}
+		return reasons[i] < reasons[j]
+	})
+
+	var content string
+	if len(reasons) == 0 {
+		content = MutedStyle.Render("\n  No unsettled debts.\n")
+	} else {
+		content = "\n" + MutedStyle.Render(fmt.Sprintf("  %-24s %14s %14s %14s", "Reason", "Lent", "Borrowed", "Net")) + "\n"
+		start, end := visibleWindow(m.offset, m.offset, m.listPageSize(), len(reasons))
+		for _, reason := range reasons[start:end] {
+			t := totals[reason]
+			content += fmt.Sprintf("  %-24s %s %s %s\n",
+				truncate(reason, 24),
+				AmountPositiveStyle.Render(fmt.Sprintf("%14s", m.formatAmountPlain(t.Lent, m.config.Currency))),
+				AmountNegativeStyle.Render(fmt.Sprintf("%14s", m.formatAmountPlain(t.Borrowed, m.config.Currency))),
+				m.formatAmount(t.Lent-t.Borrowed, m.config.Currency),
+			)
+		}
+		if end-start < len(reasons) {
+			content += MutedStyle.Render(fmt.Sprintf("\n  showing %d–%d of %d", start+1, end, len(reasons))) + "\n"
+		}
+	}
+
+	help := HelpStyle.Render("\n  ↑/↓: Scroll • Esc: Back")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateDebtsByReasonView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	maxOffset := len(m.storage.DebtsByReason()) - m.listPageSize()
+	if maxOffset < 0 {
+		maxOffset = 0
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.offset > 0 {
+			m.offset--
+		}
+	case "down", "j":
+		if m.offset < maxOffset {
+			m.offset++
+		}
+	case "esc":
+		m.popView()
+		m.offset = 0
+	}
+
+	return m, nil
+}
+
+// Due Debts view - overdue debts and those due within dueSoonWindow, reached
+// from the main menu reminder
+func (m Model) viewDueDebts() string {
+	title := TitleStyle.Render("  Due & Overdue Debts")
+
+	upcoming, overdue := m.storage.GetUpcomingAndOverdue(dueSoonWindow)
+
+	var content string
+	if len(upcoming)+len(overdue) == 0 {
+		content = MutedStyle.Render("\n  Nothing overdue or due this week.\n")
+	} else {
+		now := time.Now()
+		row := 0
+		section := func(heading string, txs []models.DebtTransaction) {
+			if len(txs) == 0 {
+				return
+			}
+			content += "\n  " + heading + "\n"
+			for _, tx := range txs {
+				cursor := "  "
+				if row == m.cursor {
+					cursor = "▸ "
+				}
+				row++
+				txType := AmountPositiveStyle.Render("LENT")
+				if tx.Type == models.Borrowed {
+					txType = AmountNegativeStyle.Render("BORROWED")
+				}
+				content += fmt.Sprintf("%s%s  %s  %s  %s  %s  %s\n",
+					cursor,
+					tx.DueDate.Format("2006-01-02"),
+					TableCellStyle.Width(12).Render(truncate(tx.PersonName, 10)),
+					txType,
+					m.formatAmountPlain(tx.Amount, m.currencyOf(tx.Currency)),
+					dueInLabel(*tx.DueDate, now),
+					MutedStyle.Render(truncate(tx.Description, 20)),
+				)
+			}
+		}
+		section(ErrorStyle.Render("Overdue"), overdue)
+		section(WarningStyle.Render("Due this week"), upcoming)
+	}
+
+	help := HelpStyle.Render("\n  Enter: Settle with this person • Esc: Back")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateDueDebtsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	upcoming, overdue := m.storage.GetUpcomingAndOverdue(dueSoonWindow)
+	// Rows are listed overdue first, as in viewDueDebts
+	due := append(overdue, upcoming...)
+	maxCursor := len(due) - 1
+	if maxCursor < 0 {
+		maxCursor = 0
+	}
+	if m.cursor > maxCursor {
+		m.cursor = maxCursor
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < maxCursor {
+			m.cursor++
+		}
+	case "enter":
+		if m.cursor < len(due) {
+			m.selectedPerson = due[m.cursor].PersonName
+			m.pushView(ViewSelectTransaction)
+			m.cursor = 0
+		}
+	case "esc":
+		m.popView()
+		m.cursor = 0
+	}
+
+	return m, nil
+}
+
+// dueReminder returns the main menu banner counting overdue debts and those
+// due this week, or "" when there are none
+func (m Model) dueReminder() string {
+	upcoming, overdue := m.storage.GetUpcomingAndOverdue(dueSoonWindow)
+	var parts []string
+	if n := len(overdue); n > 0 {
+		parts = append(parts, ErrorStyle.Render(fmt.Sprintf("%d %s overdue", n, plural(n, "debt", "debts"))))
+	}
+	if n := len(upcoming); n > 0 {
+		noun := ""
+		if len(overdue) == 0 {
+			noun = plural(n, " debt", " debts")
+		}
+		parts = append(parts, WarningStyle.Render(fmt.Sprintf("%d%s due this week", n, noun)))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "\n  ⚠ " + strings.Join(parts, ", ") + MutedStyle.Render("  (d: review)") + "\n"
+}
+
+// dueInLabel describes a due date in calendar days from now, e.g. "3 days late" or "in 2 days"
+func dueInLabel(due, now time.Time) string {
+	dueDay := time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, time.UTC)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(dueDay.Sub(today).Hours() / 24)
+	switch {
+	case days < 0:
+		return ErrorStyle.Render(fmt.Sprintf("%d %s late", -days, plural(-days, "day", "days")))
+	case days == 0:
+		return WarningStyle.Render("due today")
+	default:
+		return WarningStyle.Render(fmt.Sprintf("in %d %s", days, plural(days, "day", "days")))
+	}
+}
+
+// plural returns one when n is 1 and many otherwise
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// Missing Due Dates view - unsettled debts older than the reminder window with no due date
+func (m Model) viewMissingDueDates() string {
+	title := TitleStyle.Render("  Debts Needing a Due Date")
+
+	transactions := m.storage.DebtsMissingDueDate()
+
+	var content string
+	if len(transactions) == 0 {
+		content = MutedStyle.Render("\n  Every older debt has a due date.\n")
+	} else {
+		content = "\n"
+		for i, tx := range transactions {
+			cursor := "  "
+			if i == m.cursor {
+				cursor = "▸ "
+			}
+			txType := AmountPositiveStyle.Render("LENT")
+			if tx.Type == models.Borrowed {
+				txType = AmountNegativeStyle.Render("BORROWED")
+			}
+			line := fmt.Sprintf("%s%s  %s  %s  %s  %s",
+				cursor,
+				tx.Date.Format("2006-01-02"),
+				TableCellStyle.Width(10).Render(tx.PersonName),
+				txType,
+				m.formatAmountPlain(tx.Amount, m.currencyOf(tx.Currency)),
+				MutedStyle.Render(truncate(tx.Description, 20)),
+			)
+			content += line + "\n"
+		}
+	}
+
+	help := HelpStyle.Render("\n  Enter: Set due date • Esc: Back")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateMissingDueDatesView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	transactions := m.storage.DebtsMissingDueDate()
+	maxCursor := len(transactions) - 1
+	if maxCursor < 0 {
+		maxCursor = 0
+	}
+	if m.cursor > maxCursor {
+		m.cursor = maxCursor
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < maxCursor {
+			m.cursor++
+		}
+	case "enter":
+		if len(transactions) > 0 && m.cursor < len(transactions) {
+			m.selectedTxID = transactions[m.cursor].ID
+			m.pushView(ViewSetDueDate)
+			m.inputs = make([]textinput.Model, 1)
+			m.inputs[0] = textinput.New()
+			m.inputs[0].Placeholder = "Due Date (YYYY-MM-DD)"
+			m.inputs[0].Focus()
+			m.focusIndex = 0
+		}
+	case "esc":
+		m.popView()
+		m.cursor = 0
+	}
+
+	return m, nil
+}
+
+func (m Model) viewSetDueDate() string {
+	title := TitleStyle.Render("  Set Due Date")
+
+	var content string
+	for _, tx := range m.storage.GetDebtTransactions() {
+		if tx.ID == m.selectedTxID {
+			content = fmt.Sprintf("\n  %s  %s  %s\n\n",
+				SelectedMenuItemStyle.Render(tx.PersonName),
+				m.formatAmountPlain(tx.Amount, m.currencyOf(tx.Currency)),
+				MutedStyle.Render(tx.Date.Format("2006-01-02")),
+			)
+			break
+		}
+	}
+
+	if len(m.inputs) > 0 {
+		content += SelectedMenuItemStyle.Render("▸ Due Date:") + "\n"
+		content += "  " + FocusedInputStyle.Render(m.inputs[0].View()) + "\n"
+		content += "  " + MutedStyle.Render("Format: YYYY-MM-DD") + "\n"
+	}
+
+	help := HelpStyle.Render("Enter: Save • Esc: Cancel")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateSetDueDateView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		dueDate, err := time.Parse("2006-01-02", m.inputs[0].Value())
+		if err != nil {
+			m.message = "Invalid date format. Use YYYY-MM-DD"
+			m.messageType = "error"
+			return m, nil
+		}
+
+		if err := m.storage.SetDebtDueDate(m.selectedTxID, &dueDate); err != nil {
+			m.message = "Error saving: " + err.Error()
+			m.messageType = "error"
+			return m, nil
+		}
+
+		m.message = "Due date set!"
+		m.messageType = "success"
+		m.popView()
+		m.inputs = nil
+		m.selectedTxID = ""
+		return m, nil
+	case "esc":
+		m.popView()
+		m.inputs = nil
+		m.selectedTxID = ""
+		return m, nil
+	}
+
+	if len(m.inputs) > 0 {
+		var cmd tea.Cmd
+		m.inputs[0], cmd = m.inputs[0].Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// Settlement History view - shows all payment records from the settlement
+// ledger, each against the debt it paid off
+func (m Model) viewSettlementHistory() string {
+	title := TitleStyle.Render("  All Payments History")
+
+	settlements := m.storage.GetAllSettlements()
+	debts := make(map[string]models.DebtTransaction)
+	for _, tx := range m.storage.GetDebtTransactions() {
+		debts[tx.ID] = tx
+	}
+
+	var content string
+	if len(settlements) == 0 {
+		content = MutedStyle.Render("\n  No payments recorded yet.\n")
+	} else {
+		content = "\n"
+		kinds := m.settlementKinds()
+		// Show most recent first, limit to last 15
+		start := 0
+		if len(settlements) > 15 {
+			start = len(settlements) - 15
+		}
+		for i := len(settlements) - 1; i >= start; i-- {
+			st := settlements[i]
+			cursor := "  "
+			if len(settlements)-1-i == m.cursor {
+				cursor = "▸ "
+			}
+			action := AmountPositiveStyle.Render("RECEIVED")
+			if st.Type == models.Borrowed {
+				action = AmountNegativeStyle.Render("PAID")
+			}
+			note := st.Note
+			if note == "" {
+				note = "(no note)"
+			}
+			debt := debts[st.TransactionID]
+			against := debt.Description
+			if against == "" {
+				against = "(deleted debt)"
+			}
+			line := fmt.Sprintf("%s%s  %s  %s  %s  %s  %s  %s",
+				cursor,
+				st.Date.Format("2006-01-02"),
+				TableCellStyle.Width(10).Render(st.PersonName),
+				action,
+				m.formatAmountPlain(st.Amount, m.currencyOf(debt.Currency)),
+				settlementBadge(kinds[st.TransactionID]),
+				TableCellStyle.Width(16).Render(truncate(against, 14)),
+				MutedStyle.Render(truncate(note, 20)),
+			)
+			content += line + "\n"
+		}
+	}
+
+	help := HelpStyle.Render("\n  Esc: Back")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateSettlementHistoryView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	settlements := m.storage.GetAllSettlements()
+	maxCursor := len(settlements) - 1
+	if maxCursor < 0 {
+		maxCursor = 0
+	}
+	// Limit to visible items
+	visibleCount := len(settlements)
+	if visibleCount > 15 {
+		visibleCount = 15
+	}
+	if maxCursor >= visibleCount {
+		maxCursor = visibleCount - 1
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < maxCursor {
+			m.cursor++
+		}
+	case "esc":
+		m.popView()
+		m.cursor = 0
+	}
+
+	return m, nil
+}
+
+// Net Worth view
+func (m Model) viewNetWorth() string {
+	title := TitleStyle.Render("  My Net Worth")
+
+	investments := m.storage.GetInvestments()
+	data := m.storage.GetData()
+
+	var content string
+	if len(investments) == 0 {
+		content = MutedStyle.Render("\n  No investments recorded yet.\n")
+	} else {
+		content = "\n"
+		// Everything but the name takes 62 characters; the name gets the rest
+		nameWidth := distributeWidths(m.contentWidth()-62, []columnSpec{{Min: 20, Max: 50}})[0]
+		for i, inv := range investments {
+			cursor := "  "
+			if i == m.cursor {
+				cursor = "▸ "
+			}
+			line := fmt.Sprintf("%s[%s] %s  %s  %s",
+				cursor,
+				TableCellStyle.Width(12).Render(string(inv.Type)),
+				TableCellStyle.Width(nameWidth).Render(truncate(inv.Name, nameWidth-2)),
+				m.formatAmountPlain(inv.CurrentValue, m.currencyOf(inv.Currency)),
+				m.formatGain(inv.CurrentValue-inv.InvestedAmount, inv.InvestedAmount, m.currencyOf(inv.Currency)),
+			)
+			content += line + "\n"
+		}
+	}
+
+	content += m.allocationBreakdown()
+
+	// Summary, cached until the next change; only the CAGR depends on the clock
+	now := time.Now()
+	summary := data.Summary(now.Year(), now.Month(), m.excludedCategories())
+	gain, returnPct := summary.PortfolioReturn()
+	stats := fmt.Sprintf("\n  Total Net Worth: %s", m.formatAmountPlain(summary.NetWorth, m.config.Currency))
+	income := data.TotalInvestmentIncome()
+	if income > 0 {
+		stats += fmt.Sprintf("\n  Income Received: %s", m.formatAmountPlain(income, m.config.Currency))
+	}
+	if summary.TotalInvested > 0 {
+		stats += fmt.Sprintf("\n  Invested:        %s → %s (%+.2f%%)",
+			m.formatAmountPlain(summary.TotalInvested, m.config.Currency),
+			m.formatAmountPlain(summary.NetWorth, m.config.Currency),
+			returnPct,
+		)
+		stats += fmt.Sprintf("\n  Annualized:      %+.2f%% %s", data.PortfolioCAGR(now), MutedStyle.Render("(CAGR)"))
+	}
+	stats += fmt.Sprintf("\n  Total Return:    %s %s",
+		m.formatGain(gain+income, summary.TotalInvested, m.config.Currency),
+		MutedStyle.Render("(capital gain + income)"),
+	)
+
+	help := HelpStyle.Render("\n  Enter: Details • a: Add investment • u: Update • i: Income • s: Snapshot • h: History • %: Gain display • d: Delete • Esc: Back")
+
+	return BoxStyle.Render(title + content + stats + help)
+}
+
+// Net Worth History view - daily snapshots with a sparkline of the trend
+func (m Model) viewNetWorthHistory() string {
+	title := TitleStyle.Render("  Net Worth History")
+
+	snapshots := m.storage.GetNetWorthSnapshots()
+	if len(snapshots) == 0 {
+		content := MutedStyle.Render("\n  No snapshots yet. One is recorded each day you open debtq or change an investment.\n")
+		return BoxStyle.Render(title + content + HelpStyle.Render("\n  Esc: Back"))
+	}
+
+	// As many of the latest days as fit on one line
+	values := make([]float64, len(snapshots))
+	for i, s := range snapshots {
+		values[i] = s.Value
+	}
+	if width := m.contentWidth() - 4; width > 0 && len(values) > width {
+		values = values[len(values)-width:]
+	}
+
+	first, last := snapshots[0], snapshots[len(snapshots)-1]
+	content := "\n  " + Sparkline(values) + "\n"
+	content += fmt.Sprintf("  %s (%s) → %s (%s)  %s\n",
+		m.formatAmountPlain(first.Value, m.config.Currency), first.Date.Format("2006-01-02"),
+		m.formatAmountPlain(last.Value, m.config.Currency), last.Date.Format("2006-01-02"),
+		m.formatGain(last.Value-first.Value, first.Value, m.config.Currency),
+	)
+
+	content += "\n" + MutedStyle.Render(fmt.Sprintf("  %-10s  %16s  %16s", "Date", "Net Worth", "Change")) + "\n"
+	start, end := visibleWindow(m.offset, m.offset, m.listPageSize(), len(snapshots))
+	for row := start; row < end; row++ {
+		i := len(snapshots) - 1 - row
+		change := ""
+		if i > 0 {
+			diff := snapshots[i].Value - snapshots[i-1].Value
+			style := AmountPositiveStyle
+			if diff < 0 {
+				style = AmountNegativeStyle
+			}
+			change = style.Render(fmt.Sprintf("%16s", m.formatAmountPlain(diff, m.config.Currency)))
+		}
+		content += fmt.Sprintf("  %-10s  %16s  %s\n",
+			snapshots[i].Date.Format("2006-01-02"),
+			m.formatAmountPlain(snapshots[i].Value, m.config.Currency),
+			change,
+		)
+	}
+	if end-start < len(snapshots) {
+		content += MutedStyle.Render(fmt.Sprintf("\n  showing %d–%d of %d", start+1, end, len(snapshots))) + "\n"
+	}
+
+	help := HelpStyle.Render("\n  ↑/↓: Scroll • Esc: Back")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateNetWorthHistoryView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	maxOffset := len(m.storage.GetNetWorthSnapshots()) - m.listPageSize()
+	if maxOffset < 0 {
+		maxOffset = 0
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.offset > 0 {
+			m.offset--
+		}
+	case "down", "j":
+		if m.offset < maxOffset {
+			m.offset++
+		}
+	case "esc":
+		m.popView()
+		m.offset = 0
+	}
+
+	return m, nil
+}
+
+// allocationBreakdown renders each investment type's share of the portfolio,
+// largest first, flagging types above the concentration threshold
+func (m Model) allocationBreakdown() string {
+	allocation := m.storage.GetAllocation()
+	if len(allocation) == 0 {
+		return ""
+	}
+
+	types := make([]models.InvestmentType, 0, len(allocation))
+	for t := range allocation {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if allocation[types[i]] != allocation[types[j]] {
+			return allocation[types[i]] > allocation[types[j]]
+		}
+		return types[i] < types[j]
+	})
+
+	const barWidth = 20
+	threshold := m.config.ConcentrationThreshold()
+	content := "\n  Allocation:\n"
+	for _, t := range types {
+		share := allocation[t]
+		filled := int(share * barWidth)
+		line := fmt.Sprintf("  %s %s %5.1f%%",
+			TableCellStyle.Width(14).Render(string(t)),
+			ProgressBarStyle.Render(strings.Repeat("█", filled)+strings.Repeat("░", barWidth-filled)),
+			share*100,
+		)
+		if share > threshold {
+			line += "  " + RenderBadge("CONCENTRATED", "warning")
+		}
+		content += line + "\n"
+	}
+	return content
+}
+
+func (m *Model) updateNetWorthView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	investments := m.storage.GetInvestments()
+	maxCursor := len(investments) - 1
+	if maxCursor < 0 {
+		maxCursor = 0
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < maxCursor {
+			m.cursor++
+		}
+	case "g", "G", "ctrl+d", "ctrl+u":
+		m.cursor = m.jumpCursor(msg.String(), m.cursor, maxCursor)
+	case "enter":
+		if len(investments) > 0 && m.cursor < len(investments) {
+			m.selectedID = investments[m.cursor].ID
+			m.pushView(ViewInvestmentDetail)
+		}
+	case "esc":
+		m.popView()
+		m.cursor = 0
+	}
+
+	return m, nil
+}
+
+func (m *Model) initInvestmentInputs() {
+	m.inputs = make([]textinput.Model, 6)
+
+	m.inputs[0] = textinput.New()
+	m.inputs[0].Placeholder = "Type (stocks/mutual_funds/gold/silver/fixed_deposit/ppf/crypto/other)"
+	m.inputs[0].Focus()
+
+	m.inputs[1] = textinput.New()
+	m.inputs[1].Placeholder = "Name (e.g., HDFC Bank, SBI Bluechip)"
+
+	m.inputs[2] = textinput.New()
+	m.inputs[2].Placeholder = "Invested Amount"
+
+	m.inputs[3] = textinput.New()
+	m.inputs[3].Placeholder = "Current Value"
+
+	m.inputs[4] = textinput.New()
+	m.inputs[4].Placeholder = "Units (optional)"
+
+	m.inputs[5] = textinput.New()
+	m.inputs[5].Placeholder = "Purchase Date (YYYY-MM-DD)"
+
+	m.focusIndex = 0
+}
+
+func (m Model) viewAddInvestment() string {
+	title := TitleStyle.Render("  Add Investment")
+
+	var content string
+	labels := []string{"Type:", "Name:", "Invested:", "Current Value:", "Units:", "Purchase Date:"}
+	hints := []string{
+		"Options: stocks, mutual_funds, gold, silver, fixed_deposit, ppf, crypto, real_estate, other",
+		"e.g., HDFC Bank, SBI Bluechip, Gold 24K",
+		"Total amount, or @price per unit when units are set",
+		"Total value, or @price per unit when units are set",
+		"(optional)",
+		"Format: YYYY-MM-DD",
+	}
+	if units, err := parseAmount(m.inputs[4].Value()); err == nil && units > 0 {
+		if invested, _, err := m.parseInvestmentAmount(m.inputs[2].Value(), units); err == nil {
+			hints[4] = fmt.Sprintf("Avg price: %s per unit", m.formatAmountPlain(invested/units, m.config.Currency))
+		}
+	}
+
+	for i, input := range m.inputs {
+		label := labels[i]
+		if i == m.focusIndex {
+			content += SelectedMenuItemStyle.Render("▸ "+label) + "\n"
+			content += "  " + FocusedInputStyle.Render(input.View()) + "\n"
+			if hints[i] != "" {
+				content += "  " + MutedStyle.Render(hints[i]) + "\n"
+			}
+			content += "\n"
+		} else {
+			content += MenuItemStyle.Render("  "+label) + "\n"
+			content += "  " + InputStyle.Render(input.View()) + "\n"
+			if hints[i] != "" {
+				content += "  " + MutedStyle.Render(hints[i]) + "\n"
+			}
+			content += "\n"
+		}
+	}
+
+	help := HelpStyle.Render("+: Calculate • Tab: Next field • alt+1-6: Jump to field • Enter: Save • Esc: Cancel")
+
+	return BoxStyle.Render(title + "\n" + content + help)
+}
+
+func (m Model) viewUpdateInvestment() string {
+	title := TitleStyle.Render("  Update Investment")
+
+	var content string
+	content += "\n"
+
+	labels := []string{"Type:", "Name:", "New invested amount:", "New current value:"}
+	hints := []string{
+		"Options: stocks, mutual_funds, gold, silver, fixed_deposit, ppf, crypto, real_estate, other",
+		"",
+		"Enter the new invested amount",
+		"Enter the new current value",
+	}
+
+	for i, input := range m.inputs {
+		label := labels[i]
+		if i == m.focusIndex {
+			content += "  " + SelectedMenuItemStyle.Render("▸ "+label) + "\n"
+			content += "  " + FocusedInputStyle.Render(input.View()) + "\n"
+			if hints[i] != "" {
+				content += "  " + MutedStyle.Render(hints[i]) + "\n"
+			}
+			content += "\n"
+		} else {
+			content += "  " + MenuItemStyle.Render("  "+label) + "\n"
+			content += "  " + InputStyle.Render(input.View()) + "\n"
+			if hints[i] != "" {
+				content += "  " + MutedStyle.Render(hints[i]) + "\n"
 			}
+			content += "\n"
 		}
 	}
 
-	help := HelpStyle.Render("  +: Calculate • Tab: Next field • Enter: Confirm • Esc: Cancel")
+	help := HelpStyle.Render("\n  Tab: Next field • Enter: Save • Esc: Cancel")
 
-	return BoxStyle.Render(title + content + help)
+	return BoxStyle.Render(title + "\n" + content + help)
 }
 
-func (m *Model) updateSettleDebtView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	keyStr := msg.String()
+func (m *Model) updateAddInvestmentView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.updateFormFocus(msg.String()) {
+		return m, nil
+	}
 
-	switch keyStr {
-	case "tab", "down":
-		m.inputs[m.focusIndex].Blur()
-		m.focusIndex = (m.focusIndex + 1) % len(m.inputs)
-		m.inputs[m.focusIndex].Focus()
-	case "shift+tab", "up":
-		m.inputs[m.focusIndex].Blur()
-		m.focusIndex--
-		if m.focusIndex < 0 {
-			m.focusIndex = len(m.inputs) - 1
-		}
-		m.inputs[m.focusIndex].Focus()
+	switch msg.String() {
 	case "enter":
-		// Auto-calculate if input contains math operators
-		if len(m.inputs) > 0 && m.inputs[0].Value() != "" {
-			inputVal := m.inputs[0].Value()
-			if strings.ContainsAny(inputVal, "+-*/") {
-				calculatedValue, success := tryCalculateAmount(inputVal)
-				if success {
-					m.inputs[0].SetValue(calculatedValue)
-					m.message = "Calculated: " + calculatedValue
-					m.messageType = "info"
-				} else {
-					m.message = "Calc failed for: " + inputVal
-					m.messageType = "error"
-					return m, nil
-				}
-			}
+		invType := models.InvestmentType(m.inputs[0].Value())
+		name := m.inputs[1].Value()
+		if name == "" {
+			m.message = "Name is required"
+			m.messageType = "error"
+			return m, nil
 		}
-		// Parse amount
-		var amount float64
-		if len(m.inputs) > 0 && m.inputs[0].Value() != "" {
-			var err error
-			amount, err = strconv.ParseFloat(m.inputs[0].Value(), 64)
+
+		var units float64
+		if m.inputs[4].Value() != "" {
+			units, _ = parseAmount(m.inputs[4].Value())
+		}
+
+		invested, currency, err := m.parseInvestmentAmount(m.inputs[2].Value(), units)
+		if err != nil {
+			m.message = "Invalid invested amount: " + err.Error()
+			m.messageType = "error"
+			return m, nil
+		}
+
+		current, _, err := m.parseInvestmentAmount(m.inputs[3].Value(), units)
+		if err != nil {
+			m.message = "Invalid current value: " + err.Error()
+			m.messageType = "error"
+			return m, nil
+		}
+
+		purchaseDate := time.Now()
+		if m.inputs[5].Value() != "" {
+			purchaseDate, err = time.Parse("2006-01-02", m.inputs[5].Value())
 			if err != nil {
-				m.message = "Invalid amount"
+				m.message = "Invalid date format"
 				m.messageType = "error"
 				return m, nil
 			}
-			if amount <= 0 {
-				m.message = "Amount must be positive"
+		}
+
+		_, err = m.storage.AddInvestment(invType, name, invested, current, units, purchaseDate, "", currency)
+		if err != nil {
+			m.message = "Error saving: " + err.Error()
+			m.messageType = "error"
+			return m, nil
+		}
+
+		m.message = "Investment added!"
+		m.messageType = "success"
+		m.popView()
+		m.inputs = nil
+		m.cursor = 0
+		return m, nil
+	case "+":
+		if (m.focusIndex == 2 || m.focusIndex == 3) && len(m.inputs) > 0 {
+			currentValue := m.inputs[m.focusIndex].Value()
+			calculatedValue, success := tryCalculateAmount(currentValue)
+			if success {
+				m.inputs[m.focusIndex].SetValue(calculatedValue)
+				m.message = "Calculated: " + calculatedValue
+				m.messageType = "info"
+			}
+		}
+	case "esc":
+		m.popView()
+		m.inputs = nil
+		m.cursor = 0
+		return m, nil
+	}
+
+	if len(m.inputs) > 0 && m.focusIndex < len(m.inputs) {
+		var cmd tea.Cmd
+		m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
+
+		// Auto-calculate in amount fields (index 2: Invested, index 3: Current Value)
+		if m.focusIndex == 2 || m.focusIndex == 3 {
+			m.autoCalculateIfNeeded(m.focusIndex)
+		}
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m *Model) updateUpdateInvestmentView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.updateFormFocus(msg.String()) {
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "enter":
+		inv := m.selectedInvestment()
+		if inv == nil {
+			m.message = "Investment not found"
+			m.messageType = "error"
+			return m, nil
+		}
+
+		invType := models.InvestmentType(strings.ToLower(strings.TrimSpace(m.inputs[0].Value())))
+		if !models.IsValidInvestmentType(invType) {
+			m.message = fmt.Sprintf("Unknown investment type %q", m.inputs[0].Value())
+			m.messageType = "error"
+			return m, nil
+		}
+
+		name := strings.TrimSpace(m.inputs[1].Value())
+		if name == "" {
+			m.message = "Name is required"
+			m.messageType = "error"
+			return m, nil
+		}
+
+		if m.inputs[2].Value() == "" || m.inputs[3].Value() == "" {
+			m.message = "Both values are required"
+			m.messageType = "error"
+			return m, nil
+		}
+
+		investedAmount, err := parseAmount(m.inputs[2].Value())
+		if err != nil {
+			m.message = "Invalid invested amount"
+			m.messageType = "error"
+			return m, nil
+		}
+
+		currentValue, err := parseAmount(m.inputs[3].Value())
+		if err != nil {
+			m.message = "Invalid current value"
+			m.messageType = "error"
+			return m, nil
+		}
+
+		if investedAmount < 0 || currentValue < 0 {
+			m.message = "Values must be positive"
+			m.messageType = "error"
+			return m, nil
+		}
+
+		if invType != inv.Type || name != inv.Name {
+			if err := m.storage.UpdateInvestmentDetails(inv.ID, invType, name, inv.Notes); err != nil {
+				m.message = "Error updating: " + err.Error()
 				m.messageType = "error"
 				return m, nil
 			}
 		}
 
-		note := ""
-		if len(m.inputs) > 1 {
-			note = m.inputs[1].Value()
+		// Only record a new value point when the values actually changed
+		if investedAmount != inv.InvestedAmount || currentValue != inv.CurrentValue {
+			if err := m.storage.UpdateInvestment(inv.ID, investedAmount, currentValue); err != nil {
+				m.message = "Error updating: " + err.Error()
+				m.messageType = "error"
+				return m, nil
+			}
 		}
 
-		err := m.storage.SettleTransactionWithNote(m.selectedTxID, amount, note)
-		if err != nil {
-			m.message = "Error settling: " + err.Error()
+		m.message = "Investment updated!"
+		m.messageType = "success"
+		m.popView()
+		m.inputs = nil
+		m.selectedID = ""
+		m.cursor = 0
+		return m, nil
+	case "+":
+		if (m.focusIndex == 2 || m.focusIndex == 3) && len(m.inputs) > 0 {
+			currentValue := m.inputs[m.focusIndex].Value()
+			calculatedValue, success := tryCalculateAmount(currentValue)
+			if success {
+				m.inputs[m.focusIndex].SetValue(calculatedValue)
+				m.message = "Calculated: " + calculatedValue
+				m.messageType = "info"
+			}
+		}
+	case "esc":
+		m.popView()
+		m.inputs = nil
+		m.selectedID = ""
+		m.cursor = 0
+		return m, nil
+	}
+
+	if len(m.inputs) > 0 && m.focusIndex < len(m.inputs) {
+		var cmd tea.Cmd
+		m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
+
+		// Auto-calculate in amount fields (index 2: Invested, index 3: Current Value)
+		if m.focusIndex == 2 || m.focusIndex == 3 {
+			m.autoCalculateIfNeeded(m.focusIndex)
+		}
+		return m, cmd
+	}
+	return m, nil
+}
+
+// Investment Detail view - every field of one investment, its returns and income
+func (m Model) viewInvestmentDetail() string {
+	title := TitleStyle.Render("  Investment Details")
+
+	inv := m.selectedInvestment()
+	if inv == nil {
+		return BoxStyle.Render(title + MutedStyle.Render("\n  Investment not found.\n") + HelpStyle.Render("\n  Esc: Back"))
+	}
+
+	content := fmt.Sprintf("\n  %s  %s\n\n", SelectedMenuItemStyle.Render(inv.Name), MutedStyle.Render("["+string(inv.Type)+"]"))
+	content += fmt.Sprintf("  Invested:       %s\n", m.formatAmountPlain(inv.InvestedAmount, m.currencyOf(inv.Currency)))
+	content += fmt.Sprintf("  Current Value:  %s\n", m.formatAmountPlain(inv.CurrentValue, m.currencyOf(inv.Currency)))
+	if inv.Units > 0 {
+		content += fmt.Sprintf("  Units:          %g\n", inv.Units)
+		content += fmt.Sprintf("  Avg Price:      %s %s\n", m.formatAmountPlain(inv.AvgPricePerUnit(), m.currencyOf(inv.Currency)), MutedStyle.Render("per unit"))
+		content += fmt.Sprintf("  Current Price:  %s %s\n", m.formatAmountPlain(inv.CurrentPricePerUnit(), m.currencyOf(inv.Currency)), MutedStyle.Render("per unit"))
+	}
+	content += fmt.Sprintf("  Purchased:      %s\n", inv.PurchaseDate.Format("2006-01-02"))
+	content += fmt.Sprintf("  Last Updated:   %s\n", inv.UpdatedAt.Format("2006-01-02"))
+
+	gain := inv.CurrentValue - inv.InvestedAmount
+	income := m.storage.GetData().IncomeForInvestment(inv.ID)
+	content += fmt.Sprintf("\n  Capital Gain:   %s\n", m.formatGain(gain, inv.InvestedAmount, m.currencyOf(inv.Currency)))
+	content += fmt.Sprintf("  Income:         %s\n", m.formatAmountPlain(income, m.currencyOf(inv.Currency)))
+	content += fmt.Sprintf("  Total Return:   %s\n", m.formatGain(gain+income, inv.InvestedAmount, m.currencyOf(inv.Currency)))
+
+	if history := m.storage.GetInvestmentHistory(inv.ID); len(history) > 1 {
+		values := make([]float64, len(history))
+		for i, point := range history {
+			values[i] = point.Value
+		}
+		content += "\n  Value History:  " + Sparkline(values) + "\n"
+		// Show the most recent few values
+		for i := len(history) - 1; i >= 0 && i >= len(history)-5; i-- {
+			content += fmt.Sprintf("  %s  %s\n",
+				MutedStyle.Render(history[i].Date.Format("2006-01-02")),
+				m.formatAmountPlain(history[i].Value, m.currencyOf(inv.Currency)),
+			)
+		}
+	}
+
+	content += "\n  Notes:\n"
+	if inv.Notes == "" {
+		content += MutedStyle.Render("  (none)") + "\n"
+	} else {
+		content += "  " + inv.Notes + "\n"
+	}
+
+	if incomes := m.storage.GetInvestmentIncome(inv.ID); len(incomes) > 0 {
+		content += "\n  Income History:\n"
+		// Show most recent first
+		for i := len(incomes) - 1; i >= 0; i-- {
+			inc := incomes[i]
+			content += fmt.Sprintf("  %s  %s  %s\n",
+				inc.Date.Format("2006-01-02"),
+				TableCellStyle.Width(10).Render(string(inc.Type)),
+				m.formatAmountPlain(inc.Amount, m.currencyOf(inv.Currency)),
+			)
+		}
+	}
+
+	help := HelpStyle.Render("\n  n: Edit notes • i: Income • Esc: Back")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateInvestmentDetailView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.popView()
+		m.selectedID = ""
+	}
+
+	return m, nil
+}
+
+func (m Model) viewEditInvestmentNotes() string {
+	title := TitleStyle.Render("  Edit Investment Notes")
+
+	var content string
+	if inv := m.selectedInvestment(); inv != nil {
+		content = fmt.Sprintf("\n  %s\n\n", SelectedMenuItemStyle.Render(inv.Name))
+	}
+	if len(m.inputs) > 0 {
+		content += "  " + FocusedInputStyle.Render(m.inputs[0].View()) + "\n"
+	}
+
+	help := HelpStyle.Render("\n  Enter: Save • Esc: Cancel")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateEditInvestmentNotesView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		if err := m.storage.UpdateInvestmentNotes(m.selectedID, strings.TrimSpace(m.inputs[0].Value())); err != nil {
+			m.message = "Error saving: " + err.Error()
 			m.messageType = "error"
 			return m, nil
 		}
-
-		if amount > 0 {
-			m.message = fmt.Sprintf("Settled %s %.2f with %s!", m.config.Currency, amount, m.selectedPerson)
-		} else {
-			m.message = fmt.Sprintf("Fully settled with %s!", m.selectedPerson)
-		}
+		m.message = "Notes updated!"
 		m.messageType = "success"
-		m.currentView = ViewDebts
+		m.popView()
 		m.inputs = nil
-		m.selectedPerson = ""
-		m.selectedTxID = ""
-		m.cursor = 0
 		return m, nil
 	case "esc":
-		m.currentView = ViewSelectTransaction
+		m.popView()
 		m.inputs = nil
-		m.selectedTxID = ""
-		m.cursor = 0
 		return m, nil
 	}
 
-	if len(m.inputs) > 0 && m.focusIndex < len(m.inputs) {
-		// Get value before update
-		oldValue := m.inputs[m.focusIndex].Value()
-
-		var cmd tea.Cmd
-		m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
-
-		// Get value after update
-		newValue := m.inputs[m.focusIndex].Value()
+	var cmd tea.Cmd
+	m.inputs[0], cmd = m.inputs[0].Update(msg)
+	return m, cmd
+}
 
-		// Auto-calculate in amount field (index 0) when trailing operator is typed
-		if m.focusIndex == 0 && newValue != oldValue {
-			m.autoCalculateIfNeeded(0)
+// selectedInvestment returns the investment identified by selectedID, if any
+func (m Model) selectedInvestment() *models.Investment {
+	for _, inv := range m.storage.GetInvestments() {
+		if inv.ID == m.selectedID {
+			return &inv
 		}
-		return m, cmd
 	}
-	return m, nil
+	return nil
 }
 
-// Select Transaction view - shows unsettled transactions for a person to pick one
-func (m Model) viewSelectTransaction() string {
-	title := TitleStyle.Render("  Select Transaction to Settle")
+// Investment income view - shows dividends/interest received from the selected investment
+func (m Model) viewInvestmentIncome() string {
+	title := TitleStyle.Render("  Income History")
 
-	transactions := m.storage.GetUnsettledDebtsForPerson(m.selectedPerson)
+	var inv *models.Investment
+	for _, i := range m.storage.GetInvestments() {
+		if i.ID == m.selectedID {
+			inv = &i
+			break
+		}
+	}
 
 	var content string
-	if len(transactions) == 0 {
-		content = MutedStyle.Render(fmt.Sprintf("\n  No unsettled transactions for %s.\n", m.selectedPerson))
+	if inv == nil {
+		content = MutedStyle.Render("\n  Investment not found.\n")
 	} else {
-		content = fmt.Sprintf("\n  Transactions with %s:\n\n", SelectedMenuItemStyle.Render(m.selectedPerson))
-		for i, tx := range transactions {
-			cursor := "  "
-			if i == m.cursor {
-				cursor = "▸ "
-			}
-			txType := AmountPositiveStyle.Render("LENT")
-			if tx.Type == models.Borrowed {
-				txType = AmountNegativeStyle.Render("BORROWED")
-			}
-			desc := tx.Description
-			if desc == "" {
-				desc = "(no description)"
+		incomes := m.storage.GetInvestmentIncome(inv.ID)
+		content = fmt.Sprintf("\n  Income from %s:\n\n", SelectedMenuItemStyle.Render(inv.Name))
+
+		if len(incomes) == 0 {
+			content += MutedStyle.Render("  No income recorded yet.\n")
+		} else {
+			var total float64
+			// Show most recent first
+			for i := len(incomes) - 1; i >= 0; i-- {
+				inc := incomes[i]
+				total += inc.Amount
+				content += fmt.Sprintf("  %s  %s  %s  %s\n",
+					inc.Date.Format("2006-01-02"),
+					TableCellStyle.Width(10).Render(string(inc.Type)),
+					m.formatAmountPlain(inc.Amount, m.currencyOf(inv.Currency)),
+					MutedStyle.Render(truncate(inc.Notes, 25)),
+				)
 			}
-			line := fmt.Sprintf("%s%s  %s  %s  %s",
-				cursor,
-				tx.Date.Format("2006-01-02"),
-				txType,
-				FormatAmountPlain(tx.Amount, m.config.Currency),
-				MutedStyle.Render(truncate(desc, 30)),
-			)
-			content += line + "\n"
+			gain := inv.CurrentValue - inv.InvestedAmount
+			content += fmt.Sprintf("\n  Income Received: %s", m.formatAmountPlain(total, m.currencyOf(inv.Currency)))
+			content += fmt.Sprintf("\n  Capital Gain:    %s", m.formatAmount(gain, m.currencyOf(inv.Currency)))
+			content += fmt.Sprintf("\n  Total Return:    %s\n", m.formatAmount(gain+total, m.currencyOf(inv.Currency)))
 		}
 	}
 
-	help := HelpStyle.Render("\n  Enter: Select • h: Settlement history • Esc: Back")
+	help := HelpStyle.Render("\n  a: Record income • Esc: Back")
 
 	return BoxStyle.Render(title + content + help)
 }
 
-func (m *Model) updateSelectTransactionView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	transactions := m.storage.GetUnsettledDebtsForPerson(m.selectedPerson)
-	maxCursor := len(transactions) - 1
-	if maxCursor < 0 {
-		maxCursor = 0
-	}
-
+func (m *Model) updateInvestmentIncomeView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
-	case "up", "k":
-		if m.cursor > 0 {
-			m.cursor--
-		}
-	case "down", "j":
-		if m.cursor < maxCursor {
-			m.cursor++
-		}
-	case "enter":
-		if len(transactions) > 0 && m.cursor < len(transactions) {
-			m.selectedTxID = transactions[m.cursor].ID
-			m.currentView = ViewSettleDebt
-			m.initSettleDebtInputs()
-		}
-	case "h":
-		// Show settlement history for this person
-		m.currentView = ViewPersonHistory
-		m.cursor = 0
+	case "a":
+		m.pushView(ViewAddInvestmentIncome)
+		m.initInvestmentIncomeInputs()
 	case "esc":
-		m.currentView = ViewDebts
-		m.selectedPerson = ""
+		m.popView()
+		m.selectedID = ""
 		m.cursor = 0
 	}
 
 	return m, nil
 }
 
-// Person History view - shows payment/settlement history for a specific person
-func (m Model) viewPersonHistory() string {
-	title := TitleStyle.Render("  Payment History")
+func (m *Model) initInvestmentIncomeInputs() {
+	m.inputs = make([]textinput.Model, 4)
 
-	settlements := m.storage.GetSettlementsForPerson(m.selectedPerson)
+	m.inputs[0] = textinput.New()
+	m.inputs[0].Placeholder = "Amount"
+	m.inputs[0].Focus()
+
+	m.inputs[1] = textinput.New()
+	m.inputs[1].Placeholder = "Type (dividend/interest/other)"
+
+	m.inputs[2] = textinput.New()
+	m.inputs[2].Placeholder = "Date (YYYY-MM-DD, leave empty for today)"
+
+	m.inputs[3] = textinput.New()
+	m.inputs[3].Placeholder = "Notes (optional)"
+
+	m.focusIndex = 0
+}
+
+func (m Model) viewAddInvestmentIncome() string {
+	title := TitleStyle.Render("  Record Investment Income")
 
 	var content string
-	content = fmt.Sprintf("\n  Payments with %s:\n\n", SelectedMenuItemStyle.Render(m.selectedPerson))
+	labels := []string{"Amount:", "Type:", "Date:", "Notes:"}
 
-	if len(settlements) == 0 {
-		content += MutedStyle.Render("  No payments recorded with this person yet.\n")
-	} else {
-		// Show most recent first
-		for i := len(settlements) - 1; i >= 0; i-- {
-			st := settlements[i]
-			cursor := "  "
-			if len(settlements)-1-i == m.cursor {
-				cursor = "▸ "
-			}
-			// For settlements: if original was LENT, settlement means "received payment"
-			// if original was BORROWED, settlement means "paid back"
-			action := AmountPositiveStyle.Render("RECEIVED")
-			if st.Type == models.Borrowed {
-				action = AmountNegativeStyle.Render("PAID")
-			}
-			note := st.Note
-			if note == "" {
-				note = "(no note)"
-			}
-			line := fmt.Sprintf("%s%s  %s  %s  %s",
-				cursor,
-				st.Date.Format("2006-01-02"),
-				action,
-				FormatAmountPlain(st.Amount, m.config.Currency),
-				MutedStyle.Render(truncate(note, 25)),
-			)
-			content += line + "\n"
+	for i, input := range m.inputs {
+		label := labels[i]
+		if i == m.focusIndex {
+			content += SelectedMenuItemStyle.Render("▸ "+label) + "\n"
+			content += "  " + FocusedInputStyle.Render(input.View()) + "\n\n"
+		} else {
+			content += MenuItemStyle.Render("  "+label) + "\n"
+			content += "  " + InputStyle.Render(input.View()) + "\n\n"
 		}
 	}
 
-	help := HelpStyle.Render("\n  Esc: Back to transactions")
+	help := HelpStyle.Render("+: Calculate • Tab: Next field • Enter: Save • Esc: Cancel")
 
-	return BoxStyle.Render(title + content + help)
+	return BoxStyle.Render(title + "\n" + content + help)
 }
 
-func (m *Model) updatePersonHistoryView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	settlements := m.storage.GetSettlementsForPerson(m.selectedPerson)
-	maxCursor := len(settlements) - 1
-	if maxCursor < 0 {
-		maxCursor = 0
+func (m *Model) updateAddInvestmentIncomeView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.updateFormFocus(msg.String()) {
+		return m, nil
 	}
 
 	switch msg.String() {
-	case "up", "k":
-		if m.cursor > 0 {
-			m.cursor--
+	case "enter":
+		amount, err := parseAmount(m.inputs[0].Value())
+		if err != nil || amount <= 0 {
+			m.message = "Invalid amount"
+			m.messageType = "error"
+			return m, nil
 		}
-	case "down", "j":
-		if m.cursor < maxCursor {
-			m.cursor++
+
+		incomeType := models.InvestmentIncomeType(m.inputs[1].Value())
+		switch incomeType {
+		case "":
+			incomeType = models.IncomeOther
+		case models.IncomeDividend, models.IncomeInterest, models.IncomeOther:
+		default:
+			m.message = "Type must be 'dividend', 'interest' or 'other'"
+			m.messageType = "error"
+			return m, nil
+		}
+
+		date := time.Now()
+		if m.inputs[2].Value() != "" {
+			date, err = time.Parse("2006-01-02", m.inputs[2].Value())
+			if err != nil {
+				m.message = "Invalid date format"
+				m.messageType = "error"
+				return m, nil
+			}
+		}
+
+		_, err = m.storage.AddInvestmentIncome(m.selectedID, amount, incomeType, date, m.inputs[3].Value())
+		if err != nil {
+			m.message = "Error saving: " + err.Error()
+			m.messageType = "error"
+			return m, nil
+		}
+
+		m.message = "Income recorded!"
+		m.messageType = "success"
+		m.popView()
+		m.inputs = nil
+		return m, nil
+	case "+":
+		if m.focusIndex == 0 && len(m.inputs) > 0 {
+			currentValue := m.inputs[0].Value()
+			calculatedValue, success := tryCalculateAmount(currentValue)
+			if success {
+				m.inputs[0].SetValue(calculatedValue)
+				m.message = "Calculated: " + calculatedValue
+				m.messageType = "info"
+			}
 		}
 	case "esc":
-		m.currentView = ViewDebts
-		m.cursor = 0
+		m.popView()
+		m.inputs = nil
+		return m, nil
 	}
 
+	if len(m.inputs) > 0 && m.focusIndex < len(m.inputs) {
+		var cmd tea.Cmd
+		m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
+
+		// Auto-calculate in amount field (index 0)
+		if m.focusIndex == 0 {
+			m.autoCalculateIfNeeded(0)
+		}
+		return m, cmd
+	}
 	return m, nil
 }
 
-// Settlement History view - shows all payment records
-func (m Model) viewSettlementHistory() string {
-	title := TitleStyle.Render("  All Payments History")
+// Savings view
+func (m Model) viewSavings() string {
+	title := TitleStyle.Render("  Savings Goals")
 
-	settlements := m.storage.GetAllSettlements()
+	targets := m.storage.GetVisibleSavingsTargets()
 
 	var content string
-	if len(settlements) == 0 {
-		content = MutedStyle.Render("\n  No payments recorded yet.\n")
+	if len(targets) == 0 {
+		content = MutedStyle.Render("\n  No savings goals set yet.\n")
 	} else {
 		content = "\n"
-		// Show most recent first, limit to last 15
-		start := 0
-		if len(settlements) > 15 {
-			start = len(settlements) - 15
-		}
-		for i := len(settlements) - 1; i >= start; i-- {
-			st := settlements[i]
+		for i, target := range targets {
 			cursor := "  "
-			if len(settlements)-1-i == m.cursor {
+			if i == m.cursor {
 				cursor = "▸ "
 			}
-			action := AmountPositiveStyle.Render("RECEIVED")
-			if st.Type == models.Borrowed {
-				action = AmountNegativeStyle.Render("PAID")
+			status := "Active"
+			name := SelectedMenuItemStyle.Render(target.ProductName)
+			if target.IsCompleted {
+				status = "Done!"
+				name = SuccessStyle.Render("✓ "+target.ProductName) + " " + RenderBadge("REACHED", "success")
 			}
-			note := st.Note
-			if note == "" {
-				note = "(no note)"
+			priority := ""
+			if !target.IsCompleted {
+				priority = MutedStyle.Render(fmt.Sprintf("  Priority %d", target.EffectivePriority()))
 			}
-			line := fmt.Sprintf("%s%s  %s  %s  %s  %s",
+			line := fmt.Sprintf("%s%s\n    %s / %s  [%s]\n    %s  Due: %s%s\n",
 				cursor,
-				st.Date.Format("2006-01-02"),
-				TableCellStyle.Width(10).Render(st.PersonName),
-				action,
-				FormatAmountPlain(st.Amount, m.config.Currency),
-				MutedStyle.Render(truncate(note, 20)),
+				name,
+				m.formatAmountPlain(target.CurrentAmount, m.config.Currency),
+				m.formatAmountPlain(target.TargetAmount, m.config.Currency),
+				status,
+				ProgressBar(target.CurrentAmount, target.TargetAmount, 20),
+				target.TargetDate.Format("2006-01-02"),
+				priority,
 			)
-			content += line + "\n"
+			content += line
 		}
+		content += m.suggestedContributions(targets)
 	}
 
-	help := HelpStyle.Render("\n  Esc: Back")
+	if hidden := len(m.storage.GetSavingsTargets()) - len(targets); hidden > 0 {
+		content += MutedStyle.Render(fmt.Sprintf("\n  %d completed goal(s) hidden (older than %d days)\n", hidden, m.config.HideCompletedAfterDays))
+	}
 
-	return BoxStyle.Render(title + content + help)
-}
+	help := HelpStyle.Render("\n  a: Add goal • t: From template • y: Duplicate • c: Add contribution • w: Withdraw • h: History • e: Edit • +/-: Priority • d: Delete • Esc: Back")
 
-func (m *Model) updateSettlementHistoryView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	settlements := m.storage.GetAllSettlements()
-	maxCursor := len(settlements) - 1
-	if maxCursor < 0 {
-		maxCursor = 0
-	}
-	// Limit to visible items
-	visibleCount := len(settlements)
-	if visibleCount > 15 {
-		visibleCount = 15
-	}
-	if maxCursor >= visibleCount {
-		maxCursor = visibleCount - 1
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateSavingsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	targets := m.storage.GetVisibleSavingsTargets()
+	maxCursor := len(targets) - 1
+	if maxCursor < 0 {
+		maxCursor = 0
 	}
 
 	switch msg.String() {
@@ -1285,158 +4334,231 @@ func (m *Model) updateSettlementHistoryView(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 		if m.cursor < maxCursor {
 			m.cursor++
 		}
+	case "g", "G", "ctrl+d", "ctrl+u":
+		m.cursor = m.jumpCursor(msg.String(), m.cursor, maxCursor)
 	case "esc":
-		m.currentView = ViewDebts
+		m.popView()
 		m.cursor = 0
 	}
 
 	return m, nil
 }
 
-// Net Worth view
-func (m Model) viewNetWorth() string {
-	title := TitleStyle.Render("  My Net Worth")
+// suggestedContributions renders how the monthly savings budget could be split
+// across targets, or a hint to set one
+func (m Model) suggestedContributions(targets []models.SavingsTarget) string {
+	budget := m.config.MonthlySavingsBudget
+	if budget <= 0 {
+		return MutedStyle.Render("\n  Set a monthly savings budget in Settings for a suggested split.\n")
+	}
+	suggested := m.storage.GetData().SuggestContribution(budget)
+	if len(suggested) == 0 {
+		return ""
+	}
 
-	investments := m.storage.GetInvestments()
-	data := m.storage.GetData()
+	content := fmt.Sprintf("\n  Suggested this month (%s budget, by priority and urgency):\n", m.formatAmountPlain(budget, m.config.Currency))
+	var total float64
+	for _, target := range targets {
+		amount, ok := suggested[target.ID]
+		if !ok {
+			continue
+		}
+		total += amount
+		content += fmt.Sprintf("    %s  %s\n", TableCellStyle.Width(20).Render(truncate(target.ProductName, 18)), m.formatAmountPlain(amount, m.config.Currency))
+	}
+	if left := budget - total; left >= 0.01 {
+		content += MutedStyle.Render(fmt.Sprintf("    %s left over once every goal is covered\n", m.formatAmountPlain(left, m.config.Currency)))
+	}
+	return content
+}
 
-	var content string
-	if len(investments) == 0 {
-		content = MutedStyle.Render("\n  No investments recorded yet.\n")
-	} else {
-		content = "\n"
-		for i, inv := range investments {
-			cursor := "  "
-			if i == m.cursor {
-				cursor = "▸ "
-			}
-			gain := inv.CurrentValue - inv.InvestedAmount
-			gainPct := float64(0)
-			if inv.InvestedAmount > 0 {
-				gainPct = (gain / inv.InvestedAmount) * 100
-			}
-			line := fmt.Sprintf("%s[%s] %s  %s  %s (%.1f%%)",
-				cursor,
-				TableCellStyle.Width(12).Render(string(inv.Type)),
-				TableCellStyle.Width(20).Render(truncate(inv.Name, 20)),
-				FormatAmountPlain(inv.CurrentValue, m.config.Currency),
-				FormatAmount(gain, ""),
-				gainPct,
-			)
-			content += line + "\n"
+// Savings History view - contributions to the selected goal, newest first
+func (m Model) viewSavingsHistory() string {
+	title := TitleStyle.Render("  Contribution History")
+
+	var target *models.SavingsTarget
+	for _, t := range m.storage.GetSavingsTargets() {
+		if t.ID == m.selectedID {
+			target = &t
+			break
 		}
 	}
+	if target == nil {
+		return BoxStyle.Render(title + MutedStyle.Render("\n  Savings goal not found.\n") + HelpStyle.Render("\n  Esc: Back"))
+	}
 
-	// Summary
-	netWorth := data.NetWorth()
-	stats := fmt.Sprintf("\n  Total Net Worth: %s", FormatAmountPlain(netWorth, m.config.Currency))
+	content := fmt.Sprintf("\n  %s  %s / %s\n",
+		SelectedMenuItemStyle.Render(target.ProductName),
+		m.formatAmountPlain(target.CurrentAmount, m.config.Currency),
+		m.formatAmountPlain(target.TargetAmount, m.config.Currency),
+	)
+	if !target.IsCompleted {
+		content += fmt.Sprintf("  Days remaining: %d  •  Needed per month: %s\n",
+			target.DaysRemaining(),
+			m.formatAmountPlain(target.RequiredMonthlySavings(), m.config.Currency),
+		)
+	}
 
-	help := HelpStyle.Render("\n  a: Add investment • u: Update value • d: Delete • Esc: Back")
+	contributions := m.storage.GetSavingsContributions(target.ID)
+	if len(contributions) == 0 {
+		content += MutedStyle.Render("\n  No contributions recorded yet.\n")
+		return BoxStyle.Render(title + content + HelpStyle.Render("\n  Esc: Back"))
+	}
 
-	return BoxStyle.Render(title + content + stats + help)
+	// Running balance after each contribution, starting from whatever was saved
+	// before contributions were recorded
+	balances := make([]float64, len(contributions))
+	balance := target.CurrentAmount
+	for _, c := range contributions {
+		balance -= c.Amount
+	}
+	for i, c := range contributions {
+		balance += c.Amount
+		balances[i] = balance
+	}
+
+	content += "\n" + MutedStyle.Render(fmt.Sprintf("  %-10s  %14s  %14s  %s", "Date", "Amount", "Balance", "Notes")) + "\n"
+	start, end := visibleWindow(m.offset, m.offset, m.listPageSize(), len(contributions))
+	for row := start; row < end; row++ {
+		i := len(contributions) - 1 - row
+		c := contributions[i]
+		amount := AmountPositiveStyle.Render(fmt.Sprintf("%14s", m.formatAmountPlain(c.Amount, m.config.Currency)))
+		if c.Amount < 0 {
+			amount = AmountNegativeStyle.Render(fmt.Sprintf("%14s", m.formatAmountPlain(c.Amount, m.config.Currency)))
+		}
+		content += fmt.Sprintf("  %-10s  %s  %14s  %s\n",
+			c.Date.Format("2006-01-02"),
+			amount,
+			m.formatAmountPlain(balances[i], m.config.Currency),
+			MutedStyle.Render(truncate(c.Notes, 25)),
+		)
+	}
+	if end-start < len(contributions) {
+		content += MutedStyle.Render(fmt.Sprintf("\n  showing %d–%d of %d", start+1, end, len(contributions))) + "\n"
+	}
+
+	help := HelpStyle.Render("\n  ↑/↓: Scroll • Esc: Back")
+
+	return BoxStyle.Render(title + content + help)
 }
 
-func (m *Model) updateNetWorthView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	investments := m.storage.GetInvestments()
-	maxCursor := len(investments) - 1
-	if maxCursor < 0 {
-		maxCursor = 0
+func (m *Model) updateSavingsHistoryView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	maxOffset := len(m.storage.GetSavingsContributions(m.selectedID)) - m.listPageSize()
+	if maxOffset < 0 {
+		maxOffset = 0
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.offset > 0 {
+			m.offset--
+		}
+	case "down", "j":
+		if m.offset < maxOffset {
+			m.offset++
+		}
+	case "esc":
+		m.popView()
+		m.selectedID = ""
+		m.offset = 0
+	}
+
+	return m, nil
+}
+
+// Goal Templates view - built-in goals sized from your spending
+func (m Model) viewGoalTemplates() string {
+	title := TitleStyle.Render("  New Goal from Template")
+
+	content := "\n"
+	for i, t := range storage.GoalTemplates {
+		cursor := "  "
+		style := MenuItemStyle
+		if i == m.cursor {
+			cursor = "▸ "
+			style = SelectedMenuItemStyle
+		}
+		amount := MutedStyle.Render("needs expense history")
+		if target := m.storage.GoalTemplateAmount(t); target > 0 {
+			amount = m.formatAmountPlain(target, m.config.Currency)
+		}
+		content += fmt.Sprintf("%s  %s\n", style.Render(cursor+t.Name), amount)
+		content += "      " + MutedStyle.Render(fmt.Sprintf("%s, due in %d months", t.Description, t.MonthsToSave)) + "\n\n"
 	}
+	content += MutedStyle.Render("  Amounts are based on your average monthly spend over the last 3 months.") + "\n"
+
+	help := HelpStyle.Render("\n  Enter: Create goal • Esc: Back")
 
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateGoalTemplatesView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "up", "k":
 		if m.cursor > 0 {
 			m.cursor--
 		}
 	case "down", "j":
-		if m.cursor < maxCursor {
+		if m.cursor < len(storage.GoalTemplates)-1 {
 			m.cursor++
 		}
-	case "a":
-		m.currentView = ViewAddInvestment
-		m.initInvestmentInputs()
-	case "d":
-		if len(investments) > 0 && m.cursor < len(investments) {
-			m.currentView = ViewConfirmDelete
-			m.inputs = nil
-		}
-	case "u":
-		if len(investments) > 0 && m.cursor < len(investments) {
-			m.selectedID = investments[m.cursor].ID
-			m.currentView = ViewUpdateInvestment
-			m.inputs = make([]textinput.Model, 2)
-			m.inputs[0] = textinput.New()
-			m.inputs[0].Placeholder = "New invested amount"
-			m.inputs[0].SetValue(fmt.Sprintf("%.2f", investments[m.cursor].InvestedAmount))
-			m.inputs[1] = textinput.New()
-			m.inputs[1].Placeholder = "New current value"
-			m.inputs[1].SetValue(fmt.Sprintf("%.2f", investments[m.cursor].CurrentValue))
-			m.inputs[0].Focus()
-			m.focusIndex = 0
+	case "enter":
+		if m.cursor < len(storage.GoalTemplates) {
+			target, err := m.storage.CreateGoalFromTemplate(storage.GoalTemplates[m.cursor].Name)
+			if err != nil {
+				m.message = err.Error()
+				m.messageType = "error"
+				return m, nil
+			}
+			m.message = fmt.Sprintf("Goal %q created for %s!", target.ProductName, m.formatAmountPlain(target.TargetAmount, m.config.Currency))
+			m.messageType = "success"
+			m.popView()
+			m.cursor = 0
 		}
 	case "esc":
-		m.currentView = ViewMain
+		m.popView()
 		m.cursor = 0
 	}
 
 	return m, nil
 }
 
-func (m *Model) initInvestmentInputs() {
-	m.inputs = make([]textinput.Model, 6)
+func (m *Model) initSavingsTargetInputs() {
+	m.inputs = make([]textinput.Model, 4)
 
 	m.inputs[0] = textinput.New()
-	m.inputs[0].Placeholder = "Type (stocks/mutual_funds/gold/silver/fixed_deposit/ppf/crypto/other)"
+	m.inputs[0].Placeholder = "Product Name (e.g., iPhone 16, MacBook Pro)"
 	m.inputs[0].Focus()
 
 	m.inputs[1] = textinput.New()
-	m.inputs[1].Placeholder = "Name (e.g., HDFC Bank, SBI Bluechip)"
+	m.inputs[1].Placeholder = "Target Amount"
 
 	m.inputs[2] = textinput.New()
-	m.inputs[2].Placeholder = "Invested Amount"
+	m.inputs[2].Placeholder = "Target Date (YYYY-MM-DD)"
 
 	m.inputs[3] = textinput.New()
-	m.inputs[3].Placeholder = "Current Value"
-
-	m.inputs[4] = textinput.New()
-	m.inputs[4].Placeholder = "Units (optional)"
-
-	m.inputs[5] = textinput.New()
-	m.inputs[5].Placeholder = "Purchase Date (YYYY-MM-DD)"
+	m.inputs[3].Placeholder = "Description (optional)"
 
 	m.focusIndex = 0
 }
 
-func (m Model) viewAddInvestment() string {
-	title := TitleStyle.Render("  Add Investment")
+func (m Model) viewAddSavingsTarget() string {
+	title := TitleStyle.Render("  Add Savings Goal")
+	if m.currentView == ViewEditSavingsTarget {
+		title = TitleStyle.Render("  Edit Savings Goal")
+	}
 
 	var content string
-	labels := []string{"Type:", "Name:", "Invested:", "Current Value:", "Units:", "Purchase Date:"}
-	hints := []string{
-		"Options: stocks, mutual_funds, gold, silver, fixed_deposit, ppf, crypto, real_estate, other",
-		"e.g., HDFC Bank, SBI Bluechip, Gold 24K",
-		"",
-		"",
-		"(optional)",
-		"Format: YYYY-MM-DD",
-	}
+	labels := []string{"Product:", "Target Amount:", "Target Date:", "Description:"}
 
 	for i, input := range m.inputs {
 		label := labels[i]
 		if i == m.focusIndex {
 			content += SelectedMenuItemStyle.Render("▸ "+label) + "\n"
-			content += "  " + FocusedInputStyle.Render(input.View()) + "\n"
-			if hints[i] != "" {
-				content += "  " + MutedStyle.Render(hints[i]) + "\n"
-			}
-			content += "\n"
+			content += "  " + FocusedInputStyle.Render(input.View()) + "\n\n"
 		} else {
 			content += MenuItemStyle.Render("  "+label) + "\n"
-			content += "  " + InputStyle.Render(input.View()) + "\n"
-			if hints[i] != "" {
-				content += "  " + MutedStyle.Render(hints[i]) + "\n"
-			}
-			content += "\n"
+			content += "  " + InputStyle.Render(input.View()) + "\n\n"
 		}
 	}
 
@@ -1445,128 +4567,77 @@ func (m Model) viewAddInvestment() string {
 	return BoxStyle.Render(title + "\n" + content + help)
 }
 
-func (m Model) viewUpdateInvestment() string {
-	title := TitleStyle.Render("  Update Investment Value")
-
-	var content string
-	content += "\n"
-
-	labels := []string{"New invested amount:", "New current value:"}
-	hints := []string{"Enter the new invested amount", "Enter the new current value"}
-
-	for i, input := range m.inputs {
-		label := labels[i]
-		if i == m.focusIndex {
-			content += "  " + SelectedMenuItemStyle.Render("▸ "+label) + "\n"
-			content += "  " + FocusedInputStyle.Render(input.View()) + "\n"
-			if hints[i] != "" {
-				content += "  " + MutedStyle.Render(hints[i]) + "\n"
-			}
-			content += "\n"
-		} else {
-			content += "  " + MenuItemStyle.Render("  "+label) + "\n"
-			content += "  " + InputStyle.Render(input.View()) + "\n"
-			if hints[i] != "" {
-				content += "  " + MutedStyle.Render(hints[i]) + "\n"
-			}
-			content += "\n"
-		}
+func (m *Model) updateAddSavingsTargetView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.updateFormFocus(msg.String()) {
+		return m, nil
 	}
 
-	help := HelpStyle.Render("\n  Tab: Next field • Enter: Save • Esc: Cancel")
-
-	return BoxStyle.Render(title + "\n" + content + help)
-}
-
-func (m Model) viewConfirmDelete() string {
-	title := TitleStyle.Render("  Confirm Delete")
-
-	var content string
-	content += "\n  Are you sure you want to delete this investment?\n\n"
-	content += "  This action cannot be undone.\n"
-
-	help := HelpStyle.Render("\n  Enter: Yes, delete • Esc: Cancel")
-
-	return BoxStyle.Render(title + content + help)
-}
-
-func (m *Model) updateAddInvestmentView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
-	case "tab", "down":
-		m.inputs[m.focusIndex].Blur()
-		m.focusIndex = (m.focusIndex + 1) % len(m.inputs)
-		m.inputs[m.focusIndex].Focus()
-	case "shift+tab", "up":
-		m.inputs[m.focusIndex].Blur()
-		m.focusIndex--
-		if m.focusIndex < 0 {
-			m.focusIndex = len(m.inputs) - 1
-		}
-		m.inputs[m.focusIndex].Focus()
 	case "enter":
-		invType := models.InvestmentType(m.inputs[0].Value())
-		name := m.inputs[1].Value()
-		if name == "" {
-			m.message = "Name is required"
+		productName := m.inputs[0].Value()
+		if productName == "" {
+			m.message = "Product name is required"
 			m.messageType = "error"
 			return m, nil
 		}
 
-		invested, err := strconv.ParseFloat(m.inputs[2].Value(), 64)
+		targetAmount, err := parseAmount(m.inputs[1].Value())
 		if err != nil {
-			m.message = "Invalid invested amount"
+			m.message = "Invalid target amount"
 			m.messageType = "error"
 			return m, nil
 		}
 
-		current, err := strconv.ParseFloat(m.inputs[3].Value(), 64)
+		targetDate, err := time.Parse("2006-01-02", m.inputs[2].Value())
 		if err != nil {
-			m.message = "Invalid current value"
+			m.message = "Invalid date format (use YYYY-MM-DD)"
 			m.messageType = "error"
 			return m, nil
 		}
 
-		var units float64
-		if m.inputs[4].Value() != "" {
-			units, _ = strconv.ParseFloat(m.inputs[4].Value(), 64)
-		}
+		description := m.inputs[3].Value()
 
-		purchaseDate := time.Now()
-		if m.inputs[5].Value() != "" {
-			purchaseDate, err = time.Parse("2006-01-02", m.inputs[5].Value())
-			if err != nil {
-				m.message = "Invalid date format"
+		if m.currentView == ViewEditSavingsTarget {
+			if err := m.storage.UpdateSavingsTarget(m.selectedID, productName, targetAmount, targetDate, description); err != nil {
+				m.message = "Error saving: " + err.Error()
 				m.messageType = "error"
 				return m, nil
 			}
+			m.message = "Savings goal updated!"
+			m.messageType = "success"
+			m.popView()
+			m.inputs = nil
+			m.selectedID = ""
+			return m, nil
 		}
 
-		_, err = m.storage.AddInvestment(invType, name, invested, current, units, purchaseDate, "")
+		_, err = m.storage.AddSavingsTarget(productName, targetAmount, targetDate, description)
 		if err != nil {
 			m.message = "Error saving: " + err.Error()
 			m.messageType = "error"
 			return m, nil
 		}
 
-		m.message = "Investment added!"
+		m.message = "Savings goal created!"
 		m.messageType = "success"
-		m.currentView = ViewNetWorth
+		m.popView()
 		m.inputs = nil
 		m.cursor = 0
 		return m, nil
 	case "+":
-		if (m.focusIndex == 2 || m.focusIndex == 3) && len(m.inputs) > 0 {
-			currentValue := m.inputs[m.focusIndex].Value()
+		if m.focusIndex == 1 && len(m.inputs) > 0 {
+			currentValue := m.inputs[1].Value()
 			calculatedValue, success := tryCalculateAmount(currentValue)
 			if success {
-				m.inputs[m.focusIndex].SetValue(calculatedValue)
+				m.inputs[1].SetValue(calculatedValue)
 				m.message = "Calculated: " + calculatedValue
 				m.messageType = "info"
 			}
 		}
 	case "esc":
-		m.currentView = ViewNetWorth
+		m.popView()
 		m.inputs = nil
+		m.selectedID = ""
 		m.cursor = 0
 		return m, nil
 	}
@@ -1575,507 +4646,884 @@ func (m *Model) updateAddInvestmentView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		var cmd tea.Cmd
 		m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
 
-		// Auto-calculate in amount fields (index 2: Invested, index 3: Current Value)
-		if m.focusIndex == 2 || m.focusIndex == 3 {
-			m.autoCalculateIfNeeded(m.focusIndex)
+		// Auto-calculate in amount field (index 1: Target Amount)
+		if m.focusIndex == 1 {
+			m.autoCalculateIfNeeded(1)
+		}
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m *Model) initContributionInputs() {
+	m.inputs = make([]textinput.Model, 2)
+
+	m.inputs[0] = textinput.New()
+	m.inputs[0].Placeholder = "Amount"
+	m.inputs[0].Focus()
+
+	m.inputs[1] = textinput.New()
+	m.inputs[1].Placeholder = "Notes (optional)"
+
+	m.focusIndex = 0
+}
+
+func (m Model) viewAddContribution() string {
+	title := TitleStyle.Render("  Add Contribution")
+	if m.withdrawing {
+		title = TitleStyle.Render("  Withdraw from Goal")
+	}
+
+	var content string
+	labels := []string{"Amount:", "Notes:"}
+
+	for i, input := range m.inputs {
+		label := labels[i]
+		if i == m.focusIndex {
+			content += SelectedMenuItemStyle.Render("▸ "+label) + "\n"
+			content += "  " + FocusedInputStyle.Render(input.View()) + "\n\n"
+		} else {
+			content += MenuItemStyle.Render("  "+label) + "\n"
+			content += "  " + InputStyle.Render(input.View()) + "\n\n"
 		}
-		return m, cmd
 	}
-	return m, nil
+
+	help := HelpStyle.Render("+: Calculate • Tab: Next field • Enter: Save • Esc: Cancel")
+
+	return BoxStyle.Render(title + "\n" + content + help)
 }
 
-func (m *Model) updateUpdateInvestmentView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+func (m *Model) updateAddContributionView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.updateFormFocus(msg.String()) {
+		return m, nil
+	}
+
 	switch msg.String() {
-	case "tab", "down":
-		m.inputs[m.focusIndex].Blur()
-		m.focusIndex = (m.focusIndex + 1) % len(m.inputs)
-		m.inputs[m.focusIndex].Focus()
-	case "shift+tab", "up":
-		m.inputs[m.focusIndex].Blur()
-		m.focusIndex--
-		if m.focusIndex < 0 {
-			m.focusIndex = len(m.inputs) - 1
-		}
-		m.inputs[m.focusIndex].Focus()
 	case "enter":
-		if m.inputs[0].Value() == "" || m.inputs[1].Value() == "" {
-			m.message = "Both values are required"
-			m.messageType = "error"
-			return m, nil
-		}
-
-		investedAmount, err := strconv.ParseFloat(m.inputs[0].Value(), 64)
+		amount, err := parseAmount(m.inputs[0].Value())
 		if err != nil {
-			m.message = "Invalid invested amount"
+			m.message = "Invalid amount"
 			m.messageType = "error"
 			return m, nil
 		}
 
-		currentValue, err := strconv.ParseFloat(m.inputs[1].Value(), 64)
-		if err != nil {
-			m.message = "Invalid current value"
-			m.messageType = "error"
-			return m, nil
-		}
+		notes := m.inputs[1].Value()
 
-		if investedAmount < 0 || currentValue < 0 {
-			m.message = "Values must be positive"
-			m.messageType = "error"
-			return m, nil
+		var completedGoal bool
+		if m.withdrawing {
+			err = m.storage.WithdrawSavingsContribution(m.selectedID, amount, notes)
+		} else {
+			_, completedGoal, err = m.storage.AddSavingsContribution(m.selectedID, amount, notes)
 		}
-
-		err = m.storage.UpdateInvestment(m.selectedID, investedAmount, currentValue)
 		if err != nil {
-			m.message = "Error updating: " + err.Error()
+			m.message = "Error saving: " + err.Error()
 			m.messageType = "error"
 			return m, nil
 		}
 
-		m.message = "Investment updated!"
+		m.message = "Contribution added!"
+		if m.withdrawing {
+			m.message = "Withdrawal recorded!"
+		} else if completedGoal {
+			for _, t := range m.storage.GetSavingsTargets() {
+				if t.ID == m.selectedID {
+					m.message = "🎉 Goal reached: " + t.ProductName + "!"
+				}
+			}
+		}
+		m.withdrawing = false
 		m.messageType = "success"
-		m.currentView = ViewNetWorth
+		m.popView()
 		m.inputs = nil
 		m.selectedID = ""
 		m.cursor = 0
 		return m, nil
 	case "+":
-		if (m.focusIndex == 0 || m.focusIndex == 1) && len(m.inputs) > 0 {
-			currentValue := m.inputs[m.focusIndex].Value()
+		if m.focusIndex == 0 && len(m.inputs) > 0 {
+			currentValue := m.inputs[0].Value()
 			calculatedValue, success := tryCalculateAmount(currentValue)
 			if success {
-				m.inputs[m.focusIndex].SetValue(calculatedValue)
+				m.inputs[0].SetValue(calculatedValue)
 				m.message = "Calculated: " + calculatedValue
 				m.messageType = "info"
 			}
 		}
-	case "esc":
-		m.currentView = ViewNetWorth
-		m.inputs = nil
-		m.selectedID = ""
-		m.cursor = 0
-		return m, nil
 	}
 
 	if len(m.inputs) > 0 && m.focusIndex < len(m.inputs) {
 		var cmd tea.Cmd
 		m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
 
-		// Auto-calculate in amount fields (index 0: Invested, index 1: Current Value)
-		if m.focusIndex == 0 || m.focusIndex == 1 {
-			m.autoCalculateIfNeeded(m.focusIndex)
+		// Auto-calculate in amount field (index 0)
+		if m.focusIndex == 0 {
+			m.autoCalculateIfNeeded(0)
 		}
 		return m, cmd
 	}
 	return m, nil
 }
 
-func (m *Model) updateConfirmDeleteView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "enter":
-		investments := m.storage.GetInvestments()
-		if len(investments) > 0 && m.cursor < len(investments) {
-			m.storage.DeleteInvestment(investments[m.cursor].ID)
-			m.message = "Investment deleted"
-			m.messageType = "success"
-		}
-		m.currentView = ViewNetWorth
-		m.inputs = nil
-		m.cursor = 0
-		return m, nil
-	case "esc":
-		m.currentView = ViewNetWorth
-		m.inputs = nil
-		m.cursor = 0
-		return m, nil
+// Stats view
+func (m Model) viewStats() string {
+	title := TitleStyle.Render("  Stats & Dashboard")
+
+	data := m.storage.GetData()
+	now := time.Now()
+
+	// Cached until the next change, so redraws don't walk every record
+	summary := data.Summary(now.Year(), now.Month(), m.excludedCategories())
+	portfolioGain, portfolioPct := summary.PortfolioReturn()
+	avgDaily, projected := models.SpendProjection(summary.MonthlyExpenses, now)
+	projectedBadge := ""
+	if budget := m.config.MonthlyBudget; budget > 0 && projected > budget {
+		projectedBadge = "  " + RenderBadge("OVER BUDGET PACE", "warning")
 	}
 
-	return m, nil
+	// Accrued interest moves with the clock, so it is computed fresh
+	interestReceivable, interestPayable := data.TotalAccruedInterest(now)
+
+	content := fmt.Sprintf(`
+  %s
+  ──────────────────────────
+  Total Net Worth:     %s
+  Total Invested:      %s
+  Return:              %s (%+.2f%%)
+  Annualized (CAGR):   %+.2f%%
+
+  %s
+  ──────────────────────────
+  Total Borrowed:      %s
+  Total Lent:          %s
+  Net Position:        %s
+  Interest Receivable: %s
+  Interest Payable:    %s
+
+  %s%s
+  ──────────────────────────
+  This Month:          %s
+  Daily Average:       %s
+  Projected Month-End: %s%s
+  All Time:            %s
+%s
+  %s
+  ──────────────────────────
+  Active Goals:        %d
+  Completed Goals:     %d
+  Total Target:        %s
+  Total Saved:         %s
+  Progress:            %s
+`,
+		m.statsHeader(0),
+		m.formatAmountPlain(summary.NetWorth, m.config.Currency),
+		m.formatAmountPlain(summary.TotalInvested, m.config.Currency),
+		m.formatAmount(portfolioGain, m.config.Currency),
+		portfolioPct,
+		data.PortfolioCAGR(now),
+		m.statsHeader(1),
+		m.formatAmountPlain(summary.TotalBorrowed, m.config.Currency),
+		m.formatAmountPlain(summary.TotalLent, m.config.Currency),
+		m.formatAmount(summary.NetDebtPosition(), m.config.Currency),
+		m.formatAmountPlain(interestReceivable, m.config.Currency),
+		m.formatAmountPlain(interestPayable, m.config.Currency),
+		m.statsHeader(2),
+		m.exclusionLabel(),
+		m.formatAmountPlain(summary.MonthlyExpenses, m.config.Currency),
+		m.formatAmountPlain(avgDaily, m.config.Currency),
+		m.formatAmountPlain(projected, m.config.Currency),
+		projectedBadge,
+		m.formatAmountPlain(summary.TotalExpenses, m.config.Currency),
+		m.topCategoriesBlock(data, now)+m.topTagsBlock(data, now),
+		m.statsHeader(3),
+		summary.ActiveSavingsGoals,
+		summary.CompletedSavingsGoals,
+		m.formatAmountPlain(summary.TotalSavingsTarget, m.config.Currency),
+		m.formatAmountPlain(summary.TotalSaved, m.config.Currency),
+		ProgressBar(summary.TotalSaved, summary.TotalSavingsTarget, 20),
+	)
+
+	help := HelpStyle.Render("\n  ↑/↓: Select section • Enter: Open • c: Month/all-time categories • x: Toggle exclusions • Esc: Back to main menu")
+
+	return BoxStyle.Render(title + content + help)
 }
 
-// Savings view
-func (m Model) viewSavings() string {
-	title := TitleStyle.Render("  Savings Goals")
+// topCategoriesShown is how many tags the Stats ranking lists
+const topCategoriesShown = 5
 
-	targets := m.storage.GetSavingsTargets()
+// categoryRollupShare is the share of spend below which categories are folded
+// into "other" in the Stats chart
+const categoryRollupShare = 0.03
 
-	var content string
-	if len(targets) == 0 {
-		content = MutedStyle.Render("\n  No savings goals set yet.\n")
-	} else {
-		content = "\n"
-		for i, target := range targets {
-			cursor := "  "
-			if i == m.cursor {
-				cursor = "▸ "
-			}
-			status := "Active"
-			if target.IsCompleted {
-				status = "Done!"
-			}
-			line := fmt.Sprintf("%s%s\n    %s / %s  [%s]\n    %s  Due: %s\n",
-				cursor,
-				SelectedMenuItemStyle.Render(target.ProductName),
-				FormatAmountPlain(target.CurrentAmount, m.config.Currency),
-				FormatAmountPlain(target.TargetAmount, m.config.Currency),
-				status,
-				ProgressBar(target.CurrentAmount, target.TargetAmount, 20),
-				target.TargetDate.Format("2006-01-02"),
-			)
-			content += line
+// topCategoriesBlock charts spend per category for this month (or all time),
+// highest first, with bars scaled to the largest category
+func (m Model) topCategoriesBlock(data *models.Data, now time.Time) string {
+	excluded := m.excludedCategories()
+	label, ranked := "This Month", data.CategoryTotals(now.Year(), now.Month())
+	if m.topAllTime {
+		label, ranked = "All Time", data.TopCategoriesAllTime(0)
+	}
+	ranked = slices.DeleteFunc(ranked, func(ct models.CategoryTotal) bool {
+		return slices.Contains(excluded, ct.Category)
+	})
+	ranked = models.RollupCategories(ranked, categoryRollupShare)
+
+	block := "\n  " + MutedStyle.Render("Spending by Category ("+label+")") + "\n"
+	if len(ranked) == 0 {
+		return block + "  " + MutedStyle.Render("No expenses yet") + "\n"
+	}
+
+	var total, largest float64
+	for _, ct := range ranked {
+		total += ct.Total
+		largest = max(largest, ct.Total)
+	}
+	for _, ct := range ranked {
+		block += fmt.Sprintf("  %-14s %s %5.1f%%  %s\n", ct.Category, Bar(ct.Total, largest, 16),
+			ct.Total/total*100, m.formatAmountPlain(ct.Total, m.config.Currency))
+	}
+	return block
+}
+
+// topTagsBlock ranks spend per tag over the same period as topCategoriesBlock.
+// It is empty when no expense in that period is tagged.
+func (m Model) topTagsBlock(data *models.Data, now time.Time) string {
+	label := "This Month"
+	ranked := data.TagTotals(now.Year(), now.Month())
+	if m.topAllTime {
+		label = "All Time"
+		ranked = data.TagTotalsAllTime()
+	}
+	if len(ranked) == 0 {
+		return ""
+	}
+
+	block := "\n  " + MutedStyle.Render("Top Tags ("+label+")") + "\n"
+	for i, tt := range ranked {
+		if i == topCategoriesShown {
+			break
 		}
+		block += fmt.Sprintf("  %d. %-14s %s\n", i+1, truncate("#"+tt.Tag, 14), m.formatAmountPlain(tt.Total, m.config.Currency))
 	}
+	return block
+}
 
-	help := HelpStyle.Render("\n  a: Add goal • c: Add contribution • d: Delete • Esc: Back")
+// formatTags renders tags the way they are typed, e.g. "#vacation #work"
+func formatTags(tags []string) string {
+	return "#" + strings.Join(tags, " #")
+}
 
-	return BoxStyle.Render(title + content + help)
+// statsSections lists the Stats sections in display order with the view each one opens
+var statsSections = []struct {
+	title string
+	view  View
+}{
+	{"NET WORTH", ViewNetWorth},
+	{"DEBTS", ViewDebts},
+	{"EXPENSES", ViewExpenses},
+	{"SAVINGS GOALS", ViewSavings},
 }
 
-func (m *Model) updateSavingsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	targets := m.storage.GetSavingsTargets()
-	maxCursor := len(targets) - 1
-	if maxCursor < 0 {
-		maxCursor = 0
+// statsHeader renders a Stats section title, highlighting the section under the cursor
+func (m Model) statsHeader(section int) string {
+	if section == m.cursor {
+		return SelectedMenuItemStyle.Render("▸ " + statsSections[section].title)
 	}
+	return MenuItemStyle.Render("  " + statsSections[section].title)
+}
 
+func (m *Model) updateStatsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Stats is a launchpad: the cursor selects a section, Enter opens its view
 	switch msg.String() {
 	case "up", "k":
 		if m.cursor > 0 {
 			m.cursor--
 		}
 	case "down", "j":
-		if m.cursor < maxCursor {
+		if m.cursor < len(statsSections)-1 {
 			m.cursor++
 		}
-	case "a":
-		m.currentView = ViewAddSavingsTarget
-		m.initSavingsTargetInputs()
-	case "c":
-		if len(targets) > 0 && m.cursor < len(targets) {
-			m.selectedID = targets[m.cursor].ID
-			m.currentView = ViewAddContribution
-			m.initContributionInputs()
-		}
-	case "d":
-		if len(targets) > 0 && m.cursor < len(targets) {
-			m.storage.DeleteSavingsTarget(targets[m.cursor].ID)
-			m.message = "Goal deleted"
-			m.messageType = "success"
-			if m.cursor > 0 {
-				m.cursor--
-			}
+	case "enter":
+		if m.cursor < len(statsSections) {
+			m.pushView(statsSections[m.cursor].view)
+			m.cursor = 0
 		}
 	case "esc":
-		m.currentView = ViewMain
+		m.popView()
 		m.cursor = 0
 	}
-
 	return m, nil
 }
 
-func (m *Model) initSavingsTargetInputs() {
-	m.inputs = make([]textinput.Model, 4)
+// budgetLine renders this month's spend against config.MonthlyBudget, or "" when no budget is set
+func (m Model) budgetLine() string {
+	spent, budget := m.storage.CurrentMonthSpend()
+	if budget <= 0 {
+		return ""
+	}
+	line := fmt.Sprintf("\n  Budget: %s %s / %s",
+		BudgetBar(spent, budget, 20),
+		m.formatAmountPlain(spent, m.config.Currency),
+		m.formatAmountPlain(budget, m.config.Currency),
+	)
+	if budgetLevel(spent, budget) == "over" {
+		line += "  " + RenderBadge("OVER BUDGET", "danger")
+	}
+	return line + "\n"
+}
+
+// excludedCategories returns the categories to leave out of expense totals for this session
+func (m Model) excludedCategories() []models.ExpenseCategory {
+	if !m.applyExcluded {
+		return nil
+	}
+	return m.config.ExcludedCategories
+}
+
+// exclusionLabel returns a " (excluding x, y)" suffix when exclusions are active
+func (m Model) exclusionLabel() string {
+	excluded := m.excludedCategories()
+	if len(excluded) == 0 {
+		return ""
+	}
+	names := make([]string, len(excluded))
+	for i, c := range excluded {
+		names[i] = string(c)
+	}
+	return MutedStyle.Render(" (excluding " + strings.Join(names, ", ") + ")")
+}
+
+func (m *Model) toggleExclusions() {
+	if len(m.config.ExcludedCategories) == 0 {
+		m.message = "No excluded categories configured"
+		m.messageType = "info"
+		return
+	}
+	m.applyExcluded = !m.applyExcluded
+	if m.applyExcluded {
+		m.message = "Excluded categories left out of totals"
+	} else {
+		m.message = "Showing totals for all categories"
+	}
+	m.messageType = "info"
+}
+
+// Settings view
+func (m *Model) initSettingsInputs() {
+	m.inputs = make([]textinput.Model, 6)
 
 	m.inputs[0] = textinput.New()
-	m.inputs[0].Placeholder = "Product Name (e.g., iPhone 16, MacBook Pro)"
+	m.inputs[0].Placeholder = "Currency (e.g., INR, USD)"
+	m.inputs[0].SetValue(m.config.Currency)
 	m.inputs[0].Focus()
 
 	m.inputs[1] = textinput.New()
-	m.inputs[1].Placeholder = "Target Amount"
+	m.inputs[1].Placeholder = "Data file path"
+	m.inputs[1].SetValue(m.config.DataFile)
 
 	m.inputs[2] = textinput.New()
-	m.inputs[2].Placeholder = "Target Date (YYYY-MM-DD)"
+	m.inputs[2].Placeholder = "Obsidian vault path"
+	m.inputs[2].SetValue(m.config.ObsidianVaultPath)
 
 	m.inputs[3] = textinput.New()
-	m.inputs[3].Placeholder = "Description (optional)"
+	m.inputs[3].Placeholder = "Monthly budget (0 to disable)"
+	if m.config.MonthlyBudget > 0 {
+		m.inputs[3].SetValue(strconv.FormatFloat(m.config.MonthlyBudget, 'f', -1, 64))
+	}
+
+	m.inputs[4] = textinput.New()
+	m.inputs[4].Placeholder = "Monthly savings budget (0 to disable)"
+	if m.config.MonthlySavingsBudget > 0 {
+		m.inputs[4].SetValue(strconv.FormatFloat(m.config.MonthlySavingsBudget, 'f', -1, 64))
+	}
+
+	m.inputs[5] = newCategoryInput()
+	m.inputs[5].Placeholder = "Default expense category (empty for other)"
+	m.inputs[5].SetValue(string(m.config.DefaultCategory))
 
 	m.focusIndex = 0
 }
 
-func (m Model) viewAddSavingsTarget() string {
-	title := TitleStyle.Render("  Add Savings Goal")
+func (m Model) viewSettings() string {
+	title := TitleStyle.Render("  Settings")
 
 	var content string
-	labels := []string{"Product:", "Target Amount:", "Target Date:", "Description:"}
+	labels := []string{"Currency:", "Data File:", "Obsidian Vault:", "Monthly Budget:", "Savings Budget:", "Default Category:"}
+	hints := []string{
+		"Shown next to every amount",
+		"JSON file where all data is stored",
+		"Directory the markdown notes are synced to (must be writable)",
+		"Soft cap on total monthly spending; leave empty or 0 to disable",
+		"What you put into savings goals each month; Savings suggests how to split it",
+		"Pre-filled when adding an expense (Tab completes)",
+	}
 
 	for i, input := range m.inputs {
 		label := labels[i]
 		if i == m.focusIndex {
 			content += SelectedMenuItemStyle.Render("▸ "+label) + "\n"
-			content += "  " + FocusedInputStyle.Render(input.View()) + "\n\n"
+			content += "  " + FocusedInputStyle.Render(input.View()) + "\n"
 		} else {
 			content += MenuItemStyle.Render("  "+label) + "\n"
-			content += "  " + InputStyle.Render(input.View()) + "\n\n"
+			content += "  " + InputStyle.Render(input.View()) + "\n"
 		}
+		content += "  " + MutedStyle.Render(hints[i]) + "\n\n"
 	}
 
-	help := HelpStyle.Render("+: Calculate • Tab: Next field • Enter: Save • Esc: Cancel")
+	help := HelpStyle.Render("Tab: Next field • Enter: Save • Esc: Cancel")
 
 	return BoxStyle.Render(title + "\n" + content + help)
 }
 
-func (m *Model) updateAddSavingsTargetView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+func (m *Model) updateSettingsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Tab in the category field completes the category before moving on
+	if msg.String() == "tab" && m.focusIndex == 5 && hasCompletion(&m.inputs[5]) {
+		var cmd tea.Cmd
+		m.inputs[5], cmd = m.inputs[5].Update(msg)
+		return m, cmd
+	}
+	if m.updateFormFocus(msg.String()) {
+		return m, nil
+	}
+
 	switch msg.String() {
-	case "tab", "down":
-		m.inputs[m.focusIndex].Blur()
-		m.focusIndex = (m.focusIndex + 1) % len(m.inputs)
-		m.inputs[m.focusIndex].Focus()
-	case "shift+tab", "up":
-		m.inputs[m.focusIndex].Blur()
-		m.focusIndex--
-		if m.focusIndex < 0 {
-			m.focusIndex = len(m.inputs) - 1
-		}
-		m.inputs[m.focusIndex].Focus()
 	case "enter":
-		productName := m.inputs[0].Value()
-		if productName == "" {
-			m.message = "Product name is required"
-			m.messageType = "error"
-			return m, nil
+		updated := *m.config
+		updated.Currency = strings.TrimSpace(m.inputs[0].Value())
+		updated.DataFile = strings.TrimSpace(m.inputs[1].Value())
+		updated.ObsidianVaultPath = strings.TrimSpace(m.inputs[2].Value())
+		updated.MonthlyBudget = 0
+		if value := strings.TrimSpace(m.inputs[3].Value()); value != "" {
+			budget, err := parseAmount(value)
+			if err != nil || budget < 0 {
+				m.message = "Invalid monthly budget"
+				m.messageType = "error"
+				return m, nil
+			}
+			updated.MonthlyBudget = budget
 		}
-
-		targetAmount, err := strconv.ParseFloat(m.inputs[1].Value(), 64)
-		if err != nil {
-			m.message = "Invalid target amount"
-			m.messageType = "error"
-			return m, nil
+		updated.MonthlySavingsBudget = 0
+		if value := strings.TrimSpace(m.inputs[4].Value()); value != "" {
+			budget, err := parseAmount(value)
+			if err != nil || budget < 0 {
+				m.message = "Invalid monthly savings budget"
+				m.messageType = "error"
+				return m, nil
+			}
+			updated.MonthlySavingsBudget = budget
 		}
+		updated.DefaultCategory = models.ExpenseCategory(strings.ToLower(strings.TrimSpace(m.inputs[5].Value())))
 
-		targetDate, err := time.Parse("2006-01-02", m.inputs[2].Value())
-		if err != nil {
-			m.message = "Invalid date format (use YYYY-MM-DD)"
+		if err := updated.Validate(); err != nil {
+			m.message = "Invalid settings: " + err.Error()
 			m.messageType = "error"
 			return m, nil
 		}
 
-		description := m.inputs[3].Value()
-
-		_, err = m.storage.AddSavingsTarget(productName, targetAmount, targetDate, description)
-		if err != nil {
-			m.message = "Error saving: " + err.Error()
-			m.messageType = "error"
+		if updated.DataFile != m.config.DataFile {
+			m.confirmDataFileSwitch(updated)
 			return m, nil
 		}
-
-		m.message = "Savings goal created!"
-		m.messageType = "success"
-		m.currentView = ViewSavings
-		m.inputs = nil
-		m.cursor = 0
+		m.applySettings(updated)
 		return m, nil
-	case "+":
-		if m.focusIndex == 1 && len(m.inputs) > 0 {
-			currentValue := m.inputs[1].Value()
-			calculatedValue, success := tryCalculateAmount(currentValue)
-			if success {
-				m.inputs[1].SetValue(calculatedValue)
-				m.message = "Calculated: " + calculatedValue
-				m.messageType = "info"
-			}
-		}
 	case "esc":
-		m.currentView = ViewSavings
+		m.popView()
 		m.inputs = nil
 		m.cursor = 0
 		return m, nil
 	}
 
-	if len(m.inputs) > 0 && m.focusIndex < len(m.inputs) {
-		var cmd tea.Cmd
-		m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
+	if len(m.inputs) > 0 && m.focusIndex < len(m.inputs) {
+		var cmd tea.Cmd
+		m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// confirmDataFileSwitch asks before saving settings that point at another data
+// file, since the data shown is then replaced by that file's
+func (m *Model) confirmDataFileSwitch(updated config.Config) {
+	if m.storage.Ephemeral() {
+		m.message = "The data file cannot be changed while changes are not being saved (--no-save)"
+		m.messageType = "error"
+		return
+	}
+
+	body := fmt.Sprintf("\n  Open the data file at %s?\n", updated.DataFile)
+	if _, err := os.Stat(updated.DataFile); err == nil {
+		body += "  Its data replaces what is shown now."
+	} else {
+		body += "  It does not exist yet, so debtq starts empty."
+	}
+	body += fmt.Sprintf("\n  The current data stays in %s.\n\n", m.config.DataFile)
+
+	m.askConfirm(confirmation{
+		title: "Switch Data File",
+		body:  body,
+		yes:   "Switch",
+		run: func(m *Model) {
+			m.applySettings(updated)
+		},
+	})
+}
 
-		// Auto-calculate in amount field (index 1: Target Amount)
-		if m.focusIndex == 1 {
-			m.autoCalculateIfNeeded(1)
+// applySettings saves updated as the config, reloading the data when the data
+// file changed. Nothing changes if either step fails.
+func (m *Model) applySettings(updated config.Config) {
+	// Config is shared with storage and the Obsidian writer, so update it in place
+	previous := *m.config
+	*m.config = updated
+	switched := updated.DataFile != previous.DataFile
+	if switched {
+		if err := m.storage.Reload(); err != nil {
+			*m.config = previous
+			m.message = "Could not open data file: " + err.Error()
+			m.messageType = "error"
+			return
 		}
-		return m, cmd
 	}
-	return m, nil
+
+	if err := updated.Save(); err != nil {
+		*m.config = previous
+		if switched {
+			// Go back to the data file that was open before
+			m.storage.Reload()
+		}
+		m.message = "Error saving settings: " + err.Error()
+		m.messageType = "error"
+		return
+	}
+	m.storage.RefreshExchangeRates()
+
+	m.message = "Settings saved!"
+	m.messageType = "success"
+	if notice := m.storage.Notice(); switched && notice != "" {
+		m.message = notice
+		m.messageType = "warning"
+	}
+	m.popView()
+	m.inputs = nil
+	m.cursor = 0
+	m.offset = 0
 }
 
-func (m *Model) initContributionInputs() {
-	m.inputs = make([]textinput.Model, 2)
+// Helper functions
 
-	m.inputs[0] = textinput.New()
-	m.inputs[0].Placeholder = "Amount"
-	m.inputs[0].Focus()
+// pushView navigates forward to v, remembering the current view for popView
+func (m *Model) pushView(v View) {
+	if v == m.currentView {
+		return
+	}
+	m.viewStack = append(m.viewStack, m.currentView)
+	m.currentView = v
+}
 
-	m.inputs[1] = textinput.New()
-	m.inputs[1].Placeholder = "Notes (optional)"
+// popView returns to the view the current one was opened from, ending at ViewMain
+func (m *Model) popView() {
+	if len(m.viewStack) == 0 {
+		m.currentView = ViewMain
+		return
+	}
+	m.currentView = m.viewStack[len(m.viewStack)-1]
+	m.viewStack = m.viewStack[:len(m.viewStack)-1]
+}
 
-	m.focusIndex = 0
+// popToView unwinds the stack back to v, or to ViewMain if v is not on it
+func (m *Model) popToView(v View) {
+	for m.currentView != v && m.currentView != ViewMain {
+		m.popView()
+	}
 }
 
-func (m Model) viewAddContribution() string {
-	title := TitleStyle.Render("  Add Contribution")
+// resetView drops the navigation history and returns to the main menu
+func (m *Model) resetView() {
+	m.viewStack = nil
+	m.currentView = ViewMain
+	m.inputs = nil
+}
 
-	var content string
-	labels := []string{"Amount:", "Notes:"}
+// listPageSize returns how many list rows fit on screen alongside a view's chrome
+func (m Model) listPageSize() int {
+	size := m.height - 14
+	if size < 5 {
+		size = 5
+	}
+	return size
+}
 
-	for i, input := range m.inputs {
-		label := labels[i]
-		if i == m.focusIndex {
-			content += SelectedMenuItemStyle.Render("▸ "+label) + "\n"
-			content += "  " + FocusedInputStyle.Render(input.View()) + "\n\n"
-		} else {
-			content += MenuItemStyle.Render("  "+label) + "\n"
-			content += "  " + InputStyle.Render(input.View()) + "\n\n"
-		}
+// jumpCursor applies the vim-style list jumps: g/G to the first/last row and
+// ctrl+d/ctrl+u half a page down/up, clamped to [0, maxCursor]
+func (m Model) jumpCursor(key string, cursor, maxCursor int) int {
+	half := max(m.listPageSize()/2, 1)
+	switch key {
+	case "g":
+		cursor = 0
+	case "G":
+		cursor = maxCursor
+	case "ctrl+d":
+		cursor += half
+	case "ctrl+u":
+		cursor -= half
 	}
+	return min(max(cursor, 0), maxCursor)
+}
 
-	help := HelpStyle.Render("+: Calculate • Tab: Next field • Enter: Save • Esc: Cancel")
+// visibleWindow returns the [start, end) rows of a scrolling list of total rows,
+// starting from offset but shifted just enough to keep cursor on screen
+func visibleWindow(offset, cursor, pageSize, total int) (int, int) {
+	if cursor < offset {
+		offset = cursor
+	}
+	if cursor >= offset+pageSize {
+		offset = cursor - pageSize + 1
+	}
+	if offset > total-pageSize {
+		offset = total - pageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	end := offset + pageSize
+	if end > total {
+		end = total
+	}
+	return offset, end
+}
 
-	return BoxStyle.Render(title + "\n" + content + help)
+// settlementKinds maps each debt transaction ID to its models.Settlement* kind
+func (m Model) settlementKinds() map[string]string {
+	settlements := m.storage.GetAllSettlements()
+	kinds := make(map[string]string)
+	for _, tx := range m.storage.GetDebtTransactions() {
+		kinds[tx.ID] = tx.SettlementKind(settlements)
+	}
+	return kinds
 }
 
-func (m *Model) updateAddContributionView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "tab", "down":
-		m.inputs[m.focusIndex].Blur()
-		m.focusIndex = (m.focusIndex + 1) % len(m.inputs)
-		m.inputs[m.focusIndex].Focus()
-	case "shift+tab", "up":
-		m.inputs[m.focusIndex].Blur()
-		m.focusIndex--
-		if m.focusIndex < 0 {
-			m.focusIndex = len(m.inputs) - 1
-		}
-		m.inputs[m.focusIndex].Focus()
-	case "enter":
-		amount, err := strconv.ParseFloat(m.inputs[0].Value(), 64)
-		if err != nil {
-			m.message = "Invalid amount"
-			m.messageType = "error"
-			return m, nil
-		}
+// settlementBadge renders a badge for how a payment's transaction was settled
+func settlementBadge(kind string) string {
+	switch kind {
+	case models.SettlementFull:
+		return RenderBadge("FULL", "success")
+	case models.SettlementPartial:
+		return RenderBadge("PARTIAL", "warning")
+	default:
+		return ""
+	}
+}
 
-		notes := m.inputs[1].Value()
+// dueSoonWindow is how far ahead the main menu reminder counts debts as due this week
+const dueSoonWindow = 7 * 24 * time.Hour
 
-		_, err = m.storage.AddSavingsContribution(m.selectedID, amount, notes)
-		if err != nil {
-			m.message = "Error saving: " + err.Error()
-			m.messageType = "error"
-			return m, nil
-		}
+// dueDateLabel returns a "  due YYYY-MM-DD" suffix for transactions with a due date,
+// plus an overdue badge once the date has passed
+func dueDateLabel(tx models.DebtTransaction) string {
+	if tx.DueDate == nil {
+		return ""
+	}
+	label := "  " + MutedStyle.Render("due "+tx.DueDate.Format("2006-01-02"))
+	if tx.IsOverdue(time.Now()) {
+		label += " " + RenderBadge("OVERDUE", "danger")
+	}
+	return label
+}
 
-		m.message = "Contribution added!"
-		m.messageType = "success"
-		m.currentView = ViewSavings
-		m.inputs = nil
-		m.selectedID = ""
-		m.cursor = 0
-		return m, nil
-	case "+":
-		if m.focusIndex == 0 && len(m.inputs) > 0 {
-			currentValue := m.inputs[0].Value()
-			calculatedValue, success := tryCalculateAmount(currentValue)
-			if success {
-				m.inputs[0].SetValue(calculatedValue)
-				m.message = "Calculated: " + calculatedValue
-				m.messageType = "info"
-			}
+// formatGain renders a gain on an invested amount according to the session's gain display mode
+func (m Model) formatGain(gain, invested float64, currency string) string {
+	pct := float64(0)
+	if invested > 0 {
+		pct = (gain / invested) * 100
+	}
+	percent := fmt.Sprintf("%+.1f%%", pct)
+	if gain < 0 {
+		percent = AmountNegativeStyle.Render(percent)
+	} else {
+		percent = AmountPositiveStyle.Render(percent)
+	}
+
+	switch m.gainDisplay {
+	case gainAbsolute:
+		return m.formatAmount(gain, currency)
+	case gainPercent:
+		return percent
+	default:
+		return fmt.Sprintf("%s (%s)", m.formatAmount(gain, currency), percent)
+	}
+}
+
+// parseAmount parses an amount field, tolerating a trailing "/-" or unit text
+// ("500 rupees", "500 rs.", "500/-"). Arithmetic such as "100+50" is worked out
+// by the calculator, whose error is returned if it cannot be. Anything
+// ambiguous, such as "5 0 0" or text followed by more digits, is still an error.
+func parseAmount(input string) (float64, error) {
+	value := strings.TrimSpace(input)
+	value = strings.TrimSpace(strings.TrimSuffix(value, "/-"))
+	// Accept amounts typed with digit grouping, e.g. 1,23,456
+	value = strings.ReplaceAll(value, ",", "")
+
+	if last := strings.LastIndexFunc(value, unicode.IsDigit); last >= 0 && last < len(value)-1 {
+		tail := value[last+1:]
+		if strings.IndexFunc(tail, unicode.IsLetter) >= 0 && strings.TrimFunc(tail, func(r rune) bool {
+			return unicode.IsLetter(r) || unicode.IsSpace(r) || r == '.'
+		}) == "" {
+			value = strings.TrimSpace(value[:last+1])
 		}
 	}
 
-	if len(m.inputs) > 0 && m.focusIndex < len(m.inputs) {
-		var cmd tea.Cmd
-		m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
+	// A leading "-" is just a sign; any other operator makes it an expression
+	if strings.ContainsAny(value, "+*/()=") || strings.LastIndex(value, "-") > 0 {
+		return evaluateMathExpression(strings.TrimRight(value, "="))
+	}
+	return strconv.ParseFloat(value, 64)
+}
 
-		// Auto-calculate in amount field (index 0)
-		if m.focusIndex == 0 {
-			m.autoCalculateIfNeeded(0)
+// parseMoney parses an amount optionally tagged with a currency code, e.g.
+// "20 USD" or "USD 20". Only the base currency and currencies with an exchange
+// rate are recognized; the returned code is empty for the base currency.
+func (m Model) parseMoney(input string) (float64, string, error) {
+	fields := strings.Fields(input)
+	if len(fields) >= 2 {
+		for _, i := range []int{0, len(fields) - 1} {
+			code, err := m.config.ResolveCurrency(fields[i])
+			if err != nil {
+				// Only something written like a currency code, e.g. XYZ, is
+				// refused; other words may be unit text such as "rupees"
+				if len(fields[i]) == 3 && strings.IndexFunc(fields[i], func(r rune) bool { return r < 'A' || r > 'Z' }) < 0 {
+					return 0, "", err
+				}
+				continue
+			}
+			rest := append(append([]string(nil), fields[:i]...), fields[i+1:]...)
+			amount, err := parseAmount(strings.Join(rest, " "))
+			return amount, code, err
 		}
-		return m, cmd
 	}
-	return m, nil
+	amount, err := parseAmount(input)
+	return amount, "", err
 }
 
-// Stats view
-func (m Model) viewStats() string {
-	title := TitleStyle.Render("  Stats & Dashboard")
+// formatAmount formats amount with color based on positive/negative
+func (m Model) formatAmount(amount float64, currency string) string {
+	if amount >= 0 {
+		return AmountPositiveStyle.Render(m.formatAmountPlain(amount, currency))
+	}
+	return AmountNegativeStyle.Render(m.formatAmountPlain(amount, currency))
+}
 
-	data := m.storage.GetData()
-	now := time.Now()
+// formatAmountPlain formats amount without styling, grouping digits in the
+// configured number format. Every amount shown in the TUI goes through here.
+func (m Model) formatAmountPlain(amount float64, currency string) string {
+	return currency + " " + m.config.FormatNumber(amount, currency)
+}
 
-	// Net Worth
-	netWorth := data.NetWorth()
+// currencyOf returns the currency to display an entry's amounts in
+func (m Model) currencyOf(code string) string {
+	if code == "" {
+		return m.config.Currency
+	}
+	return code
+}
 
-	// Debts
-	totalBorrowed := data.TotalBorrowed()
-	totalLent := data.TotalLent()
+// parseInvestmentAmount parses a total amount, or "@price" meaning price per
+// unit multiplied by units, either optionally tagged with a currency code
+func (m Model) parseInvestmentAmount(input string, units float64) (float64, string, error) {
+	value := strings.TrimSpace(input)
+	if !strings.HasPrefix(value, "@") {
+		return m.parseMoney(value)
+	}
+	if units <= 0 {
+		return 0, "", fmt.Errorf("enter units to use a per-unit price")
+	}
+	price, currency, err := m.parseMoney(strings.TrimPrefix(value, "@"))
+	if err != nil {
+		return 0, "", err
+	}
+	return price * units, currency, nil
+}
 
-	// Expenses
-	monthlyExpenses := data.MonthlyExpenses(now.Year(), now.Month())
-	var totalExpenses float64
-	for _, e := range data.Expenses {
-		totalExpenses += e.Amount
+// parseInterest parses an optional interest spec like "12", "12%",
+// "8 compound" or "12 30d" into an annual rate, type and grace period in days
+// (the "30d" part, interest-free days before interest starts); empty means
+// interest-free
+func parseInterest(input string) (float64, models.InterestType, int, error) {
+	fields := strings.Fields(strings.ToLower(input))
+	if len(fields) == 0 {
+		return 0, models.InterestNone, 0, nil
 	}
 
-	// Savings
-	var activeSavings, completedSavings int
-	var totalSavingsTarget, totalSaved float64
-	for _, t := range data.SavingsTargets {
-		if t.IsCompleted {
-			completedSavings++
-		} else {
-			activeSavings++
+	graceDays := 0
+	if last := fields[len(fields)-1]; len(fields) > 1 && strings.HasSuffix(last, "d") && (unicode.IsDigit(rune(last[0])) || last[0] == '-') {
+		days, err := strconv.Atoi(strings.TrimSuffix(last, "d"))
+		if err != nil || days < 0 {
+			return 0, "", 0, fmt.Errorf("invalid grace period %q, e.g. 30d", last)
 		}
-		totalSavingsTarget += t.TargetAmount
-		totalSaved += t.CurrentAmount
+		graceDays, fields = days, fields[:len(fields)-1]
+	}
+	if len(fields) > 2 {
+		return 0, "", 0, fmt.Errorf("use a rate, optionally simple/compound and a grace period, e.g. 12 compound 30d")
 	}
 
-	content := fmt.Sprintf(`
-  %s
-  ──────────────────────────
-  Total Net Worth:     %s
-
-  %s
-  ──────────────────────────
-  Total Borrowed:      %s
-  Total Lent:          %s
-  Net Position:        %s
-
-  %s
-  ──────────────────────────
-  This Month:          %s
-  All Time:            %s
+	rate, err := strconv.ParseFloat(strings.TrimSuffix(fields[0], "%"), 64)
+	if err != nil || rate < 0 {
+		return 0, "", 0, fmt.Errorf("invalid interest rate %q", fields[0])
+	}
 
-  %s
-  ──────────────────────────
-  Active Goals:        %d
-  Completed Goals:     %d
-  Total Target:        %s
-  Total Saved:         %s
-  Progress:            %s
-`,
-		SelectedMenuItemStyle.Render("NET WORTH"),
-		FormatAmountPlain(netWorth, m.config.Currency),
-		SelectedMenuItemStyle.Render("DEBTS"),
-		FormatAmountPlain(totalBorrowed, m.config.Currency),
-		FormatAmountPlain(totalLent, m.config.Currency),
-		FormatAmount(totalLent-totalBorrowed, m.config.Currency),
-		SelectedMenuItemStyle.Render("EXPENSES"),
-		FormatAmountPlain(monthlyExpenses, m.config.Currency),
-		FormatAmountPlain(totalExpenses, m.config.Currency),
-		SelectedMenuItemStyle.Render("SAVINGS GOALS"),
-		activeSavings,
-		completedSavings,
-		FormatAmountPlain(totalSavingsTarget, m.config.Currency),
-		FormatAmountPlain(totalSaved, m.config.Currency),
-		ProgressBar(totalSaved, totalSavingsTarget, 20),
-	)
+	interestType := models.InterestSimple
+	if len(fields) == 2 {
+		interestType = models.InterestType(fields[1])
+		if interestType != models.InterestSimple && interestType != models.InterestCompound {
+			return 0, "", 0, fmt.Errorf("interest type must be 'simple' or 'compound'")
+		}
+	}
+	if rate == 0 {
+		interestType, graceDays = models.InterestNone, 0
+	}
+	return rate, interestType, graceDays, nil
+}
 
-	help := HelpStyle.Render("\n  Esc: Back to main menu")
+// parseCategory validates a typed expense category; empty means "other".
+// Unknown categories are rejected, suggesting the closest known one.
+func parseCategory(input string) (models.ExpenseCategory, error) {
+	category := models.ExpenseCategory(strings.ToLower(strings.TrimSpace(input)))
+	if category == "" {
+		return models.CategoryOther, nil
+	}
+	if models.IsValidCategory(category) {
+		return category, nil
+	}
 
-	return BoxStyle.Render(title + content + help)
+	best, bestDist := models.ExpenseCategory(""), 3
+	for _, known := range models.ExpenseCategories {
+		if d := editDistance(string(category), string(known)); d < bestDist {
+			best, bestDist = known, d
+		}
+	}
+	if best != "" {
+		return "", fmt.Errorf("unknown category %q - did you mean %q?", input, best)
+	}
+	return "", fmt.Errorf("unknown category %q", input)
 }
 
-func (m *Model) updateStatsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	// Stats view is read-only, just handle navigation
-	return m, nil
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
 }
 
-// Helper functions
 func truncate(s string, max int) string {
 	if len(s) <= max {
 		return s
@@ -2088,92 +5536,143 @@ func (m *Model) setMessage(msg, msgType string) {
 	m.messageType = msgType
 }
 
+// evaluateMathExpression evaluates an arithmetic expression with + - * / and
+// parentheses, honouring operator precedence and left-to-right associativity
 func evaluateMathExpression(expr string) (float64, error) {
-	expr = strings.TrimSpace(expr)
-	if expr == "" {
+	tokens, err := tokenizeExpression(expr)
+	if err != nil {
+		return 0, err
+	}
+	if len(tokens) == 0 {
 		return 0, fmt.Errorf("empty expression")
 	}
 
-	parts := strings.Split(expr, "+")
-	if len(parts) > 1 {
-		var result float64
-		for _, part := range parts {
-			part = strings.TrimSpace(part)
-			if part == "" {
-				continue
-			}
-			val, err := strconv.ParseFloat(part, 64)
-			if err != nil {
-				return 0, err
+	p := &exprParser{tokens: tokens}
+	result, err := p.parseExpr()
+	if err != nil {
+		return 0, err
+	}
+	if p.pos < len(p.tokens) {
+		if p.tokens[p.pos] == ")" {
+			return 0, fmt.Errorf("unbalanced parentheses: unexpected ')'")
+		}
+		return 0, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	return result, nil
+}
+
+// tokenizeExpression splits an expression into number and operator tokens
+func tokenizeExpression(expr string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ':
+			i++
+		case strings.IndexByte("+-*/()", c) >= 0:
+			tokens = append(tokens, string(c))
+			i++
+		case (c >= '0' && c <= '9') || c == '.':
+			j := i
+			for j < len(expr) && ((expr[j] >= '0' && expr[j] <= '9') || expr[j] == '.') {
+				j++
 			}
-			result += val
+			tokens = append(tokens, expr[i:j])
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q", c)
 		}
-		return result, nil
 	}
+	return tokens, nil
+}
+
+// exprParser is a recursive-descent parser over expression tokens:
+//
+//	expr   = term { ("+" | "-") term }
+//	term   = factor { ("*" | "/") factor }
+//	factor = "-" factor | "(" expr ")" | number
+type exprParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *exprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *exprParser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
 
-	parts = strings.Split(expr, "-")
-	if len(parts) > 1 {
-		val0, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+func (p *exprParser) parseExpr() (float64, error) {
+	result, err := p.parseTerm()
+	if err != nil {
+		return 0, err
+	}
+	for p.peek() == "+" || p.peek() == "-" {
+		op := p.next()
+		val, err := p.parseTerm()
 		if err != nil {
 			return 0, err
 		}
-		result := val0
-		for _, part := range parts[1:] {
-			part = strings.TrimSpace(part)
-			if part == "" {
-				continue
-			}
-			val, err := strconv.ParseFloat(part, 64)
-			if err != nil {
-				return 0, err
-			}
+		if op == "+" {
+			result += val
+		} else {
 			result -= val
 		}
-		return result, nil
 	}
+	return result, nil
+}
 
-	parts = strings.Split(expr, "*")
-	if len(parts) > 1 {
-		result := 1.0
-		for _, part := range parts {
-			part = strings.TrimSpace(part)
-			if part == "" {
-				continue
-			}
-			val, err := strconv.ParseFloat(part, 64)
-			if err != nil {
-				return 0, err
-			}
-			result *= val
-		}
-		return result, nil
+func (p *exprParser) parseTerm() (float64, error) {
+	result, err := p.parseFactor()
+	if err != nil {
+		return 0, err
 	}
-
-	parts = strings.Split(expr, "/")
-	if len(parts) > 1 {
-		val0, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	for p.peek() == "*" || p.peek() == "/" {
+		op := p.next()
+		val, err := p.parseFactor()
 		if err != nil {
 			return 0, err
 		}
-		result := val0
-		for _, part := range parts[1:] {
-			part = strings.TrimSpace(part)
-			if part == "" {
-				continue
-			}
-			val, err := strconv.ParseFloat(part, 64)
-			if err != nil {
-				return 0, err
-			}
+		if op == "*" {
+			result *= val
+		} else {
 			if val == 0 {
 				return 0, fmt.Errorf("division by zero")
 			}
 			result /= val
 		}
-		return result, nil
 	}
+	return result, nil
+}
 
-	return strconv.ParseFloat(expr, 64)
+func (p *exprParser) parseFactor() (float64, error) {
+	tok := p.next()
+	switch tok {
+	case "":
+		return 0, fmt.Errorf("unexpected end of expression")
+	case "-":
+		val, err := p.parseFactor()
+		return -val, err
+	case "(":
+		val, err := p.parseExpr()
+		if err != nil {
+			return 0, err
+		}
+		if p.next() != ")" {
+			return 0, fmt.Errorf("unbalanced parentheses: missing ')'")
+		}
+		return val, nil
+	case ")":
+		return 0, fmt.Errorf("unbalanced parentheses: unexpected ')'")
+	}
+	return strconv.ParseFloat(tok, 64)
 }
 
 func tryCalculateAmount(value string) (string, bool) {
@@ -2196,8 +5695,13 @@ func tryCalculateAmount(value string) (string, bool) {
 			return value, false
 		}
 
+		// Collapsing "10+5" before a trailing "*" or "/" would break precedence
+		if (trailingOp == '*' || trailingOp == '/') && hasTopLevelAddSub(testValue) {
+			return value, false
+		}
+
 		// Check if the remaining expression has operators to evaluate
-		if strings.ContainsAny(testValue, "+-*/") {
+		if strings.ContainsAny(testValue, "+-*/()") {
 			// Evaluate what comes before the trailing operator
 			result, err := evaluateMathExpression(testValue)
 			if err == nil {
@@ -2209,12 +5713,12 @@ func tryCalculateAmount(value string) (string, bool) {
 	}
 
 	// No trailing operator - evaluate directly
-	if !strings.ContainsAny(cleanValue, "+-*/") {
+	if !strings.ContainsAny(cleanValue, "+-*/()") {
 		return value, false
 	}
 
 	for _, char := range cleanValue {
-		if !unicode.IsDigit(char) && char != '.' && char != ' ' && char != '+' && char != '-' && char != '*' && char != '/' {
+		if !unicode.IsDigit(char) && !strings.ContainsRune(". +-*/()", char) {
 			return value, false
 		}
 	}
@@ -2227,6 +5731,59 @@ func tryCalculateAmount(value string) (string, bool) {
 	return value, false
 }
 
+// updateFormFocus handles field navigation shared by all forms: Tab/Shift+Tab
+// (and arrows) cycle through fields, alt+1..alt+9 jump straight to field N.
+// Returns true if the key was consumed.
+func (m *Model) updateFormFocus(key string) bool {
+	if len(m.inputs) == 0 {
+		return false
+	}
+
+	switch key {
+	case "tab", "down":
+		m.focusField((m.focusIndex + 1) % len(m.inputs))
+		return true
+	case "shift+tab", "up":
+		m.focusField((m.focusIndex - 1 + len(m.inputs)) % len(m.inputs))
+		return true
+	}
+
+	// Only alt-modified digits jump, so typing digits into amount fields is unaffected
+	if len(key) == 5 && strings.HasPrefix(key, "alt+") && key[4] >= '1' && key[4] <= '9' {
+		if target := int(key[4] - '1'); target < len(m.inputs) {
+			m.focusField(target)
+		}
+		return true
+	}
+
+	return false
+}
+
+// focusField blurs the current input and focuses the input at index i
+func (m *Model) focusField(i int) {
+	m.inputs[m.focusIndex].Blur()
+	m.focusIndex = i
+	m.inputs[m.focusIndex].Focus()
+}
+
+// hasTopLevelAddSub reports whether expr has a binary + or - outside parentheses
+func hasTopLevelAddSub(expr string) bool {
+	depth := 0
+	for i, c := range expr {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '+', '-':
+			if depth == 0 && i > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (m *Model) autoCalculateIfNeeded(inputIndex int) bool {
 	if len(m.inputs) == 0 || inputIndex >= len(m.inputs) {
 		return false
@@ -2245,5 +5802,12 @@ func (m *Model) autoCalculateIfNeeded(inputIndex int) bool {
 		return true
 	}
 
+	// "=" asks for a result, so say why there isn't one, e.g. a missing ")"
+	if expr := strings.TrimSpace(val); strings.HasSuffix(expr, "=") {
+		if _, err := evaluateMathExpression(strings.TrimRight(expr, "=")); err != nil {
+			m.message = "Can't calculate: " + err.Error()
+			m.messageType = "error"
+		}
+	}
 	return false
 }
@@ -0,0 +1,1260 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/debtq/debtq/internal/config"
+	"github.com/debtq/debtq/internal/models"
+	"github.com/debtq/debtq/internal/storage"
+)
+
+// newTestModel returns a Model over empty in-memory storage, with HOME in a
+// temp dir so nothing touches the real config
+func newTestModel(t *testing.T) *Model {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := config.DefaultConfig()
+	digest := false
+	cfg.ShowStartupDigest = &digest
+	st, err := storage.NewWithPersister(cfg, storage.NewMemoryPersister(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(cfg, st)
+}
+
+// keyMsg builds the key message bubbletea sends for key, named as in KeyMsg.String
+func keyMsg(key string) tea.KeyMsg {
+	named := map[string]tea.KeyType{
+		"enter": tea.KeyEnter, "esc": tea.KeyEsc, "tab": tea.KeyTab, "shift+tab": tea.KeyShiftTab,
+		"up": tea.KeyUp, "down": tea.KeyDown, "backspace": tea.KeyBackspace,
+		"ctrl+k": tea.KeyCtrlK, "ctrl+d": tea.KeyCtrlD, "ctrl+u": tea.KeyCtrlU,
+	}
+	alt := strings.HasPrefix(key, "alt+")
+	key = strings.TrimPrefix(key, "alt+")
+	if t, ok := named[key]; ok {
+		return tea.KeyMsg{Type: t, Alt: alt}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key), Alt: alt}
+}
+
+// press sends keys to m one at a time and returns the resulting model
+func press(t *testing.T, m *Model, keys ...string) *Model {
+	t.Helper()
+	for _, key := range keys {
+		next, _ := m.Update(keyMsg(key))
+		switch next := next.(type) {
+		case *Model:
+			m = next
+		case Model:
+			m = &next
+		default:
+			t.Fatalf("Update returned %T", next)
+		}
+	}
+	return m
+}
+
+// typeText types text into the focused input, one key per rune
+func typeText(t *testing.T, m *Model, text string) *Model {
+	t.Helper()
+	for _, r := range text {
+		m = press(t, m, string(r))
+	}
+	return m
+}
+
+func TestQuickFullSettleNeedsSecondPress(t *testing.T) {
+	m := newTestModel(t)
+	tx, err := m.storage.AddDebtTransaction(models.Lent, "Asha", 500, "dinner", time.Now(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.selectedPerson = tx.PersonName
+	m.pushView(ViewSelectTransaction)
+
+	m = press(t, m, "f")
+	if got := m.storage.GetUnsettledDebtsForPerson("Asha"); len(got) != 1 {
+		t.Fatalf("first f settled already: %d unsettled left", len(got))
+	}
+	if m.pendingSettle != tx.ID {
+		t.Fatalf("pendingSettle = %q, want %q", m.pendingSettle, tx.ID)
+	}
+
+	m = press(t, m, "f")
+	if got := m.storage.GetUnsettledDebtsForPerson("Asha"); len(got) != 0 {
+		t.Fatalf("second f left %d unsettled", len(got))
+	}
+	settlements := m.storage.GetSettlementsForPerson("Asha")
+	if len(settlements) != 1 || settlements[0].Amount != 500 || settlements[0].Note != "" {
+		t.Fatalf("settlements = %+v, want one of 500 without a note", settlements)
+	}
+	if m.messageType != "success" || m.currentView != ViewSelectTransaction {
+		t.Fatalf("message %q (%s) in view %d", m.message, m.messageType, m.currentView)
+	}
+}
+
+func TestQuickFullSettleCancelledByOtherKey(t *testing.T) {
+	m := newTestModel(t)
+	if _, err := m.storage.AddDebtTransaction(models.Borrowed, "Ravi", 120, "", time.Now(), nil); err != nil {
+		t.Fatal(err)
+	}
+	m.selectedPerson = "RAVI"
+	m.pushView(ViewSelectTransaction)
+
+	m = press(t, m, "f", "down", "f")
+	if got := m.storage.GetUnsettledDebtsForPerson("Ravi"); len(got) != 1 {
+		t.Fatalf("settled after an interrupted f: %d unsettled left", len(got))
+	}
+}
+
+func TestExclusionToggle(t *testing.T) {
+	m := newTestModel(t)
+	m.config.ExcludedCategories = []models.ExpenseCategory{models.CategoryUtilities}
+	now := time.Now()
+	m.storage.AddExpense(100, "lunch", models.CategoryFood, now, "", nil, "")
+	m.storage.AddExpense(900, "power", models.CategoryUtilities, now, "", nil, "")
+	m.pushView(ViewExpenses)
+
+	monthly := func() float64 {
+		return m.storage.GetData().MonthlyExpensesExcluding(now.Year(), now.Month(), m.excludedCategories())
+	}
+	if got := monthly(); got != 100 {
+		t.Fatalf("with exclusions = %.2f, want 100", got)
+	}
+	m = press(t, m, "x")
+	if got := monthly(); got != 1000 {
+		t.Fatalf("after x = %.2f, want 1000", got)
+	}
+	m = press(t, m, "x")
+	if got := monthly(); got != 100 {
+		t.Fatalf("after second x = %.2f, want 100", got)
+	}
+}
+
+func TestAltDigitJumpsToField(t *testing.T) {
+	m := newTestModel(t)
+	m.pushView(ViewAddInvestment)
+	m.initInvestmentInputs()
+
+	m = press(t, m, "alt+3")
+	if m.focusIndex != 2 || !m.inputs[2].Focused() || m.inputs[0].Focused() {
+		t.Fatalf("after alt+3 focusIndex = %d", m.focusIndex)
+	}
+	m = press(t, m, "alt+1")
+	if m.focusIndex != 0 || !m.inputs[0].Focused() || m.inputs[2].Focused() {
+		t.Fatalf("after alt+1 focusIndex = %d", m.focusIndex)
+	}
+
+	// Beyond the last field nothing moves
+	m = press(t, m, "alt+9")
+	if m.focusIndex != 0 {
+		t.Fatalf("after alt+9 focusIndex = %d, want 0", m.focusIndex)
+	}
+
+	// Plain digits are typed, not jumps
+	m = press(t, m, "alt+3", "4", "2")
+	if m.focusIndex != 2 || m.inputs[2].Value() != "42" {
+		t.Fatalf("typing digits: focusIndex = %d, value %q", m.focusIndex, m.inputs[2].Value())
+	}
+}
+
+func TestSettingsDataFileSwitchReloads(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	open := func(path string) (*config.Config, *storage.Storage) {
+		cfg := config.DefaultConfig()
+		cfg.DataFile = filepath.Join(dir, path)
+		cfg.ObsidianVaultPath = filepath.Join(dir, "vault")
+		st, err := storage.New(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cfg, st
+	}
+	_, other := open("other.json")
+	other.AddExpense(40, "from other", models.CategoryFood, time.Now(), "", nil, "")
+
+	cfg, st := open("data.json")
+	st.AddExpense(100, "from data", models.CategoryFood, time.Now(), "", nil, "")
+	m := New(cfg, st)
+	m.pushView(ViewSettings)
+	m.initSettingsInputs()
+	m.inputs[1].SetValue(filepath.Join(dir, "other.json"))
+
+	m = press(t, m, "enter")
+	if m.currentView != ViewConfirm {
+		t.Fatalf("switching the data file did not ask first (view %d)", m.currentView)
+	}
+	m = press(t, m, "enter")
+	if m.messageType != "success" {
+		t.Fatalf("switch failed: %s", m.message)
+	}
+	expenses := m.storage.GetExpenses()
+	if len(expenses) != 1 || expenses[0].Description != "from other" {
+		t.Fatalf("after switching, expenses = %+v, want the other file's", expenses)
+	}
+
+	// Saving now adds to the other file instead of overwriting it
+	m.storage.AddExpense(5, "after switch", models.CategoryFood, time.Now(), "", nil, "")
+	_, reopened := open("other.json")
+	if got := len(reopened.GetExpenses()); got != 2 {
+		t.Errorf("other.json has %d expenses, want 2", got)
+	}
+	_, original := open("data.json")
+	if got := original.GetExpenses(); len(got) != 1 || got[0].Description != "from data" {
+		t.Errorf("data.json changed: %+v", got)
+	}
+	saved, err := config.Load()
+	if err != nil || saved.DataFile != filepath.Join(dir, "other.json") {
+		t.Errorf("saved config data file = %q (%v)", saved.DataFile, err)
+	}
+}
+
+func TestSettingsDataFileSwitchRefusedInMemory(t *testing.T) {
+	m := newTestModel(t)
+	m.storage.AddExpense(100, "kept", models.CategoryFood, time.Now(), "", nil, "")
+	previous := m.config.DataFile
+	m.pushView(ViewSettings)
+	m.initSettingsInputs()
+	m.inputs[1].SetValue(filepath.Join(t.TempDir(), "new.json"))
+
+	m = press(t, m, "enter")
+	// In-memory storage has no file to switch away from
+	if m.currentView != ViewSettings || m.messageType != "error" {
+		t.Fatalf("view %d, message %q", m.currentView, m.message)
+	}
+	if m.config.DataFile != previous || len(m.storage.GetExpenses()) != 1 {
+		t.Fatalf("data file changed to %q", m.config.DataFile)
+	}
+}
+
+func TestEvaluateMathExpression(t *testing.T) {
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"2+3*4", 14},
+		{"100-20-5", 75},
+		{"10/4+1", 3.5},
+		{"10+5*2", 20},
+		{"8/2/2", 2},
+		{"-5+10", 5},
+		{" 1 + 2 ", 3},
+	}
+	for _, tt := range tests {
+		got, err := evaluateMathExpression(tt.expr)
+		if err != nil || got != tt.want {
+			t.Errorf("evaluateMathExpression(%q) = %v, %v; want %v", tt.expr, got, err, tt.want)
+		}
+	}
+
+	for _, expr := range []string{"10/0", "5+3/0", "", "2+", "2**3"} {
+		if got, err := evaluateMathExpression(expr); err == nil {
+			t.Errorf("evaluateMathExpression(%q) = %v, want an error", expr, got)
+		}
+	}
+}
+
+func TestTryCalculateAmount(t *testing.T) {
+	tests := []struct {
+		value, want string
+		ok          bool
+	}{
+		{"2+3*4", "14", true},
+		{"100-20-5=", "75", true},
+		{"10+5+", "15+", true},
+		// Collapsing 10+5 before a * would change the result
+		{"10+5*", "10+5*", false},
+		{"10*5*", "50*", true},
+		{"250", "250", false},
+		{"10/0", "10/0", false},
+		{"12abc+3", "12abc+3", false},
+	}
+	for _, tt := range tests {
+		got, ok := tryCalculateAmount(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("tryCalculateAmount(%q) = %q, %v; want %q, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCalculatorParentheses(t *testing.T) {
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"(100+50)*2", 300},
+		{"1200/(3+1)", 300},
+		{"((1+2)*(3+4))-1", 20},
+		{"2*(3+(4-1)*2)", 18},
+		{"-(5+5)", -10},
+	}
+	for _, tt := range tests {
+		got, err := evaluateMathExpression(tt.expr)
+		if err != nil || got != tt.want {
+			t.Errorf("evaluateMathExpression(%q) = %v, %v; want %v", tt.expr, got, err, tt.want)
+		}
+	}
+
+	for _, expr := range []string{"(1+2", "1+2)", "((1+2)*3", "()", ")("} {
+		_, err := evaluateMathExpression(expr)
+		if err == nil {
+			t.Errorf("evaluateMathExpression(%q) succeeded, want an error", expr)
+		}
+	}
+	if _, err := evaluateMathExpression("(1+2"); err == nil || !strings.Contains(err.Error(), "unbalanced") {
+		t.Errorf("missing ')' error = %v, want it to mention unbalanced parentheses", err)
+	}
+
+	if got, ok := tryCalculateAmount("(100+50)*2"); !ok || got != "300" {
+		t.Errorf("tryCalculateAmount = %q, %v; want 300", got, ok)
+	}
+	if got, ok := tryCalculateAmount("(100+50"); ok || got != "(100+50" {
+		t.Errorf("tryCalculateAmount on a mismatched bracket = %q, %v; want it unchanged", got, ok)
+	}
+}
+
+func TestCalculatorReportsUnbalancedParentheses(t *testing.T) {
+	m := newTestModel(t)
+	m.pushView(ViewAddExpense)
+	m.initExpenseInputs()
+
+	m = typeText(t, m, "(100+50=")
+	if m.messageType != "error" || !strings.Contains(m.message, "unbalanced parentheses") {
+		t.Fatalf("message %q (%s), want an unbalanced parentheses error", m.message, m.messageType)
+	}
+	if got := m.inputs[0].Value(); got != "(100+50=" {
+		t.Fatalf("amount field = %q, want it left as typed", got)
+	}
+
+	m = press(t, m, "backspace")
+	m = typeText(t, m, ")*2")
+	if got := m.inputs[0].Value(); got != "300" || m.messageType != "calc" {
+		t.Fatalf("amount field = %q (%s %q), want 300", got, m.messageType, m.message)
+	}
+}
+
+func TestEnterReportsUnbalancedParentheses(t *testing.T) {
+	m := newTestModel(t)
+	m.pushView(ViewAddExpense)
+	m.initExpenseInputs()
+
+	m = typeText(t, m, "(100+50")
+	m.inputs[1].SetValue("Dinner")
+	m = press(t, m, "enter")
+	if m.messageType != "error" || !strings.Contains(m.message, "unbalanced parentheses: missing ')'") || strings.Contains(m.message, "ParseFloat") {
+		t.Fatalf("message %q (%s), want the calculator's unbalanced parentheses error", m.message, m.messageType)
+	}
+	if m.currentView != ViewAddExpense || len(m.storage.GetExpenses()) != 0 {
+		t.Errorf("view %v with %d expenses, want the form kept and nothing saved", m.currentView, len(m.storage.GetExpenses()))
+	}
+
+	// A balanced expression is worked out on submit
+	m.inputs[0].SetValue("(100+50)*2")
+	m = press(t, m, "enter")
+	if expenses := m.storage.GetExpenses(); len(expenses) != 1 || expenses[0].Amount != 300 {
+		t.Errorf("after fixing the bracket: %s %q, expenses %+v", m.messageType, m.message, expenses)
+	}
+}
+
+func TestParseInterest(t *testing.T) {
+	tests := []struct {
+		input string
+		rate  float64
+		kind  models.InterestType
+		grace int
+	}{
+		{"", 0, models.InterestNone, 0},
+		{"12", 12, models.InterestSimple, 0},
+		{"12%", 12, models.InterestSimple, 0},
+		{"8 compound", 8, models.InterestCompound, 0},
+		{"12 30d", 12, models.InterestSimple, 30},
+		{"8 compound 45d", 8, models.InterestCompound, 45},
+		{"0 30d", 0, models.InterestNone, 0},
+	}
+	for _, tt := range tests {
+		rate, kind, grace, err := parseInterest(tt.input)
+		if err != nil || rate != tt.rate || kind != tt.kind || grace != tt.grace {
+			t.Errorf("parseInterest(%q) = %v, %q, %d, %v; want %v, %q, %d", tt.input, rate, kind, grace, err, tt.rate, tt.kind, tt.grace)
+		}
+	}
+	for _, input := range []string{"abc", "12 yearly", "12 -5d", "12 xd", "12 compound 30d extra"} {
+		if _, _, _, err := parseInterest(input); err == nil {
+			t.Errorf("parseInterest(%q) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestStatsSectionsOpenTheirViews(t *testing.T) {
+	tests := []struct {
+		downs int
+		want  View
+	}{
+		{0, ViewNetWorth},
+		{1, ViewDebts},
+		{2, ViewExpenses},
+		{3, ViewSavings},
+		// Down stops at the last section
+		{6, ViewSavings},
+	}
+	for _, tt := range tests {
+		m := newTestModel(t)
+		m.pushView(ViewStats)
+		for i := 0; i < tt.downs; i++ {
+			m = press(t, m, "down")
+		}
+		m = press(t, m, "enter")
+		if m.currentView != tt.want {
+			t.Errorf("%d downs opened view %d, want %d", tt.downs, m.currentView, tt.want)
+		}
+		if m = press(t, m, "esc"); m.currentView != ViewStats {
+			t.Errorf("esc from view %d returned to %d, want Stats", tt.want, m.currentView)
+		}
+	}
+
+	m := newTestModel(t)
+	m.pushView(ViewStats)
+	m = press(t, m, "down", "down", "up", "enter")
+	if m.currentView != ViewDebts {
+		t.Errorf("down, down, up opened view %d, want Debts", m.currentView)
+	}
+}
+
+func TestRecurringPauseAndEndDateKeys(t *testing.T) {
+	m := newTestModel(t)
+	r, err := m.storage.AddRecurringExpense(499, "Streaming", models.CategoryEntertainment, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.pushView(ViewRecurring)
+
+	recurring := func() models.RecurringExpense {
+		for _, got := range m.storage.GetRecurringExpenses() {
+			if got.ID == r.ID {
+				return got
+			}
+		}
+		t.Fatal("recurring expense not found")
+		return models.RecurringExpense{}
+	}
+
+	if m = press(t, m, "p"); recurring().Active {
+		t.Fatal("p did not pause")
+	}
+	if m = press(t, m, "p"); !recurring().Active {
+		t.Fatal("second p did not resume")
+	}
+
+	m = press(t, m, "e")
+	if m.currentView != ViewRecurringEndDate {
+		t.Fatalf("e opened view %d", m.currentView)
+	}
+	m = typeText(t, m, "2026-06-30")
+	m = press(t, m, "enter")
+	if end := recurring().EndDate; end == nil || end.Format("2006-01-02") != "2026-06-30" {
+		t.Fatalf("end date = %v, want 2026-06-30", end)
+	}
+	if m.currentView != ViewRecurring {
+		t.Fatalf("saving returned to view %d", m.currentView)
+	}
+
+	// The form starts with the current end date; clearing it removes it
+	m = press(t, m, "e")
+	if got := m.inputs[0].Value(); got != "2026-06-30" {
+		t.Fatalf("end date field = %q", got)
+	}
+	for range "2026-06-30" {
+		m = press(t, m, "backspace")
+	}
+	m = press(t, m, "enter")
+	if end := recurring().EndDate; end != nil {
+		t.Fatalf("end date = %v after clearing", end)
+	}
+
+	m = press(t, m, "e")
+	m = typeText(t, m, "30/06/2026")
+	m = press(t, m, "enter")
+	if m.messageType != "error" || m.currentView != ViewRecurringEndDate {
+		t.Fatalf("bad date: message %q in view %d", m.message, m.currentView)
+	}
+}
+
+func TestInvestmentDetailShowsNotes(t *testing.T) {
+	m := newTestModel(t)
+	purchased := time.Date(2025, 4, 10, 0, 0, 0, 0, time.Local)
+	if _, err := m.storage.AddInvestment(models.InvestmentMutualFunds, "Index Fund", 10000, 11500, 0, purchased, "monthly SIP via bank", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	m.pushView(ViewNetWorth)
+	m = press(t, m, "enter")
+	if m.currentView != ViewInvestmentDetail {
+		t.Fatalf("view = %v, want ViewInvestmentDetail", m.currentView)
+	}
+	view := m.viewInvestmentDetail()
+	for _, want := range []string{"Index Fund", "monthly SIP via bank", "2025-04-10"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("detail view is missing %q", want)
+		}
+	}
+
+	m = press(t, m, "n", "ctrl+u")
+	m = typeText(t, m, "paused SIP")
+	m = press(t, m, "enter")
+	if m.currentView != ViewInvestmentDetail || !strings.Contains(m.viewInvestmentDetail(), "paused SIP") {
+		t.Errorf("edited notes not shown (view %v): %s", m.currentView, m.message)
+	}
+	if inv := m.selectedInvestment(); inv == nil || inv.Notes != "paused SIP" {
+		t.Errorf("stored notes = %+v", inv)
+	}
+}
+
+func TestGainDisplayCycles(t *testing.T) {
+	m := newTestModel(t)
+	if _, err := m.storage.AddInvestment(models.InvestmentStocks, "Acme", 1000, 1250, 0, time.Now(), "", ""); err != nil {
+		t.Fatal(err)
+	}
+	amount := m.formatAmountPlain(250, m.config.Currency)
+
+	m.pushView(ViewNetWorth)
+	tests := []struct {
+		mode                gainDisplay
+		wantAmount, wantPct bool
+	}{
+		{gainAbsolute, true, false},
+		{gainPercent, false, true},
+		{gainBoth, true, true},
+	}
+	for _, tt := range tests {
+		m = press(t, m, "%")
+		if m.gainDisplay != tt.mode {
+			t.Fatalf("gainDisplay = %v, want %v", m.gainDisplay, tt.mode)
+		}
+		gain := m.formatGain(250, 1000, m.config.Currency)
+		if strings.Contains(gain, amount) != tt.wantAmount || strings.Contains(gain, "+25.0%") != tt.wantPct {
+			t.Errorf("mode %v: formatGain = %q", tt.mode, gain)
+		}
+	}
+
+	// The choice lasts for the session, not just the visit to Net Worth
+	m = press(t, m, "%", "esc")
+	m.pushView(ViewNetWorth)
+	if m.gainDisplay != gainAbsolute {
+		t.Errorf("gainDisplay after leaving Net Worth = %v, want gainAbsolute", m.gainDisplay)
+	}
+	if view := m.viewNetWorth(); strings.Contains(view, "+25.0%") {
+		t.Errorf("absolute mode still shows a percentage:\n%s", view)
+	}
+}
+
+func TestStartupDigestListsAttentionItems(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := config.DefaultConfig()
+	cfg.MonthlyBudget = 1000
+	st, err := storage.NewWithPersister(cfg, storage.NewMemoryPersister(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	due := now.AddDate(0, 0, -5)
+	if _, err := st.AddDebtTransaction(models.Lent, "Asha", 700, "rent", now.AddDate(0, 0, -20), &due); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.AddDebtTransaction(models.Borrowed, "Ravi", 300, "trip", now.AddDate(0, 0, -60), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.AddSavingsTarget("Bike", 5000, now.AddDate(0, 0, -3), ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.AddExpense(1200, "Groceries", models.CategoryFood, now, "", nil, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	m := New(cfg, st)
+	if m.currentView != ViewDigest {
+		t.Fatalf("startup view = %v, want ViewDigest", m.currentView)
+	}
+	items := m.startupDigest()
+	wants := []string{"ASHA", "overdue since " + due.Format("2006-01-02"), "Goal", "Bike", "Monthly budget exceeded", "1 older debt(s) without a due date"}
+	digest := strings.Join(items, "\n")
+	if len(items) != 4 {
+		t.Errorf("digest has %d items, want 4:\n%s", len(items), digest)
+	}
+	for _, want := range wants {
+		if !strings.Contains(digest, want) {
+			t.Errorf("digest is missing %q:\n%s", want, digest)
+		}
+	}
+
+	m = press(t, m, "x")
+	if m.currentView != ViewMain {
+		t.Errorf("after a key: view = %v, want ViewMain", m.currentView)
+	}
+
+	// Turned off, or with nothing to report, startup goes straight to the menu
+	off := false
+	cfg.ShowStartupDigest = &off
+	if m := New(cfg, st); m.currentView != ViewMain {
+		t.Errorf("digest disabled: view = %v, want ViewMain", m.currentView)
+	}
+	cfg.ShowStartupDigest = nil
+	empty, err := storage.NewWithPersister(cfg, storage.NewMemoryPersister(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := New(cfg, empty); m.currentView != ViewMain {
+		t.Errorf("nothing to report: view = %v, want ViewMain", m.currentView)
+	}
+}
+
+func TestSnapshotKeyRecordsToday(t *testing.T) {
+	m := newTestModel(t)
+	inv, err := m.storage.AddInvestment(models.InvestmentStocks, "Acme", 1000, 1200, 0, time.Now(), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Start from yesterday's snapshot only
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	m.storage.GetData().NetWorthSnapshots = []models.NetWorthSnapshot{{Date: today.AddDate(0, 0, -1), Value: 1000}}
+
+	m.pushView(ViewNetWorth)
+	m = press(t, m, "s")
+	snapshots := m.storage.GetNetWorthSnapshots()
+	if len(snapshots) != 2 || !snapshots[1].Date.Equal(today) || snapshots[1].Value != 1200 {
+		t.Fatalf("snapshots = %+v, want yesterday and today at 1200", snapshots)
+	}
+	if m.messageType != "success" || !strings.Contains(m.message, "+200.00 since "+today.AddDate(0, 0, -1).Format("2006-01-02")) {
+		t.Errorf("message = %q", m.message)
+	}
+
+	// A second capture the same day updates today's snapshot instead of adding one
+	if err := m.storage.UpdateInvestmentValue(inv.ID, 1500); err != nil {
+		t.Fatal(err)
+	}
+	m = press(t, m, "s")
+	snapshots = m.storage.GetNetWorthSnapshots()
+	if len(snapshots) != 2 || snapshots[1].Value != 1500 {
+		t.Errorf("snapshots = %+v, want today updated to 1500", snapshots)
+	}
+	if !strings.Contains(m.message, "+500.00") {
+		t.Errorf("message = %q, want the change since yesterday", m.message)
+	}
+}
+
+func TestParseCategory(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    models.ExpenseCategory
+		wantErr string
+	}{
+		{"food", models.CategoryFood, ""},
+		{"  Transport ", models.CategoryTransport, ""},
+		{"", models.CategoryOther, ""},
+		{"   ", models.CategoryOther, ""},
+		{"foodd", "", `did you mean "food"`},
+		{"helth", "", `did you mean "health"`},
+		{"groceries", "", `unknown category "groceries"`},
+	}
+	for _, tt := range tests {
+		got, err := parseCategory(tt.input)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseCategory(%q) error = %v, want %q", tt.input, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("parseCategory(%q) = %q, %v; want %q", tt.input, got, err, tt.want)
+		}
+	}
+
+	// Nothing known is close enough to suggest
+	if _, err := parseCategory("groceries"); err == nil || strings.Contains(err.Error(), "did you mean") {
+		t.Errorf("parseCategory(groceries) = %v, want no suggestion", err)
+	}
+}
+
+func TestAddExpenseRejectsUnknownCategory(t *testing.T) {
+	m := newTestModel(t)
+	m.pushView(ViewExpenses)
+	m = press(t, m, "a")
+	m.inputs[0].SetValue("250")
+	m.inputs[1].SetValue("Lunch")
+	m.inputs[2].SetValue("foodd")
+	m = press(t, m, "enter")
+	if m.messageType != "error" || !strings.Contains(m.message, `did you mean "food"`) || len(m.storage.GetExpenses()) != 0 {
+		t.Fatalf("typo: %s %q, %d expenses saved", m.messageType, m.message, len(m.storage.GetExpenses()))
+	}
+
+	m.inputs[2].SetValue("")
+	m = press(t, m, "enter")
+	expenses := m.storage.GetExpenses()
+	if len(expenses) != 1 || expenses[0].Category != models.CategoryOther {
+		t.Errorf("empty category saved %+v, want one expense in other", expenses)
+	}
+}
+
+func TestParseAmountTrailingText(t *testing.T) {
+	valid := map[string]float64{
+		"500":         500,
+		" 42.5 ":      42.5,
+		"500 rupees":  500,
+		"500rupees":   500,
+		"500 rs.":     500,
+		"500/-":       500,
+		"500 /-":      500,
+		"1,23,456/-":  123456,
+		"99.99 bucks": 99.99,
+		"500 / 2":     250,
+		"(100+50)*2=": 300,
+	}
+	for input, want := range valid {
+		if got, err := parseAmount(input); err != nil || got != want {
+			t.Errorf("parseAmount(%q) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+
+	for _, input := range []string{"", "rupees", "5 0 0", "500 rs 2", "rupees 500", "500-", "(100+50"} {
+		if got, err := parseAmount(input); err == nil {
+			t.Errorf("parseAmount(%q) = %v, want an error", input, got)
+		}
+	}
+}
+
+func TestParseMoneyCurrency(t *testing.T) {
+	m := newTestModel(t)
+	m.config.ExchangeRates = map[string]float64{"USD": 80}
+
+	for input, want := range map[string]string{"20": "", "20 USD": "USD", "usd 20": "USD", "20 " + m.config.Currency: "", "500 rupees": ""} {
+		if _, code, err := m.parseMoney(input); err != nil || code != want {
+			t.Errorf("parseMoney(%q) = %q, %v; want currency %q", input, code, err, want)
+		}
+	}
+	if _, _, err := m.parseMoney("20 XYZ"); err == nil || !strings.Contains(err.Error(), "no exchange rate configured for XYZ") {
+		t.Errorf("parseMoney(20 XYZ): %v, want a missing rate error", err)
+	}
+}
+
+func TestExpensesInlineFilter(t *testing.T) {
+	m := newTestModel(t)
+	for _, d := range []string{"Lunch", "Taxi home", "Taxi to airport", "Books"} {
+		if _, err := m.storage.AddExpense(100, d, models.CategoryOther, time.Now(), "", nil, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m.pushView(ViewExpenses)
+	m = press(t, m, "/")
+	m = typeText(t, m, "TAXI")
+	if got := len(m.listedExpenses()); got != 2 {
+		t.Fatalf("filtered to %d expenses, want 2", got)
+	}
+
+	// Enter keeps the filter; the cursor stays within the filtered rows
+	m = press(t, m, "enter", "down", "down", "down")
+	if m.expenseFilter != "TAXI" || len(m.inputs) != 0 {
+		t.Fatalf("after enter: filter %q, %d inputs", m.expenseFilter, len(m.inputs))
+	}
+	if m.cursor != 1 {
+		t.Errorf("cursor = %d, want 1 (the last of 2 matches)", m.cursor)
+	}
+	if view := m.viewExpenses(); !strings.Contains(view, `Filter: "TAXI"`) || strings.Contains(view, "Lunch") {
+		t.Errorf("filtered view:\n%s", view)
+	}
+
+	// Esc clears the filter and stays in Expenses
+	m = press(t, m, "esc")
+	if m.expenseFilter != "" || m.currentView != ViewExpenses || len(m.listedExpenses()) != 4 {
+		t.Errorf("after esc: filter %q, view %v, %d listed", m.expenseFilter, m.currentView, len(m.listedExpenses()))
+	}
+}
+
+func TestPersonHistoryShowsSettlementBadges(t *testing.T) {
+	m := newTestModel(t)
+	full, _ := m.storage.AddDebtTransaction(models.Lent, "Asha", 100, "lunch", time.Now(), nil)
+	part, _ := m.storage.AddDebtTransaction(models.Lent, "Asha", 300, "rent", time.Now(), nil)
+	if err := m.storage.SettleTransactionWithNote(full.ID, 0, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.storage.SettleTransactionWithNote(part.ID, 100, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	m.selectedPerson = "ASHA"
+	m.pushView(ViewPersonHistory)
+	view := m.viewPersonHistory()
+	if strings.Count(view, "FULL") != 1 || strings.Count(view, "PARTIAL") != 1 {
+		t.Errorf("want one FULL and one PARTIAL badge:\n%s", view)
+	}
+}
+
+func TestExpenseDateRange(t *testing.T) {
+	m := newTestModel(t)
+	for i, day := range []int{5, 10, 15, 20} {
+		date := time.Date(2026, 3, day, 0, 0, 0, 0, time.UTC)
+		if _, err := m.storage.AddExpense(float64(100*(i+1)), fmt.Sprintf("day %d", day), models.CategoryOther, date, "", nil, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Entered backwards; the range is swapped and includes both ends
+	m.pushView(ViewExpenses)
+	m = press(t, m, "t")
+	m.inputs[0].SetValue("2026-03-15")
+	m.inputs[1].SetValue("2026-03-10")
+	m = press(t, m, "enter")
+	if m.currentView != ViewExpenses || len(m.listedExpenses()) != 2 {
+		t.Fatalf("view %v, %d listed; want Expenses with 2", m.currentView, len(m.listedExpenses()))
+	}
+	view := m.viewExpenses()
+	if !strings.Contains(view, "Expenses (2026-03-10 → 2026-03-15)") {
+		t.Errorf("title does not show the range:\n%s", view)
+	}
+	if !strings.Contains(view, "In Range:   "+m.formatAmountPlain(500, m.config.Currency)) {
+		t.Errorf("total is not scoped to the range:\n%s", view)
+	}
+
+	// Clearing both dates shows everything again
+	m = press(t, m, "t", "ctrl+u", "tab", "ctrl+u", "enter")
+	if !m.expenseFrom.IsZero() || len(m.listedExpenses()) != 4 {
+		t.Errorf("after clearing: from %v, %d listed", m.expenseFrom, len(m.listedExpenses()))
+	}
+}
+
+func TestBudgetLineOnMenuAndExpenses(t *testing.T) {
+	m := newTestModel(t)
+	if m.budgetLine() != "" {
+		t.Error("budget line shown with no budget set")
+	}
+
+	m.config.MonthlyBudget = 1000
+	if _, err := m.storage.AddExpense(1200, "Rent share", models.CategoryOther, time.Now(), "", nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.storage.AddExpense(900, "Last month", models.CategoryOther, time.Date(time.Now().Year(), time.Now().Month(), 0, 12, 0, 0, 0, time.Local), "", nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	spent, budget := m.storage.CurrentMonthSpend()
+	if spent != 1200 || budget != 1000 {
+		t.Fatalf("CurrentMonthSpend = %v, %v; want 1200 of 1000", spent, budget)
+	}
+	for name, view := range map[string]string{"main menu": m.viewMain(), "expenses": m.viewExpenses()} {
+		if !strings.Contains(view, "OVER BUDGET") || !strings.Contains(view, "120%") {
+			t.Errorf("%s does not show the exceeded budget:\n%s", name, view)
+		}
+	}
+}
+
+func TestRenamePersonConfirmsMerge(t *testing.T) {
+	m := newTestModel(t)
+	m.storage.AddDebtTransaction(models.Lent, "Raj", 400, "rent", time.Now(), nil)
+	m.storage.AddDebtTransaction(models.Lent, "Raj", 100, "lunch", time.Now(), nil)
+	m.storage.AddDebtTransaction(models.Borrowed, "Raj Kumar", 150, "cab", time.Now(), nil)
+
+	m.pushView(ViewDebts)
+	m = press(t, m, "r", "ctrl+u")
+	m = typeText(t, m, "raj kumar")
+	m = press(t, m, "enter")
+	if m.currentView != ViewConfirm || m.confirm == nil || m.confirm.title != "Confirm Merge" {
+		t.Fatalf("view %v, confirm %+v; want the merge confirmation", m.currentView, m.confirm)
+	}
+	if !strings.Contains(m.confirm.body, "2 transaction(s) will be merged into RAJ KUMAR") {
+		t.Errorf("confirmation body = %q", m.confirm.body)
+	}
+
+	m = press(t, m, "enter")
+	if m.currentView != ViewDebts || m.messageType != "success" {
+		t.Fatalf("after confirming: view %v, %s %q", m.currentView, m.messageType, m.message)
+	}
+	if persons := m.visiblePersons(); len(persons) != 1 || persons[0] != "RAJ KUMAR" {
+		t.Errorf("people after merge = %v, want [RAJ KUMAR]", persons)
+	}
+}
+
+func TestBalancesTableAsksBeforeOverwriting(t *testing.T) {
+	m := newTestModel(t)
+	m.storage.AddDebtTransaction(models.Lent, "Asha", 500, "rent", time.Now(), nil)
+	path := filepath.Join(t.TempDir(), "balances.txt")
+
+	m.pushView(ViewDebts)
+	m = press(t, m, "t", "w")
+	m.inputs[0].SetValue(path)
+	m = press(t, m, "enter")
+	if data, err := os.ReadFile(path); err != nil || !strings.Contains(string(data), "ASHA") {
+		t.Fatalf("new file = %q, %v", data, err)
+	}
+
+	// An existing file is only replaced after a yes
+	if err := os.WriteFile(path, []byte("keep me"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m = press(t, m, "w")
+	m.inputs[0].SetValue(path)
+	m = press(t, m, "enter")
+	if m.currentView != ViewConfirm {
+		t.Fatalf("view = %v, want ViewConfirm", m.currentView)
+	}
+	m = press(t, m, "esc")
+	if data, _ := os.ReadFile(path); string(data) != "keep me" {
+		t.Errorf("cancelled write replaced the file with %q", data)
+	}
+	if m.currentView != ViewBalancesTable || len(m.inputs) != 1 {
+		t.Fatalf("after cancelling: view %v, %d inputs; want the path input back", m.currentView, len(m.inputs))
+	}
+	m = press(t, m, "enter", "enter")
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "ASHA") || m.messageType != "success" {
+		t.Errorf("confirmed write: %q, %s %q", data, m.messageType, m.message)
+	}
+}
+
+func TestRepeatLastExpenseKey(t *testing.T) {
+	m := newTestModel(t)
+	m.pushView(ViewExpenses)
+	m = press(t, m, ".")
+	if m.messageType != "error" || !strings.Contains(m.message, "no expenses to repeat") {
+		t.Errorf("empty: %s %q", m.messageType, m.message)
+	}
+
+	if _, err := m.storage.AddExpense(120, "Coffee", models.CategoryFood, time.Now().AddDate(0, 0, -1), "", nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	// Just added, so it may also be flagged as a possible duplicate
+	m = press(t, m, ".")
+	if !strings.HasPrefix(m.message, "Added Coffee") || len(m.storage.GetExpenses()) != 2 {
+		t.Errorf("repeat: %s %q, %d expenses", m.messageType, m.message, len(m.storage.GetExpenses()))
+	}
+}
+
+func TestSettlementHistoryListsPartPaymentsPerDebt(t *testing.T) {
+	m := newTestModel(t)
+	tx, err := m.storage.AddDebtTransactionWithInterest(models.Lent, "Asha", 300, "Tickets", time.Now(), nil, 0, "", 0, "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, note := range []string{"first half", "second half"} {
+		if err := m.storage.SettleTransactionWithNote(tx.ID, 150, note); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(m.storage.GetDebtTransactions()); n != 1 {
+		t.Fatalf("part-payments left %d transactions, want 1", n)
+	}
+
+	m.pushView(ViewSettlementHistory)
+	view := m.View()
+	for _, want := range []string{"first half", "second half"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("payments history is missing %q", want)
+		}
+	}
+	if got := strings.Count(view, "Tickets"); got != 2 {
+		t.Errorf("debt named on %d lines, want 2", got)
+	}
+	if !strings.Contains(view, m.formatAmountPlain(150, "USD")) {
+		t.Errorf("payments are not shown in the debt's currency:\n%s", view)
+	}
+}
+
+func TestAllocationBreakdownSortedWithWarning(t *testing.T) {
+	m := newTestModel(t)
+	now := time.Now()
+	if _, err := m.storage.AddInvestment(models.InvestmentGold, "Coins", 200, 200, 0, now, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.storage.AddInvestment(models.InvestmentStocks, "Index", 800, 800, 0, now, "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	out := m.allocationBreakdown()
+	stocks, gold := strings.Index(out, "stocks"), strings.Index(out, "gold")
+	if stocks < 0 || gold < 0 || stocks > gold {
+		t.Fatalf("breakdown is not sorted by share:\n%s", out)
+	}
+	if strings.Count(out, "CONCENTRATED") != 1 {
+		t.Errorf("want one concentration warning (stocks at 80%%):\n%s", out)
+	}
+
+	// Raising the threshold above the largest share clears the warning
+	m.config.ConcentrationThresholdPct = 85
+	if out := m.allocationBreakdown(); strings.Contains(out, "CONCENTRATED") {
+		t.Errorf("warning shown under an 85%% threshold:\n%s", out)
+	}
+}
+
+func TestParseInvestmentAmountPerUnit(t *testing.T) {
+	m := newTestModel(t)
+	if got, _, err := m.parseInvestmentAmount("@25.50", 4); err != nil || got != 102 {
+		t.Errorf("@25.50 x 4 = %v, %v; want 102", got, err)
+	}
+	if got, _, err := m.parseInvestmentAmount("500", 4); err != nil || got != 500 {
+		t.Errorf("plain amount = %v, %v; want 500", got, err)
+	}
+	if _, _, err := m.parseInvestmentAmount("@25", 0); err == nil {
+		t.Error("per-unit price without units accepted")
+	}
+}
+
+func TestInvestmentDetailPerUnitPrices(t *testing.T) {
+	m := newTestModel(t)
+	m.config.ExchangeRates = map[string]float64{"USD": 80}
+	if _, err := m.storage.AddInvestment(models.InvestmentStocks, "ACME", 1000, 1250, 40, time.Now(), "", "USD"); err != nil {
+		t.Fatal(err)
+	}
+	m.pushView(ViewNetWorth)
+	m = press(t, m, "enter")
+
+	view := m.viewInvestmentDetail()
+	for _, want := range []string{m.formatAmountPlain(25, "USD"), m.formatAmountPlain(31.25, "USD")} {
+		if !strings.Contains(view, want) {
+			t.Errorf("detail view is missing per-unit price %q", want)
+		}
+	}
+
+	// Without units the per-unit figures are hidden
+	if _, err := m.storage.AddInvestment(models.InvestmentGold, "Coins", 500, 600, 0, time.Now(), "", ""); err != nil {
+		t.Fatal(err)
+	}
+	m = press(t, m, "esc", "down", "enter")
+	if view := m.viewInvestmentDetail(); !strings.Contains(view, "Coins") || strings.Contains(view, "per unit") {
+		t.Errorf("detail view without units:\n%s", view)
+	}
+}
+
+func TestWithdrawKey(t *testing.T) {
+	m := newTestModel(t)
+	goal, err := m.storage.AddSavingsTarget("iPhone", 1000, time.Now().AddDate(0, 6, 0), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := m.storage.AddSavingsContribution(goal.ID, 400, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	m.pushView(ViewSavings)
+	m = press(t, m, "w")
+	if m.currentView != ViewAddContribution || !strings.Contains(m.View(), "Withdraw from Goal") {
+		t.Fatalf("w in Savings: view = %v, want the withdraw form", m.currentView)
+	}
+	m = typeText(t, m, "500")
+	m = press(t, m, "enter")
+	if m.messageType != "error" || m.currentView != ViewAddContribution {
+		t.Errorf("overdrawing: %s %q, view %v; want an error on the form", m.messageType, m.message, m.currentView)
+	}
+
+	m.inputs[0].SetValue("150")
+	m = press(t, m, "enter")
+	if m.messageType != "success" || m.currentView != ViewSavings {
+		t.Fatalf("withdrawal: %s %q, view %v", m.messageType, m.message, m.currentView)
+	}
+	if got := m.storage.GetSavingsTargets()[0].CurrentAmount; got != 250 {
+		t.Errorf("saved %.2f after withdrawing 150 of 400, want 250", got)
+	}
+}
+
+func TestSavingsHistoryNewestFirstWithBalance(t *testing.T) {
+	m := newTestModel(t)
+	goal, err := m.storage.AddSavingsTarget("Bike", 1000, time.Now().AddDate(0, 6, 0), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		amount float64
+		note   string
+	}{{100, "first"}, {250, "second"}} {
+		if _, _, err := m.storage.AddSavingsContribution(goal.ID, c.amount, c.note); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.storage.WithdrawSavingsContribution(goal.ID, 50, "third"); err != nil {
+		t.Fatal(err)
+	}
+
+	m.pushView(ViewSavings)
+	m = press(t, m, "h")
+	if m.currentView != ViewSavingsHistory {
+		t.Fatalf("h in Savings: view = %v, want ViewSavingsHistory", m.currentView)
+	}
+	view := m.View()
+	if !strings.Contains(view, "Needed per month") {
+		t.Error("history is missing the required monthly savings")
+	}
+
+	balances := map[string]float64{"first": 100, "second": 350, "third": 300}
+	var order []string
+	for _, line := range strings.Split(view, "\n") {
+		for note, balance := range balances {
+			if strings.Contains(line, note) {
+				order = append(order, note)
+				if !strings.Contains(line, m.formatAmountPlain(balance, m.config.Currency)) {
+					t.Errorf("%s row has no running balance of %.2f: %q", note, balance, line)
+				}
+			}
+		}
+	}
+	if strings.Join(order, ",") != "third,second,first" {
+		t.Errorf("rows in order %v, want newest first", order)
+	}
+}
+
+func TestPersonHistoryPartPaymentsInDebtCurrency(t *testing.T) {
+	m := newTestModel(t)
+	m.config.ExchangeRates = map[string]float64{"USD": 80}
+	tx, err := m.storage.AddDebtTransactionWithInterest(models.Lent, "Asha", 100, "hotel", time.Now(), nil, 0, "", 0, "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.storage.SettleTransactionWithNote(tx.ID, 40, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	m.selectedPerson = "ASHA"
+	m.pushView(ViewPersonHistory)
+	view := m.viewPersonHistory()
+	if want := "↳ " + m.formatAmountPlain(40, "USD") + " paid on"; !strings.Contains(view, want) {
+		t.Errorf("person history is missing %q:\n%s", want, view)
+	}
+}
+
+func TestPersonHistoryLabelsBothBalances(t *testing.T) {
+	m := newTestModel(t)
+	lent, _ := m.storage.AddDebtTransaction(models.Lent, "Asha", 500, "rent", time.Now(), nil)
+	m.storage.AddDebtTransaction(models.Borrowed, "Asha", 100, "cab", time.Now(), nil)
+	if err := m.storage.SettleTransactionWithNote(lent.ID, 200, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	m.selectedPerson = "ASHA"
+	m.pushView(ViewPersonHistory)
+	view := m.viewPersonHistory()
+	for _, want := range []string{
+		"Outstanding: owes you " + m.formatAmountPlain(200, m.config.Currency),
+		"Lifetime net: " + m.formatAmountPlain(400, m.config.Currency),
+	} {
+		if !strings.Contains(view, want) {
+			t.Errorf("person history is missing %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestDebtsCursorSkipsZeroBalancePeople(t *testing.T) {
+	m := newTestModel(t)
+	day := time.Now()
+	m.storage.AddDebtTransaction(models.Lent, "Asha", 100, "lunch", day, nil)
+	// Bina's debts cancel out: she has open transactions but is not listed
+	m.storage.AddDebtTransaction(models.Lent, "Bina", 100, "tickets", day, nil)
+	m.storage.AddDebtTransaction(models.Borrowed, "Bina", 100, "cab", day, nil)
+	m.storage.AddDebtTransaction(models.Lent, "Chen", 300, "rent", day, nil)
+
+	m.pushView(ViewDebts)
+	m = press(t, m, "down")
+	// The second row is Chen; indexing the unfiltered list would pick Bina
+	m = press(t, m, "h")
+	if m.currentView != ViewPersonHistory || m.selectedPerson != "CHEN" {
+		t.Fatalf("h on the second row: view %v, person %q; want CHEN's history", m.currentView, m.selectedPerson)
+	}
+	// Leaving the history puts the cursor back on the first row
+	m = press(t, m, "esc", "down", "s")
+	if m.selectedPerson != "CHEN" {
+		t.Fatalf("s on the second row settles with %q, want CHEN", m.selectedPerson)
+	}
+
+	// Once Chen is settled the cursor falls back onto the last listed person
+	m = press(t, m, "esc", "down")
+	if _, err := m.storage.SettleAmountForPerson("Chen", 0, ""); err != nil {
+		t.Fatal(err)
+	}
+	m = press(t, m, "h")
+	if m.selectedPerson != "ASHA" {
+		t.Errorf("after Chen settled, h opened %q's history, want ASHA", m.selectedPerson)
+	}
+}
+
+func TestRemovingDuplicateGivesBackRoundUp(t *testing.T) {
+	m := newTestModel(t)
+	goal, err := m.storage.AddSavingsTarget("Holiday", 1000, time.Now().AddDate(0, 6, 0), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.config.RoundUpSavings = true
+	m.config.RoundUpTargetID = goal.ID
+	if _, err := m.storage.AddExpense(237, "Coffee", models.CategoryFood, time.Now(), "", nil, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	m.pushView(ViewExpenses)
+	m = press(t, m, ".")
+	if m.duplicateID == "" || m.storage.GetSavingsTargets()[0].CurrentAmount != 3 {
+		t.Fatalf("repeat: %q, saved %.2f; want a flagged duplicate and 3 stashed", m.message, m.storage.GetSavingsTargets()[0].CurrentAmount)
+	}
+	m = press(t, m, "u")
+	if !strings.Contains(m.message, "round-up of") || len(m.storage.GetExpenses()) != 1 {
+		t.Errorf("u: %s %q, %d expenses", m.messageType, m.message, len(m.storage.GetExpenses()))
+	}
+	if got := m.storage.GetSavingsTargets()[0].CurrentAmount; got != 0 {
+		t.Errorf("saved %.2f after removing the duplicate, want 0", got)
+	}
+}
+
+func TestForeignCurrencyDebtAndInvestmentViews(t *testing.T) {
+	m := newTestModel(t)
+	m.config.ExchangeRates = map[string]float64{"USD": 80}
+	settled, err := m.storage.AddDebtTransactionWithInterest(models.Lent, "Asha", 100, "hotel", time.Now(), nil, 0, "", 0, "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.storage.SettleTransactionWithNote(settled.ID, 0, ""); err != nil {
+		t.Fatal(err)
+	}
+	open, err := m.storage.AddDebtTransactionWithInterest(models.Lent, "Asha", 70, "taxi", time.Now(), nil, 0, "", 0, "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.selectedTxID = open.ID
+	if view, want := m.viewSettleDebt(), "Remaining: "+m.formatAmountPlain(70, "USD"); !strings.Contains(view, want) {
+		t.Errorf("settle view is missing %q:\n%s", want, view)
+	}
+	m.selectedPerson = "ASHA"
+	if view, want := m.viewPersonHistory(), m.formatAmountPlain(100, "USD"); !strings.Contains(view, want) {
+		t.Errorf("person history is missing the settlement %q:\n%s", want, view)
+	}
+
+	inv, err := m.storage.AddInvestment(models.InvestmentStocks, "ACME", 1000, 1250, 0, time.Now(), "", "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.storage.AddInvestmentIncome(inv.ID, 30, models.IncomeDividend, time.Now(), ""); err != nil {
+		t.Fatal(err)
+	}
+	m.selectedID = inv.ID
+	m.gainDisplay = gainAbsolute
+	detail := m.viewInvestmentDetail()
+	for _, want := range []string{
+		"Capital Gain:   " + m.formatAmount(250, "USD"),
+		"Income:         " + m.formatAmountPlain(30, "USD"),
+		"Total Return:   " + m.formatAmount(280, "USD"),
+	} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("detail view is missing %q:\n%s", want, detail)
+		}
+	}
+	income := m.viewInvestmentIncome()
+	for _, want := range []string{"Income Received: " + m.formatAmountPlain(30, "USD"), "Total Return:    " + m.formatAmount(280, "USD")} {
+		if !strings.Contains(income, want) {
+			t.Errorf("income view is missing %q:\n%s", want, income)
+		}
+	}
+	if list := m.viewNetWorth(); !strings.Contains(list, m.formatAmount(250, "USD")) {
+		t.Errorf("investment row gain lacks its currency:\n%s", list)
+	}
+}
@@ -0,0 +1,269 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/debtq/debtq/internal/models"
+)
+
+// countNoun renders n with its noun, e.g. "1 expense" or "3 expenses"
+func countNoun(n int, one, many string) string {
+	return fmt.Sprintf("%d %s", n, plural(n, one, many))
+}
+
+// archiveRollups sums the live rollups of each archive file, as several
+// archiving runs can add to one file
+func (m Model) archiveRollups() map[string]models.ArchiveRollup {
+	sums := make(map[string]models.ArchiveRollup)
+	for _, r := range m.storage.GetData().Archives {
+		sum := sums[r.File]
+		sum.Expenses += r.Expenses
+		sum.ExpenseTotal += r.ExpenseTotal
+		sum.Debts += r.Debts
+		if r.Before.After(sum.Before) {
+			sum.Before = r.Before
+		}
+		sums[r.File] = sum
+	}
+	return sums
+}
+
+func (m Model) viewArchives() string {
+	title := TitleStyle.Render("  Archives")
+
+	names, err := m.storage.ListArchives()
+	var content string
+	switch {
+	case err != nil:
+		content = ErrorStyle.Render("\n  Could not list archives: "+err.Error()) + "\n"
+	case len(names) == 0:
+		content = MutedStyle.Render("\n  Nothing archived yet. Press a to move old expenses and settled debts out of the data file.\n")
+	default:
+		content = "\n"
+		rollups := m.archiveRollups()
+		for i, name := range names {
+			cursor := "  "
+			if i == m.cursor {
+				cursor = "▸ "
+			}
+			line := cursor + TableCellStyle.Width(18).Render(name)
+			if r, ok := rollups[name]; ok {
+				line += fmt.Sprintf("  %s, %s  %s", countNoun(r.Expenses, "expense", "expenses"), countNoun(r.Debts, "settled debt", "settled debts"),
+					m.formatAmountPlain(r.ExpenseTotal, m.config.Currency))
+			}
+			content += line + "\n"
+		}
+		content += "\n  " + MutedStyle.Render("Archived expenses still count in all-time totals.") + "\n"
+	}
+
+	help := HelpStyle.Render("\n  Enter: Browse • a: Archive older records • Esc: Back")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateArchivesView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	names, _ := m.storage.ListArchives()
+	maxCursor := max(len(names)-1, 0)
+
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < maxCursor {
+			m.cursor++
+		}
+	case "enter":
+		if m.cursor >= len(names) {
+			break
+		}
+		archive, err := m.storage.LoadArchive(names[m.cursor])
+		if err != nil {
+			m.message = "Could not open archive: " + err.Error()
+			m.messageType = "error"
+			break
+		}
+		m.archive, m.archiveName, m.archiveDebts = archive, names[m.cursor], false
+		m.pushView(ViewArchive)
+		m.cursor = 0
+		m.offset = 0
+	case "a":
+		m.pushView(ViewArchiveBefore)
+		m.inputs = make([]textinput.Model, 1)
+		m.inputs[0] = textinput.New()
+		m.inputs[0].Placeholder = "YYYY-MM-DD"
+		// Default to everything before this year
+		m.inputs[0].SetValue(time.Date(time.Now().Year(), time.January, 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02"))
+		m.inputs[0].Focus()
+		m.focusIndex = 0
+	case "esc":
+		m.popView()
+		m.cursor = 0
+	}
+	return m, nil
+}
+
+func (m Model) viewArchiveBefore() string {
+	title := TitleStyle.Render("  Archive Older Records")
+
+	content := "\n" + SelectedMenuItemStyle.Render("▸ Archive everything before:") + "\n"
+	if len(m.inputs) > 0 {
+		content += "  " + FocusedInputStyle.Render(m.inputs[0].View()) + "\n"
+	}
+	content += "  " + MutedStyle.Render("Expenses dated before this day, and debts settled before it, move to an archive file.") + "\n"
+	content += "  " + MutedStyle.Render("Open debts, investments, savings goals and net worth history stay.") + "\n"
+
+	help := HelpStyle.Render("\n  Enter: Continue • Esc: Cancel")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateArchiveBeforeView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		before, err := time.Parse("2006-01-02", m.inputs[0].Value())
+		if err != nil {
+			m.message = "Invalid date format"
+			m.messageType = "error"
+			return m, nil
+		}
+		m.askConfirm(confirmation{
+			title: "Archive Records",
+			body: fmt.Sprintf("\n  Move expenses dated before %s, and debts settled before it, out of the data file?\n"+
+				"  They stay readable under Archives and still count in all-time totals.\n\n", before.Format("2 Jan 2006")),
+			yes: "Archive",
+			run: func(m *Model) {
+				paths, err := m.storage.ArchiveBefore(before)
+				if err != nil {
+					m.message = "Could not archive: " + err.Error()
+					m.messageType = "error"
+					return
+				}
+				// One rollup was added per file written
+				archives := m.storage.GetData().Archives
+				var expenses, debts int
+				names := make([]string, len(paths))
+				for i, r := range archives[len(archives)-len(paths):] {
+					expenses += r.Expenses
+					debts += r.Debts
+					names[i] = filepath.Base(paths[i])
+				}
+				m.message = fmt.Sprintf("Archived %s and %s to %s",
+					countNoun(expenses, "expense", "expenses"), countNoun(debts, "settled debt", "settled debts"), strings.Join(names, ", "))
+				m.messageType = "success"
+				m.popView()
+				m.inputs = nil
+				m.cursor = 0
+			},
+		})
+		return m, nil
+	case "esc":
+		m.popView()
+		m.inputs = nil
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.inputs[0], cmd = m.inputs[0].Update(msg)
+	return m, cmd
+}
+
+// archiveRows returns how many rows ViewArchive lists in its current mode
+func (m Model) archiveRows() int {
+	if m.archive == nil {
+		return 0
+	}
+	if m.archiveDebts {
+		return len(m.archive.DebtTransactions)
+	}
+	return len(m.archive.Expenses)
+}
+
+func (m Model) viewArchive() string {
+	title := TitleStyle.Render("  Archive " + m.archiveName + " (read-only)")
+	if m.archive == nil {
+		return BoxStyle.Render(title)
+	}
+
+	var total float64
+	for _, exp := range m.archive.Expenses {
+		total += m.archive.Rates.ToBase(exp.Amount, exp.Currency)
+	}
+	content := fmt.Sprintf("\n  %s  •  %s  •  %s\n\n",
+		countNoun(len(m.archive.Expenses), "expense", "expenses"),
+		m.formatAmountPlain(total, m.config.Currency),
+		countNoun(len(m.archive.DebtTransactions), "settled debt", "settled debts"))
+
+	rows := m.archiveRows()
+	if rows == 0 {
+		content += MutedStyle.Render("  Nothing of this kind in the archive.") + "\n"
+	} else {
+		// Newest first, like the Expenses list
+		start, end := visibleWindow(m.offset, m.cursor, m.listPageSize(), rows)
+		for row := start; row < end; row++ {
+			cursor := "  "
+			if row == m.cursor {
+				cursor = "▸ "
+			}
+			i := rows - 1 - row
+			if m.archiveDebts {
+				tx := m.archive.DebtTransactions[i]
+				txType := AmountPositiveStyle.Render("LENT    ")
+				if tx.Type == models.Borrowed {
+					txType = AmountNegativeStyle.Render("BORROWED")
+				}
+				content += fmt.Sprintf("%s%s  %s  %s  %s  %s\n", cursor, tx.Date.Format("2006-01-02"),
+					TableCellStyle.Width(12).Render(truncate(tx.PersonName, 10)), txType,
+					TableCellStyle.Width(24).Render(truncate(tx.Description, 22)),
+					m.formatAmountPlain(tx.Principal(), m.currencyOf(tx.Currency)))
+			} else {
+				exp := m.archive.Expenses[i]
+				content += fmt.Sprintf("%s%s  %s  %s  %s\n", cursor, exp.Date.Format("2006-01-02"),
+					TableCellStyle.Width(30).Render(truncate(exp.Description, 28)),
+					TableCellStyle.Width(14).Render(string(exp.Category)),
+					m.formatAmountPlain(exp.Amount, m.currencyOf(exp.Currency)))
+			}
+		}
+		content += MutedStyle.Render(fmt.Sprintf("  showing %d–%d of %d", start+1, end, rows)) + "\n"
+	}
+
+	help := HelpStyle.Render("\n  Tab: Expenses/Settled debts • ↑/↓: Scroll • Esc: Back")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateArchiveView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	maxCursor := max(m.archiveRows()-1, 0)
+
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < maxCursor {
+			m.cursor++
+		}
+	case "g", "G", "ctrl+d", "ctrl+u":
+		m.cursor = m.jumpCursor(msg.String(), m.cursor, maxCursor)
+	case "tab":
+		m.archiveDebts = !m.archiveDebts
+		m.cursor = 0
+		m.offset = 0
+	case "esc":
+		m.popView()
+		m.archive = nil
+		m.cursor = 0
+		m.offset = 0
+		return m, nil
+	}
+	m.offset, _ = visibleWindow(m.offset, m.cursor, m.listPageSize(), m.archiveRows())
+	return m, nil
+}
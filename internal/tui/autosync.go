@@ -0,0 +1,56 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// autoSyncDelay is how long the data must stay unchanged before an automatic
+// Obsidian sync, so a burst of edits is written once
+const autoSyncDelay = 2 * time.Second
+
+// autoSync tracks config.AutoSync syncing. It is shared by pointer because
+// Model is copied on every Update.
+type autoSync struct {
+	synced  uint64 // Storage revision the vault last reflected
+	pending bool   // Whether an autoSyncMsg is already on its way
+}
+
+// autoSyncMsg fires autoSyncDelay after a change seen at storage revision rev
+type autoSyncMsg struct {
+	rev uint64
+}
+
+// scheduleAutoSync starts the debounce timer when the data changed since the
+// last sync and no timer is running
+func (m *Model) scheduleAutoSync() tea.Cmd {
+	// A --no-save session should not leave its changes in the vault either
+	if !m.config.AutoSync || m.autoSync.pending || m.storage.Ephemeral() {
+		return nil
+	}
+	rev := m.storage.Revision()
+	if rev == m.autoSync.synced {
+		return nil
+	}
+	m.autoSync.pending = true
+	return tea.Tick(autoSyncDelay, func(time.Time) tea.Msg {
+		return autoSyncMsg{rev: rev}
+	})
+}
+
+// runAutoSync syncs if nothing changed since msg was scheduled; otherwise
+// scheduleAutoSync restarts the timer for the newer revision
+func (m *Model) runAutoSync(msg autoSyncMsg) {
+	m.autoSync.pending = false
+	if !m.config.AutoSync || m.storage.Revision() != msg.rev {
+		return
+	}
+
+	// Don't retry a failed sync until the next change
+	m.autoSync.synced = msg.rev
+	if err := m.obsidian.SyncAllNotes(m.storage.GetData()); err != nil {
+		m.message = "Auto-sync to Obsidian failed: " + err.Error()
+		m.messageType = "error"
+	}
+}
@@ -0,0 +1,221 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// confirmation is an action that only runs once the user says yes in ViewConfirm
+type confirmation struct {
+	title string // Shown as the box title
+	body  string // What is about to happen, already rendered
+	yes   string // Label for Enter in the help line
+	run   func(m *Model)
+
+	inputs []textinput.Model // Form inputs of the view that asked, restored on close
+}
+
+// askConfirm shows c over the current view. On Enter the confirm view is
+// closed before c.run, so run sees the view that asked, inputs and all.
+func (m *Model) askConfirm(c confirmation) {
+	c.inputs = m.inputs
+	m.confirm = &c
+	m.pushView(ViewConfirm)
+	m.inputs = nil
+}
+
+func (m Model) viewConfirm() string {
+	if m.confirm == nil {
+		return ""
+	}
+	title := TitleStyle.Render("  " + m.confirm.title)
+	help := HelpStyle.Render("\n  Enter: " + m.confirm.yes + " • Esc: Cancel")
+
+	return BoxStyle.Render(title + m.confirm.body + help)
+}
+
+func (m *Model) updateConfirmView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter", "esc":
+		c := m.confirm
+		m.confirm = nil
+		m.popView()
+		if c == nil {
+			break
+		}
+		m.inputs = c.inputs
+		if msg.String() == "enter" {
+			c.run(m)
+		}
+	}
+
+	return m, nil
+}
+
+// confirmUndoSettlement lists what the last settle action recorded and undoes
+// all of it once the user agrees
+func (m *Model) confirmUndoSettlement() {
+	batch := m.storage.LastSettlementBatch()
+	if len(batch) == 0 {
+		m.message = "Could not undo: no settlement to undo"
+		m.messageType = "error"
+		return
+	}
+
+	currencies := make(map[string]string)
+	for _, tx := range m.storage.GetDebtTransactions() {
+		currencies[tx.ID] = tx.Currency
+	}
+	body := fmt.Sprintf("\n  Undo the last settle action with %s?\n\n", SelectedMenuItemStyle.Render(batch[0].PersonName))
+	for _, st := range batch {
+		line := fmt.Sprintf("  %s  %s", m.formatAmountPlain(st.Amount, m.currencyOf(currencies[st.TransactionID])), MutedStyle.Render(st.Date.Format("2006-01-02")))
+		if st.Note != "" {
+			line += "  " + MutedStyle.Render(st.Note)
+		}
+		body += line + "\n"
+	}
+	body += "\n  The amounts are added back to what is owed.\n"
+
+	m.askConfirm(confirmation{
+		title: "Undo Settlement?",
+		body:  body,
+		yes:   "Undo",
+		run: func(m *Model) {
+			if err := m.storage.UndoLastSettlement(); err != nil {
+				m.message = "Could not undo: " + err.Error()
+				m.messageType = "error"
+				return
+			}
+			if len(batch) == 1 {
+				m.message = fmt.Sprintf("Undid %s settlement with %s", m.formatAmountPlain(batch[0].Amount, m.currencyOf(currencies[batch[0].TransactionID])), batch[0].PersonName)
+			} else {
+				m.message = fmt.Sprintf("Undid %d settlements with %s", len(batch), batch[0].PersonName)
+			}
+			m.messageType = "success"
+		},
+	})
+}
+
+// deleteKind identifies which kind of record confirmDelete deletes
+type deleteKind int
+
+const (
+	deleteExpense deleteKind = iota
+	deleteInvestment
+	deleteSavingsTarget
+	deleteDebtTransaction
+	deleteRecurringExpense
+)
+
+// confirmDelete asks before deleting a record
+func (m *Model) confirmDelete(kind deleteKind, id string) {
+	m.askConfirm(confirmation{
+		title: "Confirm Delete",
+		body:  m.describeDelete(kind, id) + "  This action cannot be undone.\n",
+		yes:   "Yes, delete",
+		run: func(m *Model) {
+			var err error
+			var what string
+			switch kind {
+			case deleteExpense:
+				what = "Expense"
+				err = m.storage.DeleteExpense(id)
+			case deleteInvestment:
+				what = "Investment"
+				err = m.storage.DeleteInvestment(id)
+			case deleteSavingsTarget:
+				what = "Goal"
+				err = m.storage.DeleteSavingsTarget(id)
+			case deleteDebtTransaction:
+				what = "Transaction"
+				err = m.storage.DeleteDebtTransaction(id)
+			case deleteRecurringExpense:
+				what = "Recurring expense"
+				err = m.storage.DeleteRecurringExpense(id)
+			}
+			if err != nil {
+				m.message = "Error deleting: " + err.Error()
+				m.messageType = "error"
+				return
+			}
+			m.message = what + " deleted"
+			m.messageType = "success"
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		},
+	})
+}
+
+// describeDelete renders the record a delete confirmation is about
+func (m Model) describeDelete(kind deleteKind, id string) string {
+	var content string
+	switch kind {
+	case deleteExpense:
+		content += "\n  Are you sure you want to delete this expense?\n\n"
+		for _, exp := range m.storage.GetExpenses() {
+			if exp.ID == id {
+				content += fmt.Sprintf("  %s\n  %s  %s\n\n",
+					SelectedMenuItemStyle.Render(exp.Description),
+					m.formatAmountPlain(exp.Amount, m.currencyOf(exp.Currency)),
+					MutedStyle.Render(exp.Date.Format("2006-01-02")),
+				)
+				break
+			}
+		}
+	case deleteInvestment:
+		content += "\n  Are you sure you want to delete this investment?\n\n"
+		for _, inv := range m.storage.GetInvestments() {
+			if inv.ID == id {
+				content += fmt.Sprintf("  %s\n  [%s]  %s\n\n",
+					SelectedMenuItemStyle.Render(inv.Name),
+					inv.Type,
+					m.formatAmountPlain(inv.CurrentValue, m.currencyOf(inv.Currency)),
+				)
+				break
+			}
+		}
+	case deleteRecurringExpense:
+		content += "\n  Are you sure you want to delete this recurring expense?\n\n"
+		for _, r := range m.storage.GetRecurringExpenses() {
+			if r.ID == id {
+				content += fmt.Sprintf("  %s\n  %s  %s\n\n",
+					SelectedMenuItemStyle.Render(r.Description),
+					m.formatAmountPlain(r.Amount, m.config.Currency),
+					MutedStyle.Render(fmt.Sprintf("every month on day %d", r.DayOfMonth)),
+				)
+				break
+			}
+		}
+		content += MutedStyle.Render("  Expenses it already created are kept.") + "\n"
+	case deleteDebtTransaction:
+		content += "\n  Are you sure you want to delete this debt transaction?\n\n"
+		for _, tx := range m.storage.GetDebtTransactions() {
+			if tx.ID == id {
+				content += fmt.Sprintf("  %s %s\n  %s  %s\n\n",
+					strings.ToUpper(string(tx.Type)),
+					SelectedMenuItemStyle.Render(tx.PersonName),
+					m.formatAmountPlain(tx.Amount, m.currencyOf(tx.Currency)),
+					MutedStyle.Render(tx.Date.Format("2006-01-02")),
+				)
+				break
+			}
+		}
+	case deleteSavingsTarget:
+		content += "\n  Are you sure you want to delete this savings goal?\n\n"
+		for _, target := range m.storage.GetSavingsTargets() {
+			if target.ID == id {
+				content += fmt.Sprintf("  %s\n  %s / %s saved\n\n",
+					SelectedMenuItemStyle.Render(target.ProductName),
+					m.formatAmountPlain(target.CurrentAmount, m.config.Currency),
+					m.formatAmountPlain(target.TargetAmount, m.config.Currency),
+				)
+				break
+			}
+		}
+	}
+	return content
+}
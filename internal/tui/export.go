@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/debtq/debtq/internal/storage"
+)
+
+// exportCSVFiles are the files ExportCSVDir writes into its directory
+var exportCSVFiles = []string{storage.ExpensesCSVFile, storage.DebtsCSVFile, storage.InvestmentsCSVFile, storage.SavingsCSVFile}
+
+// openExportCSV asks for the directory to write the CSV files into,
+// suggesting a dated folder next to the data file
+func (m *Model) openExportCSV() {
+	m.pushView(ViewExportCSV)
+	m.inputs = make([]textinput.Model, 1)
+	m.inputs[0] = textinput.New()
+	m.inputs[0].Placeholder = "Directory"
+	m.inputs[0].SetValue(filepath.Join(filepath.Dir(m.config.DataFile), "export-"+time.Now().Format("2006-01-02")))
+	m.inputs[0].Focus()
+	m.focusIndex = 0
+}
+
+func (m Model) viewExportCSV() string {
+	title := TitleStyle.Render("  Export CSV")
+
+	content := "\n"
+	if len(m.inputs) > 0 {
+		content += SelectedMenuItemStyle.Render("▸ Directory:") + "\n"
+		content += "  " + FocusedInputStyle.Render(m.inputs[0].View()) + "\n"
+		content += "  " + MutedStyle.Render("Writes "+strings.Join(exportCSVFiles, ", ")) + "\n"
+	}
+
+	help := HelpStyle.Render("\n  Enter: Export • Esc: Cancel")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateExportCSVView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		dir := strings.TrimSpace(m.inputs[0].Value())
+		if dir == "" {
+			m.message = "Enter a directory to export into"
+			m.messageType = "error"
+			return m, nil
+		}
+
+		var existing []string
+		for _, name := range exportCSVFiles {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				existing = append(existing, name)
+			}
+		}
+		if len(existing) == 0 {
+			m.exportCSV(dir)
+			return m, nil
+		}
+
+		m.askConfirm(confirmation{
+			title: "Overwrite Files?",
+			body:  fmt.Sprintf("\n  %s already has %s.\n\n", dir, strings.Join(existing, ", ")),
+			yes:   "Overwrite",
+			run: func(m *Model) {
+				m.exportCSV(dir)
+			},
+		})
+		return m, nil
+	case "esc":
+		m.popView()
+		m.inputs = nil
+		return m, nil
+	}
+
+	if len(m.inputs) > 0 {
+		var cmd tea.Cmd
+		m.inputs[0], cmd = m.inputs[0].Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// exportCSV writes the CSV files into dir and leaves the export view
+func (m *Model) exportCSV(dir string) {
+	if err := m.storage.ExportCSVDir(dir); err != nil {
+		m.message = "Error exporting: " + err.Error()
+		m.messageType = "error"
+		return
+	}
+	m.message = fmt.Sprintf("Exported %s to %s", strings.Join(exportCSVFiles, ", "), dir)
+	m.messageType = "success"
+	m.popView()
+	m.inputs = nil
+}
@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// helpLines renders the keys in keymap one line per binding
+func helpLines() []string {
+	width := 0
+	for _, section := range keymap {
+		for _, b := range section.binds {
+			width = max(width, len([]rune(b.helpKey())))
+		}
+	}
+
+	var lines []string
+	for _, section := range keymap {
+		lines = append(lines, "", SelectedMenuItemStyle.Render(section.title))
+		for _, b := range section.binds {
+			if b.key == "" {
+				continue
+			}
+			pad := strings.Repeat(" ", width-len([]rune(b.helpKey())))
+			lines = append(lines, "    "+HelpKeyStyle.Render(b.helpKey())+pad+"  "+HelpDescStyle.Render(b.desc))
+		}
+	}
+	return lines
+}
+
+// helpKey is how the help overlay shows the binding's key
+func (b binding) helpKey() string {
+	if b.label != "" {
+		return b.label
+	}
+	return b.key
+}
+
+// viewHelp renders the help overlay in place of the current view
+func (m Model) viewHelp() string {
+	title := TitleStyle.Render("  Keyboard Shortcuts")
+
+	lines := helpLines()
+	start, end := visibleWindow(m.helpOffset, m.helpOffset, m.helpPageSize(), len(lines))
+	content := strings.Join(lines[start:end], "\n") + "\n"
+	if end-start < len(lines) {
+		content += MutedStyle.Render(fmt.Sprintf("\n  showing %d–%d of %d lines", start+1, end, len(lines))) + "\n"
+	}
+
+	help := HelpStyle.Render("  ↑/↓: Scroll • ?/Esc: Close")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+// helpPageSize is how many overlay lines fit on screen
+func (m Model) helpPageSize() int {
+	return max(m.height-10, 5)
+}
+
+// updateHelp handles keys while the help overlay is open; every other key is
+// swallowed so nothing happens behind it
+func (m *Model) updateHelp(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	maxOffset := max(len(helpLines())-m.helpPageSize(), 0)
+
+	switch msg.String() {
+	case "?", "esc", "q", "ctrl+c":
+		m.showHelp = false
+		m.helpOffset = 0
+	case "up", "k":
+		if m.helpOffset > 0 {
+			m.helpOffset--
+		}
+	case "down", "j":
+		if m.helpOffset < maxOffset {
+			m.helpOffset++
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,624 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keySection groups the bindings that apply in a set of views. keymap is the
+// single registry of keys and commands: the "?" overlay shows one section per
+// entry, the command palette and main menu list its named bindings, and
+// update dispatches its keys.
+type keySection struct {
+	title string
+	views []View // Views whose keys are dispatched from this section; none for sections that only document keys
+	binds []binding
+}
+
+// binding is a key, a command, or both. Bindings with run (or runFor) are
+// dispatched from the registry, on their key in the section's views and from
+// the palette. Bindings without document keys their view handles itself:
+// list navigation, Enter and Esc, form fields and multi-key sequences.
+type binding struct {
+	key   string // As reported by tea.KeyMsg.String(); "" for commands without a key
+	label string // How the overlay shows the key when it is not just key
+	desc  string // Shown in the overlay
+	name  string // Command palette entry; "" keeps the binding out of the palette
+	run   func(m *Model) tea.Cmd
+	// runFor acts on a person: the one under the cursor in Debts, or each person
+	// with an outstanding balance in the palette, where name is a format for it
+	runFor func(m *Model, person string) tea.Cmd
+}
+
+// keymap is the registry. Main Menu commands without a key are the main menu
+// items, in order.
+var keymap = []keySection{
+	{"Everywhere", nil, []binding{
+		{key: "?", desc: "Toggle this help"},
+		{key: "ctrl+k", desc: "Command palette (outside forms)"},
+		{key: "up", label: "↑/k ↓/j", desc: "Move / scroll"},
+		{key: "esc", label: "Esc", desc: "Back one step"},
+		{key: "q", desc: "Back to main menu, quit from it"},
+		{key: "ctrl+c", desc: "Quit"},
+	}},
+	{"Main Menu", []View{ViewMain}, []binding{
+		{name: "Expenses", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewExpenses)
+			m.cursor = 0
+			return nil
+		}},
+		{name: "Borrowing & Lending", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewDebts)
+			m.cursor = 0
+			return nil
+		}},
+		{name: "My Net Worth", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewNetWorth)
+			m.cursor = 0
+			return nil
+		}},
+		{name: "Savings Goals", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewSavings)
+			m.cursor = 0
+			return nil
+		}},
+		{name: "Stats & Dashboard", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewStats)
+			m.cursor = 0
+			return nil
+		}},
+		{name: "Sync to Obsidian", run: func(m *Model) tea.Cmd {
+			if err := m.obsidian.SyncAllNotes(m.storage.GetData()); err != nil {
+				m.message = "Error syncing: " + err.Error()
+				m.messageType = "error"
+			} else {
+				m.message = "Successfully synced to Obsidian!"
+				m.messageType = "success"
+			}
+			return nil
+		}},
+		{name: "Settings", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewSettings)
+			m.initSettingsInputs()
+			return nil
+		}},
+		{name: "Quit", run: func(m *Model) tea.Cmd {
+			return tea.Quit
+		}},
+		{key: "d", desc: "Debts overdue or due this week", name: "Debts overdue or due this week", run: func(m *Model) tea.Cmd {
+			// Review what the due-date reminder counts
+			m.pushView(ViewDueDebts)
+			m.cursor = 0
+			return nil
+		}},
+		{key: "e", desc: "Export everything as CSV files", name: "Export CSV", run: func(m *Model) tea.Cmd {
+			m.openExportCSV()
+			return nil
+		}},
+	}},
+	{"Expenses, Net Worth, Savings, Payment History", nil, []binding{
+		{key: "g", label: "g / G", desc: "First / last row"},
+		{key: "ctrl+d", label: "ctrl+d / ctrl+u", desc: "Half a page down / up"},
+	}},
+	{"Forms", nil, []binding{
+		{key: "tab", label: "Tab ↓ / shift+Tab ↑", desc: "Next / previous field"},
+		{key: "tab", label: "Tab", desc: "Use the suggested person (add debt) or category (add expense)"},
+		{key: "+", desc: "Calculate the amount field"},
+		{key: "alt+1", label: "alt+1-6", desc: "Jump to field (add investment)"},
+		{key: "enter", label: "Enter", desc: "Save"},
+		{key: "esc", label: "Esc", desc: "Cancel"},
+	}},
+	{"Expenses", []View{ViewExpenses}, []binding{
+		{key: "a", desc: "Add expense", name: "Add expense", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewAddExpense)
+			m.initExpenseInputs()
+			return nil
+		}},
+		{key: "A", desc: "Quick add (e.g. 250 Lunch food)", name: "Quick add expense", run: func(m *Model) tea.Cmd {
+			// One-line quick add, e.g. "250 Lunch food"
+			m.pushView(ViewQuickAddExpense)
+			m.initQuickAddInput()
+			return nil
+		}},
+		{key: ".", desc: "Repeat last expense", name: "Repeat last expense", run: func(m *Model) tea.Cmd {
+			expense, err := m.storage.RepeatLastExpense()
+			if err != nil {
+				m.message = "Cannot repeat: " + err.Error()
+				m.messageType = "error"
+				return nil
+			}
+			m.message = fmt.Sprintf("Added %s - %s (%s) for today", expense.Description, m.formatAmountPlain(expense.Amount, m.currencyOf(expense.Currency)), expense.Category)
+			m.messageType = "success"
+			m.warnIfDuplicate(expense, m.stashRoundUp(expense))
+			m.cursor, m.offset = 0, 0
+			return nil
+		}},
+		{key: "u", desc: "Remove an expense just flagged as a duplicate", run: func(m *Model) tea.Cmd {
+			if m.duplicateID == "" {
+				return nil
+			}
+			if err := m.storage.DeleteExpense(m.duplicateID); err != nil {
+				m.message = "Error removing duplicate: " + err.Error()
+				m.messageType = "error"
+			} else {
+				m.message = "Duplicate removed"
+				m.messageType = "success"
+				// The round-up it stashed goes back too
+				if stash := m.duplicateStash; stash.amount > 0 {
+					if err := m.storage.WithdrawSavingsContribution(stash.goalID, stash.amount, "Round-up of a removed duplicate"); err != nil {
+						m.message += " (round-up not given back: " + err.Error() + ")"
+						m.messageType = "warning"
+					} else {
+						m.message += fmt.Sprintf(", round-up of %s given back", m.formatAmountPlain(stash.amount, m.config.Currency))
+					}
+				}
+			}
+			m.duplicateID = ""
+			m.duplicateStash = roundUp{}
+			m.cursor, m.offset = 0, 0
+			return nil
+		}},
+		{key: "z", desc: "Archives (move old records out, browse them)", name: "Archives", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewArchives)
+			m.cursor = 0
+			return nil
+		}},
+		{key: "/", desc: "Filter by description or category", run: func(m *Model) tea.Cmd {
+			m.inputs = make([]textinput.Model, 1)
+			m.inputs[0] = textinput.New()
+			m.inputs[0].Placeholder = "description, category or tag (#tag for an exact tag)"
+			m.inputs[0].SetValue(m.expenseFilter)
+			m.inputs[0].Focus()
+			m.focusIndex = 0
+			return nil
+		}},
+		{key: "t", desc: "Date range", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewExpenseRange)
+			m.inputs = make([]textinput.Model, 2)
+			m.inputs[0] = textinput.New()
+			m.inputs[0].Placeholder = "From (YYYY-MM-DD)"
+			m.inputs[1] = textinput.New()
+			m.inputs[1].Placeholder = "To (YYYY-MM-DD, leave empty for today)"
+			if !m.expenseFrom.IsZero() {
+				m.inputs[0].SetValue(m.expenseFrom.Format("2006-01-02"))
+				m.inputs[1].SetValue(m.expenseTo.Format("2006-01-02"))
+			}
+			m.inputs[0].Focus()
+			m.focusIndex = 0
+			return nil
+		}},
+		{key: "r", desc: "Recurring expenses", name: "Recurring expenses", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewRecurring)
+			m.cursor = 0
+			return nil
+		}},
+		{key: "i", desc: "Import CSV", name: "Import expenses from CSV", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewImportExpenses)
+			m.inputs = make([]textinput.Model, 1)
+			m.inputs[0] = textinput.New()
+			m.inputs[0].Placeholder = "Path to CSV file"
+			m.inputs[0].Focus()
+			m.focusIndex = 0
+			return nil
+		}},
+		{key: "d", desc: "Delete", run: func(m *Model) tea.Cmd {
+			if exp := expenseAtRow(m.listedExpenses(), m.cursor); exp != nil {
+				m.confirmDelete(deleteExpense, exp.ID)
+			}
+			return nil
+		}},
+		{key: "enter", label: "Enter", desc: "Details"},
+		{key: "x", desc: "Toggle category exclusions", run: func(m *Model) tea.Cmd {
+			m.toggleExclusions()
+			return nil
+		}},
+	}},
+	{"Expense Details", []View{ViewExpenseDetail}, []binding{
+		{key: "n", desc: "Edit notes", run: func(m *Model) tea.Cmd {
+			if exp := m.selectedExpense(); exp != nil {
+				m.pushView(ViewEditExpenseNotes)
+				m.inputs = make([]textinput.Model, 1)
+				m.inputs[0] = textinput.New()
+				m.inputs[0].Placeholder = "Notes"
+				m.inputs[0].SetValue(exp.Notes)
+				m.inputs[0].Focus()
+				m.focusIndex = 0
+			}
+			return nil
+		}},
+	}},
+	{"Recurring Expenses", []View{ViewRecurring}, []binding{
+		{key: "a", desc: "Add", name: "Add recurring expense", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewAddRecurring)
+			m.initRecurringInputs()
+			return nil
+		}},
+		{key: "p", desc: "Pause / resume", run: func(m *Model) tea.Cmd {
+			if r, ok := at(m.storage.GetRecurringExpenses(), m.cursor); ok {
+				if err := m.storage.SetRecurringExpenseActive(r.ID, !r.Active); err != nil {
+					m.message = "Error saving: " + err.Error()
+					m.messageType = "error"
+				}
+			}
+			return nil
+		}},
+		{key: "e", desc: "Set or clear the end date", run: func(m *Model) tea.Cmd {
+			if r, ok := at(m.storage.GetRecurringExpenses(), m.cursor); ok {
+				m.selectedID = r.ID
+				m.pushView(ViewRecurringEndDate)
+				m.inputs = make([]textinput.Model, 1)
+				m.inputs[0] = textinput.New()
+				m.inputs[0].Placeholder = "End Date (YYYY-MM-DD)"
+				if r.EndDate != nil {
+					m.inputs[0].SetValue(r.EndDate.Format("2006-01-02"))
+				}
+				m.inputs[0].Focus()
+				m.focusIndex = 0
+			}
+			return nil
+		}},
+		{key: "d", desc: "Delete", run: func(m *Model) tea.Cmd {
+			if r, ok := at(m.storage.GetRecurringExpenses(), m.cursor); ok {
+				m.confirmDelete(deleteRecurringExpense, r.ID)
+			}
+			return nil
+		}},
+	}},
+	{"Borrowing & Lending", []View{ViewDebts}, []binding{
+		{key: "a", desc: "Add debt", name: "Add debt", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewAddDebt)
+			m.initDebtInputs()
+			return nil
+		}},
+		{key: "B", desc: "Split a bill among several people", name: "Split a bill", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewSplitBill)
+			m.initSplitBillInputs()
+			return nil
+		}},
+		{key: "s", desc: "Settle", name: "Settle with %s", runFor: func(m *Model, person string) tea.Cmd {
+			m.selectedPerson = person
+			m.pushView(ViewSelectTransaction)
+			m.cursor = 0
+			return nil
+		}},
+		{key: "S", desc: "Settle everything with a person", name: "Settle everything with %s", runFor: func(m *Model, person string) tea.Cmd {
+			m.selectedPerson = person
+			m.pushView(ViewSettlePerson)
+			m.inputs = make([]textinput.Model, 1)
+			m.inputs[0] = textinput.New()
+			m.inputs[0].Placeholder = "Note (e.g., Cash, UPI, Bank transfer)"
+			m.inputs[0].Focus()
+			m.focusIndex = 0
+			return nil
+		}},
+		{key: "h", desc: "Person history", name: "Payment history with %s", runFor: func(m *Model, person string) tea.Cmd {
+			m.selectedPerson = person
+			m.pushView(ViewPersonHistory)
+			m.cursor = 0
+			return nil
+		}},
+		{key: "g", desc: "All payments", name: "All payments history", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewSettlementHistory)
+			m.cursor = 0
+			return nil
+		}},
+		{key: "m", desc: "Missing due dates", name: "Debts missing a due date", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewMissingDueDates)
+			m.cursor = 0
+			return nil
+		}},
+		{key: "b", desc: "By reason", name: "Debts by reason", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewDebtsByReason)
+			m.offset = 0
+			return nil
+		}},
+		{key: "p", desc: "People", name: "People", run: func(m *Model) tea.Cmd {
+			// Everyone, including people who are fully settled
+			m.pushView(ViewPeople)
+			m.cursor = 0
+			m.offset = 0
+			return nil
+		}},
+		{key: "t", desc: "Balances table (w writes it to a file)", name: "Balances table", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewBalancesTable)
+			m.inputs = nil
+			return nil
+		}},
+		{key: "r", desc: "Rename / merge person", runFor: func(m *Model, person string) tea.Cmd {
+			m.selectedPerson = person
+			m.pushView(ViewRenamePerson)
+			m.inputs = make([]textinput.Model, 1)
+			m.inputs[0] = textinput.New()
+			m.inputs[0].Placeholder = "New name (an existing name merges the two)"
+			m.inputs[0].SetValue(person)
+			m.inputs[0].Focus()
+			m.focusIndex = 0
+			return nil
+		}},
+		{key: "u", desc: "Undo last settlement", name: "Undo last settlement", run: func(m *Model) tea.Cmd {
+			m.confirmUndoSettlement()
+			return nil
+		}},
+	}},
+	{"People", nil, []binding{
+		{key: "enter", label: "Enter", desc: "Payment history"},
+	}},
+	{"Payment History", []View{ViewPersonHistory}, []binding{
+		{key: "e", desc: "Pay the next installment of an EMI", run: func(m *Model) tea.Cmd {
+			m.confirmInstallmentPayment()
+			return nil
+		}},
+	}},
+	{"Settle: Pick a Transaction", []View{ViewSelectTransaction}, []binding{
+		{key: "enter", label: "Enter", desc: "Settle"},
+		// Handled by the view: the first f asks, a second f settles
+		{key: "f", desc: "Settle in full"},
+		{key: "e", desc: "Edit", run: func(m *Model) tea.Cmd {
+			if tx, ok := at(m.storage.GetUnsettledDebtsForPerson(m.selectedPerson), m.cursor); ok {
+				m.selectedTxID = tx.ID
+				m.pushView(ViewEditDebt)
+				m.initEditDebtInputs(tx)
+			}
+			return nil
+		}},
+		{key: "d", desc: "Delete", run: func(m *Model) tea.Cmd {
+			if tx, ok := at(m.storage.GetUnsettledDebtsForPerson(m.selectedPerson), m.cursor); ok {
+				m.confirmDelete(deleteDebtTransaction, tx.ID)
+			}
+			return nil
+		}},
+		{key: "h", desc: "Settlement history", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewPersonHistory)
+			m.cursor = 0
+			return nil
+		}},
+	}},
+	{"My Net Worth", []View{ViewNetWorth}, []binding{
+		{key: "enter", label: "Enter", desc: "Investment details"},
+		{key: "a", desc: "Add investment", name: "Add investment", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewAddInvestment)
+			m.initInvestmentInputs()
+			return nil
+		}},
+		{key: "u", desc: "Update", run: func(m *Model) tea.Cmd {
+			inv, ok := at(m.storage.GetInvestments(), m.cursor)
+			if !ok {
+				return nil
+			}
+			m.selectedID = inv.ID
+			m.pushView(ViewUpdateInvestment)
+			m.inputs = make([]textinput.Model, 4)
+			m.inputs[0] = textinput.New()
+			m.inputs[0].Placeholder = "Type"
+			m.inputs[0].SetValue(string(inv.Type))
+			m.inputs[1] = textinput.New()
+			m.inputs[1].Placeholder = "Name"
+			m.inputs[1].SetValue(inv.Name)
+			m.inputs[2] = textinput.New()
+			m.inputs[2].Placeholder = "New invested amount"
+			m.inputs[2].SetValue(fmt.Sprintf("%.2f", inv.InvestedAmount))
+			m.inputs[3] = textinput.New()
+			m.inputs[3].Placeholder = "New current value"
+			m.inputs[3].SetValue(fmt.Sprintf("%.2f", inv.CurrentValue))
+			m.inputs[2].Focus()
+			m.focusIndex = 2
+			return nil
+		}},
+		{key: "i", desc: "Income", run: func(m *Model) tea.Cmd {
+			if inv, ok := at(m.storage.GetInvestments(), m.cursor); ok {
+				m.selectedID = inv.ID
+				m.pushView(ViewInvestmentIncome)
+			}
+			return nil
+		}},
+		{key: "s", desc: "Snapshot net worth", name: "Snapshot net worth", run: func(m *Model) tea.Cmd {
+			snapshot, previous, err := m.storage.RecordNetWorthSnapshot()
+			if err != nil {
+				m.message = "Error saving snapshot: " + err.Error()
+				m.messageType = "error"
+				return nil
+			}
+			m.message = "Snapshot saved: " + m.formatAmountPlain(snapshot.Value, m.config.Currency)
+			if previous != nil {
+				m.message += fmt.Sprintf(" (%+.2f since %s)", snapshot.Value-previous.Value, previous.Date.Format("2006-01-02"))
+			}
+			m.messageType = "success"
+			return nil
+		}},
+		{key: "h", desc: "Net worth history", name: "Net worth history", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewNetWorthHistory)
+			m.offset = 0
+			return nil
+		}},
+		{key: "%", desc: "Cycle gain display", run: func(m *Model) tea.Cmd {
+			m.gainDisplay = m.gainDisplay.next()
+			return nil
+		}},
+		{key: "d", desc: "Delete", run: func(m *Model) tea.Cmd {
+			if inv, ok := at(m.storage.GetInvestments(), m.cursor); ok {
+				m.confirmDelete(deleteInvestment, inv.ID)
+			}
+			return nil
+		}},
+	}},
+	{"Investment Details", []View{ViewInvestmentDetail}, []binding{
+		{key: "n", desc: "Edit notes", run: func(m *Model) tea.Cmd {
+			if inv := m.selectedInvestment(); inv != nil {
+				m.pushView(ViewEditInvestmentNotes)
+				m.inputs = make([]textinput.Model, 1)
+				m.inputs[0] = textinput.New()
+				m.inputs[0].Placeholder = "Notes"
+				m.inputs[0].SetValue(inv.Notes)
+				m.inputs[0].Focus()
+				m.focusIndex = 0
+			}
+			return nil
+		}},
+		{key: "i", desc: "Income (a: record income)", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewInvestmentIncome)
+			return nil
+		}},
+	}},
+	{"Savings Goals", []View{ViewSavings}, []binding{
+		{key: "a", desc: "Add goal", name: "Add savings goal", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewAddSavingsTarget)
+			m.initSavingsTargetInputs()
+			return nil
+		}},
+		{key: "t", desc: "From template", name: "Savings goal from a template", run: func(m *Model) tea.Cmd {
+			m.pushView(ViewGoalTemplates)
+			m.cursor = 0
+			return nil
+		}},
+		{key: "y", desc: "Duplicate", run: func(m *Model) tea.Cmd {
+			if target, ok := at(m.storage.GetVisibleSavingsTargets(), m.cursor); ok {
+				if _, err := m.storage.DuplicateSavingsTarget(target.ID); err != nil {
+					m.message = "Error duplicating: " + err.Error()
+					m.messageType = "error"
+				} else {
+					m.message = "Goal duplicated!"
+					m.messageType = "success"
+				}
+			}
+			return nil
+		}},
+		{key: "c", desc: "Add contribution", run: func(m *Model) tea.Cmd {
+			if target, ok := at(m.storage.GetVisibleSavingsTargets(), m.cursor); ok {
+				m.selectedID = target.ID
+				m.pushView(ViewAddContribution)
+				m.withdrawing = false
+				m.initContributionInputs()
+			}
+			return nil
+		}},
+		{key: "w", desc: "Withdraw", run: func(m *Model) tea.Cmd {
+			if target, ok := at(m.storage.GetVisibleSavingsTargets(), m.cursor); ok {
+				m.selectedID = target.ID
+				m.pushView(ViewAddContribution)
+				m.withdrawing = true
+				m.initContributionInputs()
+			}
+			return nil
+		}},
+		{key: "h", desc: "Contribution history", run: func(m *Model) tea.Cmd {
+			if target, ok := at(m.storage.GetVisibleSavingsTargets(), m.cursor); ok {
+				m.selectedID = target.ID
+				m.pushView(ViewSavingsHistory)
+				m.offset = 0
+			}
+			return nil
+		}},
+		{key: "e", desc: "Edit", run: func(m *Model) tea.Cmd {
+			if target, ok := at(m.storage.GetVisibleSavingsTargets(), m.cursor); ok {
+				m.selectedID = target.ID
+				m.pushView(ViewEditSavingsTarget)
+				m.initSavingsTargetInputs()
+				m.inputs[0].SetValue(target.ProductName)
+				m.inputs[1].SetValue(fmt.Sprintf("%.2f", target.TargetAmount))
+				m.inputs[2].SetValue(target.TargetDate.Format("2006-01-02"))
+				m.inputs[3].SetValue(target.Description)
+			}
+			return nil
+		}},
+		{key: "+", desc: "Raise priority", run: func(m *Model) tea.Cmd {
+			m.shiftSavingsPriority(1)
+			return nil
+		}},
+		{key: "-", desc: "Lower priority", run: func(m *Model) tea.Cmd {
+			m.shiftSavingsPriority(-1)
+			return nil
+		}},
+		{key: "d", desc: "Delete", run: func(m *Model) tea.Cmd {
+			if target, ok := at(m.storage.GetVisibleSavingsTargets(), m.cursor); ok {
+				m.confirmDelete(deleteSavingsTarget, target.ID)
+			}
+			return nil
+		}},
+	}},
+	{"Goal Templates", nil, []binding{
+		{key: "enter", label: "Enter", desc: "Create goal"},
+	}},
+	{"Stats & Dashboard", []View{ViewStats}, []binding{
+		{key: "enter", label: "Enter", desc: "Open section"},
+		{key: "c", desc: "Month / all-time category chart", run: func(m *Model) tea.Cmd {
+			m.topAllTime = !m.topAllTime
+			return nil
+		}},
+		{key: "x", desc: "Toggle category exclusions", run: func(m *Model) tea.Cmd {
+			m.toggleExclusions()
+			return nil
+		}},
+	}},
+}
+
+// at returns items[i] when i is a valid index
+func at[T any](items []T, i int) (T, bool) {
+	if i < 0 || i >= len(items) {
+		var zero T
+		return zero, false
+	}
+	return items[i], true
+}
+
+// boundKey returns the registry binding key runs in view, if any
+func boundKey(view View, key string) *binding {
+	for _, section := range keymap {
+		for _, v := range section.views {
+			if v != view {
+				continue
+			}
+			for i := range section.binds {
+				b := &section.binds[i]
+				if b.key == key && (b.run != nil || b.runFor != nil) {
+					return b
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// runKey runs b for the current view. Person bindings act on the person
+// under the cursor in Debts.
+func (m *Model) runKey(b *binding) tea.Cmd {
+	if b.runFor == nil {
+		return b.run(m)
+	}
+	persons := m.visiblePersons()
+	if len(persons) == 0 {
+		return nil
+	}
+	// A settlement elsewhere may have removed people since the cursor last moved
+	return b.runFor(m, persons[min(m.cursor, len(persons)-1)])
+}
+
+// mainMenu returns the main menu items: the Main Menu commands without a key
+func mainMenu() []binding {
+	var items []binding
+	for _, section := range keymap {
+		if section.title != "Main Menu" {
+			continue
+		}
+		for _, b := range section.binds {
+			if b.key == "" {
+				items = append(items, b)
+			}
+		}
+	}
+	return items
+}
+
+// shiftSavingsPriority raises (by > 0) or lowers the priority of the selected
+// unfinished savings goal
+func (m *Model) shiftSavingsPriority(by int) {
+	target, ok := at(m.storage.GetVisibleSavingsTargets(), m.cursor)
+	if !ok || target.IsCompleted {
+		return
+	}
+	if err := m.storage.SetSavingsPriority(target.ID, target.EffectivePriority()+by); err != nil {
+		m.message = err.Error()
+		m.messageType = "error"
+	}
+}
@@ -0,0 +1,202 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/debtq/debtq/internal/models"
+	"github.com/debtq/debtq/internal/storage"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		query, target string
+		ok            bool
+	}{
+		{"", "Add expense", true},
+		{"addexp", "Add expense", true},
+		{"ADD", "add expense", true},
+		{"ae", "Add expense", true},
+		{"ea", "Add", false},
+		{"xyz", "Add expense", false},
+	}
+	for _, tt := range tests {
+		if _, ok := fuzzyMatch(tt.query, tt.target); ok != tt.ok {
+			t.Errorf("fuzzyMatch(%q, %q) ok = %v, want %v", tt.query, tt.target, ok, tt.ok)
+		}
+	}
+
+	// Word starts and consecutive runs beat scattered letters
+	start, _ := fuzzyMatch("se", "Settle everything")
+	scattered, _ := fuzzyMatch("se", "Quick add expense")
+	if start <= scattered {
+		t.Errorf("word start scored %d, scattered %d", start, scattered)
+	}
+}
+
+func TestFilterCommandsBestMatchFirst(t *testing.T) {
+	commands := []command{
+		{name: "Repeat last expense"},
+		{name: "Savings Goals"},
+		{name: "People"},
+	}
+	got := filterCommands(commands, "pe")
+	if len(got) != 2 || got[0].name != "People" || got[1].name != "Repeat last expense" {
+		t.Fatalf("filterCommands(pe) = %v", got)
+	}
+	if got := filterCommands(commands, ""); len(got) != len(commands) {
+		t.Errorf("empty query kept %d of %d commands", len(got), len(commands))
+	}
+}
+
+func TestPaletteRunsCommand(t *testing.T) {
+	m := newTestModel(t)
+	m = press(t, m, "ctrl+k")
+	m = typeText(t, m, "add expense")
+	m = press(t, m, "enter")
+	if m.currentView != ViewAddExpense {
+		t.Fatalf("view = %v, want ViewAddExpense", m.currentView)
+	}
+}
+
+func TestPaletteSettlesWithPerson(t *testing.T) {
+	m := newTestModel(t)
+	if _, err := m.storage.AddDebtTransaction(models.Lent, "Asha", 500, "dinner", time.Now(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.storage.AddDebtTransaction(models.Lent, "Ravi", 200, "taxi", time.Now(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	m = press(t, m, "ctrl+k")
+	m = typeText(t, m, "settle with ravi")
+	m = press(t, m, "enter")
+	if m.currentView != ViewSelectTransaction || m.selectedPerson != "RAVI" {
+		t.Fatalf("view = %v, person = %q; want the RAVI settle picker", m.currentView, m.selectedPerson)
+	}
+}
+
+func TestKeysDispatchFromRegistry(t *testing.T) {
+	m := newTestModel(t)
+	m.pushView(ViewExpenses)
+	m = press(t, m, "a")
+	if m.currentView != ViewAddExpense {
+		t.Fatalf("a in Expenses: view = %v, want ViewAddExpense", m.currentView)
+	}
+
+	// While typing, registry keys go into the input
+	m = typeText(t, m, "ar")
+	if m.currentView != ViewAddExpense || m.inputs[m.focusIndex].Value() != "ar" {
+		t.Errorf("typing: view = %v, input = %q", m.currentView, m.inputs[m.focusIndex].Value())
+	}
+}
+
+func TestKeymapConsistent(t *testing.T) {
+	for _, section := range keymap {
+		seen := make(map[string]bool)
+		for _, b := range section.binds {
+			runs := b.run != nil || b.runFor != nil
+			if b.name != "" && !runs {
+				t.Errorf("%s: %q is named but does nothing", section.title, b.name)
+			}
+			if b.runFor != nil && !strings.Contains(b.name, "%s") && b.name != "" {
+				t.Errorf("%s: person binding %q has no %%s for the person", section.title, b.name)
+			}
+			if !runs || b.key == "" {
+				continue
+			}
+			if seen[b.key] {
+				t.Errorf("%s: key %q bound twice", section.title, b.key)
+			}
+			seen[b.key] = true
+		}
+	}
+}
+
+func TestHelpListsRegistry(t *testing.T) {
+	help := strings.Join(helpLines(), "\n")
+	for _, want := range []string{"Export everything as CSV files", "Undo last settlement", "Set or clear the end date"} {
+		if !strings.Contains(help, want) {
+			t.Errorf("help is missing %q", want)
+		}
+	}
+}
+
+func TestExportCSVAsksBeforeOverwriting(t *testing.T) {
+	m := newTestModel(t)
+	dir := t.TempDir()
+	m.config.DataFile = filepath.Join(dir, "data.json")
+	if _, err := m.storage.AddExpense(120, "Lunch", models.CategoryFood, time.Now(), "", nil, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	m = press(t, m, "e")
+	if m.currentView != ViewExportCSV {
+		t.Fatalf("e in Main Menu: view = %v, want ViewExportCSV", m.currentView)
+	}
+	out := filepath.Join(dir, "out")
+	m.inputs[0].SetValue(out)
+	m = press(t, m, "enter")
+	if m.messageType != "success" || m.currentView != ViewMain {
+		t.Fatalf("export: %s %q, view %v", m.messageType, m.message, m.currentView)
+	}
+	for _, name := range exportCSVFiles {
+		if _, err := os.Stat(filepath.Join(out, name)); err != nil {
+			t.Errorf("%s not written: %v", name, err)
+		}
+	}
+
+	// A second export into the same directory asks first; Esc keeps the files
+	path := filepath.Join(out, storage.ExpensesCSVFile)
+	if err := os.WriteFile(path, []byte("kept"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m = press(t, m, "e")
+	m.inputs[0].SetValue(out)
+	m = press(t, m, "enter")
+	if m.currentView != ViewConfirm {
+		t.Fatalf("view = %v, want ViewConfirm", m.currentView)
+	}
+	m = press(t, m, "esc")
+	if data, _ := os.ReadFile(path); string(data) != "kept" {
+		t.Errorf("cancelled export overwrote %s", path)
+	}
+	m = press(t, m, "enter", "enter")
+	if data, _ := os.ReadFile(path); string(data) == "kept" {
+		t.Errorf("confirmed export left %s unchanged", path)
+	}
+}
+
+func TestUndoSettlementConfirmsFirst(t *testing.T) {
+	m := newTestModel(t)
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i, desc := range []string{"dinner", "taxi"} {
+		if _, err := m.storage.AddDebtTransaction(models.Lent, "Asha", 100, desc, day.AddDate(0, 0, i), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := m.storage.SettleAmountForPerson("Asha", 200, "upi"); err != nil {
+		t.Fatal(err)
+	}
+
+	m.pushView(ViewDebts)
+	m = press(t, m, "u")
+	if m.currentView != ViewConfirm {
+		t.Fatalf("u in Debts: view = %v, want ViewConfirm", m.currentView)
+	}
+	m = press(t, m, "esc")
+	if n := len(m.storage.GetAllSettlements()); n != 2 || m.currentView != ViewDebts {
+		t.Fatalf("after Esc: %d settlements, view %v; want 2 in ViewDebts", n, m.currentView)
+	}
+
+	m = press(t, m, "u", "enter")
+	if n := len(m.storage.GetAllSettlements()); n != 0 {
+		t.Fatalf("%d settlements left after undo, want 0", n)
+	}
+	if m.messageType != "success" || !strings.Contains(m.message, "2 settlements") {
+		t.Errorf("message = %s %q", m.messageType, m.message)
+	}
+}
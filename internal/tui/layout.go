@@ -0,0 +1,54 @@
+package tui
+
+// columnSpec bounds the width of a flexible list column. Max 0 means unbounded.
+type columnSpec struct {
+	Min int
+	Max int
+}
+
+// distributeWidths splits total characters among columns: every column gets its
+// Min, then the remainder is shared evenly among columns still below their Max.
+// If total is smaller than the sum of minimums, the minimums are returned.
+func distributeWidths(total int, cols []columnSpec) []int {
+	widths := make([]int, len(cols))
+	remaining := total
+	for i, c := range cols {
+		widths[i] = c.Min
+		remaining -= c.Min
+	}
+
+	for remaining > 0 {
+		var growable []int
+		for i, c := range cols {
+			if c.Max == 0 || widths[i] < c.Max {
+				growable = append(growable, i)
+			}
+		}
+		if len(growable) == 0 {
+			break
+		}
+
+		share := remaining / len(growable)
+		if share == 0 {
+			// Fewer characters left than columns: hand them out one at a time
+			for _, i := range growable[:remaining] {
+				widths[i]++
+			}
+			break
+		}
+		for _, i := range growable {
+			add := share
+			if max := cols[i].Max; max > 0 && widths[i]+add > max {
+				add = max - widths[i]
+			}
+			widths[i] += add
+			remaining -= add
+		}
+	}
+	return widths
+}
+
+// contentWidth returns the usable width inside BoxStyle (border and padding removed)
+func (m Model) contentWidth() int {
+	return m.width - 6
+}
@@ -0,0 +1,48 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDistributeWidths(t *testing.T) {
+	tests := []struct {
+		name  string
+		total int
+		cols  []columnSpec
+		want  []int
+	}{
+		{"exactly the minimums", 30, []columnSpec{{10, 20}, {20, 0}}, []int{10, 20}},
+		{"narrower than the minimums", 10, []columnSpec{{10, 20}, {20, 0}}, []int{10, 20}},
+		{"shared evenly", 50, []columnSpec{{10, 0}, {20, 0}}, []int{20, 30}},
+		{"capped column gives the rest away", 60, []columnSpec{{10, 15}, {20, 0}}, []int{15, 45}},
+		{"odd characters one at a time", 33, []columnSpec{{10, 0}, {10, 0}, {10, 0}}, []int{11, 11, 11}},
+		{"leftover after a partial share", 32, []columnSpec{{10, 0}, {10, 0}, {10, 0}}, []int{11, 11, 10}},
+		{"every column capped", 100, []columnSpec{{10, 12}, {10, 14}}, []int{12, 14}},
+		{"no columns", 40, nil, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := distributeWidths(tt.total, tt.cols)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("distributeWidths(%d, %v) = %v, want %v", tt.total, tt.cols, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistributeWidthsNeverExceedsTotal(t *testing.T) {
+	cols := []columnSpec{{8, 30}, {12, 0}, {6, 10}}
+	for total := 26; total <= 200; total++ {
+		sum := 0
+		for i, w := range distributeWidths(total, cols) {
+			if w < cols[i].Min || (cols[i].Max > 0 && w > cols[i].Max) {
+				t.Fatalf("total %d: column %d is %d, outside %v", total, i, w, cols[i])
+			}
+			sum += w
+		}
+		if sum != total {
+			t.Fatalf("total %d: columns add up to %d", total, sum)
+		}
+	}
+}
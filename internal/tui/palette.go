@@ -0,0 +1,188 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// command is one command palette entry
+type command struct {
+	name string // What the command does
+	hint string // Where its key is, e.g. "a in Expenses"
+	run  func(m *Model) tea.Cmd
+}
+
+// paletteCommands returns the palette entries for the named bindings in
+// keymap. Person bindings become one entry per person with an outstanding
+// balance, e.g. "Settle with ASHA".
+func (m Model) paletteCommands() []command {
+	var persons []string
+	var commands []command
+	for _, section := range keymap {
+		for _, b := range section.binds {
+			if b.name == "" {
+				continue
+			}
+			hint := section.title
+			if b.key != "" {
+				hint = b.key + " in " + section.title
+			}
+			if b.runFor == nil {
+				commands = append(commands, command{b.name, hint, b.run})
+				continue
+			}
+			if persons == nil {
+				persons = m.visiblePersons()
+			}
+			for _, person := range persons {
+				runFor := b.runFor
+				commands = append(commands, command{fmt.Sprintf(b.name, person), hint, func(m *Model) tea.Cmd {
+					return runFor(m, person)
+				}})
+			}
+		}
+	}
+	return commands
+}
+
+// filterCommands returns the commands fuzzily matching query, best match first
+func filterCommands(commands []command, query string) []command {
+	type scored struct {
+		command command
+		score   int
+	}
+
+	var matches []scored
+	for _, c := range commands {
+		if score, ok := fuzzyMatch(query, c.name); ok {
+			matches = append(matches, scored{c, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	result := make([]command, len(matches))
+	for i, match := range matches {
+		result[i] = match.command
+	}
+	return result
+}
+
+// fuzzyMatch reports whether every character of query appears in target in order
+// (case-insensitive). Consecutive runs and matches at word starts score higher.
+func fuzzyMatch(query, target string) (int, bool) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	target = strings.ToLower(target)
+	if query == "" {
+		return 0, true
+	}
+
+	score := 0
+	qi := 0
+	prevMatch := -2
+	for ti := 0; ti < len(target) && qi < len(query); ti++ {
+		if target[ti] != query[qi] {
+			continue
+		}
+		score++
+		if ti == prevMatch+1 {
+			score += 2
+		}
+		if ti == 0 || target[ti-1] == ' ' {
+			score += 3
+		}
+		prevMatch = ti
+		qi++
+	}
+	if qi < len(query) {
+		return 0, false
+	}
+	return score, true
+}
+
+// openPalette shows the command palette over the current view
+func (m *Model) openPalette() {
+	m.pushView(ViewCommandPalette)
+	m.cursor = 0
+	m.inputs = make([]textinput.Model, 1)
+	m.inputs[0] = textinput.New()
+	m.inputs[0].Placeholder = "Type a command..."
+	m.inputs[0].Focus()
+	m.focusIndex = 0
+}
+
+func (m Model) viewCommandPalette() string {
+	title := TitleStyle.Render("  Command Palette")
+
+	var content string
+	if len(m.inputs) > 0 {
+		content = "\n  " + FocusedInputStyle.Render(m.inputs[0].View()) + "\n\n"
+	}
+
+	query := ""
+	if len(m.inputs) > 0 {
+		query = m.inputs[0].Value()
+	}
+	matches := filterCommands(m.paletteCommands(), query)
+	if len(matches) == 0 {
+		content += MutedStyle.Render("  No matching commands.\n")
+	}
+	for i, c := range matches {
+		cursor := "  "
+		style := MenuItemStyle
+		if i == m.cursor {
+			cursor = "▸ "
+			style = SelectedMenuItemStyle
+		}
+		content += fmt.Sprintf("%s  %s\n", style.Render(cursor+c.name), MutedStyle.Render(c.hint))
+	}
+
+	help := HelpStyle.Render("\n  ↑/↓: Navigate • Enter: Run • Esc: Close")
+
+	return BoxStyle.Render(title + content + help)
+}
+
+func (m *Model) updateCommandPaletteView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	matches := filterCommands(m.paletteCommands(), m.inputs[0].Value())
+
+	switch msg.String() {
+	case "up", "ctrl+p":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		return m, nil
+	case "down", "ctrl+n":
+		if m.cursor < len(matches)-1 {
+			m.cursor++
+		}
+		return m, nil
+	case "enter":
+		// Actions navigate onward from the view the palette was opened over
+		m.popView()
+		m.inputs = nil
+		if m.cursor >= len(matches) {
+			return m, nil
+		}
+		selected := matches[m.cursor]
+		m.cursor = 0
+		return m, selected.run(m)
+	case "esc", "ctrl+k":
+		m.popView()
+		m.inputs = nil
+		m.cursor = 0
+		return m, nil
+	}
+
+	oldQuery := m.inputs[0].Value()
+	var cmd tea.Cmd
+	m.inputs[0], cmd = m.inputs[0].Update(msg)
+	if m.inputs[0].Value() != oldQuery {
+		m.cursor = 0
+	}
+	return m, cmd
+}
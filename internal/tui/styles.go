@@ -2,6 +2,8 @@ package tui
 
 import (
 	"fmt"
+	"math"
+	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -77,6 +79,13 @@ var (
 			Foreground(Muted).
 			MarginTop(1)
 
+	HelpKeyStyle = lipgloss.NewStyle().
+			Foreground(Accent).
+			Bold(true)
+
+	HelpDescStyle = lipgloss.NewStyle().
+			Foreground(TextSecondary)
+
 	InputStyle = lipgloss.NewStyle().
 			BorderStyle(lipgloss.NormalBorder()).
 			BorderForeground(Primary).
@@ -88,25 +97,101 @@ var (
 				Padding(0, 1)
 )
 
-// FormatAmount formats amount with color based on positive/negative
-func FormatAmount(amount float64, currency string) string {
-	if amount >= 0 {
-		return AmountPositiveStyle.Render(currency + " " + formatFloat(amount))
+// RenderBadge renders a short label as a colored badge.
+// kind is one of "success", "warning", "danger"; anything else renders muted.
+func RenderBadge(text, kind string) string {
+	color := Muted
+	switch kind {
+	case "success":
+		color = Secondary
+	case "warning":
+		color = Accent
+	case "danger":
+		color = Danger
 	}
-	return AmountNegativeStyle.Render(currency + " " + formatFloat(amount))
+	return lipgloss.NewStyle().
+		Bold(true).
+		Foreground(TextPrimary).
+		Background(color).
+		Padding(0, 1).
+		Render(text)
 }
 
-// FormatAmountPlain formats amount without styling
-func FormatAmountPlain(amount float64, currency string) string {
-	return currency + " " + formatFloat(amount)
+// budgetNearPct is the share of a budget from which BudgetBar turns amber
+const budgetNearPct = 0.8
+
+// budgetLevel classifies spending against a budget as "ok", "near" (80% or
+// more) or "over" (above the budget)
+func budgetLevel(spent, budget float64) string {
+	switch {
+	case budget <= 0:
+		return "ok"
+	case spent > budget:
+		return "over"
+	case spent >= budget*budgetNearPct:
+		return "near"
+	default:
+		return "ok"
+	}
 }
 
-func formatFloat(f float64) string {
-	return lipgloss.NewStyle().Render(floatToString(f))
+// BudgetBar renders spending against a budget, amber near the limit and red over it
+func BudgetBar(spent, budget float64, width int) string {
+	if budget <= 0 {
+		return ""
+	}
+	pct := spent / budget
+	filled := int(math.Min(pct, 1) * float64(width))
+
+	style := ProgressBarStyle
+	switch budgetLevel(spent, budget) {
+	case "over":
+		style = ErrorStyle
+	case "near":
+		style = WarningStyle
+	}
+	return style.Render(strings.Repeat("█", filled)+strings.Repeat("░", width-filled)) +
+		style.Render(fmt.Sprintf(" %.0f%%", pct*100))
+}
+
+// sparkTicks are the bar heights Sparkline draws with, lowest first
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders values as a one-line chart scaled between their min and max
+func Sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		tick := 0
+		if hi > lo {
+			tick = int((v - lo) / (hi - lo) * float64(len(sparkTicks)-1))
+		}
+		b.WriteRune(sparkTicks[tick])
+	}
+	return ProgressBarStyle.Render(b.String())
 }
 
-func floatToString(f float64) string {
-	return fmt.Sprintf("%.2f", f)
+// Bar renders value as a horizontal bar scaled so that max fills width
+func Bar(value, max float64, width int) string {
+	if max <= 0 {
+		return ""
+	}
+	filled := int(value / max * float64(width))
+	if filled > width {
+		filled = width
+	}
+	if filled == 0 && value > 0 {
+		filled = 1
+	}
+	return ProgressBarStyle.Render(strings.Repeat("█", filled) + strings.Repeat("░", width-filled))
 }
 
 // ProgressBar creates a visual progress bar
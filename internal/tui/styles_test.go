@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/debtq/debtq/internal/config"
+)
+
+func TestBudgetLevel(t *testing.T) {
+	tests := []struct {
+		spent, budget float64
+		want          string
+	}{
+		{500, 0, "ok"}, // No budget set
+		{0, 1000, "ok"},
+		{799.99, 1000, "ok"},
+		{800, 1000, "near"},
+		{1000, 1000, "near"}, // Exactly at the cap is not over it
+		{1000.01, 1000, "over"},
+		{5000, 1000, "over"},
+	}
+	for _, tt := range tests {
+		if got := budgetLevel(tt.spent, tt.budget); got != tt.want {
+			t.Errorf("budgetLevel(%v, %v) = %q, want %q", tt.spent, tt.budget, got, tt.want)
+		}
+	}
+}
+
+func TestBudgetBarColors(t *testing.T) {
+	bar := func(filled int, pct string) string {
+		return strings.Repeat("█", filled) + strings.Repeat("░", 10-filled) + pct
+	}
+	tests := []struct {
+		spent float64
+		want  string
+	}{
+		{500, ProgressBarStyle.Render(bar(5, "")) + ProgressBarStyle.Render(" 50%")},
+		{850, WarningStyle.Render(bar(8, "")) + WarningStyle.Render(" 85%")},
+		{1500, ErrorStyle.Render(bar(10, "")) + ErrorStyle.Render(" 150%")},
+	}
+	for _, tt := range tests {
+		if got := BudgetBar(tt.spent, 1000, 10); got != tt.want {
+			t.Errorf("BudgetBar(%v) = %q, want %q", tt.spent, got, tt.want)
+		}
+	}
+	if got := BudgetBar(500, 0, 10); got != "" {
+		t.Errorf("BudgetBar without a budget = %q, want nothing", got)
+	}
+}
+
+func TestFormatAmountUsesModelConfig(t *testing.T) {
+	m := newTestModel(t)
+	m.config.NumberFormat = config.NumberFormatIndian
+	if got := m.formatAmountPlain(-1234567.5, "USD"); got != "USD -12,34,567.50" {
+		t.Errorf("indian = %q", got)
+	}
+	m.config.NumberFormat = config.NumberFormatWestern
+	if got := m.formatAmountPlain(-1234567.5, "INR"); got != "INR -1,234,567.50" {
+		t.Errorf("western = %q", got)
+	}
+}
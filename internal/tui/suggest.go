@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+
+	"github.com/debtq/debtq/internal/models"
+)
+
+// maxSuggestions caps how many matches are listed under a form field
+const maxSuggestions = 5
+
+// suggestions returns the options matching what has been typed so far, prefix
+// matches first, each group keeping the order of options. Nothing is suggested
+// for an empty value or once the value is already an exact option.
+func suggestions(value string, options []string) []string {
+	typed := strings.ToLower(strings.TrimSpace(value))
+	if typed == "" {
+		return nil
+	}
+
+	var prefix, contains []string
+	for _, opt := range options {
+		lower := strings.ToLower(opt)
+		switch {
+		case lower == typed:
+			return nil
+		case strings.HasPrefix(lower, typed):
+			prefix = append(prefix, opt)
+		case strings.Contains(lower, typed):
+			contains = append(contains, opt)
+		}
+	}
+
+	matches := append(prefix, contains...)
+	if len(matches) > maxSuggestions {
+		matches = matches[:maxSuggestions]
+	}
+	return matches
+}
+
+// personSuggestions returns existing people matching the add-debt person field
+func (m Model) personSuggestions() []string {
+	if len(m.inputs) < 2 {
+		return nil
+	}
+	people := m.storage.GetPeople()
+	names := make([]string, len(people))
+	for i, p := range people {
+		names[i] = p.Name
+	}
+	return suggestions(m.inputs[1].Value(), names)
+}
+
+// newCategoryInput returns a category input that completes the typed prefix
+// inline, e.g. "fo" shows "food" with the rest in MutedStyle
+func newCategoryInput() textinput.Model {
+	input := textinput.New()
+	names := make([]string, len(models.ExpenseCategories))
+	for i, c := range models.ExpenseCategories {
+		names[i] = string(c)
+	}
+	input.ShowSuggestions = true
+	input.SetSuggestions(names)
+	input.CompletionStyle = MutedStyle
+	return input
+}
+
+// hasCompletion reports whether input is showing an inline completion that
+// would add something, so Tab should complete rather than change field
+func hasCompletion(input *textinput.Model) bool {
+	completion := input.CurrentSuggestion()
+	return completion != "" && !strings.EqualFold(completion, input.Value())
+}
+
+// acceptSuggestion replaces input i with the first of matches, reporting
+// whether there was one to accept
+func (m *Model) acceptSuggestion(i int, matches []string) bool {
+	if len(matches) == 0 {
+		return false
+	}
+	m.inputs[i].SetValue(matches[0])
+	m.inputs[i].CursorEnd()
+	return true
+}